@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -18,7 +19,7 @@ func main() {
 
 func printConnectionState(c *octoprint.Client) {
 	r := octoprint.ConnectionRequest{}
-	s, err := r.Do(c)
+	s, err := r.Do(context.Background(), c)
 	if err != nil {
 		log.Error("error requesting connection state: %s", err)
 	}
@@ -28,13 +29,18 @@ func printConnectionState(c *octoprint.Client) {
 
 func printTemperature(c *octoprint.Client) {
 	r := octoprint.StateRequest{}
-	s, err := r.Do(c)
+	s, err := r.Do(context.Background(), c)
 	if err != nil {
 		log.Error("error requesting state: %s", err)
 	}
 
 	fmt.Println("Current Temperatures:")
 	for tool, state := range s.Temperature.Current {
-		fmt.Printf("- %s: %.1f°C / %.1f°C\n", tool, state.Actual, state.Target)
+		if state.Target == nil {
+			fmt.Printf("- %s: %.1f°C\n", tool, state.Actual)
+			continue
+		}
+
+		fmt.Printf("- %s: %.1f°C / %.1f°C\n", tool, state.Actual, *state.Target)
 	}
 }
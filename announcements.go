@@ -0,0 +1,68 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const URIAnnouncements = "/api/plugin/announcements"
+
+var AnnouncementsErrors = statusMapping{
+	400: "The channel is unknown or the command is invalid",
+	403: "The user is not allowed to read announcements",
+}
+
+// AnnouncementsRequest retrieves the announcement channels and their entries,
+// using the bundled announcements plugin.
+type AnnouncementsRequest struct {
+	// Force whether to refresh the channels, bypassing the cache of the
+	// server.
+	Force bool
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *AnnouncementsRequest) Do(ctx context.Context, c *Client) (map[string]*AnnouncementChannel, error) {
+	uri := URIAnnouncements
+	if cmd.Force {
+		uri = fmt.Sprintf("%s?force=true", uri)
+	}
+
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, AnnouncementsErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	var r map[string]*AnnouncementChannel
+	if err := c.decode(uri, b, &r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// AnnouncementsReadRequest marks the entries of a channel as read, up to the
+// given publication date.
+type AnnouncementsReadRequest struct {
+	// Channel is the identifier of the channel, e.g. `_important`.
+	Channel string `json:"channel"`
+	// Until is the publication date, as unix timestamp, up to which the
+	// entries are marked as read. Usually the date of the newest entry, see
+	// AnnouncementChannel.Latest.
+	Until int64 `json:"until"`
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *AnnouncementsReadRequest) Do(ctx context.Context, c *Client) error {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(struct {
+		Command string `json:"command"`
+		AnnouncementsReadRequest
+	}{"read", *cmd}); err != nil {
+		return err
+	}
+
+	_, err := c.doJSONRequest(ctx, "POST", URIAnnouncements, b, AnnouncementsErrors)
+	return err
+}
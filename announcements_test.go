@@ -0,0 +1,54 @@
+package octoprint
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnouncementsRequest_Do(t *testing.T) {
+	var query, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+			w.WriteHeader(204)
+			return
+		}
+
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"_important": {
+			"channel": "_important",
+			"name": "Important Announcements",
+			"priority": 1,
+			"enabled": true,
+			"forced": true,
+			"unread": 1,
+			"read_until": 1500000000,
+			"data": [
+				{"title": "<b>Security</b>", "title_without_tags": "Security", "published": 1600000000, "read": false},
+				{"title": "Old", "published": 1500000000, "read": true}
+			]
+		}}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	channels, err := (&AnnouncementsRequest{Force: true}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "force=true", query)
+
+	ch := channels["_important"]
+	assert.True(t, ch.Forced)
+	assert.Equal(t, 1, ch.Unread)
+	assert.Equal(t, "Security", ch.Data[0].TitleWithoutTags)
+	assert.Equal(t, int64(1600000000), ch.Latest())
+
+	err = (&AnnouncementsReadRequest{Channel: ch.Channel, Until: ch.Latest()}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"command": "read", "channel": "_important", "until": 1600000000}`, body)
+}
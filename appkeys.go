@@ -0,0 +1,154 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	URIAppKeysProbe   = "/plugin/appkeys/probe"
+	URIAppKeysRequest = "/plugin/appkeys/request"
+)
+
+var (
+	// ErrAppKeyDenied the application key request was denied by the user or
+	// timed out before being answered.
+	ErrAppKeyDenied = errors.New("Application key request denied or timed out")
+
+	AppKeysProbeErrors = statusMapping{
+		404: "The Application Keys plugin is not installed or disabled",
+	}
+	AppKeysPollErrors = statusMapping{
+		404: "The application key request was denied or has timed out",
+	}
+)
+
+// AppKeyProbeRequest checks if the Application Keys workflow is supported by
+// the server. Returns an error matching ErrNotFound if it is not.
+type AppKeyProbeRequest struct{}
+
+// Do sends an API request and returns an error if any.
+func (cmd *AppKeyProbeRequest) Do(ctx context.Context, c *Client) error {
+	_, err := c.doJSONRequest(ctx, "GET", URIAppKeysProbe, nil, AppKeysProbeErrors)
+	return err
+}
+
+// AppKeyRequest starts the authorization process for an application, the
+// user has to confirm it at the OctoPrint UI.
+type AppKeyRequest struct {
+	// App is the identifier of the application requesting access, it will be
+	// displayed to the user.
+	App string `json:"app"`
+	// User is the optional user to restrict the request to. If provided only
+	// this user will be able to confirm the request.
+	User string `json:"user,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *AppKeyRequest) Do(ctx context.Context, c *Client) (*AppKeyResponse, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	b2, err := c.doJSONRequest(ctx, "POST", URIAppKeysRequest, b, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &AppKeyResponse{}
+	if err := c.decode(URIAppKeysRequest, b2, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// AppKeyPollRequest polls the decision of the user about an AppKeyRequest.
+// Returns an error matching ErrNotFound when the request was denied or it timed
+// out.
+type AppKeyPollRequest struct {
+	// AppToken is the token returned by the AppKeyRequest.
+	AppToken string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *AppKeyPollRequest) Do(ctx context.Context, c *Client) (*AppKeyPollResponse, error) {
+	uri := fmt.Sprintf("%s/%s", URIAppKeysRequest, cmd.AppToken)
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, AppKeysPollErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &AppKeyPollResponse{}
+	if len(b) == 0 {
+		return r, nil
+	}
+
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// AppKeyAuthorizationRequest runs the complete Application Keys workflow:
+// probes the server, requests an application key and blocks until the user
+// grants or denies it at the OctoPrint UI.
+type AppKeyAuthorizationRequest struct {
+	// App is the identifier of the application requesting access, it will be
+	// displayed to the user.
+	App string
+	// User is the optional user to restrict the request to.
+	User string
+	// PollInterval is the interval between polls of the user decision,
+	// defaults to one second.
+	PollInterval time.Duration
+}
+
+// Do sends the API requests and returns the granted API key. ErrAppKeyDenied
+// is returned if the user denied the request or it timed out.
+func (cmd *AppKeyAuthorizationRequest) Do(ctx context.Context, c *Client) (string, error) {
+	if err := (&AppKeyProbeRequest{}).Do(ctx, c); err != nil {
+		return "", err
+	}
+
+	r, err := (&AppKeyRequest{App: cmd.App, User: cmd.User}).Do(ctx, c)
+	if err != nil {
+		return "", err
+	}
+
+	interval := cmd.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	poll := &AppKeyPollRequest{AppToken: r.AppToken}
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+
+		pr, err := poll.Do(ctx, c)
+		if errors.Is(err, ErrNotFound) {
+			return "", ErrAppKeyDenied
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if pr.APIKey != "" {
+			return pr.APIKey, nil
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package octoprint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAppKeysServer(grant bool) *httptest.Server {
+	var polls int
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case URIAppKeysProbe:
+			w.WriteHeader(204)
+		case URIAppKeysRequest:
+			w.WriteHeader(201)
+			w.Write([]byte(`{"app_token": "foo"}`))
+		case URIAppKeysRequest + "/foo":
+			polls++
+			switch {
+			case polls < 2:
+				w.WriteHeader(202)
+			case grant:
+				w.Write([]byte(`{"api_key": "bar"}`))
+			default:
+				w.WriteHeader(404)
+			}
+		default:
+			w.WriteHeader(500)
+		}
+	}))
+}
+
+func TestAppKeyAuthorizationRequest_Do(t *testing.T) {
+	ts := newAppKeysServer(true)
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r := &AppKeyAuthorizationRequest{App: "go-octoprint", PollInterval: time.Millisecond}
+	key, err := r.Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", key)
+}
+
+func TestAppKeyAuthorizationRequest_DoDenied(t *testing.T) {
+	ts := newAppKeysServer(false)
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r := &AppKeyAuthorizationRequest{App: "go-octoprint", PollInterval: time.Millisecond}
+	_, err := r.Do(context.Background(), cli)
+	assert.Equal(t, ErrAppKeyDenied, err)
+}
@@ -0,0 +1,150 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+)
+
+const (
+	URIBackups        = "/plugin/backup/backup"
+	URIBackupDownload = "/plugin/backup/download"
+	URIBackupRestore  = "/plugin/backup/restore"
+)
+
+var (
+	BackupsErrors = statusMapping{
+		403: "The user is not an admin",
+		404: "The backup does not exist",
+	}
+	BackupCreateErrors = statusMapping{
+		400: "A backup is already in progress",
+		403: "The user is not an admin",
+	}
+	BackupRestoreErrors = statusMapping{
+		400: "The backup is invalid or restoring is not supported on this system",
+		403: "The user is not an admin",
+	}
+)
+
+// BackupsRequest retrieves the list of backups available on the server, using
+// the bundled backup plugin.
+type BackupsRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *BackupsRequest) Do(ctx context.Context, c *Client) (*BackupsResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIBackups, nil, BackupsErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &BackupsResponse{}
+	if err := c.decode(URIBackups, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// BackupCreateRequest starts the creation of a new backup. The backup is
+// created asynchronously, BackupsResponse.InProgress reports whether it's
+// still being created.
+type BackupCreateRequest struct {
+	// Exclude are the parts to exclude from the backup, any of `config`,
+	// `uploads` and `timelapse`.
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *BackupCreateRequest) Do(ctx context.Context, c *Client) (*BackupCreateResponse, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	b2, err := c.doJSONRequest(ctx, "POST", URIBackups, b, BackupCreateErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &BackupCreateResponse{}
+	if err := c.decode(URIBackups, b2, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// BackupDownloadRequest downloads a backup, streaming its content to the given
+// writer.
+type BackupDownloadRequest struct {
+	// Filename of the backup to download.
+	Filename string
+	// Progress is called while downloading the backup. Optional.
+	Progress ProgressFunc
+}
+
+// Do sends an API request writing the content of the backup to w, and returns
+// the amount of bytes written, even on error.
+func (cmd *BackupDownloadRequest) Do(ctx context.Context, c *Client, w io.Writer) (int64, error) {
+	uri := fmt.Sprintf("%s/%s", URIBackupDownload, url.PathEscape(cmd.Filename))
+	return c.download(ctx, uri, BackupsErrors, w, cmd.Progress)
+}
+
+// BackupDeleteRequest deletes a backup.
+type BackupDeleteRequest struct {
+	// Filename of the backup to delete.
+	Filename string
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *BackupDeleteRequest) Do(ctx context.Context, c *Client) error {
+	uri := fmt.Sprintf("%s/%s", URIBackups, url.PathEscape(cmd.Filename))
+	_, err := c.doJSONRequest(ctx, "DELETE", uri, nil, BackupsErrors)
+	return err
+}
+
+// BackupRestoreRequest restores a backup uploaded from the given reader, its
+// content is streamed without buffering it in memory. The server restarts
+// once the backup is restored.
+type BackupRestoreRequest struct {
+	// Filename of the backup being uploaded.
+	Filename string
+	// Backup is the content of the backup.
+	Backup io.Reader
+	// Progress is called while uploading the backup. Optional.
+	Progress ProgressFunc
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *BackupRestoreRequest) Do(ctx context.Context, c *Client) error {
+	pr, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(cmd.writeMultipart(w))
+	}()
+
+	defer pr.Close()
+
+	ctx = WithTimeoutClass(ctx, TransferClass)
+	_, err := c.doRequest(ctx, "POST", URIBackupRestore, w.FormDataContentType(), pr, BackupRestoreErrors)
+	return err
+}
+
+func (cmd *BackupRestoreRequest) writeMultipart(w *multipart.Writer) error {
+	fw, err := w.CreateFormFile("file", cmd.Filename)
+	if err != nil {
+		return err
+	}
+
+	r := newProgressReader(cmd.Backup, readerSize(cmd.Backup), cmd.Progress)
+	if _, err := io.Copy(fw, r); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
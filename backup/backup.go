@@ -0,0 +1,287 @@
+// Package backup creates periodic backups of OctoPrint, using the bundled
+// backup plugin, storing them in a Sink and pruning the old ones.
+//
+//	m := backup.New(c, backup.NewDirSink("/srv/backups"))
+//	m.Interval = 24 * time.Hour
+//	m.Retention = backup.Retention{Keep: 7}
+//	err := m.Run(ctx)
+//
+// The retention policy is applied to the backups stored in OctoPrint, and to
+// the ones in the Sink if it implements Pruner.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+// DefaultInterval is the default interval between backups.
+const DefaultInterval = 24 * time.Hour
+
+// ErrNotStarted is returned when OctoPrint doesn't start the creation of the
+// backup.
+var ErrNotStarted = errors.New("The creation of the backup was not started")
+
+// Sink stores the backups.
+type Sink interface {
+	// Create returns a writer storing the backup with the given name. The
+	// backup is only stored if Close returns nil, and must be discarded if
+	// the writer is closed after a failed write, reported by Abort.
+	Create(ctx context.Context, name string) (Writer, error)
+}
+
+// Writer writes a backup to a Sink.
+type Writer interface {
+	io.WriteCloser
+	// Abort discards the backup being written.
+	Abort() error
+}
+
+// Pruner is implemented by the sinks able to prune the stored backups.
+type Pruner interface {
+	// Prune deletes the backups expired by the retention policy, returning
+	// their names.
+	Prune(ctx context.Context, r Retention) ([]string, error)
+}
+
+// Retention is the retention policy of the backups. The most recent backup is
+// always kept.
+type Retention struct {
+	// Keep is the amount of backups to keep, all of them if zero.
+	Keep int
+	// MaxAge is the maximum age of the backups kept, no limit if zero.
+	MaxAge time.Duration
+}
+
+// Expired returns the indexes of the expired backups, given their creation
+// dates.
+func (r Retention) Expired(now time.Time, dates []time.Time) []int {
+	idx := make([]int, len(dates))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	sort.SliceStable(idx, func(i, j int) bool {
+		return dates[idx[i]].After(dates[idx[j]])
+	})
+
+	var expired []int
+	for n, i := range idx {
+		if n == 0 {
+			continue
+		}
+
+		if (r.Keep > 0 && n >= r.Keep) || (r.MaxAge > 0 && now.Sub(dates[i]) > r.MaxAge) {
+			expired = append(expired, i)
+		}
+	}
+
+	sort.Ints(expired)
+	return expired
+}
+
+// Manager creates periodic backups.
+type Manager struct {
+	// Client is the client of the OctoPrint server.
+	Client *octoprint.Client
+	// Sink stores the backups, if nil the backups are only kept in
+	// OctoPrint.
+	Sink Sink
+	// Interval between backups, zero means DefaultInterval.
+	Interval time.Duration
+	// Exclude are the parts to exclude from the backups, any of `config`,
+	// `uploads` and `timelapse`.
+	Exclude []string
+	// Retention is the retention policy of the backups.
+	Retention Retention
+	// PollInterval is the interval between polls while the backup is being
+	// created, zero means octoprint.DefaultWatchInterval.
+	PollInterval time.Duration
+	// OnBackup is called for every backup created and stored.
+	OnBackup func(b *octoprint.Backup)
+	// OnError is called with the errors creating or pruning the backups, Run
+	// keeps running.
+	OnError func(err error)
+}
+
+// New returns a Manager storing the backups in sink.
+func New(c *octoprint.Client, sink Sink) *Manager {
+	return &Manager{Client: c, Sink: sink}
+}
+
+// Run creates a backup every Interval, until ctx is done, returning ctx.Err().
+// The first backup is created an Interval after the most recent backup in
+// OctoPrint, immediately if there is none, so restarts don't add backups.
+func (m *Manager) Run(ctx context.Context) error {
+	next := time.Now()
+	if r, err := (&octoprint.BackupsRequest{}).Do(ctx, m.Client); err != nil {
+		m.error(err)
+	} else if last := latest(r.Backups); last != nil {
+		next = last.Date.Add(m.interval())
+	}
+
+	for {
+		t := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+
+		if _, err := m.Backup(ctx); err != nil && ctx.Err() == nil {
+			m.error(err)
+		}
+
+		if _, err := m.Prune(ctx); err != nil && ctx.Err() == nil {
+			m.error(err)
+		}
+
+		next = time.Now().Add(m.interval())
+	}
+}
+
+// Backup creates a backup, waiting for OctoPrint to finish it, and stores it in
+// the Sink.
+func (m *Manager) Backup(ctx context.Context) (*octoprint.Backup, error) {
+	r, err := (&octoprint.BackupCreateRequest{Exclude: m.Exclude}).Do(ctx, m.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.Started {
+		return nil, ErrNotStarted
+	}
+
+	b, err := m.wait(ctx, r.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Sink != nil {
+		if err := m.store(ctx, b); err != nil {
+			return nil, fmt.Errorf("error storing backup %q: %w", b.Name, err)
+		}
+	}
+
+	if m.OnBackup != nil {
+		m.OnBackup(b)
+	}
+
+	return b, nil
+}
+
+// wait waits for the backup to be created.
+func (m *Manager) wait(ctx context.Context, name string) (*octoprint.Backup, error) {
+	t := time.NewTicker(m.pollInterval())
+	defer t.Stop()
+
+	for {
+		r, err := (&octoprint.BackupsRequest{}).Do(ctx, m.Client)
+		if err != nil {
+			return nil, err
+		}
+
+		if !r.InProgress {
+			for _, b := range r.Backups {
+				if b.Name == name {
+					return b, nil
+				}
+			}
+
+			return nil, fmt.Errorf("backup %q not found, its creation failed", name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (m *Manager) store(ctx context.Context, b *octoprint.Backup) error {
+	w, err := m.Sink.Create(ctx, b.Name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := (&octoprint.BackupDownloadRequest{Filename: b.Name}).Do(ctx, m.Client, w); err != nil {
+		w.Abort()
+		return err
+	}
+
+	return w.Close()
+}
+
+// Prune deletes the backups expired by the retention policy, from OctoPrint
+// and from the Sink if it implements Pruner, returning the names of the
+// backups deleted from OctoPrint.
+func (m *Manager) Prune(ctx context.Context) ([]string, error) {
+	if p, ok := m.Sink.(Pruner); ok {
+		if _, err := p.Prune(ctx, m.Retention); err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := (&octoprint.BackupsRequest{}).Do(ctx, m.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]time.Time, len(r.Backups))
+	for i, b := range r.Backups {
+		dates[i] = b.Date.Time
+	}
+
+	var deleted []string
+	for _, i := range m.Retention.Expired(time.Now(), dates) {
+		name := r.Backups[i].Name
+		if err := (&octoprint.BackupDeleteRequest{Filename: name}).Do(ctx, m.Client); err != nil {
+			return deleted, err
+		}
+
+		deleted = append(deleted, name)
+	}
+
+	return deleted, nil
+}
+
+func (m *Manager) interval() time.Duration {
+	if m.Interval <= 0 {
+		return DefaultInterval
+	}
+
+	return m.Interval
+}
+
+func (m *Manager) pollInterval() time.Duration {
+	if m.PollInterval <= 0 {
+		return octoprint.DefaultWatchInterval
+	}
+
+	return m.PollInterval
+}
+
+func (m *Manager) error(err error) {
+	if m.OnError != nil {
+		m.OnError(err)
+	}
+}
+
+func latest(backups []*octoprint.Backup) *octoprint.Backup {
+	var last *octoprint.Backup
+	for _, b := range backups {
+		if last == nil || b.Date.After(last.Date.Time) {
+			last = b
+		}
+	}
+
+	return last
+}
@@ -0,0 +1,176 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/stretchr/testify/assert"
+)
+
+// server is a fake backup plugin, creating the backups after one poll.
+type server struct {
+	sync.Mutex
+	backups  []string
+	creating string
+	deleted  []string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	switch {
+	case r.Method == "POST" && r.URL.Path == octoprint.URIBackups:
+		s.creating = fmt.Sprintf("backup-%d.zip", len(s.backups)+1)
+		fmt.Fprintf(w, `{"started": true, "name": %q}`, s.creating)
+	case r.Method == "GET" && r.URL.Path == octoprint.URIBackups:
+		inProgress := s.creating != ""
+		if inProgress {
+			s.backups = append(s.backups, s.creating)
+			s.creating = ""
+		}
+
+		var backups []string
+		for i, name := range s.backups {
+			backups = append(backups, fmt.Sprintf(`{"name": %q, "date": %d}`, name, 1600000000+i*3600))
+		}
+
+		fmt.Fprintf(w, `{"backup_in_progress": %t, "backups": [%s]}`, inProgress, strings.Join(backups, ","))
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, octoprint.URIBackupDownload+"/"):
+		w.Write([]byte(strings.TrimPrefix(r.URL.Path, octoprint.URIBackupDownload+"/")))
+	case r.Method == "DELETE":
+		name := strings.TrimPrefix(r.URL.Path, octoprint.URIBackups+"/")
+		s.deleted = append(s.deleted, name)
+		for i, b := range s.backups {
+			if b == name {
+				s.backups = append(s.backups[:i], s.backups[i+1:]...)
+				break
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestManager_Backup(t *testing.T) {
+	srv := &server{backups: []string{"old-1.zip", "old-2.zip"}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "backup")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var stored []string
+	m := New(octoprint.NewClient(ts.URL, ""), NewDirSink(dir))
+	m.PollInterval = time.Millisecond
+	m.Retention = Retention{Keep: 2}
+	m.OnBackup = func(b *octoprint.Backup) {
+		stored = append(stored, b.Name)
+	}
+
+	b, err := m.Backup(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, "backup-3.zip", b.Name)
+	assert.Equal(t, []string{"backup-3.zip"}, stored)
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "backup-3.zip"))
+	assert.NoError(t, err)
+	assert.Equal(t, "backup-3.zip", string(content))
+
+	deleted, err := m.Prune(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old-1.zip"}, deleted)
+	assert.Equal(t, []string{"old-2.zip", "backup-3.zip"}, srv.backups)
+}
+
+func TestManager_Run(t *testing.T) {
+	srv := &server{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	done := make(chan string, 1)
+	m := New(octoprint.NewClient(ts.URL, ""), nil)
+	m.PollInterval = time.Millisecond
+	m.OnBackup = func(b *octoprint.Backup) {
+		done <- b.Name
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- m.Run(ctx) }()
+
+	// without backups the first one is created immediately.
+	select {
+	case name := <-done:
+		assert.Equal(t, "backup-1.zip", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("backup not created")
+	}
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-errs)
+}
+
+func TestRetention_Expired(t *testing.T) {
+	now := time.Now()
+	dates := []time.Time{
+		now.Add(-3 * time.Hour),
+		now.Add(-1 * time.Hour),
+		now.Add(-48 * time.Hour),
+		now.Add(-2 * time.Hour),
+	}
+
+	assert.Nil(t, Retention{}.Expired(now, dates))
+	assert.Equal(t, []int{0, 2}, Retention{Keep: 2}.Expired(now, dates))
+	assert.Equal(t, []int{2}, Retention{MaxAge: 24 * time.Hour}.Expired(now, dates))
+	assert.Equal(t, []int{0, 2, 3}, Retention{Keep: 3, MaxAge: 90 * time.Minute}.Expired(now, dates))
+
+	// the most recent backup is always kept.
+	assert.Nil(t, Retention{MaxAge: time.Minute}.Expired(now, dates[1:2]))
+}
+
+func TestDirSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "backup")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := NewDirSink(filepath.Join(dir, "backups"))
+	for i, name := range []string{"a.zip", "b.zip", "c.zip"} {
+		w, err := s.Create(context.Background(), name)
+		assert.NoError(t, err)
+		w.Write([]byte(name))
+		assert.NoError(t, w.Close())
+
+		mtime := time.Now().Add(time.Duration(i-3) * time.Hour)
+		assert.NoError(t, os.Chtimes(filepath.Join(s.Dir, name), mtime, mtime))
+	}
+
+	w, err := s.Create(context.Background(), "d.zip")
+	assert.NoError(t, err)
+	assert.NoError(t, w.Abort())
+
+	_, err = s.Create(context.Background(), "../e.zip")
+	assert.Error(t, err)
+
+	deleted, err := s.Prune(context.Background(), Retention{Keep: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.zip", "b.zip"}, deleted)
+
+	infos, err := ioutil.ReadDir(s.Dir)
+	assert.NoError(t, err)
+	assert.Len(t, infos, 1)
+	assert.Equal(t, "c.zip", infos[0].Name())
+}
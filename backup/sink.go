@@ -0,0 +1,101 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// partialSuffix is the suffix of the backups being written by a DirSink.
+const partialSuffix = ".part"
+
+// DirSink is a Sink storing the backups in a local directory, or any mounted
+// remote storage. It implements Pruner, by the modification time of the
+// files.
+type DirSink struct {
+	// Dir is the directory of the backups, created if it doesn't exist.
+	Dir string
+}
+
+// NewDirSink returns a DirSink storing the backups in dir.
+func NewDirSink(dir string) *DirSink {
+	return &DirSink{Dir: dir}
+}
+
+// Create returns a writer to a temporary file, renamed to the name of the
+// backup on Close.
+func (s *DirSink) Create(ctx context.Context, name string) (Writer, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid backup name %q", name)
+	}
+
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(s.Dir, name)
+	f, err := os.Create(path + partialSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileWriter{File: f, path: path}, nil
+}
+
+// Prune deletes the expired backups, ignoring the files being written.
+func (s *DirSink) Prune(ctx context.Context, r Retention) ([]string, error) {
+	infos, err := ioutil.ReadDir(s.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var files []os.FileInfo
+	var dates []time.Time
+	for _, fi := range infos {
+		if !fi.Mode().IsRegular() || strings.HasSuffix(fi.Name(), partialSuffix) ||
+			strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+
+		files = append(files, fi)
+		dates = append(dates, fi.ModTime())
+	}
+
+	var deleted []string
+	for _, i := range r.Expired(time.Now(), dates) {
+		if err := os.Remove(filepath.Join(s.Dir, files[i].Name())); err != nil {
+			return deleted, err
+		}
+
+		deleted = append(deleted, files[i].Name())
+	}
+
+	return deleted, nil
+}
+
+type fileWriter struct {
+	*os.File
+	path string
+}
+
+func (w *fileWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		os.Remove(w.File.Name())
+		return err
+	}
+
+	return os.Rename(w.File.Name(), w.path)
+}
+
+func (w *fileWriter) Abort() error {
+	w.File.Close()
+	return os.Remove(w.File.Name())
+}
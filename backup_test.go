@@ -0,0 +1,95 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupRequests_Do(t *testing.T) {
+	var method, path, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(b)
+		switch {
+		case r.Method == "GET" && r.URL.Path == URIBackups:
+			w.Write([]byte(`{
+				"backups": [{
+					"name": "octoprint-backup-20190101-120000.zip",
+					"date": 1546344000,
+					"size": 1024,
+					"url": "/plugin/backup/download/octoprint-backup-20190101-120000.zip"
+				}],
+				"backup_in_progress": false,
+				"restore_supported": true,
+				"unknown_plugins": []
+			}`))
+		case r.Method == "POST":
+			w.WriteHeader(201)
+			w.Write([]byte(`{"started": true, "name": "octoprint-backup-20190102-120000.zip"}`))
+		case r.Method == "GET":
+			w.Write([]byte("zip"))
+		default:
+			w.WriteHeader(204)
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	cli := NewClient(ts.URL, "")
+
+	r, err := (&BackupsRequest{}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Len(t, r.Backups, 1)
+	assert.Equal(t, uint64(1024), r.Backups[0].Size)
+	assert.True(t, r.RestoreSupported)
+
+	created, err := (&BackupCreateRequest{Exclude: []string{"timelapse"}}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.True(t, created.Started)
+	assert.JSONEq(t, `{"exclude": ["timelapse"]}`, body)
+
+	buf := bytes.NewBuffer(nil)
+	_, err = (&BackupDownloadRequest{Filename: created.Name}).Do(ctx, cli, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, URIBackupDownload+"/"+created.Name, path)
+	assert.Equal(t, "zip", buf.String())
+
+	err = (&BackupDeleteRequest{Filename: created.Name}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE", method)
+	assert.Equal(t, URIBackups+"/"+created.Name, path)
+}
+
+func TestBackupRestoreRequest_Do(t *testing.T) {
+	var filename, content string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+
+		b, _ := ioutil.ReadAll(f)
+		filename, content = h.Filename, string(b)
+		w.Write([]byte(`{"started": true}`))
+	}))
+	defer ts.Close()
+
+	var transferred int64
+	cli := NewClient(ts.URL, "")
+	err := (&BackupRestoreRequest{
+		Filename: "backup.zip",
+		Backup:   bytes.NewBufferString("zip content"),
+		Progress: func(n, _ int64) { transferred = n },
+	}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "backup.zip", filename)
+	assert.Equal(t, "zip content", content)
+	assert.Equal(t, int64(11), transferred)
+}
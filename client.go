@@ -1,16 +1,120 @@
 package octoprint
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 )
 
-// ErrUnauthorized missing or invalid API key
-var ErrUnauthorized = errors.New("Missing or invalid API key")
+var (
+	// ErrBadRequest the request was invalid, e.g. unknown command or invalid
+	// parameters (400).
+	ErrBadRequest = errors.New("Bad request")
+	// ErrUnauthorized missing or invalid API key (401).
+	ErrUnauthorized = errors.New("Missing or invalid API key")
+	// ErrForbidden the API key or user lacks the rights for the request (403).
+	ErrForbidden = errors.New("Insufficient rights")
+	// ErrNotFound the requested resource does not exist (404).
+	ErrNotFound = errors.New("Not found")
+	// ErrConflict the request conflicts with the current state of the printer,
+	// e.g. the printer is not operational or a file is being printed (409).
+	ErrConflict = errors.New("Conflict with the current state")
+	// ErrUnsupportedMediaType the uploaded file type is not supported (415).
+	ErrUnsupportedMediaType = errors.New("Unsupported media type")
+	// ErrInternalServer OctoPrint failed internally while handling the
+	// request (500).
+	ErrInternalServer = errors.New("Internal server error")
+	// ErrServiceUnavailable OctoPrint, or a proxy in front of it, is
+	// temporarily unable to handle the request (502, 503 and 504).
+	ErrServiceUnavailable = errors.New("Service unavailable")
+)
+
+// APIError is returned when OctoPrint answers with a non successful status
+// code. It can be matched against the sentinel errors (ErrConflict,
+// ErrNotFound, ...) using errors.Is.
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Message is the `error` field of the JSON error body sent by OctoPrint,
+	// empty if none was provided.
+	Message string
+	// Description is the meaning of the status code for the issued request,
+	// as documented by the OctoPrint REST API.
+	Description string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Description
+	if msg == "" {
+		msg = fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	}
+
+	if e.Message != "" && e.Message != msg {
+		msg = fmt.Sprintf("%s: %s", msg, e.Message)
+	}
+
+	return msg
+}
+
+// Unwrap returns the sentinel error matching the status code, if any.
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case 400:
+		return ErrBadRequest
+	case 401:
+		return ErrUnauthorized
+	case 403:
+		return ErrForbidden
+	case 404:
+		return ErrNotFound
+	case 409:
+		return ErrConflict
+	case 415:
+		return ErrUnsupportedMediaType
+	case 500:
+		return ErrInternalServer
+	case 502, 503, 504:
+		return ErrServiceUnavailable
+	}
+
+	return nil
+}
+
+// decodeErrorExcerpt is the maximum length of the body included in a
+// DecodeError.
+const decodeErrorExcerpt = 512
+
+// DecodeError is returned when the body of a successful response can't be
+// decoded, e.g. due to an unexpected schema or unknown fields in strict mode.
+type DecodeError struct {
+	// Endpoint is the URI of the request, relative to the client Endpoint.
+	Endpoint string
+	// Type is the Go type the body was decoded into.
+	Type string
+	// Body is an excerpt of the body of the response.
+	Body string
+	// Err is the error returned by the JSON decoder.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("error decoding response of %s into %s: %s, body: %q",
+		e.Endpoint, e.Type, e.Err, e.Body,
+	)
+}
+
+// Unwrap returns the error returned by the JSON decoder.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
 
 // A Client manages communication with the OctoPrint API.
 type Client struct {
@@ -19,15 +123,79 @@ type Client struct {
 	// APIKey used to connect to the OctoPrint REST API server.
 	APIKey string
 
-	c *http.Client
+	c           *http.Client
+	retry       *RetryPolicy
+	middlewares []Middleware
+	log         Logger
+	limiter     *rateLimiter
+	timeouts    Timeouts
+	basicAuth   *url.Userinfo
+	headers     http.Header
+	strict      bool
+
+	mu      sync.Mutex
+	user    string
+	session string
+}
+
+// Option configures optional behaviour of a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the http.Client used to talk to the OctoPrint server,
+// allowing to configure TLS, proxies, timeouts or instrumentation. By default a
+// client without keep-alives is used.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.c = hc
+	}
+}
+
+// WithBasicAuth sets HTTP Basic credentials sent with every request, along the
+// API key, e.g. for OctoPrint instances behind an authenticating reverse proxy.
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) {
+		c.basicAuth = url.UserPassword(username, password)
+	}
+}
+
+// WithHeader adds a static header sent with every request, e.g.
+// `X-Forwarded-User` for instances relying on reverse proxy authentication.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = make(http.Header)
+		}
+
+		c.headers.Add(key, value)
+	}
+}
+
+// WithStrictDecoding makes the client fail decoding responses with fields not
+// present in the response types, to catch schema changes of OctoPrint or its
+// plugins, e.g. in CI. By default unknown fields are ignored. Types with their
+// own decoding, such as FileInformation, are not checked.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strict = true
+	}
+}
+
+// WithTransport sets the http.RoundTripper used by the underlying
+// http.Client, keeping the rest of its configuration.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		hc := *c.c
+		hc.Transport = rt
+		c.c = &hc
+	}
 }
 
 // NewClient returns a new OctoPrint API client with provided base URL and API
 // Key. If baseURL does not have a trailing slash, one is added automatically. If
 // `Access Control` is enabled at OctoPrint configuration an apiKey should be
 // provided (http://docs.octoprint.org/en/master/api/general.html#authorization).
-func NewClient(endpoint, apiKey string) *Client {
-	return &Client{
+func NewClient(endpoint, apiKey string, opts ...Option) *Client {
+	c := &Client{
 		Endpoint: endpoint,
 		APIKey:   apiKey,
 		c: &http.Client{
@@ -35,52 +203,153 @@ func NewClient(endpoint, apiKey string) *Client {
 				DisableKeepAlives: true,
 			},
 		},
+		log: nopLogger{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	return c
 }
 
 func (c *Client) doJSONRequest(
-	method, target string, body io.Reader, m statusMapping,
+	ctx context.Context, method, target string, body io.Reader, m statusMapping,
 ) ([]byte, error) {
-	return c.doRequest(method, target, "application/json", body, m)
+	return c.doRequest(ctx, method, target, "application/json", body, m)
 }
 
 func (c *Client) doRequest(
-	method, target, contentType string, body io.Reader, m statusMapping,
+	ctx context.Context, method, target, contentType string, body io.Reader, m statusMapping,
 ) ([]byte, error) {
-	req, err := http.NewRequest(method, joinURL(c.Endpoint, target), body)
+	h := make(http.Header)
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+
+	var b []byte
+	err := c.send(ctx, method, target, body, h, func(resp *http.Response) (err error) {
+		b, err = c.handleResponse(resp, m)
+		return err
+	})
+
+	return b, err
+}
+
+// doStreamRequest sends a request without body and calls fn with the response
+// if successful, allowing its body to be streamed instead of being buffered.
+// Errors returned by fn are never retried, since part of the body may have
+// already been consumed.
+func (c *Client) doStreamRequest(
+	ctx context.Context, method, target string, h http.Header, m statusMapping,
+	fn func(*http.Response) error,
+) error {
+	err := c.send(ctx, method, target, nil, h, func(resp *http.Response) error {
+		if resp.StatusCode < 200 || resp.StatusCode > 209 {
+			_, err := c.handleResponse(resp, m)
+			return err
+		}
+
+		defer resp.Body.Close()
+		if err := fn(resp); err != nil {
+			return &streamError{err}
+		}
+
+		return nil
+	})
+
+	if se, ok := err.(*streamError); ok {
+		return se.err
+	}
+
+	return err
+}
+
+// streamError is an error produced while consuming the body of a response.
+type streamError struct {
+	err error
+}
+
+func (e *streamError) Error() string { return e.err.Error() }
+func (e *streamError) Unwrap() error { return e.err }
+
+// send sends a request to OctoPrint, applying the retry policy, the rate
+// limit and the timeouts, and calls handle with each response received.
+func (c *Client) send(
+	ctx context.Context, method, target string, body io.Reader, h http.Header,
+	handle func(*http.Response) error,
+) error {
+	req, err := http.NewRequestWithContext(ctx, method, joinURL(c.Endpoint, target), body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	req.Header.Add("Host", "localhost:5000")
 	req.Header.Add("Accept", "*/*")
 	req.Header.Add("User-Agent", fmt.Sprintf("go-octoprint/%s", Version))
-	if contentType != "" {
-		req.Header.Add("Content-Type", contentType)
+	for k, v := range h {
+		req.Header[k] = v
 	}
 
-	req.Header.Add("X-Api-Key", c.APIKey)
+	c.authorize(req)
 
-	resp, err := c.c.Do(req)
-	if err != nil {
-		return nil, err
+	meta, _ := ResponseMetaFromContext(ctx)
+	if meta != nil {
+		*meta = ResponseMeta{}
 	}
 
-	return c.handleResponse(resp, m)
-}
+	rt := c.roundTrip()
+	return c.retry.do(req, c.log, func(req *http.Request) error {
+		if err := c.limiter.Wait(req.Context()); err != nil {
+			return err
+		}
 
-func (c *Client) handleResponse(r *http.Response, m statusMapping) ([]byte, error) {
-	defer r.Body.Close()
+		if meta != nil {
+			meta.Attempts++
+		}
+
+		req, cancel := c.timeouts.withTimeout(req)
+		defer cancel()
+
+		uri, start := redactURL(req.URL), time.Now()
+		c.log.Debug("sending request", "method", req.Method, "url", uri)
 
-	if m != nil {
-		if err := m.Error(r.StatusCode); err != nil {
-			return nil, err
+		resp, err := rt(req)
+		if err != nil {
+			c.log.Debug("request failed", "method", req.Method, "url", uri,
+				"duration", time.Since(start), "error", err)
+			return err
 		}
+
+		err = handle(resp)
+		if meta != nil {
+			meta.record(req, resp, time.Since(start))
+		}
+
+		c.log.Debug("request finished", "method", req.Method, "url", uri,
+			"status", resp.StatusCode, "duration", time.Since(start))
+		return err
+	})
+}
+
+// authorize adds the static headers and the credentials to the request.
+func (c *Client) authorize(req *http.Request) {
+	for k, v := range c.headers {
+		req.Header[k] = append(req.Header[k], v...)
+	}
+
+	if c.basicAuth != nil {
+		password, _ := c.basicAuth.Password()
+		req.SetBasicAuth(c.basicAuth.Username(), password)
 	}
 
-	if r.StatusCode == 401 {
-		return nil, ErrUnauthorized
+	if c.APIKey != "" {
+		req.Header.Set("X-Api-Key", c.APIKey)
 	}
+}
+
+func (c *Client) handleResponse(r *http.Response, m statusMapping) ([]byte, error) {
+	defer r.Body.Close()
 
 	if r.StatusCode == 204 {
 		return nil, nil
@@ -95,7 +364,46 @@ func (c *Client) handleResponse(r *http.Response, m statusMapping) ([]byte, erro
 		return body, nil
 	}
 
-	return nil, fmt.Errorf("unexpected status code: %d", r.StatusCode)
+	return nil, newAPIError(r.StatusCode, body, m)
+}
+
+// decode decodes the body of a response of uri into v, logging the failures,
+// returned as a DecodeError.
+func (c *Client) decode(uri string, b []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(b))
+	if c.strict {
+		d.DisallowUnknownFields()
+	}
+
+	if err := d.Decode(v); err != nil {
+		c.log.Warn("failed to decode response", "type", fmt.Sprintf("%T", v), "url", uri, "error", err)
+
+		excerpt := b
+		if len(excerpt) > decodeErrorExcerpt {
+			excerpt = excerpt[:decodeErrorExcerpt]
+		}
+
+		return &DecodeError{Endpoint: uri, Type: fmt.Sprintf("%T", v), Body: string(excerpt), Err: err}
+	}
+
+	return nil
+}
+
+func newAPIError(code int, body []byte, m statusMapping) *APIError {
+	e := &APIError{StatusCode: code, Description: m.description(code)}
+	if e.Description == "" && code == 401 {
+		e.Description = ErrUnauthorized.Error()
+	}
+
+	var payload struct {
+		Error string `json:"error"`
+	}
+
+	if err := json.Unmarshal(body, &payload); err == nil {
+		e.Message = payload.Error
+	}
+
+	return e
 }
 
 func joinURL(base, uri string) string {
@@ -106,11 +414,6 @@ func joinURL(base, uri string) string {
 
 type statusMapping map[int]string
 
-func (m *statusMapping) Error(code int) error {
-	err, ok := (*m)[code]
-	if ok {
-		return fmt.Errorf(err)
-	}
-
-	return nil
+func (m statusMapping) description(code int) string {
+	return m[code]
 }
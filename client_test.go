@@ -0,0 +1,128 @@
+package octoprint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_APIError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(409)
+		w.Write([]byte(`{"error": "Printer is not operational"}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	err := (&StartRequest{}).Do(context.Background(), cli)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrConflict))
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 409, apiErr.StatusCode)
+	assert.Equal(t, "Printer is not operational", apiErr.Message)
+	assert.Equal(t, StartErrors[409], apiErr.Description)
+}
+
+func TestClient_APIErrorUnauthorized(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(401)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	_, err := (&VersionRequest{}).Do(context.Background(), cli)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+	assert.Equal(t, ErrUnauthorized.Error(), err.Error())
+}
+
+type countingTransport struct {
+	calls int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.calls++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestWithTransport(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"api": "0.1", "server": "1.3.9"}`))
+	}))
+	defer ts.Close()
+
+	rt := &countingTransport{}
+	cli := NewClient(ts.URL, "", WithTransport(rt))
+	_, err := (&VersionRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rt.calls)
+
+	rt = &countingTransport{}
+	cli = NewClient(ts.URL, "", WithHTTPClient(&http.Client{Transport: rt}))
+	_, err = (&VersionRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rt.calls)
+}
+
+func TestWithBasicAuthAndHeader(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "foo" || pass != "bar" {
+			w.WriteHeader(401)
+			return
+		}
+
+		if r.Header.Get("X-Api-Key") != "key" || r.Header.Get("X-Forwarded-User") != "qux" {
+			w.WriteHeader(403)
+			return
+		}
+
+		w.Write([]byte(`{"api": "0.1", "server": "1.3.9"}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "key",
+		WithBasicAuth("foo", "bar"),
+		WithHeader("X-Forwarded-User", "qux"),
+	)
+
+	_, err := (&VersionRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+}
+
+func TestClient_DecodeError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"api": 1, "server": "1.9.0"}`))
+	}))
+	defer ts.Close()
+
+	_, err := (&VersionRequest{}).Do(context.Background(), NewClient(ts.URL, ""))
+	assert.Error(t, err)
+
+	var decodeErr *DecodeError
+	assert.True(t, errors.As(err, &decodeErr))
+	assert.Equal(t, URIVersion, decodeErr.Endpoint)
+	assert.Equal(t, "*octoprint.VersionResponse", decodeErr.Type)
+	assert.Equal(t, `{"api": 1, "server": "1.9.0"}`, decodeErr.Body)
+	assert.Contains(t, err.Error(), URIVersion)
+}
+
+func TestWithStrictDecoding(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"api": "0.1", "server": "1.9.0", "text": "OctoPrint 1.9.0", "plugin": true}`))
+	}))
+	defer ts.Close()
+
+	_, err := (&VersionRequest{}).Do(context.Background(), NewClient(ts.URL, ""))
+	assert.NoError(t, err)
+
+	_, err = (&VersionRequest{}).Do(context.Background(), NewClient(ts.URL, "", WithStrictDecoding()))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown field "plugin"`)
+}
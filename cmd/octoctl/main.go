@@ -0,0 +1,436 @@
+// Command octoctl is a command-line client for OctoPrint, built on top of
+// go-octoprint.
+//
+//	octoctl [flags] <command> [arguments]
+//
+// The server and the API key are taken from the -url and -key flags, or the
+// OCTOPRINT_URL and OCTOPRINT_API_KEY environment variables. With -json the
+// responses are written as JSON, suitable for scripts.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+const usage = `Usage: octoctl [flags] <command> [arguments]
+
+Commands:
+  status                           printer, job and temperature state
+  job start|pause|resume|cancel    control the current job
+  files list [-location] [-r]      list the files
+  files upload [-location] [-path] [-select] [-print] <file>...
+  files select [-location] [-print] <path>
+  temp set <tool>=<target>...      set temperatures, e.g. tool0=210 bed=60
+  gcode <command>...               send G-code commands to the printer
+  connection status|disconnect     state of the connection to the printer
+  connection connect [-port] [-baudrate] [-profile]
+
+Flags:
+`
+
+// errUsage is returned on invalid command lines, printing the usage.
+var errUsage = errors.New("invalid command line")
+
+func main() {
+	err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr)
+	switch {
+	case err == errUsage:
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintln(os.Stderr, "octoctl:", err)
+		os.Exit(1)
+	}
+}
+
+// cli is a parsed command line.
+type cli struct {
+	c    *octoprint.Client
+	out  io.Writer
+	json bool
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("octoctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	endpoint := fs.String("url", os.Getenv("OCTOPRINT_URL"), "URL of the OctoPrint server")
+	key := fs.String("key", os.Getenv("OCTOPRINT_API_KEY"), "API key")
+	asJSON := fs.Bool("json", false, "write the responses as JSON")
+	timeout := fs.Duration("timeout", 0, "timeout of the command, e.g. 30s")
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+
+		return errUsage
+	}
+
+	if fs.NArg() == 0 || *endpoint == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	cmd := &cli{
+		c:    octoprint.NewClient(*endpoint, *key),
+		out:  stdout,
+		json: *asJSON,
+	}
+
+	var err error
+	name, args := fs.Arg(0), fs.Args()[1:]
+	switch name {
+	case "status":
+		err = cmd.status(ctx)
+	case "job":
+		err = cmd.job(ctx, args)
+	case "files":
+		err = cmd.files(ctx, args, stderr)
+	case "temp":
+		err = cmd.temp(ctx, args)
+	case "gcode":
+		err = cmd.gcode(ctx, args)
+	case "connection":
+		err = cmd.connection(ctx, args, stderr)
+	default:
+		err = fmt.Errorf("unknown command %q", name)
+	}
+
+	if errors.Is(err, errUsage) {
+		fs.Usage()
+	}
+
+	return err
+}
+
+func (cmd *cli) status(ctx context.Context) error {
+	state, err := (&octoprint.StateRequest{Exclude: []string{"sd"}}).Do(ctx, cmd.c)
+	if errors.Is(err, octoprint.ErrConflict) {
+		// the printer is not connected.
+		state, err = &octoprint.FullStateResponse{}, nil
+		state.State.Text = "Offline"
+	}
+
+	if err != nil {
+		return err
+	}
+
+	job, err := (&octoprint.JobRequest{}).Do(ctx, cmd.c)
+	if err != nil {
+		return err
+	}
+
+	if cmd.json {
+		return cmd.encode(struct {
+			Printer *octoprint.FullStateResponse `json:"printer"`
+			Job     *octoprint.JobResponse       `json:"job"`
+		}{state, job})
+	}
+
+	fmt.Fprintf(cmd.out, "State:    %s\n", state.State.Text)
+	if state.State.Error != "" {
+		fmt.Fprintf(cmd.out, "Error:    %s\n", state.State.Error)
+	}
+
+	if job.Job.File.Name != "" {
+		fmt.Fprintf(cmd.out, "File:     %s\n", job.Job.File.Path)
+		fmt.Fprintf(cmd.out, "Progress: %.1f%% (%s elapsed, %s left)\n",
+			job.Progress.Completion,
+			seconds(job.Progress.PrintTime),
+			seconds(job.Progress.PrintTimeLeft),
+		)
+	}
+
+	tools := make([]string, 0, len(state.Temperature.Current))
+	for tool := range state.Temperature.Current {
+		tools = append(tools, tool)
+	}
+
+	sort.Strings(tools)
+	for _, tool := range tools {
+		t := state.Temperature.Current[tool]
+		target := "-"
+		if t.Target != nil {
+			target = fmt.Sprintf("%.1f°C", *t.Target)
+		}
+
+		fmt.Fprintf(cmd.out, "%-9s %.1f°C / %s\n", tool+":", t.Actual, target)
+	}
+
+	return nil
+}
+
+func (cmd *cli) job(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "start":
+		return (&octoprint.StartRequest{}).Do(ctx, cmd.c)
+	case "pause":
+		return (&octoprint.PauseRequest{Action: octoprint.Pause}).Do(ctx, cmd.c)
+	case "resume":
+		return (&octoprint.PauseRequest{Action: octoprint.Resume}).Do(ctx, cmd.c)
+	case "cancel":
+		return (&octoprint.CancelRequest{}).Do(ctx, cmd.c)
+	default:
+		return fmt.Errorf("unknown job command %q", args[0])
+	}
+}
+
+func (cmd *cli) files(ctx context.Context, args []string, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	fs := flag.NewFlagSet("files "+args[0], flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	location := fs.String("location", string(octoprint.Local), "location of the files, local or sdcard")
+
+	switch args[0] {
+	case "list":
+		recursive := fs.Bool("r", false, "list the subfolders recursively")
+		if err := fs.Parse(args[1:]); err != nil || fs.NArg() != 0 {
+			return errUsage
+		}
+
+		return cmd.list(ctx, octoprint.Location(*location), *recursive)
+	case "upload":
+		path := fs.String("path", "", "folder where to upload the files")
+		sel := fs.Bool("select", false, "select the file after uploading it")
+		start := fs.Bool("print", false, "start printing the file after uploading it")
+		if err := fs.Parse(args[1:]); err != nil || fs.NArg() == 0 {
+			return errUsage
+		}
+
+		return cmd.upload(ctx, &octoprint.UploadFileRequest{
+			Location: octoprint.Location(*location),
+			Path:     *path,
+			Select:   *sel,
+			Print:    *start,
+		}, fs.Args())
+	case "select":
+		start := fs.Bool("print", false, "start printing the file")
+		if err := fs.Parse(args[1:]); err != nil || fs.NArg() != 1 {
+			return errUsage
+		}
+
+		return (&octoprint.SelectFileRequest{
+			Location: octoprint.Location(*location),
+			Path:     fs.Arg(0),
+			Print:    *start,
+		}).Do(ctx, cmd.c)
+	default:
+		return fmt.Errorf("unknown files command %q", args[0])
+	}
+}
+
+func (cmd *cli) list(ctx context.Context, l octoprint.Location, recursive bool) error {
+	r, err := (&octoprint.FilesRequest{Location: l, Recursive: recursive}).Do(ctx, cmd.c)
+	if err != nil {
+		return err
+	}
+
+	if cmd.json {
+		return cmd.encode(r)
+	}
+
+	for _, f := range r.Files {
+		err := f.Walk(func(f *octoprint.FileInformation) error {
+			if f.Type == "folder" {
+				_, err := fmt.Fprintf(cmd.out, "%s/\n", f.Path)
+				return err
+			}
+
+			date := "-"
+			if !f.Date.IsZero() {
+				date = f.Date.Format(time.RFC3339)
+			}
+
+			_, err := fmt.Fprintf(cmd.out, "%s\t%d\t%s\n", f.Path, f.Size, date)
+			return err
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cmd *cli) upload(ctx context.Context, req *octoprint.UploadFileRequest, files []string) error {
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+		if err := req.AddFile(filepath.Base(name), f); err != nil {
+			return err
+		}
+	}
+
+	r, err := req.Do(ctx, cmd.c)
+	if err != nil {
+		return err
+	}
+
+	if cmd.json {
+		return cmd.encode(r)
+	}
+
+	for _, f := range []*octoprint.FileInformation{r.File.Local, r.File.SDCard} {
+		if f != nil {
+			fmt.Fprintf(cmd.out, "uploaded %s (%s)\n", f.Path, f.Origin)
+		}
+	}
+
+	return nil
+}
+
+func (cmd *cli) temp(ctx context.Context, args []string) error {
+	if len(args) < 2 || args[0] != "set" {
+		return errUsage
+	}
+
+	tools, bed, err := parseTargets(args[1:])
+	if err != nil {
+		return err
+	}
+
+	if len(tools) != 0 {
+		if err := (&octoprint.ToolTargetRequest{Targets: tools}).Do(ctx, cmd.c); err != nil {
+			return err
+		}
+	}
+
+	if bed != nil {
+		return (&octoprint.BedTargetRequest{Target: *bed}).Do(ctx, cmd.c)
+	}
+
+	return nil
+}
+
+// parseTargets parses temperature targets in the form `<tool>=<target>`,
+// returning the targets of the tools and the bed, nil if not set.
+func parseTargets(args []string) (tools map[string]float64, bed *float64, err error) {
+	tools = make(map[string]float64)
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return nil, nil, fmt.Errorf("invalid temperature target %q, expecting <tool>=<target>", arg)
+		}
+
+		target, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid temperature %q for %s", parts[1], parts[0])
+		}
+
+		switch {
+		case parts[0] == "bed":
+			bed = &target
+		case strings.HasPrefix(parts[0], "tool"):
+			tools[parts[0]] = target
+		default:
+			return nil, nil, fmt.Errorf("invalid tool %q, expecting tool{n} or bed", parts[0])
+		}
+	}
+
+	return tools, bed, nil
+}
+
+func (cmd *cli) gcode(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	return (&octoprint.CommandRequest{Commands: args}).Do(ctx, cmd.c)
+}
+
+func (cmd *cli) connection(ctx context.Context, args []string, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "status":
+		r, err := (&octoprint.ConnectionRequest{}).Do(ctx, cmd.c)
+		if err != nil {
+			return err
+		}
+
+		if cmd.json {
+			return cmd.encode(r)
+		}
+
+		fmt.Fprintf(cmd.out, "State:    %s\n", r.Current.State)
+		if r.Current.Port != "" {
+			fmt.Fprintf(cmd.out, "Port:     %s (%d)\n", r.Current.Port, r.Current.BaudRate)
+		}
+
+		if r.Current.PrinterProfile != "" {
+			fmt.Fprintf(cmd.out, "Profile:  %s\n", r.Current.PrinterProfile)
+		}
+
+		return nil
+	case "connect":
+		fs := flag.NewFlagSet("connection connect", flag.ContinueOnError)
+		fs.SetOutput(stderr)
+		port := fs.String("port", "", "serial port, auto detected if not set")
+		baudrate := fs.Int("baudrate", 0, "baud rate, auto detected if not set")
+		profile := fs.String("profile", "", "printer profile, the default if not set")
+		if err := fs.Parse(args[1:]); err != nil || fs.NArg() != 0 {
+			return errUsage
+		}
+
+		return (&octoprint.ConnectRequest{
+			Port:           *port,
+			BaudRate:       *baudrate,
+			PrinterProfile: *profile,
+		}).Do(ctx, cmd.c)
+	case "disconnect":
+		return (&octoprint.DisconnectRequest{}).Do(ctx, cmd.c)
+	default:
+		return fmt.Errorf("unknown connection command %q", args[0])
+	}
+}
+
+func (cmd *cli) encode(v interface{}) error {
+	e := json.NewEncoder(cmd.out)
+	e.SetIndent("", "  ")
+	return e.Encode(v)
+}
+
+// seconds formats a duration in seconds, as reported by OctoPrint.
+func seconds(s *float64) string {
+	if s == nil {
+		return "unknown"
+	}
+
+	return (time.Duration(*s) * time.Second).String()
+}
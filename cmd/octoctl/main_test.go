@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(t *testing.T, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		*requests = append(*requests, r.Method+" "+r.URL.Path+" "+string(bytes.TrimSpace(body)))
+
+		switch r.URL.Path {
+		case "/api/printer":
+			w.Write([]byte(`{
+				"state": {"text": "Printing"},
+				"temperature": {
+					"tool0": {"actual": 209.5, "target": 210},
+					"bed": {"actual": 59.8, "target": 60}
+				}
+			}`))
+		case "/api/job":
+			if r.Method == "GET" {
+				w.Write([]byte(`{
+					"job": {"file": {"name": "cube.gcode", "path": "parts/cube.gcode"}},
+					"progress": {"completion": 42.5, "printTime": 600, "printTimeLeft": 900},
+					"state": "Printing"
+				}`))
+				return
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/files/local":
+			w.Write([]byte(`{"files": [
+				{"name": "parts", "path": "parts", "type": "folder", "children": [
+					{"name": "cube.gcode", "path": "parts/cube.gcode", "type": "machinecode", "size": 1024}
+				]}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+}
+
+func TestRunStatus(t *testing.T) {
+	var requests []string
+	ts := newTestServer(t, &requests)
+	defer ts.Close()
+
+	out := bytes.NewBuffer(nil)
+	err := run(context.Background(), []string{"-url", ts.URL, "status"}, out, ioutil.Discard)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "State:    Printing\n"+
+		"File:     parts/cube.gcode\n"+
+		"Progress: 42.5% (10m0s elapsed, 15m0s left)\n"+
+		"bed:      59.8°C / 60.0°C\n"+
+		"tool0:    209.5°C / 210.0°C\n", out.String())
+}
+
+func TestRunStatusJSON(t *testing.T) {
+	var requests []string
+	ts := newTestServer(t, &requests)
+	defer ts.Close()
+
+	out := bytes.NewBuffer(nil)
+	err := run(context.Background(), []string{"-url", ts.URL, "-json", "status"}, out, ioutil.Discard)
+	assert.NoError(t, err)
+
+	var r struct {
+		Printer struct {
+			State struct{ Text string }
+		}
+		Job struct{ State string }
+	}
+
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &r))
+	assert.Equal(t, "Printing", r.Printer.State.Text)
+	assert.Equal(t, "Printing", r.Job.State)
+}
+
+func TestRunFilesList(t *testing.T) {
+	var requests []string
+	ts := newTestServer(t, &requests)
+	defer ts.Close()
+
+	out := bytes.NewBuffer(nil)
+	err := run(context.Background(), []string{"-url", ts.URL, "files", "list", "-r"}, out, ioutil.Discard)
+	assert.NoError(t, err)
+	assert.Equal(t, "parts/\nparts/cube.gcode\t1024\t-\n", out.String())
+}
+
+func TestRunCommands(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		requests []string
+	}{
+		{[]string{"job", "pause"}, []string{`POST /api/job {"command":"pause","action":"pause"}`}},
+		{[]string{"temp", "set", "tool0=210", "bed=60"}, []string{
+			`POST /api/printer/tool {"command":"target","targets":{"tool0":210}}`,
+			`POST /api/printer/bed {"command":"target","target":60}`,
+		}},
+		{[]string{"gcode", "G28", "M84"}, []string{`POST /api/printer/command {"commands":["G28","M84"]}`}},
+	} {
+		var requests []string
+		ts := newTestServer(t, &requests)
+
+		args := append([]string{"-url", ts.URL}, tc.args...)
+		err := run(context.Background(), args, ioutil.Discard, ioutil.Discard)
+		ts.Close()
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.requests, requests)
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	err := run(context.Background(), []string{"-url", "http://localhost", "job"}, ioutil.Discard, ioutil.Discard)
+	assert.Equal(t, errUsage, err)
+
+	err = run(context.Background(), []string{"-url", "http://localhost", "foo"}, ioutil.Discard, ioutil.Discard)
+	assert.EqualError(t, err, `unknown command "foo"`)
+}
+
+func TestParseTargets(t *testing.T) {
+	tools, bed, err := parseTargets([]string{"tool0=210", "tool1=200.5"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]float64{"tool0": 210, "tool1": 200.5}, tools)
+	assert.Nil(t, bed)
+
+	_, _, err = parseTargets([]string{"tool0"})
+	assert.Error(t, err)
+
+	_, _, err = parseTargets([]string{"chamber=40"})
+	assert.Error(t, err)
+
+	_, _, err = parseTargets([]string{"bed=hot"})
+	assert.Error(t, err)
+}
@@ -1,7 +1,12 @@
 package octoprint
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +22,26 @@ const (
 	ZAxis Axis = "z"
 )
 
+// String returns the name of the axis.
+func (a Axis) String() string {
+	return string(a)
+}
+
+// IsValid returns true if the axis is x, y or z.
+func (a Axis) IsValid() bool {
+	return a == XAxis || a == YAxis || a == ZAxis
+}
+
+// ParseAxis returns the axis with the given name, case insensitively.
+func ParseAxis(s string) (Axis, error) {
+	a := Axis(strings.ToLower(s))
+	if !a.IsValid() {
+		return "", fmt.Errorf("invalid axis %q, must be x, y or z", s)
+	}
+
+	return a, nil
+}
+
 // FullStateResponse contains informantion about the current state of the printer.
 type FullStateResponse struct {
 	//Temperature is the printer’s temperature state data.
@@ -25,6 +50,28 @@ type FullStateResponse struct {
 	SD SDState `json:"sd"`
 	// State is the printer’s general state.
 	State PrinterState `json:"state"`
+	// Extra are the fields not known by the client, e.g. added by plugins.
+	Extra map[string]json.RawMessage `json:"-"`
+	// Raw is the response as returned by OctoPrint.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON satisfies json.Unmarshaler, setting Extra and Raw.
+func (r *FullStateResponse) UnmarshalJSON(b []byte) error {
+	type fullStateResponse FullStateResponse
+	i := &fullStateResponse{}
+	if err := json.Unmarshal(b, i); err != nil {
+		return err
+	}
+
+	extra, err := extraFields(b, i)
+	if err != nil {
+		return err
+	}
+
+	*r = FullStateResponse(*i)
+	r.Extra, r.Raw = extra, append(json.RawMessage(nil), b...)
+	return nil
 }
 
 // JobResponse is the response from a job command.
@@ -33,25 +80,126 @@ type JobResponse struct {
 	Job JobInformation `json:"job"`
 	// Progress contains information regarding the progress of the current job.
 	Progress ProgressInformation `json:"progress"`
+	// State is the textual representation of the current state of the job
+	// or the printer, e.g. “Printing”, “Paused” or “Operational”.
+	State string `json:"state"`
+	// Error is the error that caused the job to fail, if any.
+	Error string `json:"error"`
+	// Extra are the fields not known by the client, e.g. added by plugins.
+	Extra map[string]json.RawMessage `json:"-"`
+	// Raw is the response as returned by OctoPrint.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON satisfies json.Unmarshaler, setting Extra and Raw.
+func (r *JobResponse) UnmarshalJSON(b []byte) error {
+	type jobResponse JobResponse
+	i := &jobResponse{}
+	if err := json.Unmarshal(b, i); err != nil {
+		return err
+	}
+
+	extra, err := extraFields(b, i)
+	if err != nil {
+		return err
+	}
+
+	*r = JobResponse(*i)
+	r.Extra, r.Raw = extra, append(json.RawMessage(nil), b...)
+	return nil
+}
+
+// IsActive returns true if the state of the job is one of a running print,
+// including starting, paused, cancelling and finishing prints.
+func (r *JobResponse) IsActive() bool {
+	for _, prefix := range []string{
+		"Starting", "Printing", "Pausing", "Paused", "Resuming", "Finishing", "Cancelling",
+	} {
+		if strings.HasPrefix(r.State, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsPaused returns true if the job is paused or being paused.
+func (r *JobResponse) IsPaused() bool {
+	return strings.HasPrefix(r.State, "Paused") || strings.HasPrefix(r.State, "Pausing")
+}
+
+// IsOffline returns true if the server is not connected to the printer, e.g.
+// after a restart, excluding disconnections caused by errors.
+func (r *JobResponse) IsOffline() bool {
+	if strings.HasPrefix(r.State, "Offline after error") {
+		return false
+	}
+
+	for _, prefix := range []string{"Offline", "Opening", "Detecting", "Connecting"} {
+		if strings.HasPrefix(r.State, prefix) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // JobInformation contains information regarding the target of the current job.
 type JobInformation struct {
 	// File is the file that is the target of the current print job.
 	File FileInformation `json:"file"`
-	// EstimatedPrintTime is the estimated print time for the file, in seconds.
-	EstimatedPrintTime float64 `json:"estimatedPrintTime"`
-	// LastPrintTime is the print time of the last print of the file, in seconds.
-	LastPrintTime float64 `json:"lastPrintTime"`
+	// EstimatedPrintTime is the estimated print time for the file, in
+	// seconds, nil if unknown.
+	EstimatedPrintTime *float64 `json:"estimatedPrintTime"`
+	// LastPrintTime is the print time of the last print of the file, in
+	// seconds, nil if unknown.
+	LastPrintTime *float64 `json:"lastPrintTime"`
+	// AveragePrintTime is the average print time of the previous prints of
+	// the file, in seconds, nil if unknown.
+	AveragePrintTime *float64 `json:"averagePrintTime"`
 	// Filament contains Information regarding the estimated filament
-	// usage of the print job.
-	Filament struct {
-		// Length of filament used, in mm
-		Length float64 `json:"length"`
-		// Volume of filament used, in cm³
-		Volume float64 `json:"volume"`
-	} `json:"filament"`
+	// usage of the print job, by tool (e.g. `tool0`, `tool1`).
+	Filament map[string]Filament `json:"filament"`
+	// User is the name of the user who started the print job.
+	User         string `json:"user"`
 	FilePosition uint64 `json:"filepos"`
+	// Extra are the fields not known by the client, e.g. added by plugins.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON satisfies json.Unmarshaler, setting Extra.
+func (j *JobInformation) UnmarshalJSON(b []byte) error {
+	type jobInformation JobInformation
+	i := &jobInformation{}
+	if err := json.Unmarshal(b, i); err != nil {
+		return err
+	}
+
+	extra, err := extraFields(b, i)
+	if err != nil {
+		return err
+	}
+
+	*j = JobInformation(*i)
+	j.Extra = extra
+	return nil
+}
+
+// EstimatedPrintDuration returns EstimatedPrintTime as a time.Duration, zero
+// if unknown.
+func (j *JobInformation) EstimatedPrintDuration() time.Duration {
+	return secondsDuration(j.EstimatedPrintTime)
+}
+
+// LastPrintDuration returns LastPrintTime as a time.Duration, zero if unknown.
+func (j *JobInformation) LastPrintDuration() time.Duration {
+	return secondsDuration(j.LastPrintTime)
+}
+
+// AveragePrintDuration returns AveragePrintTime as a time.Duration, zero if
+// unknown.
+func (j *JobInformation) AveragePrintDuration() time.Duration {
+	return secondsDuration(j.AveragePrintTime)
 }
 
 // ProgressInformation contains information regarding the progress of the
@@ -62,10 +210,113 @@ type ProgressInformation struct {
 	// FilePosition current position in the file being printed, in bytes
 	// from the beginning.
 	FilePosition uint64 `json:"filepos"`
-	// PrintTime is time already spent printing, in seconds
-	PrintTime float64 `json:"printTime"`
-	// PrintTimeLeft is estimate of time left to print, in seconds
-	PrintTimeLeft float64 `json:"printTimeLeft"`
+	// PrintTime is time already spent printing, in seconds, nil if not
+	// printing.
+	PrintTime *float64 `json:"printTime"`
+	// PrintTimeLeft is estimate of time left to print, in seconds, nil if
+	// not printing or unknown.
+	PrintTimeLeft *float64 `json:"printTimeLeft"`
+	// PrintTimeLeftOrigin is the origin of PrintTimeLeft, empty if unknown.
+	PrintTimeLeftOrigin PrintTimeLeftOrigin `json:"printTimeLeftOrigin"`
+}
+
+// PrintDuration returns PrintTime as a time.Duration, zero if unknown.
+func (p *ProgressInformation) PrintDuration() time.Duration {
+	return secondsDuration(p.PrintTime)
+}
+
+// PrintTimeLeftDuration returns PrintTimeLeft as a time.Duration, zero if
+// unknown.
+func (p *ProgressInformation) PrintTimeLeftDuration() time.Duration {
+	return secondsDuration(p.PrintTimeLeft)
+}
+
+// Equal returns true if both progresses have the same values.
+func (p *ProgressInformation) Equal(o *ProgressInformation) bool {
+	return p.Completion == o.Completion && p.FilePosition == o.FilePosition &&
+		equalFloat(p.PrintTime, o.PrintTime) && equalFloat(p.PrintTimeLeft, o.PrintTimeLeft) &&
+		p.PrintTimeLeftOrigin == o.PrintTimeLeftOrigin
+}
+
+// PrintTimeLeftOrigin is the origin of the estimation of the time left to
+// print, from the least to the most accurate.
+type PrintTimeLeftOrigin string
+
+const (
+	// PrintTimeLeftLinear is a linear extrapolation of the progress, the
+	// least accurate estimation.
+	PrintTimeLeftLinear PrintTimeLeftOrigin = "linear"
+	// PrintTimeLeftAnalysis is based on the analysis of the file.
+	PrintTimeLeftAnalysis PrintTimeLeftOrigin = "analysis"
+	// PrintTimeLeftMixedAnalysis is a mix of the analysis of the file and
+	// the linear extrapolation.
+	PrintTimeLeftMixedAnalysis PrintTimeLeftOrigin = "mixed-analysis"
+	// PrintTimeLeftAverage is based on the average time of previous prints
+	// of the file, on the same printer profile.
+	PrintTimeLeftAverage PrintTimeLeftOrigin = "average"
+	// PrintTimeLeftMixedAverage is a mix of the average time of previous
+	// prints and the linear extrapolation.
+	PrintTimeLeftMixedAverage PrintTimeLeftOrigin = "mixed-average"
+	// PrintTimeLeftEstimate is the estimation of OctoPrint once the print is
+	// stable, the most accurate one.
+	PrintTimeLeftEstimate PrintTimeLeftOrigin = "estimate"
+)
+
+// extraFields returns the fields of the JSON object b not matching any field
+// of the struct pointed by v, nil if none. Names are matched case
+// insensitively, as encoding/json does.
+func extraFields(b []byte, v interface{}) (map[string]json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+
+		known[strings.ToLower(name)] = true
+	}
+
+	for k := range raw {
+		if known[strings.ToLower(k)] {
+			delete(raw, k)
+		}
+	}
+
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	return raw, nil
+}
+
+// secondsDuration converts an amount of seconds to a time.Duration, zero if
+// nil.
+func secondsDuration(s *float64) time.Duration {
+	if s == nil {
+		return 0
+	}
+
+	return time.Duration(*s * float64(time.Second))
+}
+
+func equalFloat(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
 }
 
 // TemperatureState is the printer’s temperature state data.
@@ -77,55 +328,186 @@ type temperatureState struct {
 	History []*HistoricTemperatureData `json:"history"`
 }
 
+// UnmarshalJSON satisfies json.Unmarshaler, decoding the temperatures of the
+// tools, reported as keys of the object along `history`. Null tools and
+// history entries are skipped.
 func (r *TemperatureState) UnmarshalJSON(b []byte) error {
-	var raw map[string]interface{}
+	var raw map[string]json.RawMessage
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
 
-	history := raw["history"]
-	delete(raw, "history")
-	b, _ = json.Marshal(map[string]interface{}{
-		"current": raw,
-		"history": history,
-	})
+	s := TemperatureState{}
+	if history, ok := raw["history"]; ok {
+		delete(raw, "history")
+		if err := json.Unmarshal(history, &s.History); err != nil {
+			return fmt.Errorf("invalid temperature history: %w", err)
+		}
 
-	i := &temperatureState{}
-	if err := json.Unmarshal(b, i); err != nil {
+		s.History = compactHistory(s.History)
+	}
+
+	current, err := decodeTemperatures(raw)
+	if err != nil {
 		return err
 	}
 
-	*r = TemperatureState(*i)
+	s.Current = current
+	*r = s
 	return nil
 }
 
+// decodeTemperatures decodes the temperatures of the tools, by name, skipping
+// the null ones.
+func decodeTemperatures(raw map[string]json.RawMessage) (map[string]TemperatureData, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	temps := make(map[string]TemperatureData, len(raw))
+	for name, v := range raw {
+		if isJSONNull(v) {
+			continue
+		}
+
+		var t TemperatureData
+		if err := json.Unmarshal(v, &t); err != nil {
+			return nil, fmt.Errorf("invalid temperature of %q: %w", name, err)
+		}
+
+		temps[name] = t
+	}
+
+	return temps, nil
+}
+
+func compactHistory(history []*HistoricTemperatureData) []*HistoricTemperatureData {
+	r := history[:0]
+	for _, h := range history {
+		if h != nil {
+			r = append(r, h)
+		}
+	}
+
+	return r
+}
+
+func isJSONNull(b json.RawMessage) bool {
+	return string(bytes.TrimSpace(b)) == "null"
+}
+
 // TemperatureData is temperature stats for a tool.
 type TemperatureData struct {
 	// Actual current temperature.
 	Actual float64 `json:"actual"`
-	// Target temperature, may be nil if no target temperature is set.
-	Target float64 `json:"target"`
-	// Offset currently configured temperature offset to apply, will be left
-	// out for historic temperature information.
-	Offset float64 `json:"offset"`
+	// Target temperature, nil if no target temperature is set, e.g. for
+	// tools without setpoint, distinguishing it from a target of 0°C.
+	Target *float64 `json:"target"`
+	// Offset currently configured temperature offset to apply, nil for
+	// historic temperature information, where it is left out.
+	Offset *float64 `json:"offset,omitempty"`
+}
+
+// IsHeating returns true if the tool has a target temperature set above 0°C.
+func (t TemperatureData) IsHeating() bool {
+	return t.Target != nil && *t.Target > 0
+}
+
+// HeaterState is the temperature data and history of a single heater.
+type HeaterState struct {
+	// Name of the heater, e.g. `tool0`, `bed` or `chamber`.
+	Name string `json:"name"`
+	// Current temperature stats.
+	Current TemperatureData `json:"current"`
+	// History of the heater, empty if it wasn't requested.
+	History []*HeaterHistoryData `json:"history"`
+}
+
+// HeaterHistoryData is a data point of the history of a heater.
+type HeaterHistoryData struct {
+	// Time of this data point.
+	Time UnixTime `json:"time"`
+	TemperatureData
+}
+
+// Heater returns the temperature data and history of the heater with the
+// given name, e.g. the response of a BedStateRequest for `bed`, or nil if
+// it's not reported. The data points of the history without the heater are
+// skipped.
+func (r *TemperatureState) Heater(name string) *HeaterState {
+	current, ok := r.Current[name]
+	if !ok {
+		return nil
+	}
+
+	h := &HeaterState{Name: name, Current: current}
+	for _, d := range r.History {
+		if t, ok := d.Tools[name]; ok {
+			h.History = append(h.History, &HeaterHistoryData{Time: d.Time, TemperatureData: t})
+		}
+	}
+
+	return h
 }
 
 // PrinterState current state of the printer.
 type PrinterState struct {
-	Text  string `json:"text"`
-	Flags struct {
-		Operations    bool `json:"operational"`
-		Paused        bool `json:"paused"`
-		Printing      bool `json:"printing"`
-		SDReady       bool `json:"sdReady"`
-		Error         bool `json:"error"`
-		Ready         bool `json:"ready"`
-		ClosedOnError bool `json:"closedOrError"`
-	} `json:"flags"`
+	// Text is a textual representation of the current state of the printer,
+	// e.g. “Operational” or “Printing”.
+	Text string `json:"text"`
+	// Error is the last error reported by the printer, if any.
+	Error string `json:"error"`
+	// Flags details the state of the printer.
+	Flags PrinterStateFlags `json:"flags"`
+}
+
+// PrinterStateFlags are the flags of the state of the printer.
+type PrinterStateFlags struct {
+	Operational   bool `json:"operational"`
+	Paused        bool `json:"paused"`
+	Printing      bool `json:"printing"`
+	Pausing       bool `json:"pausing"`
+	Cancelling    bool `json:"cancelling"`
+	Resuming      bool `json:"resuming"`
+	Finishing     bool `json:"finishing"`
+	SDReady       bool `json:"sdReady"`
+	Error         bool `json:"error"`
+	Ready         bool `json:"ready"`
+	ClosedOrError bool `json:"closedOrError"`
+
+	// Operations is set along Operational when decoding.
+	//
+	// Deprecated: use Operational.
+	Operations bool `json:"-"`
+	// ClosedOnError is set along ClosedOrError when decoding.
+	//
+	// Deprecated: use ClosedOrError.
+	ClosedOnError bool `json:"-"`
+}
+
+type printerStateFlags PrinterStateFlags
+
+func (f *PrinterStateFlags) UnmarshalJSON(b []byte) error {
+	i := &printerStateFlags{}
+	if err := json.Unmarshal(b, i); err != nil {
+		return err
+	}
+
+	i.Operations, i.ClosedOnError = i.Operational, i.ClosedOrError
+	*f = PrinterStateFlags(*i)
+	return nil
+}
+
+func (f PrinterStateFlags) MarshalJSON() ([]byte, error) {
+	i := printerStateFlags(f)
+	i.Operational = i.Operational || i.Operations
+	i.ClosedOrError = i.ClosedOrError || i.ClosedOnError
+	return json.Marshal(i)
 }
 
 // SDState is the state of the sd reader.
 type SDState struct {
+	// Ready is true if the SD card has been initialized and is available.
 	Ready bool `json:"ready"`
 }
 
@@ -133,39 +515,66 @@ type SDState struct {
 type HistoricTemperatureData historicTemperatureData
 type historicTemperatureData struct {
 	// Time of this data point.
-	Time JSONTime `json:"time"`
+	Time UnixTime `json:"time"`
 	// Tools is temperature stats a set of tools.
 	Tools map[string]TemperatureData `json:"tools"`
 }
 
+// UnmarshalJSON satisfies json.Unmarshaler, decoding the temperatures of the
+// tools, reported as keys of the object along `time`. A missing time is left
+// zero.
 func (h *HistoricTemperatureData) UnmarshalJSON(b []byte) error {
-	var raw map[string]interface{}
+	var raw map[string]json.RawMessage
 	if err := json.Unmarshal(b, &raw); err != nil {
 		return err
 	}
 
-	ts := raw["time"]
-	delete(raw, "time")
-	b, _ = json.Marshal(map[string]interface{}{
-		"time":  ts,
-		"tools": raw,
-	})
+	d := HistoricTemperatureData{}
+	if ts, ok := raw["time"]; ok {
+		delete(raw, "time")
+		if err := json.Unmarshal(ts, &d.Time); err != nil {
+			return fmt.Errorf("invalid temperature time: %w", err)
+		}
+	}
 
-	i := &historicTemperatureData{}
-	if err := json.Unmarshal(b, i); err != nil {
+	tools, err := decodeTemperatures(raw)
+	if err != nil {
 		return err
 	}
 
-	*h = HistoricTemperatureData(*i)
+	d.Tools = tools
+	*h = d
 	return nil
 }
 
-// VersionResponse is the response from a job command.
+// VersionResponse is the response from a version request.
 type VersionResponse struct {
 	// API is the API version.
 	API string `json:"api"`
 	// Server is the server version.
 	Server string `json:"server"`
+	// Text is the server version including the server name, e.g.
+	// “OctoPrint 1.5.0”.
+	Text string `json:"text"`
+}
+
+// SemVer parses the server version.
+func (r *VersionResponse) SemVer() (SemVer, error) {
+	return ParseSemVer(r.Server)
+}
+
+// ServerResponse is the response from a server request.
+type ServerResponse struct {
+	// Version is the server version.
+	Version string `json:"version"`
+	// Safemode is the reason the server is running in safe mode, e.g.
+	// `incomplete_startup`, `flag` or `settings`. Empty if not in safe mode.
+	Safemode string `json:"safemode"`
+}
+
+// SemVer parses the server version.
+func (r *ServerResponse) SemVer() (SemVer, error) {
+	return ParseSemVer(r.Version)
 }
 
 type ConnectionState string
@@ -177,6 +586,44 @@ const (
 // The states are  based on:
 // https://github.com/foosel/OctoPrint/blob/77753ca02602d3a798d6b0a22535e6fd69ff448a/src/octoprint/util/comm.py#L549
 
+// connectionStates are the prefixes of the states reported by OctoPrint, some
+// states are followed by details, e.g. `Printing from SD` or `Error: ...`.
+var connectionStates = []string{
+	"Offline", "Opening serial connection", "Detecting serial connection",
+	"Detecting baudrate", "Connecting", "Operational", "Starting", "Printing",
+	"Sending file to SD", "Transfering file to SD", "Transferring file to SD",
+	"Pausing", "Paused", "Resuming", "Finishing", "Cancelling", "Closed",
+	"Error", "Unknown",
+}
+
+// String returns the textual representation of the state.
+func (s ConnectionState) String() string {
+	return string(s)
+}
+
+// IsValid returns true if the state is one of the states reported by
+// OctoPrint.
+func (s ConnectionState) IsValid() bool {
+	for _, prefix := range connectionStates {
+		if strings.HasPrefix(string(s), prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseConnectionState returns the state with the given textual
+// representation, returning an error if it's not valid.
+func ParseConnectionState(s string) (ConnectionState, error) {
+	state := ConnectionState(s)
+	if !state.IsValid() {
+		return "", fmt.Errorf("unknown connection state %q", s)
+	}
+
+	return state, nil
+}
+
 func (s ConnectionState) IsOperational() bool {
 	return strings.HasPrefix(string(s), "Operational")
 }
@@ -243,24 +690,168 @@ type Profile struct {
 	ID string `json:"id"`
 	// Name is the display name of the profile.
 	Name string `json:"name"`
+	// Color of the profile, used for visually identifying it.
+	Color string `json:"color,omitempty"`
+	// Model is the printer model.
+	Model string `json:"model,omitempty"`
+	// Default whether this is the default profile.
+	Default bool `json:"default,omitempty"`
+	// Current whether this is the profile currently in use.
+	Current bool `json:"current,omitempty"`
+	// Resource is the URL of the profile in the API.
+	Resource string `json:"resource,omitempty"`
+	// Volume describes the print volume of the printer.
+	Volume *ProfileVolume `json:"volume,omitempty"`
+	// HeatedBed whether the printer has a heated bed.
+	HeatedBed bool `json:"heatedBed"`
+	// HeatedChamber whether the printer has a heated chamber.
+	HeatedChamber bool `json:"heatedChamber"`
+	// Axes describes the printer's axes.
+	Axes *ProfileAxes `json:"axes,omitempty"`
+	// Extruder describes the printer's extruders.
+	Extruder *ProfileExtruder `json:"extruder,omitempty"`
+}
+
+// ProfileVolume is the print volume of a printer profile.
+type ProfileVolume struct {
+	// FormFactor of the print bed, `rectangular` or `circular`.
+	FormFactor string `json:"formFactor"`
+	// Origin of the coordinate system, `lowerleft` or `center`.
+	Origin string `json:"origin"`
+	// Width of the print volume, in mm. For circular beds the diameter.
+	Width float64 `json:"width"`
+	// Depth of the print volume, in mm. For circular beds the diameter.
+	Depth float64 `json:"depth"`
+	// Height of the print volume, in mm.
+	Height float64 `json:"height"`
+	// CustomBox is the custom bounding box the nozzle can travel in, outside
+	// the print volume. Nil if the print volume is also the bounding box.
+	CustomBox *CustomBox `json:"custom_box"`
+}
+
+type profileVolume ProfileVolume
+
+func (v *ProfileVolume) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		profileVolume
+		CustomBox json.RawMessage `json:"custom_box"`
+	}{}
+
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*v = ProfileVolume(raw.profileVolume)
+	v.CustomBox = nil
+	if len(raw.CustomBox) == 0 || raw.CustomBox[0] != '{' {
+		return nil
+	}
+
+	v.CustomBox = &CustomBox{}
+	return json.Unmarshal(raw.CustomBox, v.CustomBox)
+}
+
+func (v ProfileVolume) MarshalJSON() ([]byte, error) {
+	raw := struct {
+		profileVolume
+		CustomBox interface{} `json:"custom_box"`
+	}{profileVolume: profileVolume(v), CustomBox: false}
+
+	if v.CustomBox != nil {
+		raw.CustomBox = v.CustomBox
+	}
+
+	return json.Marshal(raw)
+}
+
+// CustomBox is the bounding box the nozzle can travel in, in mm.
+type CustomBox struct {
+	XMin float64 `json:"x_min"`
+	XMax float64 `json:"x_max"`
+	YMin float64 `json:"y_min"`
+	YMax float64 `json:"y_max"`
+	ZMin float64 `json:"z_min"`
+	ZMax float64 `json:"z_max"`
+}
+
+// ProfileAxes describes the axes of a printer profile.
+type ProfileAxes struct {
+	X ProfileAxis `json:"x"`
+	Y ProfileAxis `json:"y"`
+	Z ProfileAxis `json:"z"`
+	E ProfileAxis `json:"e"`
+}
+
+// ProfileAxis describes a single axis of a printer profile.
+type ProfileAxis struct {
+	// Speed is the maximum speed of the axis, in mm/min.
+	Speed float64 `json:"speed"`
+	// Inverted whether the axis' control is inverted.
+	Inverted bool `json:"inverted"`
+}
+
+// ProfileExtruder describes the extruders of a printer profile.
+type ProfileExtruder struct {
+	// Count is the number of extruders.
+	Count int `json:"count"`
+	// Offsets of every extruder relative to the first one, as [x, y] in mm.
+	Offsets [][2]float64 `json:"offsets"`
+	// NozzleDiameter is the diameter of the nozzles, in mm.
+	NozzleDiameter float64 `json:"nozzleDiameter"`
+	// SharedNozzle whether all the extruders share a single nozzle.
+	SharedNozzle bool `json:"sharedNozzle"`
+	// DefaultExtrusionLength is the default length to extrude, in mm.
+	DefaultExtrusionLength float64 `json:"defaultExtrusionLength"`
+}
+
+// PrinterProfilesResponse is the response to a PrinterProfileListRequest.
+type PrinterProfilesResponse struct {
+	// Profiles is the list of printer profiles, by identifier.
+	Profiles map[string]*Profile `json:"profiles"`
 }
 
 // FilesResponse is the response to a FilesRequest.
 type FilesResponse struct {
 	// Files is the list of requested files. Might be an empty list if no files
 	// are available
-	Files []*FileInformation
+	Files []*FileInformation `json:"files"`
 	// Free is the amount of disk space in bytes available in the local disk
 	// space (refers to OctoPrint’s `uploads` folder). Only returned if file
 	// list was requested for origin `local` or all origins.
-	Free uint64
+	Free uint64 `json:"free"`
+	// Total is the total amount of disk space in bytes of the local disk
+	// space. Only returned if file list was requested for origin `local` or
+	// all origins.
+	Total uint64 `json:"total"`
+}
+
+// Walk calls fn for every file and folder of the tree, parents before their
+// children. If fn returns an error the walk is stopped and the error returned.
+func (r *FilesResponse) Walk(fn func(*FileInformation) error) error {
+	return walkFiles(r.Files, fn)
+}
+
+// Flatten returns all the files of the tree, skipping the folders.
+func (r *FilesResponse) Flatten() []*FileInformation {
+	return flattenFiles(r.Files)
+}
+
+// Find returns the file or folder of the tree at the given path, nil if not
+// found. Folders not listed recursively are not searched.
+func (r *FilesResponse) Find(path string) *FileInformation {
+	return findFile(r.Files, strings.Trim(path, "/"))
 }
 
 // FileInformation contains information regarding a file.
 type FileInformation struct {
 	// Name is name of the file without path. E.g. “file.gco” for a file
-	// “file.gco” located anywhere in the file system.
+	// “file.gco” located anywhere in the file system. For `sdcard` files this
+	// is the short name reported by the printer.
 	Name string `json:"name"`
+	// Display is the name of the file to display, may contain characters not
+	// allowed in the file system. Defaults to Name if not set. For `sdcard`
+	// files this is the long name, if reported by the printer.
+	Display string `json:"display"`
 	// Path is the path to the file within the location. E.g.
 	//“folder/subfolder/file.gco” for a file “file.gco” located within “folder”
 	// and “subfolder” relative to the root of the location.
@@ -278,22 +869,110 @@ type FileInformation struct {
 	// files if the printer supports file sizes for sd card files.
 	Size uint64 `json:"size"`
 	// Date when this file was uploaded. Only available for `local` files.
-	Date JSONTime `json:"date"`
+	Date UnixTime `json:"date"`
 	// Origin of the file, `local` when stored in OctoPrint’s `uploads` folder,
 	// `sdcard` when stored on the printer’s SD card (if available)
-	Origin string `json:"origin"`
-	// Refs references relevant to this file, left out in abridged versio
+	Origin Location `json:"origin"`
+	// Refs references relevant to this file, left out in abridged version.
 	Refs Reference `json:"refs"`
 	// GCodeAnalysis information from the analysis of the GCODE file, if
 	// available. Left out in abridged version.
 	GCodeAnalysis GCodeAnalysisInformation `json:"gcodeAnalysis"`
-	// Print information from the print stats of a file.
-	Print PrintStats `json:"print"`
+	// Prints information from the print stats of a file.
+	Prints PrintStats `json:"prints"`
+	// Statistics information from the print statistics of a file, by printer
+	// profile.
+	Statistics PrintStatistics `json:"statistics"`
+	// Children files and folders contained in the folder, only populated for
+	// folders. When not listed recursively only the direct children are
+	// returned.
+	Children []*FileInformation `json:"children"`
+	// Folder is true if the entry is a folder, set when decoding.
+	Folder bool `json:"-"`
+	// Extra are the fields not known by the client, e.g. added by plugins.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON satisfies json.Unmarshaler, setting Folder and Extra.
+func (f *FileInformation) UnmarshalJSON(b []byte) error {
+	type fileInformation FileInformation
+	i := (*fileInformation)(f)
+	if err := json.Unmarshal(b, i); err != nil {
+		return err
+	}
+
+	extra, err := extraFields(b, i)
+	if err != nil {
+		return err
+	}
+
+	f.Folder = f.Type == "folder" || (len(f.TypePath) == 1 && f.TypePath[0] == "folder")
+	f.Extra = extra
+	return nil
+}
+
+// Walk calls fn for the file and, in case of a folder, for all its descendants,
+// parents before their children. If fn returns an error the walk is stopped
+// and the error returned.
+func (f *FileInformation) Walk(fn func(*FileInformation) error) error {
+	if err := fn(f); err != nil {
+		return err
+	}
+
+	return walkFiles(f.Children, fn)
+}
+
+// Flatten returns all the files contained in the folder and its subfolders,
+// skipping the folders. Returns the file itself if it's not a folder.
+func (f *FileInformation) Flatten() []*FileInformation {
+	return flattenFiles([]*FileInformation{f})
+}
+
+// Find returns the descendant of the folder at the given path, relative to the
+// root of the location as Path, nil if not found.
+func (f *FileInformation) Find(path string) *FileInformation {
+	return findFile(f.Children, strings.Trim(path, "/"))
+}
+
+func walkFiles(files []*FileInformation, fn func(*FileInformation) error) error {
+	for _, f := range files {
+		if err := f.Walk(fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func flattenFiles(files []*FileInformation) []*FileInformation {
+	var r []*FileInformation
+	walkFiles(files, func(f *FileInformation) error {
+		if !f.IsFolder() {
+			r = append(r, f)
+		}
+
+		return nil
+	})
+
+	return r
+}
+
+func findFile(files []*FileInformation, path string) *FileInformation {
+	for _, f := range files {
+		switch {
+		case f.Path == path:
+			return f
+		case f.IsFolder() && strings.HasPrefix(path, f.Path+"/"):
+			return findFile(f.Children, path)
+		}
+	}
+
+	return nil
 }
 
 // IsFolder it returns true if the file is a folder.
 func (f *FileInformation) IsFolder() bool {
-	if len(f.TypePath) == 1 && f.TypePath[0] == "folder" {
+	if f.Folder || (len(f.TypePath) == 1 && f.TypePath[0] == "folder") {
 		return true
 	}
 
@@ -316,13 +995,45 @@ type Reference struct {
 type GCodeAnalysisInformation struct {
 	// EstimatedPrintTime is the estimated print time of the file, in seconds.
 	EstimatedPrintTime float64 `json:"estimatedPrintTime"`
-	// Filament estimated usage of filament
-	Filament struct {
-		// Length estimated of filament used, in mm
-		Length uint32 `json:"length"`
-		// Volume estimated of filament used, in cm³
-		Volume float64 `json:"volume"`
-	} `json:"filament"`
+	// Filament estimated usage of filament, by tool.
+	Filament map[string]Filament `json:"filament"`
+	// Dimensions of the printed object.
+	Dimensions Dimensions `json:"dimensions"`
+	// PrintingArea is the area in which the object is printed, only the
+	// moves extruding filament.
+	PrintingArea Area `json:"printingArea"`
+	// TravelArea is the area covered by all the moves, including travels.
+	TravelArea Area `json:"travelArea"`
+}
+
+// EstimatedPrintDuration returns EstimatedPrintTime as a time.Duration.
+func (a *GCodeAnalysisInformation) EstimatedPrintDuration() time.Duration {
+	return secondsDuration(&a.EstimatedPrintTime)
+}
+
+// Filament is the usage of filament of a tool.
+type Filament struct {
+	// Length of filament used, in mm
+	Length float64 `json:"length"`
+	// Volume of filament used, in cm³
+	Volume float64 `json:"volume"`
+}
+
+// Dimensions of an object, in mm.
+type Dimensions struct {
+	Depth  float64 `json:"depth"`
+	Height float64 `json:"height"`
+	Width  float64 `json:"width"`
+}
+
+// Area is a bounding box, in mm.
+type Area struct {
+	MinX float64 `json:"minX"`
+	MaxX float64 `json:"maxX"`
+	MinY float64 `json:"minY"`
+	MaxY float64 `json:"maxY"`
+	MinZ float64 `json:"minZ"`
+	MaxZ float64 `json:"maxZ"`
 }
 
 // PrintStats information from the print stats of a file.
@@ -334,12 +1045,24 @@ type PrintStats struct {
 	// Last print information.
 	Last struct {
 		// Date of the last print.
-		Date JSONTime `json:"date"`
+		Date UnixTime `json:"date"`
+		// PrintTime is the duration of the last print, in seconds.
+		PrintTime float64 `json:"printTime"`
 		// Success or not.
 		Success bool `json:"success"`
 	} `json:"last"`
 }
 
+// PrintStatistics information from the print statistics of a file.
+type PrintStatistics struct {
+	// AveragePrintTime is the average print time of successful prints of the
+	// file, in seconds, by printer profile.
+	AveragePrintTime map[string]float64 `json:"averagePrintTime"`
+	// LastPrintTime is the print time of the last successful print of the
+	// file, in seconds, by printer profile.
+	LastPrintTime map[string]float64 `json:"lastPrintTime"`
+}
+
 // UploadFileResponse is the response to a UploadFileRequest.
 type UploadFileResponse struct {
 	// Abridged information regarding the file that was just uploaded. If only
@@ -355,6 +1078,9 @@ type UploadFileResponse struct {
 		// to the printer’s SD card.
 		SDCard *FileInformation `json:"sdcard"`
 	} `json:"files"`
+	// Folder is the information regarding the folder that was just created.
+	// Only contained if a new folder was created.
+	Folder *FileInformation `json:"folder"`
 	// Done whether any file processing after upload has already finished or
 	// not, e.g. due to first needing to perform a slicing step. Clients may
 	// use this information to direct progress displays related to the upload.
@@ -367,6 +1093,32 @@ type SystemCommandsResponse struct {
 	Custom []*CommandDefinition `json:"custom"`
 }
 
+// Commands returns the executable commands of both sources, skipping the
+// dividers.
+func (r *SystemCommandsResponse) Commands() []*CommandDefinition {
+	var cmds []*CommandDefinition
+	for _, list := range [][]*CommandDefinition{r.Core, r.Custom} {
+		for _, cmd := range list {
+			if !cmd.IsDivider() {
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+
+	return cmds
+}
+
+// Find returns the command with the given source and action, nil if not found.
+func (r *SystemCommandsResponse) Find(source CommandSource, action string) *CommandDefinition {
+	for _, cmd := range r.Commands() {
+		if cmd.Source == source && cmd.Action == action {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
 // CommandSource is the source of the command definition.
 type CommandSource string
 
@@ -384,13 +1136,15 @@ type CommandDefinition struct {
 	// Command is the full command line to execute for the command.
 	Command string `json:"command"`
 	// Action is an identifier to refer to the command programmatically. The
-	// special `action` string divider signifies a `divider` in the menu.
+	// special action `divider` signifies a divider in the menu, see
+	// IsDivider.
 	Action string `json:"action"`
 	// Confirm if present and set, this text will be displayed to the user in a
 	// confirmation dialog they have to acknowledge in order to really execute
-	// the command.
+	// the command. RawConfirm is the raw value, `false` if no confirmation
+	// is needed.
 	RawConfirm json.RawMessage `json:"confirm"`
-	Confirm string `json:"-"`
+	Confirm    string          `json:"-"`
 	// Async whether to execute the command asynchronously or wait for its
 	// result before responding to the HTTP execution request.
 	Async bool `json:"async"`
@@ -402,24 +1156,76 @@ type CommandDefinition struct {
 	Resource string `json:"resource"`
 }
 
-type JSONTime struct{ time.Time }
+// CommandDivider is the action of the entries signifying a divider in the
+// System menu.
+const CommandDivider = "divider"
 
-func (t JSONTime) MarshalJSON() ([]byte, error) {
-	return []byte(strconv.FormatInt(time.Time(t.Time).Unix(), 10)), nil
+// IsDivider returns true if the entry is a divider in the System menu instead
+// of an executable command.
+func (cmd *CommandDefinition) IsDivider() bool {
+	return cmd.Action == CommandDivider
 }
 
-func (t *JSONTime) UnmarshalJSON(s []byte) (err error) {
+type commandDefinition CommandDefinition
+
+func (cmd *CommandDefinition) UnmarshalJSON(b []byte) error {
+	raw := (*commandDefinition)(cmd)
+	if err := json.Unmarshal(b, raw); err != nil {
+		return err
+	}
+
+	// confirm is either the text to display or false if no confirmation is
+	// needed.
+	cmd.Confirm = ""
+	if len(cmd.RawConfirm) != 0 && cmd.RawConfirm[0] == '"' {
+		return json.Unmarshal(cmd.RawConfirm, &cmd.Confirm)
+	}
+
+	return nil
+}
+
+// UnixTime is a time.Time encoded as seconds since the Unix epoch, as
+// OctoPrint reports the dates of files, backups, logs and temperatures. Null
+// decodes to the zero time, encoded back as null.
+type UnixTime struct{ time.Time }
+
+// JSONTime is the former name of UnixTime.
+//
+// Deprecated: use UnixTime instead.
+type JSONTime = UnixTime
+
+// MarshalJSON satisfies json.Marshaler, keeping the fractional seconds if
+// any.
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+
+	if t.Nanosecond() == 0 {
+		return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+	}
+
+	frac := strings.TrimRight(fmt.Sprintf("%09d", t.Nanosecond()), "0")
+	return []byte(fmt.Sprintf("%d.%s", t.Unix(), frac)), nil
+}
+
+// UnmarshalJSON satisfies json.Unmarshaler, accepting integer and fractional
+// seconds, also quoted.
+func (t *UnixTime) UnmarshalJSON(s []byte) (err error) {
 	r := strings.Replace(string(s), `"`, ``, -1)
 	if r == "null" {
 		return nil
 	}
 
-	q, err := strconv.ParseInt(r, 10, 64)
+	// some timestamps, e.g. the date of the last print, have fractional
+	// seconds.
+	q, err := strconv.ParseFloat(r, 64)
 	if err != nil {
 		return err
 	}
 
-	t.Time = time.Unix(q, 0)
+	sec, frac := math.Modf(q)
+	t.Time = time.Unix(int64(sec), int64(frac*1e9))
 	return
 }
 
@@ -549,12 +1355,27 @@ type Settings struct {
 	// lines from the display terminal log.
 	TerminalFilters []*TerminalFilter `json:"terminalFilters"`
 	// Webcam settings to configure webcam support.
-	Webcam *WebcamConfig `json:"json"`
+	Webcam *WebcamConfig `json:"webcam"`
+	// Appearance settings to configure the look of the UI.
+	Appearance *AppearanceConfig `json:"appearance"`
+	// Printer settings of the printer.
+	Printer *PrinterConfig `json:"printer"`
+	// Scripts GCODE scripts configured on the server.
+	Scripts *ScriptsConfig `json:"scripts"`
+	// Plugins settings of every plugin, by plugin identifier. Each plugin
+	// defines its own schema, hence they are left undecoded.
+	Plugins map[string]json.RawMessage `json:"plugins"`
+}
+
+// Plugin decodes the settings of the plugin with the given identifier into v.
+// Returns false if there are no settings for the plugin.
+func (s *Settings) Plugin(id string, v interface{}) (bool, error) {
+	raw, ok := s.Plugins[id]
+	if !ok {
+		return false, nil
+	}
 
-	// Un-handled values
-	Appearance interface{} `json:"appearance"`
-	Plugins    interface{} `json:"plugins"`
-	Printer    interface{} `json:"printer"`
+	return true, json.Unmarshal(raw, v)
 }
 
 // APIConfig REST API settings.
@@ -563,6 +1384,46 @@ type APIConfig struct {
 	Enabled bool `json:"enabled"`
 	// Key current API key needed for accessing the API
 	Key string `json:"key"`
+	// AllowCrossOrigin whether to allow cross origin requests to the API.
+	AllowCrossOrigin bool `json:"allowCrossOrigin"`
+}
+
+// AppearanceConfig settings to configure the look of the UI.
+type AppearanceConfig struct {
+	// Name to display in the title bar of the UI.
+	Name string `json:"name"`
+	// Color of the navigation bar, e.g. `default`, `red` or `blue`.
+	Color string `json:"color"`
+	// ColorTransparent whether to make the color of the navigation bar
+	// transparent.
+	ColorTransparent bool `json:"colorTransparent"`
+	// ColorIcon whether to also use the color for the icon of the UI.
+	ColorIcon bool `json:"colorIcon"`
+	// DefaultLanguage of the UI, `_default` for the browser's language.
+	DefaultLanguage string `json:"defaultLanguage"`
+	// ShowFahrenheitAlso whether to also show temperatures in Fahrenheit.
+	ShowFahrenheitAlso bool `json:"showFahrenheitAlso"`
+	// FuzzyTimes whether to show fuzzy times (e.g. “in 2 minutes”).
+	FuzzyTimes bool `json:"fuzzyTimes"`
+	// CloseModalsWithClick whether modal dialogs can be closed by clicking
+	// outside of them.
+	CloseModalsWithClick bool `json:"closeModalsWithClick"`
+	// ShowInternalFilename whether to show the internal filename of the files
+	// besides their display name.
+	ShowInternalFilename bool `json:"showInternalFilename"`
+}
+
+// PrinterConfig settings of the printer.
+type PrinterConfig struct {
+	// DefaultExtrusionLength is the default length to extrude, in mm.
+	DefaultExtrusionLength float64 `json:"defaultExtrusionLength"`
+}
+
+// ScriptsConfig GCODE scripts configured on the server.
+type ScriptsConfig struct {
+	// GCode scripts by name, e.g. `afterPrintCancelled` or
+	// `beforePrintStarted`.
+	GCode map[string]string `json:"gcode"`
 }
 
 // FeaturesConfig settings to enable or disable OctoPrint features.
@@ -570,7 +1431,7 @@ type FeaturesConfig struct {
 	// SizeThreshold maximum size a GCODE file may have to automatically be
 	// loaded into the viewer, defaults to 20MB. Maps to
 	// gcodeViewer.sizeThreshold in config.yaml.
-	SizeThreshold uint64
+	SizeThreshold uint64 `json:"sizeThreshold"`
 	// MobileSizeThreshold maximum size a GCODE file may have on mobile devices
 	// to automatically be loaded into the viewer, defaults to 2MB. Maps to
 	// gcodeViewer.mobileSizeThreshold in config.yaml.
@@ -630,6 +1491,18 @@ type FeaturesConfig struct {
 	// BlockWhileDwelling whether to block all sending to the printer while a G4
 	// (dwell) command is active (true, repetier) or not (false).
 	BlockWhileDwelling bool `json:"blockWhileDwelling"`
+	// PrintStartConfirmation whether to show a confirmation on print start
+	// (true) or not (false).
+	PrintStartConfirmation bool `json:"printStartConfirmation"`
+	// UploadOverwriteConfirmation whether to show a confirmation before
+	// overwriting an existing file on upload (true) or not (false).
+	UploadOverwriteConfirmation bool `json:"uploadOverwriteConfirmation"`
+	// G90InfluencesExtruder whether G90 also sets the extruder to absolute
+	// mode (true) or not (false).
+	G90InfluencesExtruder bool `json:"g90InfluencesExtruder"`
+	// AutoUppercaseBlacklist commands that should never be auto-uppercased
+	// when sent from the terminal.
+	AutoUppercaseBlacklist []string `json:"autoUppercaseBlacklist"`
 }
 
 // FolderConfig settings to set custom paths for folders used by OctoPrint.
@@ -716,9 +1589,14 @@ type SerialConfig struct {
 	// TriggerOkForM29 whether to "manually" trigger an ok for M29 (a lot of
 	// versions of this command are buggy and the responds skips on the ok)
 	TriggerOkForM29 bool `json:"triggerOkForM29"`
-	// SupportResendsWIthoutOk whether to support resends without follow-up ok
+	// SupportResendsWithoutOk whether to support resends without follow-up ok
 	// or not.
-	SupportResendsWIthoutOk string `json:"supportResendsWIthoutOk"`
+	SupportResendsWithoutOk string `json:"supportResendsWithoutOk"`
+	// SupportResendsWIthoutOk is set along SupportResendsWithoutOk when
+	// decoding.
+	//
+	// Deprecated: use SupportResendsWithoutOk.
+	SupportResendsWIthoutOk string `json:"-"`
 	// Maps to serial.maxCommunicationTimeouts.idle in config.yaml
 	MaxTimeoutsIdle float64 `json:"maxTimeoutsIdle"`
 	// MaxTimeoutsPrinting maximum number of consecutive communication timeouts
@@ -732,6 +1610,28 @@ type SerialConfig struct {
 	MaxTimeoutsLong float64 `json:"maxTimeoutsLong"`
 }
 
+type serialConfig SerialConfig
+
+func (c *SerialConfig) UnmarshalJSON(b []byte) error {
+	i := &serialConfig{}
+	if err := json.Unmarshal(b, i); err != nil {
+		return err
+	}
+
+	i.SupportResendsWIthoutOk = i.SupportResendsWithoutOk
+	*c = SerialConfig(*i)
+	return nil
+}
+
+func (c SerialConfig) MarshalJSON() ([]byte, error) {
+	i := serialConfig(c)
+	if i.SupportResendsWithoutOk == "" {
+		i.SupportResendsWithoutOk = i.SupportResendsWIthoutOk
+	}
+
+	return json.Marshal(i)
+}
+
 // ServerConfig settings to configure the server.
 type ServerConfig struct {
 	// Commands to restart/shutdown octoprint or the system it's running on.
@@ -775,6 +1675,8 @@ type ServerConfig struct {
 		// TTL is time to live of the cached blacklist, in secs (default: 15mins)
 		TTL int `json:"ttl"`
 	} `json:"pluginBlacklist"`
+	// AllowFraming whether to allow the UI to be embedded in frames.
+	AllowFraming bool `json:"allowFraming"`
 }
 
 // TemperatureConfig temperature profiles which will be displayed in the
@@ -802,6 +1704,10 @@ type TerminalFilter struct {
 
 // WebcamConfig settings to configure webcam support.
 type WebcamConfig struct {
+	// WebcamEnabled whether to enable the webcam support in the UI.
+	WebcamEnabled bool `json:"webcamEnabled"`
+	// TimelapseEnabled whether to enable the timelapse support.
+	TimelapseEnabled bool `json:"timelapseEnabled"`
 	// StreamUrl use this option to enable display of a webcam stream in the
 	// UI, e.g. via MJPG-Streamer. Webcam support will be disabled if not
 	// set. Maps to webcam.stream in config.yaml.
@@ -810,25 +1716,802 @@ type WebcamConfig struct {
 	// e.g. via MJPG-Streamer. Timelapse support will be disabled if not set.
 	// Maps to webcam.snapshot in config.yaml.
 	SnapshotURL string `json:"snapshotUrl"`
+	// StreamRatio is the aspect ratio of the stream, `16:9` or `4:3`.
+	StreamRatio string `json:"streamRatio"`
+	// StreamTimeout time to wait for the stream to load, in seconds.
+	StreamTimeout int `json:"streamTimeout"`
+	// SnapshotTimeout time to wait for a snapshot, in seconds.
+	SnapshotTimeout int `json:"snapshotTimeout"`
+	// SnapshotSSLValidation whether to validate the SSL certificate of the
+	// snapshot URL.
+	SnapshotSSLValidation bool `json:"snapshotSslValidation"`
 	// FFmpegPath path to ffmpeg binary to use for creating timelapse
 	// recordings. Timelapse support will be disabled if not set. Maps to
 	// webcam.ffmpeg in config.yaml.
 	FFmpegPath string `json:"ffmpegPath"`
 	// Bitrate to use for rendering the timelapse video. This gets directly
-	// passed to ffmpeg.
-	Bitrate int `json:"bitrate"`
+	// passed to ffmpeg, e.g. `10000k`.
+	Bitrate string `json:"bitrate"`
 	// FFmpegThreads number of how many threads to instruct ffmpeg to use for
 	// encoding. Defaults to 1. Should be left at 1 for RPi1.
 	FFmpegThreads int `json:"ffmpegThreads"`
+	// FFmpegVideoCodec is the video codec used to render the timelapse
+	// recordings, e.g. `libx264`.
+	FFmpegVideoCodec string `json:"ffmpegVideoCodec"`
 	// Watermark whether to include a "created with OctoPrint" watermark in the
 	// generated timelapse movies.
-	Watermark string `json:"watermark"`
+	Watermark bool `json:"watermark"`
 	// FlipH whether to flip the webcam horizontally.
 	FlipH bool `json:"flipH"`
 	// FlipV whether to flip the webcam vertically.
 	FlipV bool `json:"flipV"`
 	// Rotate90 whether to rotate the webcam 90° counter clockwise.
 	Rotate90 bool `json:"rotate90"`
+	// CacheBuster whether to append a cache buster to the stream URL.
+	CacheBuster bool `json:"cacheBuster"`
+}
+
+// AppKeyResponse is the response to an AppKeyRequest.
+type AppKeyResponse struct {
+	// AppToken is the token to use to poll the decision of the user.
+	AppToken string `json:"app_token"`
+}
+
+// AppKeyPollResponse is the response to an AppKeyPollRequest.
+type AppKeyPollResponse struct {
+	// APIKey is the granted API key, empty while the decision of the user is
+	// still pending.
+	APIKey string `json:"api_key"`
+}
+
+// APIKeyResponse is the response to an API key generation request.
+type APIKeyResponse struct {
+	// APIKey is the newly generated API key.
+	APIKey string `json:"apikey"`
+}
+
+// User describes an OctoPrint user.
+type User struct {
+	// Name is the name of the user.
+	Name string `json:"name"`
+	// Active whether the user account is active.
+	Active bool `json:"active"`
+	// Admin whether the user has admin rights.
+	Admin bool `json:"admin"`
+	// User whether the user has user rights.
+	User bool `json:"user"`
+	// APIKey is the API key of the user, if one was generated.
+	APIKey string `json:"apikey"`
+	// Groups is the list of groups the user belongs to.
+	Groups []string `json:"groups"`
+	// Permissions is the list of permissions granted directly to the user.
+	Permissions []string `json:"permissions"`
+	// Roles is the list of roles of the user, deprecated in favour of groups
+	// and permissions.
+	Roles []string `json:"roles"`
+	// Settings are the per user settings.
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// UsersResponse is the response to the users requests.
+type UsersResponse struct {
+	// Users is the list of registered users.
+	Users []*User `json:"users"`
+}
+
+// Group describes a permission group.
+type Group struct {
+	// Key is the identifier of the group.
+	Key string `json:"key"`
+	// Name is the display name of the group.
+	Name string `json:"name"`
+	// Description of the group.
+	Description string `json:"description"`
+	// Permissions keys of the permissions assigned to the group.
+	Permissions []string `json:"permissions"`
+	// Subgroups keys of the groups included in the group.
+	Subgroups []string `json:"subgroups"`
+	// Needs are the needs granted by the group, by type.
+	Needs map[string][]string `json:"needs"`
+	// Default whether the group is assigned to new users by default.
+	Default bool `json:"default"`
+	// Removable whether the group can be removed.
+	Removable bool `json:"removable"`
+	// Changeable whether the group can be modified.
+	Changeable bool `json:"changeable"`
+	// Toggleable whether the group can be assigned to or removed from users.
+	Toggleable bool `json:"toggleable"`
+}
+
+// GroupsResponse is the response to the groups requests.
+type GroupsResponse struct {
+	// Groups is the list of permission groups.
+	Groups []*Group `json:"groups"`
+}
+
+// Permission describes a permission that can be granted to groups and users.
+type Permission struct {
+	// Key is the identifier of the permission.
+	Key string `json:"key"`
+	// Name is the display name of the permission.
+	Name string `json:"name"`
+	// Description of the permission.
+	Description string `json:"description"`
+	// Dangerous whether the permission is considered dangerous, mostly
+	// because it allows to mess with the server or the printer.
+	Dangerous bool `json:"dangerous"`
+	// DefaultGroups keys of the groups the permission is assigned to by
+	// default.
+	DefaultGroups []string `json:"default_groups"`
+	// Needs are the needs fulfilled by the permission, by type.
+	Needs map[string][]string `json:"needs"`
+}
+
+// PermissionsResponse is the response to a PermissionsRequest.
+type PermissionsResponse struct {
+	// Permissions is the list of available permissions.
+	Permissions []*Permission `json:"permissions"`
+}
+
+// Find returns the permission with the given key, nil if not found.
+func (r *PermissionsResponse) Find(key string) *Permission {
+	for _, p := range r.Permissions {
+		if p.Key == key {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// LoginResponse is the response to a LoginRequest.
+type LoginResponse struct {
+	User
+	// Session is the identifier of the login session, used e.g. to
+	// authenticate the push socket.
+	Session string `json:"session"`
+	// IsExternalClient whether the client is considered external to the
+	// local network of the server.
+	IsExternalClient bool `json:"_is_external_client"`
+}
+
+// LogFile describes a log file of the server.
+type LogFile struct {
+	// Name of the log file, e.g. `octoprint.log`.
+	Name string `json:"name"`
+	// Size of the log file in bytes.
+	Size uint64 `json:"size"`
+	// Date when the log file was last modified.
+	Date UnixTime `json:"date"`
+	// Refs references relevant to this log file.
+	Refs Reference `json:"refs"`
+}
+
+// LogsResponse is the response to a LogsRequest.
+type LogsResponse struct {
+	// Files is the list of available log files.
+	Files []*LogFile `json:"files"`
+	// Free is the amount of disk space available in the logs folder, in
+	// bytes.
+	Free uint64 `json:"free"`
+	// Total is the total disk space of the logs folder, in bytes.
+	Total uint64 `json:"total"`
+}
+
+// LoggingSetup is the logging configuration of the server.
+type LoggingSetup struct {
+	// Loggers is the list of available loggers.
+	Loggers []string `json:"loggers"`
+	// Levels are the configured levels, by logger name.
+	Levels map[string]string `json:"levels"`
+}
+
+// UtilTestPathResponse is the response to a UtilTestPathRequest.
+type UtilTestPathResponse struct {
+	// Path that was tested.
+	Path string `json:"path"`
+	// Exists whether the path exists.
+	Exists bool `json:"exists"`
+	// TypeOK whether the path is of the expected type.
+	TypeOK bool `json:"typeok"`
+	// BrokenSymlink whether the path is a broken symlink.
+	BrokenSymlink bool `json:"broken_symlink"`
+	// Access whether the path has the expected access.
+	Access bool `json:"access"`
+	// Result whether all the checks were successful.
+	Result bool `json:"result"`
+}
+
+// UtilTestURLResponse is the response to a UtilTestURLRequest.
+type UtilTestURLResponse struct {
+	// URL that was tested.
+	URL string `json:"url"`
+	// Status is the status code of the response.
+	Status int `json:"status"`
+	// Result whether the test was successful.
+	Result bool `json:"result"`
+	// Response is the response, if requested.
+	Response *struct {
+		// Headers of the response.
+		Headers map[string]string `json:"headers"`
+		// Content of the response, either the decoded JSON or the base64
+		// encoded bytes, depending on the requested response.
+		Content json.RawMessage `json:"content"`
+		// ContentType of the response.
+		ContentType string `json:"content_type"`
+	} `json:"response"`
+}
+
+// UtilTestServerResponse is the response to a UtilTestServerRequest.
+type UtilTestServerResponse struct {
+	// Host that was tested.
+	Host string `json:"host"`
+	// Port that was tested.
+	Port int `json:"port"`
+	// Protocol used for the test.
+	Protocol string `json:"protocol"`
+	// Result whether the server was reachable.
+	Result bool `json:"result"`
+}
+
+// UtilTestResolutionResponse is the response to a UtilTestResolutionRequest.
+type UtilTestResolutionResponse struct {
+	// Name that was resolved.
+	Name string `json:"name"`
+	// Result whether the name could be resolved.
+	Result bool `json:"result"`
+}
+
+// UtilTestAddressResponse is the response to a UtilTestAddressRequest.
+type UtilTestAddressResponse struct {
+	// Address that was tested.
+	Address string `json:"address"`
+	// IsLANAddress whether the address is part of the local network.
+	IsLANAddress bool `json:"is_lan_address"`
+	// Result whether the address is part of the local network.
+	Result bool `json:"result"`
+}
+
+// Wizard describes a setup wizard.
+type Wizard struct {
+	// Required whether the wizard needs to be completed.
+	Required bool `json:"required"`
+	// Ignored whether the wizard was ignored by the user.
+	Ignored bool `json:"ignored"`
+	// Version of the wizard, wizards are shown again if their version
+	// changes. Nil if the wizard is not versioned.
+	Version *int `json:"version"`
+	// Details are wizard specific details.
+	Details map[string]interface{} `json:"details"`
+}
+
+// WizardResponse is the response to a WizardRequest, the wizards by name.
+type WizardResponse map[string]*Wizard
+
+// Required returns the sorted names of the wizards still required.
+func (r WizardResponse) Required() []string {
+	var names []string
+	for name, w := range r {
+		if w.Required && !w.Ignored {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// TimelapseResponse is the response to the timelapse requests.
+type TimelapseResponse struct {
+	// Config is the current timelapse configuration.
+	Config *TimelapseConfig `json:"config"`
+	// Enabled whether timelapse support is enabled.
+	Enabled bool `json:"enabled"`
+	// Files are the finished timelapses.
+	Files []*TimelapseFile `json:"files"`
+	// Unrendered are the unrendered timelapses, only retrieved if requested.
+	Unrendered []*UnrenderedTimelapse `json:"unrendered"`
+}
+
+// TimelapseConfig is the timelapse configuration.
+type TimelapseConfig struct {
+	// Type of timelapse, `off`, `zchange` or `timed`.
+	Type string `json:"type"`
+	// PostRoll is the amount of seconds to keep recording after the print
+	// finished.
+	PostRoll int `json:"postRoll,omitempty"`
+	// FPS is the frame rate of the rendered video.
+	FPS int `json:"fps,omitempty"`
+	// Interval between snapshots in seconds, only for `timed` timelapses.
+	Interval int `json:"interval,omitempty"`
+	// RetractionZHop is the z-hop of retractions to ignore, in mm, only for
+	// `zchange` timelapses.
+	RetractionZHop float64 `json:"retractionZHop,omitempty"`
+	// MinDelay is the minimum delay between snapshots in seconds, only for
+	// `zchange` timelapses.
+	MinDelay float64 `json:"minDelay,omitempty"`
+}
+
+// TimelapseFile describes a finished timelapse.
+type TimelapseFile struct {
+	// Name is the filename of the timelapse.
+	Name string `json:"name"`
+	// Size is the human readable size of the timelapse, e.g. `1.2MB`.
+	Size string `json:"size"`
+	// Bytes is the size of the timelapse in bytes.
+	Bytes uint64 `json:"bytes"`
+	// Date is the recording date, formatted as `2006-01-02 15:04`.
+	Date string `json:"date"`
+	// Timestamp is the recording date.
+	Timestamp UnixTime `json:"timestamp"`
+	// URL to download the timelapse.
+	URL string `json:"url"`
+}
+
+// UnrenderedTimelapse describes a timelapse whose frames haven't been rendered
+// into a video yet.
+type UnrenderedTimelapse struct {
+	// Name of the timelapse.
+	Name string `json:"name"`
+	// Size is the human readable size of the frames, e.g. `1.2MB`.
+	Size string `json:"size"`
+	// Bytes is the size of the frames in bytes.
+	Bytes uint64 `json:"bytes"`
+	// Date is the recording date, formatted as `2006-01-02 15:04`.
+	Date string `json:"date"`
+	// Timestamp is the recording date.
+	Timestamp UnixTime `json:"timestamp"`
+	// Recording whether the timelapse is still being recorded.
+	Recording bool `json:"recording"`
+	// Rendering whether the timelapse is being rendered.
+	Rendering bool `json:"rendering"`
+	// Processing whether the timelapse is being processed.
+	Processing bool `json:"processing"`
+}
+
+// Slicer describes a slicer available on the server.
+type Slicer struct {
+	// Key is the identifier of the slicer.
+	Key string `json:"key"`
+	// DisplayName of the slicer.
+	DisplayName string `json:"displayName"`
+	// Default whether the slicer is the default one.
+	Default bool `json:"default"`
+	// Configured whether the slicer is configured and can be used.
+	Configured bool `json:"configured"`
+	// Profiles of the slicer, by identifier. The data of the profiles is not
+	// included.
+	Profiles map[string]*SlicingProfile `json:"profiles"`
+}
+
+// SlicingProfile describes a profile of a slicer.
+type SlicingProfile struct {
+	// Key is the identifier of the profile.
+	Key string `json:"key"`
+	// DisplayName of the profile.
+	DisplayName string `json:"displayName"`
+	// Description of the profile.
+	Description string `json:"description"`
+	// Default whether the profile is the default of the slicer.
+	Default bool `json:"default"`
+	// Resource is the URL of the profile in the API.
+	Resource string `json:"resource"`
+	// Data are the slicer specific settings of the profile, only included
+	// when retrieving a single profile.
+	Data map[string]interface{} `json:"data"`
+}
+
+// Backup describes a backup available on the server.
+type Backup struct {
+	// Name is the filename of the backup.
+	Name string `json:"name"`
+	// Date when the backup was created.
+	Date UnixTime `json:"date"`
+	// Size of the backup in bytes.
+	Size uint64 `json:"size"`
+	// URL to download the backup.
+	URL string `json:"url"`
+}
+
+// BackupsResponse is the response to a BackupsRequest.
+type BackupsResponse struct {
+	// Backups is the list of available backups.
+	Backups []*Backup `json:"backups"`
+	// InProgress whether a backup is currently being created.
+	InProgress bool `json:"backup_in_progress"`
+	// RestoreSupported whether restoring backups is supported on the server.
+	RestoreSupported bool `json:"restore_supported"`
+	// UnknownPlugins are plugins included in backups that are not available
+	// for installation.
+	UnknownPlugins []interface{} `json:"unknown_plugins"`
+}
+
+// BackupCreateResponse is the response to a BackupCreateRequest.
+type BackupCreateResponse struct {
+	// Started whether the creation of the backup was started.
+	Started bool `json:"started"`
+	// Name is the filename of the backup being created.
+	Name string `json:"name"`
+}
+
+// SoftwareUpdateCheckResponse is the response to a SoftwareUpdateCheckRequest.
+type SoftwareUpdateCheckResponse struct {
+	// Status is the overall status, `current`, `updateAvailable`,
+	// `updatePossible` or `inProgress`.
+	Status string `json:"status"`
+	// Information about every component, by identifier, e.g. `octoprint`.
+	Information map[string]*SoftwareUpdateComponent `json:"information"`
+}
+
+// Available returns the identifiers of the components with updates available.
+func (r *SoftwareUpdateCheckResponse) Available() []string {
+	var ids []string
+	for id, c := range r.Information {
+		if c.UpdateAvailable {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+// SoftwareUpdateComponent describes the update information of a component.
+type SoftwareUpdateComponent struct {
+	// DisplayName of the component.
+	DisplayName string `json:"displayName"`
+	// DisplayVersion is the current version of the component, to display.
+	DisplayVersion string `json:"displayVersion"`
+	// Information are the current and available versions.
+	Information struct {
+		// Local is the installed version.
+		Local SoftwareUpdateVersion `json:"local"`
+		// Remote is the latest available version.
+		Remote SoftwareUpdateVersion `json:"remote"`
+	} `json:"information"`
+	// UpdateAvailable whether an update is available.
+	UpdateAvailable bool `json:"updateAvailable"`
+	// UpdatePossible whether the update can be applied.
+	UpdatePossible bool `json:"updatePossible"`
+	// Online whether the server was online when checking the update.
+	Online bool `json:"online"`
+	// Error is the error reported while checking the update, if any.
+	Error string `json:"error"`
+	// ReleaseNotes is the URL of the release notes.
+	ReleaseNotes string `json:"releaseNotes"`
+}
+
+// SoftwareUpdateVersion is a version of a component.
+type SoftwareUpdateVersion struct {
+	// Name of the version, to display.
+	Name string `json:"name"`
+	// Value is the version identifier.
+	Value string `json:"value"`
+}
+
+// SoftwareUpdateResponse is the response to a SoftwareUpdateRequest.
+type SoftwareUpdateResponse struct {
+	// Order are the components that will be updated, in order.
+	Order []string `json:"order"`
+}
+
+// PluginListResponse is the response to a PluginListRequest.
+type PluginListResponse struct {
+	// Plugins is the list of installed plugins.
+	Plugins []*PluginInfo `json:"plugins"`
+}
+
+// Find returns the plugin with the given key, nil if not found.
+func (r *PluginListResponse) Find(key string) *PluginInfo {
+	for _, p := range r.Plugins {
+		if p.Key == key {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// PluginInfo describes an installed plugin.
+type PluginInfo struct {
+	// Key is the identifier of the plugin.
+	Key string `json:"key"`
+	// Name of the plugin.
+	Name string `json:"name"`
+	// Description of the plugin.
+	Description string `json:"description"`
+	// Version of the plugin.
+	Version string `json:"version"`
+	// Author of the plugin.
+	Author string `json:"author"`
+	// License of the plugin.
+	License string `json:"license"`
+	// URL is the homepage of the plugin.
+	URL string `json:"url"`
+	// Bundled whether the plugin is bundled with OctoPrint.
+	Bundled bool `json:"bundled"`
+	// Managable whether the plugin can be uninstalled.
+	Managable bool `json:"managable"`
+	// Enabled whether the plugin is enabled.
+	Enabled bool `json:"enabled"`
+	// Blacklisted whether the plugin is blacklisted.
+	Blacklisted bool `json:"blacklisted"`
+	// ForcedDisabled whether the plugin is disabled and can't be enabled.
+	ForcedDisabled bool `json:"forced_disabled"`
+	// Incompatible whether the plugin is incompatible with the server.
+	Incompatible bool `json:"incompatible"`
+	// SafeModeVictim whether the plugin is disabled due to the safe mode.
+	SafeModeVictim bool `json:"safe_mode_victim"`
+	// PendingEnable whether the plugin will be enabled after a restart.
+	PendingEnable bool `json:"pending_enable"`
+	// PendingDisable whether the plugin will be disabled after a restart.
+	PendingDisable bool `json:"pending_disable"`
+	// PendingInstall whether the plugin will be installed after a restart.
+	PendingInstall bool `json:"pending_install"`
+	// PendingUninstall whether the plugin will be uninstalled after a
+	// restart.
+	PendingUninstall bool `json:"pending_uninstall"`
+	// Origin of the plugin, `entry_point` or `folder`.
+	Origin string `json:"origin"`
+	// Notifications are the notices published for the installed version of
+	// the plugin.
+	Notifications []*PluginNotice `json:"notifications"`
+}
+
+// PluginNotice is a notice published about a plugin, e.g. a known issue.
+type PluginNotice struct {
+	// Text of the notice.
+	Text string `json:"text"`
+	// Date when the notice was published.
+	Date string `json:"date"`
+	// Link to more information.
+	Link string `json:"link"`
+	// Important whether the notice is important.
+	Important bool `json:"important"`
+	// Versions of the plugin the notice applies to, all if empty.
+	Versions []string `json:"versions"`
+}
+
+// PluginRepository is the plugin repository.
+type PluginRepository struct {
+	// Available whether the repository could be retrieved.
+	Available bool `json:"available"`
+	// Plugins is the list of plugins on the repository.
+	Plugins []*RepositoryPlugin `json:"plugins"`
+}
+
+// Find returns the plugin with the given identifier, nil if not found.
+func (r *PluginRepository) Find(id string) *RepositoryPlugin {
+	for _, p := range r.Plugins {
+		if p.ID == id {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// RepositoryPlugin describes a plugin on the plugin repository.
+type RepositoryPlugin struct {
+	// ID is the identifier of the plugin.
+	ID string `json:"id"`
+	// Title of the plugin.
+	Title string `json:"title"`
+	// Description of the plugin.
+	Description string `json:"description"`
+	// Author of the plugin.
+	Author string `json:"author"`
+	// License of the plugin.
+	License string `json:"license"`
+	// Homepage of the plugin.
+	Homepage string `json:"homepage"`
+	// Archive is the URL to install the plugin from.
+	Archive string `json:"archive"`
+	// FollowDependencyLinks whether the dependency links need to be
+	// followed when installing the plugin.
+	FollowDependencyLinks bool `json:"follow_dependency_links"`
+	// IsCompatible reports the compatibility with the server.
+	IsCompatible struct {
+		OctoPrint bool `json:"octoprint"`
+		OS        bool `json:"os"`
+		Python    bool `json:"python"`
+	} `json:"is_compatible"`
+}
+
+// PluginCommandResponse is the response to a plugin manager command.
+type PluginCommandResponse struct {
+	// Result whether the command was successful.
+	Result bool `json:"result"`
+	// InProgress whether the command is still being processed.
+	InProgress bool `json:"in_progress"`
+	// NeedsRestart whether the server must be restarted to apply the
+	// change.
+	NeedsRestart bool `json:"needs_restart"`
+	// NeedsRefresh whether the UI must be refreshed to apply the change.
+	NeedsRefresh bool `json:"needs_refresh"`
+	// NeedsReconnect whether the printer must be reconnected to apply the
+	// change.
+	NeedsReconnect bool `json:"needs_reconnect"`
+	// Plugin is the affected plugin, if any.
+	Plugin *PluginInfo `json:"plugin"`
+}
+
+// AnnouncementChannel is a channel of the announcements plugin.
+type AnnouncementChannel struct {
+	// Channel is the identifier of the channel.
+	Channel string `json:"channel"`
+	// Name of the channel.
+	Name string `json:"name"`
+	// Description of the channel.
+	Description string `json:"description"`
+	// Priority of the channel, 1 being the highest.
+	Priority int `json:"priority"`
+	// Enabled whether the channel is enabled.
+	Enabled bool `json:"enabled"`
+	// Forced whether the channel can't be disabled.
+	Forced bool `json:"forced"`
+	// Data are the entries of the channel.
+	Data []*Announcement `json:"data"`
+	// Unread is the number of unread entries.
+	Unread int `json:"unread"`
+	// ReadUntil is the publication date up to which the entries were read,
+	// as unix timestamp.
+	ReadUntil int64 `json:"read_until"`
+	// URL of the channel feed.
+	URL string `json:"url"`
+}
+
+// Latest returns the publication date of the newest entry, as unix timestamp.
+func (c *AnnouncementChannel) Latest() int64 {
+	var latest int64
+	for _, a := range c.Data {
+		if a.Published > latest {
+			latest = a.Published
+		}
+	}
+
+	return latest
+}
+
+// Announcement is an entry of an announcement channel.
+type Announcement struct {
+	// Title of the entry, may contain HTML.
+	Title string `json:"title"`
+	// TitleWithoutTags is the title without HTML tags.
+	TitleWithoutTags string `json:"title_without_tags"`
+	// Summary of the entry, may contain HTML.
+	Summary string `json:"summary"`
+	// SummaryWithoutTags is the summary without HTML tags.
+	SummaryWithoutTags string `json:"summary_without_tags"`
+	// Published is the publication date, as unix timestamp.
+	Published int64 `json:"published"`
+	// Link to the full entry.
+	Link string `json:"link"`
+	// Read whether the entry was read.
+	Read bool `json:"read"`
+}
+
+// FirmwareCheckResponse is the response to a FirmwareCheckRequest.
+type FirmwareCheckResponse struct {
+	// Warnings are the active warnings, empty if no issue was detected.
+	Warnings []*FirmwareWarning `json:"warnings"`
+}
+
+func (r *FirmwareCheckResponse) UnmarshalJSON(b []byte) error {
+	raw := struct {
+		Warnings json.RawMessage `json:"warnings"`
+	}{}
+
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	r.Warnings = nil
+	if len(raw.Warnings) == 0 {
+		return nil
+	}
+
+	if raw.Warnings[0] != '{' {
+		return json.Unmarshal(raw.Warnings, &r.Warnings)
+	}
+
+	// warnings may also be reported by check identifier.
+	var byID map[string]*FirmwareWarning
+	if err := json.Unmarshal(raw.Warnings, &byID); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(byID))
+	for id := range byID {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		w := byID[id]
+		if w.Type == "" {
+			w.Type = id
+		}
+
+		r.Warnings = append(r.Warnings, w)
+	}
+
+	return nil
+}
+
+// Critical returns true if any of the warnings is critical.
+func (r *FirmwareCheckResponse) Critical() bool {
+	for _, w := range r.Warnings {
+		if w.Severity == "critical" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FirmwareWarning is a warning about the firmware of the printer.
+type FirmwareWarning struct {
+	// Type is the identifier of the check raising the warning, e.g.
+	// `firmware-unsafe`.
+	Type string `json:"type"`
+	// Message describing the issue.
+	Message string `json:"message"`
+	// Severity of the warning, `critical` or `warning`.
+	Severity string `json:"severity"`
+	// URL with further information about the issue.
+	URL string `json:"url"`
+}
+
+// PiSupportResponse is the response to a PiSupportRequest.
+type PiSupportResponse struct {
+	// Model of the Raspberry Pi, e.g. “Raspberry Pi 4 Model B Rev 1.1”.
+	Model string `json:"model"`
+	// OctoPiVersion is the version of OctoPi, if running on it.
+	OctoPiVersion string `json:"octopi_version"`
+	// ThrottleState is the current throttle state.
+	ThrottleState ThrottleState `json:"throttle_state"`
+}
+
+// ThrottleState is the throttle state of a Raspberry Pi.
+type ThrottleState struct {
+	// CurrentUndervoltage whether the supply voltage is currently too low.
+	CurrentUndervoltage bool `json:"current_undervoltage"`
+	// PastUndervoltage whether the supply voltage was too low since boot.
+	PastUndervoltage bool `json:"past_undervoltage"`
+	// CurrentOverheat whether the Pi is currently overheating.
+	CurrentOverheat bool `json:"current_overheat"`
+	// PastOverheat whether the Pi overheated since boot.
+	PastOverheat bool `json:"past_overheat"`
+	// CurrentIssue whether there is currently any issue.
+	CurrentIssue bool `json:"current_issue"`
+	// PastIssue whether there was any issue since boot.
+	PastIssue bool `json:"past_issue"`
+	// RawValue is the raw value reported by the firmware, see ThrottleFlag.
+	RawValue int64 `json:"raw_value"`
+}
+
+// Has returns true if the given flag is set on the raw value.
+func (s *ThrottleState) Has(f ThrottleFlag) bool {
+	return s.RawValue&int64(f) != 0
+}
+
+// ConnectivityResponse is the response to a ConnectivityRequest.
+type ConnectivityResponse struct {
+	// Enabled whether the connectivity check is enabled, if disabled the
+	// server is always assumed to be online.
+	Enabled bool `json:"enabled"`
+	// Online whether the server has internet access.
+	Online bool `json:"online"`
+	// ConnectionOK whether the check host could be reached.
+	ConnectionOK bool `json:"connection_ok"`
+	// ConnectionCheck is the host and port used to check the connection,
+	// e.g. `1.1.1.1:53`.
+	ConnectionCheck string `json:"connection_check"`
+	// ResolutionOK whether the check name could be resolved.
+	ResolutionOK bool `json:"resolution_ok"`
+	// ResolutionCheck is the name used to check the name resolution.
+	ResolutionCheck string `json:"resolution_check"`
 }
 
 // TemperatureProfile describes the temperature profile preset for a given
@@ -837,4 +2520,5 @@ type TemperatureProfile struct {
 	Name     string  `json:"name"`
 	Bed      float64 `json:"bed"`
 	Extruder float64 `json:"extruder"`
+	Chamber  float64 `json:"chamber,omitempty"`
 }
@@ -3,6 +3,7 @@ package octoprint
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -27,8 +28,12 @@ func TestHistoricTemperatureData(t *testing.T) {
 
 	assert.Len(t, h.Tools, 2)
 	assert.False(t, h.Time.IsZero())
-	assert.Equal(t, h.Tools["tool0"].Target, 220.)
+	assert.Equal(t, 220., *h.Tools["tool0"].Target)
+	assert.True(t, h.Tools["tool0"].IsHeating())
+	assert.Nil(t, h.Tools["tool0"].Offset)
 	assert.Equal(t, h.Tools["tool1"].Actual, 25.3)
+	assert.Nil(t, h.Tools["tool1"].Target)
+	assert.False(t, h.Tools["tool1"].IsHeating())
 }
 
 func TestTemperatureState(t *testing.T) {
@@ -76,12 +81,84 @@ func TestTemperatureState(t *testing.T) {
 	assert.Len(t, r.Current, 2)
 	assert.Equal(t, r.Current["tool0"].Actual, 214.8821)
 	assert.Equal(t, r.Current["tool1"].Actual, 25.3)
+	assert.Nil(t, r.Current["tool1"].Target)
+	assert.Equal(t, 0., *r.Current["tool1"].Offset)
 
 	assert.Len(t, r.History, 2)
 	assert.Equal(t, r.History[0].Tools["tool0"].Actual, 214.8821)
 	assert.Equal(t, r.History[1].Tools["tool0"].Actual, 212.32)
 }
 
+// printerHistoryJSON is the temperature of a printer state returned by
+// OctoPrint 1.9 with `history=true&limit=2`, with a chamber not present.
+const printerHistoryJSON = `{
+	"bed": {"actual": 59.98, "offset": 0, "target": 60.0},
+	"chamber": null,
+	"history": [{
+		"bed": {"actual": 59.96, "target": 60.0},
+		"chamber": null,
+		"time": 1695302611,
+		"tool0": {"actual": 214.91, "target": 215.0}
+	}, {
+		"bed": {"actual": 59.98, "target": 60.0},
+		"chamber": null,
+		"time": 1695302613,
+		"tool0": {"actual": 215.02, "target": 215.0}
+	}],
+	"tool0": {"actual": 215.02, "offset": 0, "target": 215.0}
+}`
+
+func TestTemperatureState_UnmarshalJSONRealPayload(t *testing.T) {
+	r := &TemperatureState{}
+	assert.NoError(t, json.Unmarshal([]byte(printerHistoryJSON), r))
+
+	assert.Len(t, r.Current, 2)
+	assert.Equal(t, 59.98, r.Current["bed"].Actual)
+	assert.NotContains(t, r.Current, "chamber")
+
+	assert.Len(t, r.History, 2)
+	assert.Equal(t, int64(1695302613), r.History[1].Time.Unix())
+	assert.Len(t, r.History[1].Tools, 2)
+	assert.Equal(t, 215.02, r.History[1].Tools["tool0"].Actual)
+}
+
+func TestTemperatureState_UnmarshalJSONMalformed(t *testing.T) {
+	for _, js := range []string{
+		`{"tool0": 215.0}`,
+		`{"tool0": {"actual": "hot"}}`,
+		`{"history": {"time": 1695302611}}`,
+		`{"history": [{"time": "yesterday"}]}`,
+		`{"history": [{"time": 1695302611, "tool0": [215.0]}]}`,
+		`[]`,
+	} {
+		r := &TemperatureState{}
+		assert.Error(t, json.Unmarshal([]byte(js), r), js)
+	}
+
+	// missing keys and null entries are not errors.
+	r := &TemperatureState{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"history": [null, {"tool0": {"actual": 20}}]}`), r))
+	assert.Len(t, r.History, 1)
+	assert.True(t, r.History[0].Time.IsZero())
+	assert.Equal(t, 20., r.History[0].Tools["tool0"].Actual)
+}
+
+// TestTemperatureState_UnmarshalJSONTruncated decodes every prefix and
+// single byte mutation of a real payload, which must never panic.
+func TestTemperatureState_UnmarshalJSONTruncated(t *testing.T) {
+	js := []byte(printerHistoryJSON)
+	for i := range js {
+		json.Unmarshal(js[:i], &TemperatureState{})
+
+		mutated := append([]byte(nil), js...)
+		for _, c := range []byte(`{}[]":,0n`) {
+			mutated[i] = c
+			json.Unmarshal(mutated, &TemperatureState{})
+			json.Unmarshal(mutated, &HistoricTemperatureData{})
+		}
+	}
+}
+
 func TestFullStateResponse(t *testing.T) {
 	js := []byte(`
 		{
@@ -169,8 +246,303 @@ func TestFileInformation_IsFolder(t *testing.T) {
 	assert.False(t, f.IsFolder())
 }
 
-func TestJSONTime_UnmarshalJSONWithNull(t *testing.T) {
-	time := &JSONTime{}
+func TestUnixTime_UnmarshalJSONWithNull(t *testing.T) {
+	time := &UnixTime{}
 	err := time.UnmarshalJSON([]byte("null"))
 	assert.NoError(t, err)
 }
+
+func TestUnixTime_UnmarshalJSONWithFraction(t *testing.T) {
+	time := &UnixTime{}
+	err := time.UnmarshalJSON([]byte("1664450000.25"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1664450000), time.Unix())
+	assert.Equal(t, 250000000, time.Nanosecond())
+}
+
+func TestUnixTime_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(struct {
+		Date  UnixTime `json:"date"`
+		Last  UnixTime `json:"last"`
+		Empty UnixTime `json:"empty"`
+	}{
+		Date: UnixTime{time.Unix(1664450000, 0)},
+		Last: UnixTime{time.Unix(1664450000, 250000000)},
+	})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"date": 1664450000, "last": 1664450000.25, "empty": null}`, string(b))
+}
+
+func TestFileInformation(t *testing.T) {
+	js := []byte(`{
+		"name": "whistle_v2.gcode",
+		"display": "whistle v2.gcode",
+		"path": "whistle_v2.gcode",
+		"type": "machinecode",
+		"typePath": ["machinecode", "gcode"],
+		"hash": "...",
+		"size": 1468987,
+		"date": 1378847754,
+		"origin": "local",
+		"refs": {
+			"resource": "http://example.com/api/files/local/whistle_v2.gcode",
+			"download": "http://example.com/downloads/files/local/whistle_v2.gcode"
+		},
+		"gcodeAnalysis": {
+			"estimatedPrintTime": 1188,
+			"filament": {
+				"tool0": {"length": 810, "volume": 5.36},
+				"tool1": {"length": 12.5, "volume": 0.1}
+			},
+			"dimensions": {"depth": 20.5, "height": 9.8, "width": 40},
+			"printingArea": {"maxX": 120, "maxY": 110.5, "maxZ": 9.8, "minX": 80, "minY": 90, "minZ": 0.2},
+			"travelArea": {"maxX": 235, "maxY": 210, "maxZ": 15, "minX": 0, "minY": -3, "minZ": 0}
+		},
+		"prints": {
+			"failure": 4,
+			"success": 23,
+			"last": {"date": 1387144346, "printTime": 1205.5, "success": true}
+		},
+		"statistics": {
+			"averagePrintTime": {"_default": 1198.3},
+			"lastPrintTime": {"_default": 1205.5}
+		}
+	}`)
+
+	f := &FileInformation{}
+	err := json.Unmarshal(js, f)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "whistle v2.gcode", f.Display)
+	assert.Equal(t, []string{"machinecode", "gcode"}, f.TypePath)
+	assert.Equal(t, uint64(1468987), f.Size)
+	assert.Equal(t, int64(1378847754), f.Date.Unix())
+	assert.Equal(t, "http://example.com/downloads/files/local/whistle_v2.gcode", f.Refs.Download)
+	assert.Equal(t, 1188., f.GCodeAnalysis.EstimatedPrintTime)
+	assert.Len(t, f.GCodeAnalysis.Filament, 2)
+	assert.Equal(t, 810., f.GCodeAnalysis.Filament["tool0"].Length)
+	assert.Equal(t, 40., f.GCodeAnalysis.Dimensions.Width)
+	assert.Equal(t, 1188*time.Second, f.GCodeAnalysis.EstimatedPrintDuration())
+	assert.Equal(t, Area{MinX: 80, MaxX: 120, MinY: 90, MaxY: 110.5, MinZ: 0.2, MaxZ: 9.8}, f.GCodeAnalysis.PrintingArea)
+	assert.Equal(t, -3., f.GCodeAnalysis.TravelArea.MinY)
+	assert.Equal(t, 23, f.Prints.Success)
+	assert.Equal(t, 1205.5, f.Prints.Last.PrintTime)
+	assert.Equal(t, 1198.3, f.Statistics.AveragePrintTime["_default"])
+}
+
+func TestFullStateResponse_UnmarshalJSON(t *testing.T) {
+	js := []byte(`{
+		"state": {"text": "Operational", "flags": {"operational": true}},
+		"sd": {"ready": false},
+		"temperature": {"bed": {"actual": 21.4, "target": 0}},
+		"enclosure": {"temperature": 28.5, "humidity": 41}
+	}`)
+
+	r := &FullStateResponse{}
+	assert.NoError(t, json.Unmarshal(js, r))
+	assert.Equal(t, "Operational", r.State.Text)
+	assert.Len(t, r.Extra, 1)
+	assert.JSONEq(t, `{"temperature": 28.5, "humidity": 41}`, string(r.Extra["enclosure"]))
+	assert.JSONEq(t, string(js), string(r.Raw))
+
+	r = &FullStateResponse{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"state": {"text": "Offline"}}`), r))
+	assert.Nil(t, r.Extra)
+}
+
+func TestFilesResponse_Walk(t *testing.T) {
+	js := []byte(`{
+		"files": [{
+			"name": "folder",
+			"path": "folder",
+			"type": "folder",
+			"typePath": ["folder"],
+			"children": [{
+				"name": "sub",
+				"path": "folder/sub",
+				"type": "folder",
+				"typePath": ["folder"],
+				"children": [{
+					"name": "deep.gcode",
+					"path": "folder/sub/deep.gcode",
+					"type": "machinecode",
+					"typePath": ["machinecode", "gcode"]
+				}]
+			}, {
+				"name": "foo.stl",
+				"path": "folder/foo.stl",
+				"type": "model",
+				"typePath": ["model", "stl"]
+			}]
+		}, {
+			"name": "bar.gcode",
+			"path": "bar.gcode",
+			"type": "machinecode",
+			"typePath": ["machinecode", "gcode"]
+		}],
+		"free": 12345,
+		"total": 54321
+	}`)
+
+	r := &FilesResponse{}
+	err := json.Unmarshal(js, r)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(54321), r.Total)
+
+	var paths []string
+	err = r.Walk(func(f *FileInformation) error {
+		paths = append(paths, f.Path)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"folder", "folder/sub", "folder/sub/deep.gcode", "folder/foo.stl", "bar.gcode",
+	}, paths)
+
+	files := r.Flatten()
+	assert.Len(t, files, 3)
+	assert.Len(t, r.Files[0].Flatten(), 2)
+	assert.Len(t, r.Files[1].Flatten(), 1)
+}
+
+func TestFilesResponse_Find(t *testing.T) {
+	js := []byte(`{
+		"files": [{
+			"name": "folder",
+			"path": "folder",
+			"type": "folder",
+			"children": [{
+				"name": "sub",
+				"path": "folder/sub",
+				"type": "folder",
+				"typePath": ["folder"],
+				"children": [{
+					"name": "deep.gcode",
+					"path": "folder/sub/deep.gcode",
+					"type": "machinecode",
+					"typePath": ["machinecode", "gcode"]
+				}]
+			}]
+		}, {
+			"name": "folder.gcode",
+			"path": "folder.gcode",
+			"type": "machinecode",
+			"typePath": ["machinecode", "gcode"]
+		}]
+	}`)
+
+	r := &FilesResponse{}
+	assert.NoError(t, json.Unmarshal(js, r))
+
+	// the type is enough to detect folders.
+	assert.True(t, r.Files[0].Folder)
+	assert.True(t, r.Files[0].Children[0].Folder)
+	assert.False(t, r.Files[1].Folder)
+
+	f := r.Find("/folder/sub/deep.gcode")
+	assert.NotNil(t, f)
+	assert.Equal(t, "deep.gcode", f.Name)
+	assert.Equal(t, f, r.Files[0].Find("folder/sub/deep.gcode"))
+	assert.Equal(t, "folder.gcode", r.Find("folder.gcode").Name)
+	assert.Equal(t, "sub", r.Find("folder/sub").Name)
+
+	assert.Nil(t, r.Find("folder/missing.gcode"))
+	assert.Nil(t, r.Files[1].Find("folder.gcode"))
+}
+
+func TestPrinterState_UnmarshalJSON(t *testing.T) {
+	js := []byte(`{
+		"text": "Cancelling",
+		"error": "Thermal runaway",
+		"flags": {
+			"operational": true,
+			"printing": false,
+			"pausing": false,
+			"cancelling": true,
+			"resuming": false,
+			"finishing": true,
+			"error": false,
+			"closedOrError": false
+		}
+	}`)
+
+	s := &PrinterState{}
+	err := json.Unmarshal(js, s)
+	assert.NoError(t, err)
+	assert.Equal(t, "Cancelling", s.Text)
+	assert.Equal(t, "Thermal runaway", s.Error)
+	assert.True(t, s.Flags.Operational)
+	assert.True(t, s.Flags.Cancelling)
+	assert.True(t, s.Flags.Finishing)
+	assert.False(t, s.Flags.Pausing)
+	assert.False(t, s.Flags.Resuming)
+
+	// the deprecated fields are still set.
+	assert.True(t, s.Flags.Operations)
+}
+
+func TestPrinterStateFlags_MarshalJSON(t *testing.T) {
+	b, err := json.Marshal(PrinterStateFlags{Operations: true, ClosedOrError: true})
+	assert.NoError(t, err)
+
+	var m map[string]bool
+	assert.NoError(t, json.Unmarshal(b, &m))
+	assert.True(t, m["operational"])
+	assert.True(t, m["closedOrError"])
+	assert.NotContains(t, m, "Operations")
+	assert.NotContains(t, m, "ClosedOnError")
+}
+
+func TestParseAxis(t *testing.T) {
+	a, err := ParseAxis("X")
+	assert.NoError(t, err)
+	assert.Equal(t, XAxis, a)
+	assert.Equal(t, "x", a.String())
+
+	_, err = ParseAxis("e")
+	assert.EqualError(t, err, `invalid axis "e", must be x, y or z`)
+	assert.False(t, Axis("").IsValid())
+}
+
+func TestParseConnectionState(t *testing.T) {
+	for _, s := range []string{"Operational", "Printing from SD", "Error: Too many consecutive timeouts"} {
+		state, err := ParseConnectionState(s)
+		assert.NoError(t, err)
+		assert.Equal(t, s, state.String())
+	}
+
+	_, err := ParseConnectionState("Melting")
+	assert.EqualError(t, err, `unknown connection state "Melting"`)
+	assert.False(t, ConnectionState("").IsValid())
+}
+
+func TestParseLocation(t *testing.T) {
+	l, err := ParseLocation("sdcard")
+	assert.NoError(t, err)
+	assert.Equal(t, SDCard, l)
+	assert.Equal(t, "sdcard", l.String())
+
+	_, err = ParseLocation("cloud")
+	assert.EqualError(t, err, `invalid location "cloud", must be local or sdcard`)
+}
+
+func TestJobResponse_State(t *testing.T) {
+	for _, tc := range []struct {
+		state                   string
+		active, paused, offline bool
+	}{
+		{"Printing from SD", true, false, false},
+		{"Pausing", true, true, false},
+		{"Operational", false, false, false},
+		{"Offline", false, false, true},
+		{"Detecting serial connection", false, false, true},
+		{"Offline after error", false, false, false},
+	} {
+		r := &JobResponse{State: tc.state}
+		assert.Equal(t, tc.active, r.IsActive(), tc.state)
+		assert.Equal(t, tc.paused, r.IsPaused(), tc.state)
+		assert.Equal(t, tc.offline, r.IsOffline(), tc.state)
+	}
+}
@@ -2,7 +2,9 @@ package octoprint
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 )
 
@@ -18,21 +20,22 @@ var ConnectionErrors = statusMapping{
 type ConnectionRequest struct{}
 
 // Do sends an API request and returns the API response.
-func (cmd *ConnectionRequest) Do(c *Client) (*ConnectionResponse, error) {
-	b, err := c.doJSONRequest("GET", URIConnection, nil, nil)
+func (cmd *ConnectionRequest) Do(ctx context.Context, c *Client) (*ConnectionResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIConnection, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &ConnectionResponse{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(URIConnection, b, r); err != nil {
 		return nil, err
 	}
 
 	return r, err
 }
 
-// ConnectRequest sets the given target temperature on the printer’s tools.
+// ConnectRequest instructs OctoPrint to connect to the printer, or if already
+// connected, to reconnect with the given settings.
 type ConnectRequest struct {
 	// Port specific port to connect to. If not set the current `portPreference`
 	// will be used, or if no preference is available auto detection will be
@@ -54,16 +57,29 @@ type ConnectRequest struct {
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *ConnectRequest) Do(c *Client) error {
+func (cmd *ConnectRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URIConnection, b, ConnectionErrors)
+	_, err := c.doJSONRequest(ctx, "POST", URIConnection, b, ConnectionErrors)
 	return err
 }
 
+// Validate checks the baud rate of the request.
+func (cmd *ConnectRequest) Validate() error {
+	if cmd.BaudRate < 0 {
+		return fmt.Errorf("invalid baud rate %d, must not be negative", cmd.BaudRate)
+	}
+
+	return nil
+}
+
 func (cmd *ConnectRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
 		Command string `json:"command"`
@@ -78,35 +94,24 @@ func (cmd *ConnectRequest) encode(w io.Writer) error {
 type DisconnectRequest struct{}
 
 // Do sends an API request and returns an error if any.
-func (cmd *DisconnectRequest) Do(c *Client) error {
-	payload := map[string]string{"command": "disconnect"}
-
-	b := bytes.NewBuffer(nil)
-	if err := json.NewEncoder(b).Encode(payload); err != nil {
-		return err
-	}
-
-	_, err := c.doJSONRequest("POST", URIConnection, b, ConnectionErrors)
-	return err
+func (cmd *DisconnectRequest) Do(ctx context.Context, c *Client) error {
+	return doCommandRequest(ctx, c, URIConnection, "disconnect", ConnectionErrors)
 }
 
-// FakesACKRequest fakes an acknowledgment message for OctoPrint in case one got
+// FakeAckRequest fakes an acknowledgment message for OctoPrint in case one got
 // lost on the serial line and the communication with the printer since stalled.
 //
 // This should only be used in “emergencies” (e.g. to save prints), the reason
 // for the lost acknowledgment should always be properly investigated and
 // removed instead of depending on this “symptom solver”.
-type FakesACKRequest struct{}
+type FakeAckRequest struct{}
 
 // Do sends an API request and returns an error if any.
-func (cmd *FakesACKRequest) Do(c *Client) error {
-	payload := map[string]string{"command": "fake_ack"}
-
-	b := bytes.NewBuffer(nil)
-	if err := json.NewEncoder(b).Encode(payload); err != nil {
-		return err
-	}
-
-	_, err := c.doJSONRequest("POST", URIConnection, b, ConnectionErrors)
-	return err
+func (cmd *FakeAckRequest) Do(ctx context.Context, c *Client) error {
+	return doCommandRequest(ctx, c, URIConnection, "fake_ack", ConnectionErrors)
 }
+
+// FakesACKRequest is the former name of FakeAckRequest.
+//
+// Deprecated: use FakeAckRequest instead.
+type FakesACKRequest = FakeAckRequest
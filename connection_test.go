@@ -0,0 +1,52 @@
+package octoprint
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionCommands_Do(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != URIConnection {
+			w.WriteHeader(404)
+			return
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+
+	err := (&ConnectRequest{
+		Port:           "/dev/ttyACM0",
+		BaudRate:       115200,
+		PrinterProfile: "_default",
+		Save:           true,
+	}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"command": "connect",
+		"port": "/dev/ttyACM0",
+		"baudrate": 115200,
+		"printerProfile": "_default",
+		"save": true,
+		"autoconnect": false
+	}`, body)
+
+	err = (&DisconnectRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"command": "disconnect"}`, body)
+
+	err = (&FakeAckRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"command": "fake_ack"}`, body)
+}
@@ -0,0 +1,40 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+)
+
+const URIConnectivity = "/api/connectivity"
+
+// ConnectivityRequest retrieves the result of the online connectivity check of
+// the server, allowing to tell apart an unreachable server from a server
+// without internet access. Available since OctoPrint 1.5.0.
+type ConnectivityRequest struct {
+	// Check whether to run the check again instead of returning the last
+	// result.
+	Check bool
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *ConnectivityRequest) Do(ctx context.Context, c *Client) (*ConnectivityResponse, error) {
+	var b []byte
+	var err error
+	if cmd.Check {
+		payload := bytes.NewBufferString(`{"command": "check"}`)
+		b, err = c.doJSONRequest(ctx, "POST", URIConnectivity, payload, nil)
+	} else {
+		b, err = c.doJSONRequest(ctx, "GET", URIConnectivity, nil, nil)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ConnectivityResponse{}
+	if err := c.decode(URIConnectivity, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
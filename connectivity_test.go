@@ -0,0 +1,42 @@
+package octoprint
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectivityRequest_Do(t *testing.T) {
+	var method, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		method, body = r.Method, string(b)
+		w.Write([]byte(`{
+			"enabled": true,
+			"online": false,
+			"connection_ok": true,
+			"connection_check": "1.1.1.1:53",
+			"resolution_ok": false,
+			"resolution_check": "octoprint.org"
+		}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&ConnectivityRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", method)
+	assert.True(t, r.Enabled)
+	assert.False(t, r.Online)
+	assert.True(t, r.ConnectionOK)
+	assert.Equal(t, "octoprint.org", r.ResolutionCheck)
+
+	_, err = (&ConnectivityRequest{Check: true}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", method)
+	assert.JSONEq(t, `{"command": "check"}`, body)
+}
@@ -0,0 +1,85 @@
+package octoprint
+
+import (
+	"context"
+)
+
+const URICurrentUser = "/api/currentuser"
+
+// Keys of the permissions bundled with OctoPrint.
+const (
+	PermissionAdmin             = "ADMIN"
+	PermissionStatus            = "STATUS"
+	PermissionConnection        = "CONNECTION"
+	PermissionWebcam            = "WEBCAM"
+	PermissionSystem            = "SYSTEM"
+	PermissionFilesList         = "FILES_LIST"
+	PermissionFilesUpload       = "FILES_UPLOAD"
+	PermissionFilesDownload     = "FILES_DOWNLOAD"
+	PermissionFilesDelete       = "FILES_DELETE"
+	PermissionFilesSelect       = "FILES_SELECT"
+	PermissionPrint             = "PRINT"
+	PermissionGCodeViewer       = "GCODE_VIEWER"
+	PermissionMonitorTerminal   = "MONITOR_TERMINAL"
+	PermissionControl           = "CONTROL"
+	PermissionSlice             = "SLICE"
+	PermissionTimelapseList     = "TIMELAPSE_LIST"
+	PermissionTimelapseDownload = "TIMELAPSE_DOWNLOAD"
+	PermissionTimelapseDelete   = "TIMELAPSE_DELETE"
+	PermissionTimelapseAdmin    = "TIMELAPSE_ADMIN"
+	PermissionSettingsRead      = "SETTINGS_READ"
+	PermissionSettings          = "SETTINGS"
+)
+
+// CurrentUserRequest retrieves information about the user the client is
+// authenticated as, by API key or login session, including its effective
+// permissions.
+type CurrentUserRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *CurrentUserRequest) Do(ctx context.Context, c *Client) (*CurrentUserResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URICurrentUser, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &CurrentUserResponse{}
+	if err := c.decode(URICurrentUser, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// CurrentUserResponse is the response to a CurrentUserRequest.
+type CurrentUserResponse struct {
+	// Name of the user, empty for anonymous access.
+	Name string `json:"name"`
+	// Permissions keys of the effective permissions of the user.
+	Permissions []string `json:"permissions"`
+	// Groups keys of the groups of the user.
+	Groups []string `json:"groups"`
+}
+
+// HasPermission returns true if the user was granted the permission with the
+// given key, either directly or by being an admin.
+func (r *CurrentUserResponse) HasPermission(key string) bool {
+	for _, p := range r.Permissions {
+		if p == key || p == PermissionAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// InGroup returns true if the user belongs to the group with the given key.
+func (r *CurrentUserResponse) InGroup(key string) bool {
+	for _, g := range r.Groups {
+		if g == key {
+			return true
+		}
+	}
+
+	return false
+}
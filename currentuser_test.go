@@ -0,0 +1,29 @@
+package octoprint
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrentUserResponse_HasPermission(t *testing.T) {
+	js := []byte(`{
+		"name": "foo",
+		"permissions": ["STATUS", "CONNECTION", "FILES_LIST"],
+		"groups": ["users"]
+	}`)
+
+	r := &CurrentUserResponse{}
+	err := json.Unmarshal(js, r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "foo", r.Name)
+	assert.True(t, r.HasPermission(PermissionStatus))
+	assert.False(t, r.HasPermission(PermissionPrint))
+	assert.True(t, r.InGroup("users"))
+	assert.False(t, r.InGroup("admins"))
+
+	r.Permissions = []string{PermissionAdmin}
+	assert.True(t, r.HasPermission(PermissionPrint))
+}
@@ -0,0 +1,139 @@
+// Package discovery finds OctoPrint instances on the local network, using the
+// announcements of the bundled discovery plugin of OctoPrint.
+//
+//	instances, err := discovery.Discover(ctx)
+//	for _, i := range instances {
+//		c := i.NewClient("<api-key>")
+//	}
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+// DefaultTimeout is the time spent waiting for answers when the context has
+// no deadline.
+const DefaultTimeout = 3 * time.Second
+
+// Instance is an OctoPrint instance found on the network.
+type Instance struct {
+	// Name of the instance, as announced, e.g. `OctoPrint instance on octopi`.
+	Name string
+	// Host is the host name of the instance, e.g. `octopi.local`.
+	Host string
+	// Addrs are the addresses of the host, if announced.
+	Addrs []net.IP
+	// Port of the HTTP server.
+	Port int
+	// Path is the path of OctoPrint on the HTTP server, e.g. `/` or
+	// `/octoprint/`.
+	Path string
+	// UUID is the unique identifier of the instance, if announced.
+	UUID string
+	// Version is the version of OctoPrint, if announced.
+	Version string
+	// APIVersion is the version of the API, if announced.
+	APIVersion string
+	// Model is the model of the printer, if announced.
+	Model string
+}
+
+// URL returns the URL of the instance. The address of the host is preferred
+// to its name, as `.local` names are not resolved everywhere.
+func (i *Instance) URL() string {
+	host := i.Host
+	if len(i.Addrs) != 0 {
+		host = i.Addrs[0].String()
+	}
+
+	path := i.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	port := i.Port
+	if port == 0 {
+		port = 80
+	}
+
+	return fmt.Sprintf("http://%s%s", net.JoinHostPort(host, strconv.Itoa(port)), path)
+}
+
+// NewClient returns a new client for the instance.
+func (i *Instance) NewClient(apiKey string, opts ...octoprint.Option) *octoprint.Client {
+	return octoprint.NewClient(strings.TrimSuffix(i.URL(), "/"), apiKey, opts...)
+}
+
+// Discover searches OctoPrint instances with MDNS and SSDP at the same time,
+// until ctx is done, or DefaultTimeout if ctx has no deadline. The instances
+// found by both are returned once, as found by MDNS. An error is returned
+// only if both fail.
+func Discover(ctx context.Context) ([]*Instance, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var ssdp []*Instance
+	var ssdpErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ssdp, ssdpErr = SSDP(ctx, nil)
+	}()
+
+	instances, err := MDNS(ctx)
+	<-done
+
+	if err != nil && ssdpErr != nil {
+		return nil, err
+	}
+
+	for _, i := range ssdp {
+		if !containsInstance(instances, i) {
+			instances = append(instances, i)
+		}
+	}
+
+	return instances, nil
+}
+
+// containsInstance returns true if i is in instances, by UUID or by address
+// and port.
+func containsInstance(instances []*Instance, i *Instance) bool {
+	for _, o := range instances {
+		if i.UUID != "" && strings.EqualFold(o.UUID, i.UUID) {
+			return true
+		}
+
+		if o.key() == i.key() {
+			return true
+		}
+
+		for _, ip := range i.Addrs {
+			if o.Port == i.Port && containsIP(o.Addrs, ip) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (i *Instance) key() string {
+	return fmt.Sprintf("%s:%d%s", strings.ToLower(i.Host), i.Port, i.Path)
+}
+
+// withTimeout returns ctx with DefaultTimeout if it has no deadline.
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, DefaultTimeout)
+}
@@ -0,0 +1,411 @@
+package discovery
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// ServiceOctoPrint is the DNS-SD service announced by OctoPrint.
+	ServiceOctoPrint = "_octoprint._tcp.local."
+	// ServiceHTTP is the DNS-SD service of HTTP servers, also announced by
+	// OctoPrint.
+	ServiceHTTP = "_http._tcp.local."
+)
+
+// mdnsAddr is the IPv4 multicast address of mDNS.
+var mdnsAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+var errMalformed = errors.New("malformed DNS message")
+
+// DNS record types.
+const (
+	typeA    = 1
+	typePTR  = 12
+	typeTXT  = 16
+	typeAAAA = 28
+	typeSRV  = 33
+)
+
+// MDNS browses the ServiceOctoPrint and ServiceHTTP services over IPv4
+// multicast DNS, collecting the answers until ctx is done, or DefaultTimeout
+// if ctx has no deadline. Instances of ServiceHTTP are only returned if their
+// TXT records identify them as OctoPrint.
+func MDNS(ctx context.Context) ([]*Instance, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+	return browse(ctx, conn, mdnsAddr)
+}
+
+// browse sends the query to addr, with the unicast response bit set so the
+// answers are sent back to conn.
+func browse(ctx context.Context, conn net.PacketConn, addr net.Addr) ([]*Instance, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if _, err := conn.WriteTo(encodeQuery(ServiceOctoPrint, ServiceHTTP), addr); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.SetReadDeadline(time.Now())
+	}()
+
+	rs := newRecordSet()
+	buf := make([]byte, 9000)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+
+			return nil, err
+		}
+
+		records, err := parseMessage(buf[:n])
+		if err != nil {
+			// ignore malformed or unrelated packets.
+			continue
+		}
+
+		rs.add(records...)
+	}
+
+	return rs.instances(), nil
+}
+
+// recordSet are the records received, indexed by name.
+type recordSet struct {
+	ptr   map[string][]*record
+	srv   map[string]*record
+	txt   map[string]*record
+	addrs map[string][]net.IP
+}
+
+func newRecordSet() *recordSet {
+	return &recordSet{
+		ptr:   make(map[string][]*record),
+		srv:   make(map[string]*record),
+		txt:   make(map[string]*record),
+		addrs: make(map[string][]net.IP),
+	}
+}
+
+func (rs *recordSet) add(records ...*record) {
+	for _, r := range records {
+		switch r.typ {
+		case typePTR:
+			if !rs.hasPTR(r) {
+				rs.ptr[r.name] = append(rs.ptr[r.name], r)
+			}
+		case typeSRV:
+			rs.srv[r.name] = r
+		case typeTXT:
+			rs.txt[r.name] = r
+		case typeA, typeAAAA:
+			if !containsIP(rs.addrs[r.name], r.ip) {
+				rs.addrs[r.name] = append(rs.addrs[r.name], r.ip)
+			}
+		}
+	}
+}
+
+func (rs *recordSet) hasPTR(r *record) bool {
+	for _, p := range rs.ptr[r.name] {
+		if strings.EqualFold(p.ptr, r.ptr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// instances returns the instances announced, skipping the ones without SRV
+// record. Instances of ServiceOctoPrint are preferred to the same instance
+// announced as ServiceHTTP.
+func (rs *recordSet) instances() []*Instance {
+	var instances []*Instance
+	seen := make(map[string]bool)
+	for _, service := range []string{ServiceOctoPrint, ServiceHTTP} {
+		for _, p := range rs.ptr[service] {
+			name := strings.ToLower(p.ptr)
+			srv, ok := rs.srv[name]
+			if !ok {
+				continue
+			}
+
+			txt := parseTXT(rs.txt[name])
+			if service == ServiceHTTP && !isOctoPrintHTTP(p.instance, txt) {
+				continue
+			}
+
+			i := &Instance{
+				Name:       p.instance,
+				Host:       strings.TrimSuffix(srv.target, "."),
+				Addrs:      rs.addrs[strings.ToLower(srv.target)],
+				Port:       srv.port,
+				Path:       txt["path"],
+				UUID:       txt["uuid"],
+				Version:    txt["version"],
+				APIVersion: txt["api"],
+				Model:      txt["model"],
+			}
+
+			if i.Path == "" {
+				i.Path = "/"
+			}
+
+			if seen[i.key()] {
+				continue
+			}
+
+			seen[i.key()] = true
+			instances = append(instances, i)
+		}
+	}
+
+	return instances
+}
+
+// isOctoPrintHTTP returns true if a ServiceHTTP instance is OctoPrint, by the
+// TXT records of its discovery plugin or its default name.
+func isOctoPrintHTTP(name string, txt map[string]string) bool {
+	if _, ok := txt["api"]; ok {
+		return true
+	}
+
+	return strings.HasPrefix(name, "OctoPrint")
+}
+
+func parseTXT(r *record) map[string]string {
+	txt := make(map[string]string)
+	if r == nil {
+		return txt
+	}
+
+	for _, s := range r.txt {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) == 2 {
+			txt[strings.ToLower(parts[0])] = parts[1]
+		}
+	}
+
+	return txt
+}
+
+func containsIP(ips []net.IP, ip net.IP) bool {
+	for _, i := range ips {
+		if i.Equal(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// record is a resource record of a DNS message.
+type record struct {
+	// name is the lowercase name of the record.
+	name string
+	typ  uint16
+
+	// PTR, ptr is the name pointed and instance its first label.
+	ptr      string
+	instance string
+	// SRV.
+	target string
+	port   int
+	// TXT.
+	txt []string
+	// A and AAAA.
+	ip net.IP
+}
+
+// encodeQuery encodes a query of the PTR records of the given names.
+func encodeQuery(names ...string) []byte {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint16(b[4:], uint16(len(names)))
+	for _, name := range names {
+		for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+			b = append(b, byte(len(label)))
+			b = append(b, label...)
+		}
+
+		// type PTR, class IN with the unicast response bit.
+		b = append(b, 0, 0, typePTR, 0x80, 1)
+	}
+
+	return b
+}
+
+// parseMessage returns the records of the answer, authority and additional
+// sections of a DNS response.
+func parseMessage(b []byte) ([]*record, error) {
+	if len(b) < 12 || b[2]&0x80 == 0 {
+		return nil, errMalformed
+	}
+
+	p := &parser{b: b, off: 12}
+	qd := int(binary.BigEndian.Uint16(b[4:]))
+	rr := int(binary.BigEndian.Uint16(b[6:])) +
+		int(binary.BigEndian.Uint16(b[8:])) +
+		int(binary.BigEndian.Uint16(b[10:]))
+
+	for i := 0; i < qd; i++ {
+		if _, err := p.name(); err != nil {
+			return nil, err
+		}
+
+		if err := p.skip(4); err != nil {
+			return nil, err
+		}
+	}
+
+	var records []*record
+	for i := 0; i < rr; i++ {
+		r, err := p.record()
+		if err != nil {
+			return nil, err
+		}
+
+		if r != nil {
+			records = append(records, r)
+		}
+	}
+
+	return records, nil
+}
+
+type parser struct {
+	b   []byte
+	off int
+}
+
+// record returns the next record, nil if its type is not supported.
+func (p *parser) record() (*record, error) {
+	labels, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.b) < p.off+10 {
+		return nil, errMalformed
+	}
+
+	typ := binary.BigEndian.Uint16(p.b[p.off:])
+	length := int(binary.BigEndian.Uint16(p.b[p.off+8:]))
+	p.off += 10
+
+	start, end := p.off, p.off+length
+	if len(p.b) < end {
+		return nil, errMalformed
+	}
+
+	defer func() { p.off = end }()
+
+	r := &record{name: strings.ToLower(joinLabels(labels)), typ: typ}
+	switch typ {
+	case typePTR:
+		labels, err := p.name()
+		if err != nil || len(labels) == 0 {
+			return nil, errMalformed
+		}
+
+		r.ptr, r.instance = joinLabels(labels), labels[0]
+	case typeSRV:
+		if length < 7 {
+			return nil, errMalformed
+		}
+
+		r.port = int(binary.BigEndian.Uint16(p.b[start+4:]))
+		p.off += 6
+		labels, err := p.name()
+		if err != nil {
+			return nil, errMalformed
+		}
+
+		r.target = joinLabels(labels)
+	case typeTXT:
+		for i := start; i < end; {
+			n := int(p.b[i])
+			if i+1+n > end {
+				return nil, errMalformed
+			}
+
+			r.txt = append(r.txt, string(p.b[i+1:i+1+n]))
+			i += 1 + n
+		}
+	case typeA, typeAAAA:
+		if length != net.IPv4len && length != net.IPv6len {
+			return nil, errMalformed
+		}
+
+		r.ip = net.IP(append([]byte(nil), p.b[start:end]...))
+	default:
+		return nil, nil
+	}
+
+	return r, nil
+}
+
+// name returns the labels of the next name, following the compression
+// pointers.
+func (p *parser) name() ([]string, error) {
+	var labels []string
+	off, jumped := p.off, false
+	for hops := 0; ; hops++ {
+		if off >= len(p.b) || hops > 128 {
+			return nil, errMalformed
+		}
+
+		n := int(p.b[off])
+		switch {
+		case n == 0:
+			if !jumped {
+				p.off = off + 1
+			}
+
+			return labels, nil
+		case n&0xC0 == 0xC0:
+			if off+1 >= len(p.b) {
+				return nil, errMalformed
+			}
+
+			if !jumped {
+				p.off = off + 2
+			}
+
+			off, jumped = int(binary.BigEndian.Uint16(p.b[off:])&0x3FFF), true
+		default:
+			if off+1+n > len(p.b) {
+				return nil, errMalformed
+			}
+
+			labels = append(labels, string(p.b[off+1:off+1+n]))
+			off += 1 + n
+		}
+	}
+}
+
+func (p *parser) skip(n int) error {
+	if len(p.b) < p.off+n {
+		return errMalformed
+	}
+
+	p.off += n
+	return nil
+}
+
+func joinLabels(labels []string) string {
+	return strings.Join(labels, ".") + "."
+}
@@ -0,0 +1,160 @@
+package discovery
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dnsMessage builds DNS responses for the tests.
+type dnsMessage struct {
+	b []byte
+	n int
+}
+
+func newDNSMessage() *dnsMessage {
+	return &dnsMessage{b: []byte{0, 0, 0x84, 0, 0, 0, 0, 0, 0, 0, 0, 0}}
+}
+
+func (m *dnsMessage) name(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+
+	return append(b, 0)
+}
+
+func (m *dnsMessage) record(name string, typ uint16, data []byte) *dnsMessage {
+	m.b = append(m.b, m.name(name)...)
+	m.b = append(m.b, byte(typ>>8), byte(typ), 0x80, 1, 0, 0, 0x11, 0x94)
+	m.b = append(m.b, byte(len(data)>>8), byte(len(data)))
+	m.b = append(m.b, data...)
+	m.n++
+	return m
+}
+
+func (m *dnsMessage) ptr(name, target string) *dnsMessage {
+	return m.record(name, typePTR, m.name(target))
+}
+
+func (m *dnsMessage) srv(name, target string, port int) *dnsMessage {
+	data := []byte{0, 0, 0, 0, byte(port >> 8), byte(port)}
+	return m.record(name, typeSRV, append(data, m.name(target)...))
+}
+
+func (m *dnsMessage) txt(name string, entries ...string) *dnsMessage {
+	var data []byte
+	for _, e := range entries {
+		data = append(data, byte(len(e)))
+		data = append(data, e...)
+	}
+
+	return m.record(name, typeTXT, data)
+}
+
+func (m *dnsMessage) bytes() []byte {
+	binary.BigEndian.PutUint16(m.b[6:], uint16(m.n))
+	return m.b
+}
+
+func TestBrowse(t *testing.T) {
+	server, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer server.Close()
+
+	response := newDNSMessage().
+		ptr(ServiceOctoPrint, "OctoPrint instance on octopi._octoprint._tcp.local.").
+		srv("OctoPrint instance on octopi._octoprint._tcp.local.", "octopi.local.", 80).
+		txt("OctoPrint instance on octopi._octoprint._tcp.local.",
+			"path=/", "version=1.9.2", "api=0.1", "uuid=e1a3c9c4", "model=Prusa i3",
+		).
+		record("octopi.local.", typeA, []byte{192, 168, 1, 10}).
+		ptr(ServiceHTTP, "OctoPrint instance on octopi._http._tcp.local.").
+		srv("OctoPrint instance on octopi._http._tcp.local.", "octopi.local.", 80).
+		txt("OctoPrint instance on octopi._http._tcp.local.", "path=/").
+		ptr(ServiceHTTP, "Router._http._tcp.local.").
+		srv("Router._http._tcp.local.", "router.local.", 80).
+		ptr(ServiceHTTP, "Ender._http._tcp.local.").
+		srv("Ender._http._tcp.local.", "ender.local.", 5000).
+		txt("Ender._http._tcp.local.", "path=/octoprint/", "api=0.1").
+		bytes()
+
+	go func() {
+		buf := make([]byte, 1500)
+		n, addr, err := server.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		assert.Equal(t, encodeQuery(ServiceOctoPrint, ServiceHTTP), buf[:n])
+		server.WriteTo([]byte("garbage"), addr)
+		server.WriteTo(response, addr)
+	}()
+
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	instances, err := browse(ctx, conn, server.LocalAddr())
+	assert.NoError(t, err)
+	assert.Len(t, instances, 2)
+
+	assert.Equal(t, &Instance{
+		Name:       "OctoPrint instance on octopi",
+		Host:       "octopi.local",
+		Addrs:      []net.IP{{192, 168, 1, 10}},
+		Port:       80,
+		Path:       "/",
+		UUID:       "e1a3c9c4",
+		Version:    "1.9.2",
+		APIVersion: "0.1",
+		Model:      "Prusa i3",
+	}, instances[0])
+	assert.Equal(t, "http://192.168.1.10:80/", instances[0].URL())
+
+	assert.Equal(t, "Ender", instances[1].Name)
+	assert.Equal(t, "http://ender.local:5000/octoprint/", instances[1].URL())
+}
+
+func TestParseMessageCompression(t *testing.T) {
+	m := newDNSMessage().ptr(ServiceOctoPrint, "foo._octoprint._tcp.local.")
+	// SRV target pointing to the name of the first record.
+	m.record("foo._octoprint._tcp.local.", typeSRV, []byte{0, 0, 0, 0, 0x13, 0x88, 0xC0, 12})
+
+	records, err := parseMessage(m.bytes())
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, "foo._octoprint._tcp.local.", records[0].ptr)
+	assert.Equal(t, "foo", records[0].instance)
+	assert.Equal(t, ServiceOctoPrint, records[1].target)
+	assert.Equal(t, 5000, records[1].port)
+}
+
+func TestParseMessageMalformed(t *testing.T) {
+	b := newDNSMessage().ptr(ServiceOctoPrint, "foo._octoprint._tcp.local.").bytes()
+	for i := 0; i < len(b); i++ {
+		_, err := parseMessage(b[:i])
+		assert.Error(t, err)
+	}
+
+	// pointer loop
+	loop := newDNSMessage().b
+	loop[7] = 1
+	loop = append(loop, 0xC0, 12)
+	_, err := parseMessage(loop)
+	assert.Error(t, err)
+
+	// query
+	_, err = parseMessage(encodeQuery(ServiceOctoPrint))
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SearchTarget is the UPnP device type announced by OctoPrint.
+const SearchTarget = "urn:schemas-upnp-org:device:basic:1"
+
+// descriptionPath is the path of the device description of OctoPrint, served
+// by its discovery plugin.
+const descriptionPath = "/plugin/discovery/discovery.xml"
+
+// ssdpAddr is the IPv4 multicast address of SSDP.
+var ssdpAddr = &net.UDPAddr{IP: net.IPv4(239, 255, 255, 250), Port: 1900}
+
+// SSDP searches the UPnP devices announced by OctoPrint with SSDP, collecting
+// the answers until ctx is done, or DefaultTimeout if ctx has no deadline. The
+// device description of every instance is requested with hc, if nil
+// http.DefaultClient is used.
+//
+// It complements MDNS on networks filtering multicast DNS.
+func SSDP(ctx context.Context, hc *http.Client) ([]*Instance, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+	return search(ctx, conn, ssdpAddr, hc)
+}
+
+func search(ctx context.Context, conn net.PacketConn, addr net.Addr, hc *http.Client) ([]*Instance, error) {
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	// devices wait up to MX seconds before answering, half of the time
+	// available leaves time to request the descriptions.
+	mx := 1
+	if deadline, ok := ctx.Deadline(); ok {
+		if s := int(time.Until(deadline) / time.Second / 2); s > mx {
+			mx = s
+		}
+	}
+
+	if mx > 5 {
+		mx = 5
+	}
+
+	msg := fmt.Sprintf("M-SEARCH * HTTP/1.1\r\n"+
+		"HOST: %s\r\n"+
+		"MAN: \"ssdp:discover\"\r\n"+
+		"MX: %d\r\n"+
+		"ST: %s\r\n\r\n", ssdpAddr, mx, SearchTarget)
+
+	if _, err := conn.WriteTo([]byte(msg), addr); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.SetReadDeadline(time.Now())
+	}()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		instances []*Instance
+	)
+
+	seen := make(map[string]bool)
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+
+			return nil, err
+		}
+
+		location, ok := parseSearchResponse(buf[:n])
+		if !ok || seen[location] {
+			continue
+		}
+
+		seen[location] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			i, err := describe(ctx, hc, location)
+			if err != nil {
+				// the description could not be retrieved in time.
+				return
+			}
+
+			mu.Lock()
+			instances = append(instances, i)
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+	return instances, nil
+}
+
+// parseSearchResponse returns the location of the device description of an
+// OctoPrint instance, if the response is one.
+func parseSearchResponse(b []byte) (string, bool) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), nil)
+	if err != nil {
+		return "", false
+	}
+
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", false
+	}
+
+	location := resp.Header.Get("Location")
+	u, err := url.Parse(location)
+	if err != nil || !strings.HasSuffix(u.Path, descriptionPath) {
+		return "", false
+	}
+
+	return location, true
+}
+
+// deviceDescription is the UPnP device description of OctoPrint.
+type deviceDescription struct {
+	Device struct {
+		FriendlyName    string `xml:"friendlyName"`
+		ModelName       string `xml:"modelName"`
+		UDN             string `xml:"UDN"`
+		PresentationURL string `xml:"presentationURL"`
+	} `xml:"device"`
+}
+
+func describe(ctx context.Context, hc *http.Client, location string) (*Instance, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error requesting %s: %s", location, resp.Status)
+	}
+
+	d := &deviceDescription{}
+	if err := xml.NewDecoder(resp.Body).Decode(d); err != nil {
+		return nil, fmt.Errorf("invalid device description %s: %w", location, err)
+	}
+
+	// the presentation URL is the address of OctoPrint, defaults to the
+	// root of the server of the description.
+	base, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := base.Parse(d.Device.PresentationURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid presentation URL %q: %w", d.Device.PresentationURL, err)
+	}
+
+	if d.Device.PresentationURL == "" {
+		u.Path = strings.TrimSuffix(u.Path, descriptionPath) + "/"
+	}
+
+	port, _ := strconv.Atoi(u.Port())
+	if port == 0 {
+		port = 80
+	}
+
+	i := &Instance{
+		Name:  d.Device.FriendlyName,
+		Host:  u.Hostname(),
+		Port:  port,
+		Path:  u.Path,
+		UUID:  strings.TrimPrefix(d.Device.UDN, "uuid:"),
+		Model: d.Device.ModelName,
+	}
+
+	if ip := net.ParseIP(i.Host); ip != nil {
+		i.Addrs = []net.IP{ip}
+	}
+
+	if i.Path == "" {
+		i.Path = "/"
+	}
+
+	return i, nil
+}
@@ -0,0 +1,103 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:basic:1</deviceType>
+    <friendlyName>OctoPrint instance on octopi</friendlyName>
+    <modelName>Prusa i3</modelName>
+    <UDN>uuid:e1a3c9c4-0c8b-4c4e-9c1e-4f7bfb4e1d2a</UDN>
+    <presentationURL>%s</presentationURL>
+  </device>
+</root>`
+
+func TestSearch(t *testing.T) {
+	var presentation string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, descriptionPath, r.URL.Path)
+		fmt.Fprintf(w, testDescription, presentation)
+	}))
+	defer ts.Close()
+
+	presentation = ts.URL + "/octoprint/"
+
+	server, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer server.Close()
+
+	go func() {
+		buf := make([]byte, 1500)
+		n, addr, err := server.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		assert.True(t, strings.HasPrefix(string(buf[:n]), "M-SEARCH * HTTP/1.1\r\n"))
+		assert.Contains(t, string(buf[:n]), "ST: "+SearchTarget+"\r\n")
+
+		for _, location := range []string{
+			ts.URL + descriptionPath,
+			ts.URL + descriptionPath, // repeated
+			"http://192.168.1.1/rootDesc.xml",
+		} {
+			server.WriteTo([]byte("HTTP/1.1 200 OK\r\n"+
+				"CACHE-CONTROL: max-age=1800\r\n"+
+				"LOCATION: "+location+"\r\n"+
+				"ST: "+SearchTarget+"\r\n"+
+				"USN: uuid:e1a3c9c4-0c8b-4c4e-9c1e-4f7bfb4e1d2a::"+SearchTarget+"\r\n\r\n"), addr)
+		}
+	}()
+
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	instances, err := search(ctx, conn, server.LocalAddr(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, instances, 1)
+
+	i := instances[0]
+	assert.Equal(t, "OctoPrint instance on octopi", i.Name)
+	assert.Equal(t, "127.0.0.1", i.Host)
+	assert.Equal(t, []net.IP{net.ParseIP("127.0.0.1")}, i.Addrs)
+	assert.Equal(t, "/octoprint/", i.Path)
+	assert.Equal(t, "e1a3c9c4-0c8b-4c4e-9c1e-4f7bfb4e1d2a", i.UUID)
+	assert.Equal(t, "Prusa i3", i.Model)
+	assert.Equal(t, presentation, i.URL())
+}
+
+func TestContainsInstance(t *testing.T) {
+	instances := []*Instance{{
+		Host:  "octopi.local",
+		Addrs: []net.IP{{192, 168, 1, 10}},
+		Port:  80,
+		Path:  "/",
+	}}
+
+	assert.True(t, containsInstance(instances, &Instance{
+		Host: "192.168.1.10", Addrs: []net.IP{{192, 168, 1, 10}}, Port: 80, Path: "/",
+	}))
+
+	assert.False(t, containsInstance(instances, &Instance{
+		Host: "192.168.1.10", Addrs: []net.IP{{192, 168, 1, 10}}, Port: 5000, Path: "/",
+	}))
+
+	instances[0].UUID = "foo"
+	assert.True(t, containsInstance(instances, &Instance{Host: "octopi", UUID: "FOO"}))
+}
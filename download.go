@@ -0,0 +1,227 @@
+package octoprint
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const URIDownloadFiles = "/downloads/files"
+
+var (
+	// ErrHashMismatch the hash of the downloaded content doesn't match the
+	// one reported by OctoPrint.
+	ErrHashMismatch = errors.New("Hash of the downloaded file does not match")
+	// ErrNotDownloadable the file has no download reference, as folders or
+	// files stored on the SD card.
+	ErrNotDownloadable = errors.New("The file has no download reference")
+
+	DownloadErrors = statusMapping{
+		404: "The file was not found",
+	}
+)
+
+// DownloadFileRequest downloads a file stored at OctoPrint’s `uploads` folder,
+// streaming its content to the given writer.
+type DownloadFileRequest struct {
+	// Location of the file to download, only `local` is supported.
+	Location Location
+	// Path of the file to download.
+	Path string
+	// Offset is the amount of bytes to skip from the beginning of the file,
+	// allowing an interrupted download to be resumed by setting it to the
+	// amount of bytes already written.
+	Offset int64
+	// Hash is the expected hash of the file, as reported by FileInformation.
+	// If Verify is set and Hash is empty it is retrieved from the server.
+	// Only verified when downloading the whole file (Offset is zero).
+	Hash string
+	// Verify whether to verify the hash of the downloaded content.
+	Verify bool
+	// Progress is called while downloading the file. Optional.
+	Progress ProgressFunc
+}
+
+// Validate checks the location, the path and the offset of the request.
+func (cmd *DownloadFileRequest) Validate() error {
+	if cmd.Location != Local {
+		return fmt.Errorf("invalid location %q, only local files can be downloaded", cmd.Location)
+	}
+
+	if err := validateFile(cmd.Location, cmd.Path); err != nil {
+		return err
+	}
+
+	if cmd.Offset < 0 {
+		return fmt.Errorf("invalid offset %d, must not be negative", cmd.Offset)
+	}
+
+	return nil
+}
+
+// Do sends an API request writing the content of the file to w, and returns
+// the amount of bytes written, even on error.
+func (cmd *DownloadFileRequest) Do(ctx context.Context, c *Client, w io.Writer) (int64, error) {
+	if err := cmd.Validate(); err != nil {
+		return 0, err
+	}
+
+	expected := cmd.Hash
+	if cmd.Verify && cmd.Offset == 0 && expected == "" {
+		f, err := (&FileRequest{Location: cmd.Location, Filename: cmd.Path}).Do(ctx, c)
+		if err != nil {
+			return 0, err
+		}
+
+		expected = f.Hash
+	}
+
+	var h hash.Hash
+	if cmd.Verify && cmd.Offset == 0 {
+		h = newFileHash(expected)
+	}
+
+	header := make(http.Header)
+	if cmd.Offset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", cmd.Offset))
+	}
+
+	var written int64
+	ctx = WithTimeoutClass(ctx, TransferClass)
+	uri := fmt.Sprintf("%s/%s/%s", URIDownloadFiles, cmd.Location, escapePath(cmd.Path))
+	err := c.doStreamRequest(ctx, "GET", uri, header, DownloadErrors, func(resp *http.Response) error {
+		body := io.Reader(resp.Body)
+		if cmd.Offset > 0 && resp.StatusCode != http.StatusPartialContent {
+			if _, err := io.CopyN(ioutil.Discard, body, cmd.Offset); err != nil {
+				return err
+			}
+		}
+
+		total := int64(-1)
+		if resp.ContentLength >= 0 {
+			total = resp.ContentLength
+			if resp.StatusCode == http.StatusPartialContent {
+				total += cmd.Offset
+			}
+		}
+
+		dst := newProgressWriter(w, cmd.Offset, total, cmd.Progress)
+		if h != nil {
+			dst = io.MultiWriter(dst, h)
+		}
+
+		var err error
+		written, err = io.Copy(dst, body)
+		return err
+	})
+
+	if err != nil {
+		return written, err
+	}
+
+	if h != nil && !strings.EqualFold(hex.EncodeToString(h.Sum(nil)), expected) {
+		return written, ErrHashMismatch
+	}
+
+	return written, nil
+}
+
+// DownloadRequest returns a request downloading the file, verifying its hash.
+// Returns ErrNotDownloadable if the file has no download reference.
+func (f *FileInformation) DownloadRequest() (*DownloadFileRequest, error) {
+	r, err := f.Refs.DownloadRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Hash, r.Verify = f.Hash, f.Hash != ""
+	return r, nil
+}
+
+// DownloadRequest returns a request downloading the file referenced by
+// Download, to be sent with the same client that returned the reference.
+// Returns ErrNotDownloadable if Download is empty.
+func (r *Reference) DownloadRequest() (*DownloadFileRequest, error) {
+	if r.Download == "" {
+		return nil, ErrNotDownloadable
+	}
+
+	location, path, err := parseReference(r.Download, URIDownloadFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DownloadFileRequest{Location: location, Path: path}, nil
+}
+
+// FileRequest returns a request retrieving the information of the file or
+// folder referenced by Resource.
+func (r *Reference) FileRequest() (*FileRequest, error) {
+	location, path, err := parseReference(r.Resource, URIFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileRequest{Location: location, Filename: path}, nil
+}
+
+// parseReference returns the location and the path of a reference URL, of the
+// form `<prefix>/<location>/<path>`. The prefix may be preceded by the base
+// path of an OctoPrint server behind a reverse proxy.
+func parseReference(ref, prefix string) (Location, string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", "", err
+	}
+
+	i := strings.Index(u.Path, prefix+"/")
+	if i == -1 {
+		return "", "", fmt.Errorf("unexpected reference %q", ref)
+	}
+
+	parts := strings.SplitN(u.Path[i+len(prefix)+1:], "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unexpected reference %q", ref)
+	}
+
+	return Location(parts[0]), parts[1], nil
+}
+
+// download streams the content at the given URI to w, returning the amount of
+// bytes written, even on error.
+func (c *Client) download(
+	ctx context.Context, uri string, m statusMapping, w io.Writer, progress ProgressFunc,
+) (int64, error) {
+	var written int64
+	ctx = WithTimeoutClass(ctx, TransferClass)
+	err := c.doStreamRequest(ctx, "GET", uri, nil, m, func(resp *http.Response) error {
+		var err error
+		written, err = io.Copy(newProgressWriter(w, 0, resp.ContentLength, progress), resp.Body)
+		return err
+	})
+
+	return written, err
+}
+
+// newFileHash returns the hash.Hash matching the length of the given hex
+// encoded hash, OctoPrint uses SHA1 while older versions may report MD5. Nil is
+// returned if unknown.
+func newFileHash(expected string) hash.Hash {
+	switch len(expected) {
+	case hex.EncodedLen(md5.Size):
+		return md5.New()
+	case hex.EncodedLen(sha1.Size):
+		return sha1.New()
+	}
+
+	return nil
+}
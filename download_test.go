@@ -0,0 +1,114 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testGCode = "G28\nG1 X10 Y10\nM104 S0\n"
+
+func newDownloadServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != URIDownloadFiles+"/local/foo.gcode" {
+			w.WriteHeader(404)
+			return
+		}
+
+		http.ServeContent(w, r, "foo.gcode", time.Time{}, strings.NewReader(testGCode))
+	}))
+}
+
+func TestDownloadFileRequest_Do(t *testing.T) {
+	ts := newDownloadServer()
+	defer ts.Close()
+
+	sum := sha1.Sum([]byte(testGCode))
+	cli := NewClient(ts.URL, "")
+
+	var last, total int64
+	buf := bytes.NewBuffer(nil)
+	r := &DownloadFileRequest{
+		Location: Local,
+		Path:     "foo.gcode",
+		Hash:     hex.EncodeToString(sum[:]),
+		Verify:   true,
+		Progress: func(n, t int64) { last, total = n, t },
+	}
+
+	n, err := r.Do(context.Background(), cli, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(testGCode)), n)
+	assert.Equal(t, testGCode, buf.String())
+	assert.Equal(t, int64(len(testGCode)), last)
+	assert.Equal(t, int64(len(testGCode)), total)
+
+	r.Hash = strings.Repeat("0", 40)
+	_, err = r.Do(context.Background(), cli, bytes.NewBuffer(nil))
+	assert.Equal(t, ErrHashMismatch, err)
+}
+
+func TestDownloadFileRequest_DoWithOffset(t *testing.T) {
+	ts := newDownloadServer()
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+
+	buf := bytes.NewBufferString(testGCode[:4])
+	r := &DownloadFileRequest{Location: Local, Path: "foo.gcode", Offset: 4}
+	n, err := r.Do(context.Background(), cli, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(testGCode)-4), n)
+	assert.Equal(t, testGCode, buf.String())
+}
+
+func TestFileInformation_DownloadRequest(t *testing.T) {
+	ts := newDownloadServer()
+	defer ts.Close()
+
+	sum := sha1.Sum([]byte(testGCode))
+	f := &FileInformation{
+		Hash: hex.EncodeToString(sum[:]),
+		Refs: Reference{Download: ts.URL + URIDownloadFiles + "/local/foo.gcode"},
+	}
+
+	r, err := f.DownloadRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, Local, r.Location)
+	assert.Equal(t, "foo.gcode", r.Path)
+	assert.True(t, r.Verify)
+
+	buf := bytes.NewBuffer(nil)
+	_, err = r.Do(context.Background(), NewClient(ts.URL, ""), buf)
+	assert.NoError(t, err)
+	assert.Equal(t, testGCode, buf.String())
+
+	_, err = (&FileInformation{Origin: SDCard}).DownloadRequest()
+	assert.Equal(t, ErrNotDownloadable, err)
+}
+
+func TestReference_FileRequest(t *testing.T) {
+	ref := &Reference{
+		Resource: "http://octopi.local/octoprint/api/files/local/parts/my%20bracket.stl",
+		Download: "http://octopi.local/octoprint/downloads/files/local/parts/my%20bracket.stl",
+	}
+
+	f, err := ref.FileRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, &FileRequest{Location: Local, Filename: "parts/my bracket.stl"}, f)
+
+	d, err := ref.DownloadRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, &DownloadFileRequest{Location: Local, Path: "parts/my bracket.stl"}, d)
+
+	_, err = (&Reference{Resource: "http://octopi.local/api/files/local"}).FileRequest()
+	assert.Error(t, err)
+}
@@ -0,0 +1,303 @@
+// Package exporter exposes the state of one or more printers as Prometheus
+// metrics, scraped from the REST API on every request of the metrics endpoint.
+//
+//	e := exporter.New()
+//	e.Add("prusa", octoprint.NewClient("<octoprint-url>", "<api-key>"))
+//	http.Handle("/metrics", e)
+//
+// The metrics are written in the Prometheus text exposition format, without
+// depending on the Prometheus client library.
+package exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+// DefaultTimeout is the default timeout of the scrape of a printer.
+const DefaultTimeout = 10 * time.Second
+
+// Exporter is an http.Handler serving the metrics of the printers added. It is
+// safe for concurrent use.
+type Exporter struct {
+	// Timeout of the scrape of every printer, zero means DefaultTimeout.
+	Timeout time.Duration
+
+	mu       sync.RWMutex
+	printers map[string]*octoprint.Client
+}
+
+// New returns a new Exporter without printers.
+func New() *Exporter {
+	return &Exporter{printers: make(map[string]*octoprint.Client)}
+}
+
+// Add adds a printer, its metrics are labeled with the given name.
+func (e *Exporter) Add(name string, c *octoprint.Client) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.printers[name] = c
+}
+
+// Remove removes a printer.
+func (e *Exporter) Remove(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.printers, name)
+}
+
+// ServeHTTP implements http.Handler, scraping all the printers concurrently.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.Write(r.Context(), w)
+}
+
+// Write scrapes all the printers concurrently and writes their metrics to w.
+func (e *Exporter) Write(ctx context.Context, w io.Writer) error {
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	e.mu.RLock()
+	names := make([]string, 0, len(e.printers))
+	for name := range e.printers {
+		names = append(names, name)
+	}
+
+	clients := make([]*octoprint.Client, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		clients[i] = e.printers[name]
+	}
+
+	e.mu.RUnlock()
+
+	set := newMetricSet()
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(name string, c *octoprint.Client) {
+			defer wg.Done()
+			scrape(ctx, set, name, c)
+		}(names[i], clients[i])
+	}
+
+	wg.Wait()
+	return set.write(w)
+}
+
+func scrape(ctx context.Context, set *metricSet, name string, c *octoprint.Client) {
+	start := time.Now()
+	printer := label{"printer", name}
+	up := 1.
+
+	defer func() {
+		set.add("octoprint_up", "Whether the last scrape of the printer succeeded.", up, printer)
+		set.add("octoprint_scrape_duration_seconds", "Duration of the scrape of the printer.",
+			time.Since(start).Seconds(), printer,
+		)
+	}()
+
+	conn, err := (&octoprint.ConnectionRequest{}).Do(ctx, c)
+	if err != nil {
+		up = 0
+		return
+	}
+
+	set.add("octoprint_connection_state", "State of the connection to the printer, 1 for the current state.",
+		1, printer, label{"state", string(conn.Current.State)},
+	)
+
+	state, err := (&octoprint.StateRequest{}).Do(ctx, c)
+	switch {
+	case errors.Is(err, octoprint.ErrConflict):
+		// the printer is not connected.
+		state = &octoprint.FullStateResponse{}
+	case err != nil:
+		up = 0
+		return
+	}
+
+	for _, tool := range sortedTools(state.Temperature.Current) {
+		t := state.Temperature.Current[tool]
+		set.add("octoprint_temperature_celsius", "Actual temperature of the tool.",
+			t.Actual, printer, label{"tool", tool},
+		)
+		// tools without setpoint have no target.
+		if t.Target != nil {
+			set.add("octoprint_temperature_target_celsius", "Target temperature of the tool.",
+				*t.Target, printer, label{"tool", tool},
+			)
+		}
+	}
+
+	f := state.State.Flags
+	for _, flag := range []struct {
+		name  string
+		value bool
+	}{
+		{"operational", f.Operational},
+		{"printing", f.Printing},
+		{"paused", f.Paused},
+		{"pausing", f.Pausing},
+		{"cancelling", f.Cancelling},
+		{"resuming", f.Resuming},
+		{"finishing", f.Finishing},
+		{"ready", f.Ready},
+		{"error", f.Error},
+		{"closed_or_error", f.ClosedOrError},
+	} {
+		set.add("octoprint_printer_state", "State flags of the printer.",
+			boolValue(flag.value), printer, label{"flag", flag.name},
+		)
+	}
+
+	set.add("octoprint_sd_ready", "Whether the SD card of the printer is ready.",
+		boolValue(state.SD.Ready), printer,
+	)
+
+	job, err := (&octoprint.JobRequest{}).Do(ctx, c)
+	if err != nil {
+		up = 0
+		return
+	}
+
+	set.add("octoprint_job_progress_ratio", "Completion of the current job, from 0 to 1.",
+		job.Progress.Completion/100, printer,
+	)
+
+	// the times are unknown while not printing.
+	if job.Progress.PrintTime != nil {
+		set.add("octoprint_job_print_time_seconds", "Time spent printing the current job.",
+			*job.Progress.PrintTime, printer,
+		)
+	}
+
+	if job.Progress.PrintTimeLeft != nil {
+		set.add("octoprint_job_print_time_left_seconds", "Estimated time left of the current job.",
+			*job.Progress.PrintTimeLeft, printer,
+		)
+	}
+}
+
+func sortedTools(temps map[string]octoprint.TemperatureData) []string {
+	tools := make([]string, 0, len(temps))
+	for tool := range temps {
+		tools = append(tools, tool)
+	}
+
+	sort.Strings(tools)
+	return tools
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+type label struct {
+	name, value string
+}
+
+type sample struct {
+	labels []label
+	value  float64
+}
+
+type metric struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+// metricSet are the gauges of a scrape, by name.
+type metricSet struct {
+	mu      sync.Mutex
+	metrics map[string]*metric
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{metrics: make(map[string]*metric)}
+}
+
+func (s *metricSet) add(name, help string, value float64, labels ...label) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.metrics[name]
+	if !ok {
+		m = &metric{name: name, help: help}
+		s.metrics[name] = m
+	}
+
+	m.samples = append(m.samples, sample{labels: labels, value: value})
+}
+
+// write writes the metrics sorted by name, and the samples by labels.
+func (s *metricSet) write(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.metrics))
+	for name := range s.metrics {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	b := &strings.Builder{}
+	for _, name := range names {
+		m := s.metrics[name]
+		fmt.Fprintf(b, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(b, "# TYPE %s gauge\n", m.name)
+
+		lines := make([]string, len(m.samples))
+		for i, sample := range m.samples {
+			lines[i] = fmt.Sprintf("%s%s %s\n", m.name, formatLabels(sample.labels), formatValue(sample.value))
+		}
+
+		sort.Strings(lines)
+		for _, l := range lines {
+			b.WriteString(l)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatLabels(labels []label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = fmt.Sprintf(`%s="%s"`, l.name, labelReplacer.Replace(l.value))
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
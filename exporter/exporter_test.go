@@ -0,0 +1,92 @@
+package exporter
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExporter(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case octoprint.URIConnection:
+			w.Write([]byte(`{"current": {"state": "Printing"}}`))
+		case octoprint.URIPrinter:
+			w.Write([]byte(`{
+				"state": {"text": "Printing", "flags": {"operational": true, "printing": true}},
+				"sd": {"ready": true},
+				"temperature": {
+					"tool0": {"actual": 209.5, "target": 210},
+					"bed": {"actual": 60, "target": 60}
+				}
+			}`))
+		case octoprint.JobTool:
+			w.Write([]byte(`{"progress": {"completion": 42.5, "printTime": 600, "printTimeLeft": 900}}`))
+		}
+	}))
+	defer ts.Close()
+
+	offline := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case octoprint.URIConnection:
+			w.Write([]byte(`{"current": {"state": "Closed"}}`))
+		case octoprint.URIPrinter:
+			w.WriteHeader(http.StatusConflict)
+		case octoprint.JobTool:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer offline.Close()
+
+	e := New()
+	e.Add("prusa", octoprint.NewClient(ts.URL, ""))
+	e.Add("ender", octoprint.NewClient(offline.URL, ""))
+	e.Add("down", octoprint.NewClient("http://127.0.0.1:1", ""))
+
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	b, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	metrics := string(b)
+	for _, line := range []string{
+		"# TYPE octoprint_up gauge",
+		`octoprint_up{printer="down"} 0`,
+		`octoprint_up{printer="ender"} 1`,
+		`octoprint_up{printer="prusa"} 1`,
+		`octoprint_connection_state{printer="ender",state="Closed"} 1`,
+		`octoprint_connection_state{printer="prusa",state="Printing"} 1`,
+		`octoprint_temperature_celsius{printer="prusa",tool="bed"} 60`,
+		`octoprint_temperature_celsius{printer="prusa",tool="tool0"} 209.5`,
+		`octoprint_temperature_target_celsius{printer="prusa",tool="tool0"} 210`,
+		`octoprint_printer_state{printer="prusa",flag="printing"} 1`,
+		`octoprint_printer_state{printer="prusa",flag="paused"} 0`,
+		`octoprint_printer_state{printer="ender",flag="operational"} 0`,
+		`octoprint_sd_ready{printer="prusa"} 1`,
+		`octoprint_job_progress_ratio{printer="prusa"} 0.425`,
+		`octoprint_job_print_time_seconds{printer="prusa"} 600`,
+		`octoprint_job_print_time_left_seconds{printer="prusa"} 900`,
+	} {
+		assert.Contains(t, metrics, line+"\n")
+	}
+
+	assert.False(t, strings.Contains(metrics, `octoprint_temperature_celsius{printer="ender"`))
+	assert.Equal(t, 1, strings.Count(metrics, "# HELP octoprint_up "))
+}
+
+func TestFormatLabels(t *testing.T) {
+	assert.Equal(t, "", formatLabels(nil))
+	assert.Equal(t, `{printer="a\"b\\c\nd"}`, formatLabels([]label{{"printer", "a\"b\\c\nd"}}))
+}
@@ -2,10 +2,14 @@ package octoprint
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/url"
+	"strings"
 )
 
 type Location string
@@ -17,6 +21,26 @@ const (
 	SDCard Location = "sdcard"
 )
 
+// String returns the name of the location.
+func (l Location) String() string {
+	return string(l)
+}
+
+// IsValid returns true if the location is local or sdcard.
+func (l Location) IsValid() bool {
+	return l == Local || l == SDCard
+}
+
+// ParseLocation returns the location with the given name.
+func ParseLocation(s string) (Location, error) {
+	l := Location(s)
+	if !l.IsValid() {
+		return "", fmt.Errorf("invalid location %q, must be local or sdcard", s)
+	}
+
+	return l, nil
+}
+
 var (
 	FilesLocationGETErrors = statusMapping{
 		404: "Location is neither local nor sdcard",
@@ -35,8 +59,8 @@ var (
 		409: "Selected file is supposed to start printing directly but the printer is not operational or if a file to be sliced is supposed to be selected or start printing directly but the printer is not operational or already printing.",
 	}
 	FilesLocationDeleteErrors = statusMapping{
-		404: "Location is neither local nor sdcard",
-		409: "The file to be deleted is currently being printed",
+		404: "Location is neither local nor sdcard or the file or folder was not found",
+		409: "The file to be deleted is currently being printed, or the folder to be deleted contains it",
 	}
 )
 
@@ -52,19 +76,26 @@ type FileRequest struct {
 	Recursive bool
 }
 
+// Validate checks the location and the path of the request.
+func (cmd *FileRequest) Validate() error {
+	return validateFile(cmd.Location, cmd.Filename)
+}
+
 // Do sends an API request and returns the API response
-func (cmd *FileRequest) Do(c *Client) (*FileInformation, error) {
-	uri := fmt.Sprintf("%s/%s/%s?recursive=%t", URIFiles,
-		cmd.Location, cmd.Filename, cmd.Recursive,
-	)
+func (cmd *FileRequest) Do(ctx context.Context, c *Client) (*FileInformation, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	uri := fmt.Sprintf("%s?recursive=%t", fileURI(cmd.Location, cmd.Filename), cmd.Recursive)
 
-	b, err := c.doJSONRequest("GET", uri, nil, FilesLocationGETErrors)
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, FilesLocationGETErrors)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &FileInformation{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(uri, b, r); err != nil {
 		return nil, err
 	}
 
@@ -79,22 +110,53 @@ type FilesRequest struct {
 	// Recursive if set to true, return all files and folders recursively.
 	// Otherwise only return items on same level.
 	Recursive bool
+	// Filter if set only returns files of the given type, e.g. `machinecode`
+	// or `model`. Folders are always returned.
+	Filter string
+	// Force if set to true forces a refresh of the file list, bypassing the
+	// cache of the server.
+	Force bool
+}
+
+// Validate checks the location of the request, if any.
+func (cmd *FilesRequest) Validate() error {
+	if cmd.Location == "" {
+		return nil
+	}
+
+	return validateLocation(cmd.Location)
 }
 
 // Do sends an API request and returns the API response.
-func (cmd *FilesRequest) Do(c *Client) (*FilesResponse, error) {
-	uri := fmt.Sprintf("%s?recursive=%t", URIFiles, cmd.Recursive)
+func (cmd *FilesRequest) Do(ctx context.Context, c *Client) (*FilesResponse, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	uri := URIFiles
 	if cmd.Location != "" {
-		uri = fmt.Sprintf("%s/%s?recursive=%t", URIFiles, cmd.Location, cmd.Recursive)
+		uri = fmt.Sprintf("%s/%s", URIFiles, cmd.Location)
 	}
 
-	b, err := c.doJSONRequest("GET", uri, nil, FilesLocationGETErrors)
+	q := url.Values{}
+	q.Set("recursive", fmt.Sprintf("%t", cmd.Recursive))
+	if cmd.Filter != "" {
+		q.Set("filter", cmd.Filter)
+	}
+
+	if cmd.Force {
+		q.Set("force", "true")
+	}
+
+	uri = fmt.Sprintf("%s?%s", uri, q.Encode())
+
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, FilesLocationGETErrors)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &FilesResponse{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(uri, b, r); err != nil {
 		return nil, err
 	}
 
@@ -102,7 +164,8 @@ func (cmd *FilesRequest) Do(c *Client) (*FilesResponse, error) {
 }
 
 // UploadFileRequest uploads a file to the selected location or create a new
-// empty folder on it.
+// empty folder on it. The content of the file is streamed from the provided
+// reader, without buffering it in memory.
 type UploadFileRequest struct {
 	// Location is the target location to which to upload the file. Currently
 	// only `local` and `sdcard` are supported here, with local referring to
@@ -117,82 +180,240 @@ type UploadFileRequest struct {
 	// not (false). If set, select is implicitely true as well. Optional,
 	// defaults to false. Ignored when creating a folder.
 	Print bool
-	b     *bytes.Buffer
-	w     *multipart.Writer
+	// Path is the folder within the location where to upload the file or
+	// create the folder. Optional, defaults to the root of the location.
+	Path string
+	// UserData is optional data to store with the file, it will be encoded
+	// as JSON. Ignored when creating a folder.
+	UserData interface{}
+	// Progress is called while uploading the content of the files. Optional.
+	Progress ProgressFunc
+
+	files   []uploadFile
+	folders []string
 }
 
-// AddFile adds a new file to be uploaded from a given reader.
-func (req *UploadFileRequest) AddFile(filename string, r io.Reader) error {
-	w, err := req.writer().CreateFormFile("file", filename)
-	if err != nil {
-		return err
-	}
+type uploadFile struct {
+	filename string
+	r        io.Reader
+}
 
-	_, err = io.Copy(w, r)
-	return err
+// AddFile adds a new file to be uploaded from a given reader. The reader is
+// not consumed until the request is sent.
+func (req *UploadFileRequest) AddFile(filename string, r io.Reader) error {
+	req.files = append(req.files, uploadFile{filename: filename, r: r})
+	return nil
+}
 
+// AddFolder adds a new folder to be created.
+func (req *UploadFileRequest) AddFolder(folder string) error {
+	req.folders = append(req.folders, folder)
+	return nil
 }
 
-func (req *UploadFileRequest) writer() *multipart.Writer {
-	if req.w == nil {
-		req.b = bytes.NewBuffer(nil)
-		req.w = multipart.NewWriter(req.b)
+// Validate checks the location of the request and that there is at least one
+// file or folder to upload.
+func (req *UploadFileRequest) Validate() error {
+	if err := validateLocation(req.Location); err != nil {
+		return err
 	}
 
-	return req.w
-}
+	if len(req.files) == 0 && len(req.folders) == 0 {
+		return fmt.Errorf("at least one file or folder must be provided")
+	}
 
-// AddFolder adds a new folder to be created.
-func (req *UploadFileRequest) AddFolder(folder string) error {
-	return req.writer().WriteField("foldername", folder)
+	return nil
 }
 
 // Do sends an API request and returns the API response.
-func (req *UploadFileRequest) Do(c *Client) (*UploadFileResponse, error) {
-	req.addSelectPrintAndClose()
+func (req *UploadFileRequest) Do(ctx context.Context, c *Client) (*UploadFileResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	w := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(req.writeMultipart(w))
+	}()
+
+	defer pr.Close()
 
+	ctx = WithTimeoutClass(ctx, TransferClass)
 	uri := fmt.Sprintf("%s/%s", URIFiles, req.Location)
-	b, err := c.doRequest("POST", uri, req.w.FormDataContentType(), req.b, FilesLocationPOSTErrors)
+	b, err := c.doRequest(ctx, "POST", uri, w.FormDataContentType(), pr, FilesLocationPOSTErrors)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &UploadFileResponse{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(uri, b, r); err != nil {
 		return nil, err
 	}
 
 	return r, err
 }
 
-func (req *UploadFileRequest) addSelectPrintAndClose() error {
-	err := req.writer().WriteField("select", fmt.Sprintf("%t", req.Select))
-	if err != nil {
+func (req *UploadFileRequest) writeMultipart(w *multipart.Writer) error {
+	if err := req.writeFields(w); err != nil {
 		return err
 	}
 
-	err = req.writer().WriteField("print", fmt.Sprintf("%t", req.Print))
-	if err != nil {
+	var sent int64
+	total := req.size()
+	for _, f := range req.files {
+		fw, err := w.CreateFormFile("file", f.filename)
+		if err != nil {
+			return err
+		}
+
+		fn := req.Progress
+		if fn != nil {
+			offset := sent
+			fn = func(n, _ int64) { req.Progress(offset+n, total) }
+		}
+
+		n, err := io.Copy(fw, newProgressReader(f.r, total, fn))
+		if err != nil {
+			return err
+		}
+
+		sent += n
+	}
+
+	return w.Close()
+}
+
+// size returns the total size of the files to upload, -1 if unknown.
+func (req *UploadFileRequest) size() int64 {
+	var total int64
+	for _, f := range req.files {
+		size := readerSize(f.r)
+		if size < 0 {
+			return -1
+		}
+
+		total += size
+	}
+
+	return total
+}
+
+func (req *UploadFileRequest) writeFields(w *multipart.Writer) error {
+	for _, folder := range req.folders {
+		if err := w.WriteField("foldername", folder); err != nil {
+			return err
+		}
+	}
+
+	if req.Path != "" {
+		if err := w.WriteField("path", req.Path); err != nil {
+			return err
+		}
+	}
+
+	if req.UserData != nil {
+		userdata, err := json.Marshal(req.UserData)
+		if err != nil {
+			return err
+		}
+
+		if err := w.WriteField("userdata", string(userdata)); err != nil {
+			return err
+		}
+	}
+
+	if err := w.WriteField("select", fmt.Sprintf("%t", req.Select)); err != nil {
 		return err
 	}
 
-	return req.writer().Close()
+	return w.WriteField("print", fmt.Sprintf("%t", req.Print))
+}
+
+// CreateFolderRequest creates a new folder on the selected location.
+type CreateFolderRequest struct {
+	// Location is the target location on which to create the folder, only
+	// `local` is supported.
+	Location Location
+	// Path of the folder to create, e.g. `folder/subfolder`.
+	Path string
+	// Parents whether to create any missing parent folders too, existing ones
+	// are left untouched.
+	Parents bool
+}
+
+// Validate checks the location and the path of the request.
+func (cmd *CreateFolderRequest) Validate() error {
+	if cmd.Location != Local {
+		return fmt.Errorf("invalid location %q, folders can only be created on local", cmd.Location)
+	}
+
+	for _, s := range strings.Split(strings.Trim(cmd.Path, "/"), "/") {
+		if s == "" {
+			return fmt.Errorf("invalid path %q, must be a non-empty folder path", cmd.Path)
+		}
+	}
+
+	return nil
+}
+
+// Do sends the API requests and returns the API response of the creation of
+// the last folder.
+func (cmd *CreateFolderRequest) Do(ctx context.Context, c *Client) (*UploadFileResponse, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	segments := strings.Split(strings.Trim(cmd.Path, "/"), "/")
+	parents, name := segments[:len(segments)-1], segments[len(segments)-1]
+
+	if cmd.Parents {
+		for i := range parents {
+			_, err := createFolder(ctx, c, cmd.Location, strings.Join(parents[:i], "/"), parents[i])
+			if err != nil && !errors.Is(err, ErrConflict) {
+				return nil, err
+			}
+		}
+	}
+
+	return createFolder(ctx, c, cmd.Location, strings.Join(parents, "/"), name)
+}
+
+func createFolder(ctx context.Context, c *Client, l Location, path, name string) (*UploadFileResponse, error) {
+	r := &UploadFileRequest{Location: l, Path: path}
+	if err := r.AddFolder(name); err != nil {
+		return nil, err
+	}
+
+	return r.Do(ctx, c)
 }
 
-// DeleteFileRequest delete the selected path on the selected location.
+// DeleteFileRequest delete the selected path on the selected location. The
+// path can be either a file or a folder, in which case all its content is
+// deleted too. If the file is currently being printed the returned error
+// matches ErrConflict.
 type DeleteFileRequest struct {
 	// Location is the target location on which to delete the file, either
-	// `local` (for OctoPrint’s uploads folder) or \sdcard\ for the printer’s
+	// `local` (for OctoPrint’s uploads folder) or `sdcard` for the printer’s
 	// SD card (if available)
 	Location Location
-	// Path of the file to delete
+	// Path of the file or folder to delete
 	Path string
 }
 
+// Validate checks the location and the path of the request.
+func (req *DeleteFileRequest) Validate() error {
+	return validateFile(req.Location, req.Path)
+}
+
 // Do sends an API request and returns error if any.
-func (req *DeleteFileRequest) Do(c *Client) error {
-	uri := fmt.Sprintf("%s/%s/%s", URIFiles, req.Location, req.Path)
-	if _, err := c.doJSONRequest("DELETE", uri, nil, FilesLocationDeleteErrors); err != nil {
+func (req *DeleteFileRequest) Do(ctx context.Context, c *Client) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	uri := fileURI(req.Location, req.Path)
+	if _, err := c.doJSONRequest(ctx, "DELETE", uri, nil, FilesLocationDeleteErrors); err != nil {
 		return err
 	}
 
@@ -214,15 +435,24 @@ type SelectFileRequest struct {
 	Print bool `json:"print"`
 }
 
+// Validate checks the location and the path of the request.
+func (cmd *SelectFileRequest) Validate() error {
+	return validateFile(cmd.Location, cmd.Path)
+}
+
 // Do sends an API request and returns an error if any.
-func (cmd *SelectFileRequest) Do(c *Client) error {
+func (cmd *SelectFileRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	uri := fmt.Sprintf("%s/%s/%s", URIFiles, cmd.Location, cmd.Path)
-	_, err := c.doJSONRequest("POST", uri, b, FilesLocationPathPOSTErrors)
+	uri := fileURI(cmd.Location, cmd.Path)
+	_, err := c.doJSONRequest(ctx, "POST", uri, b, FilesLocationPathPOSTErrors)
 	return err
 }
 
@@ -235,3 +465,249 @@ func (cmd *SelectFileRequest) encode(w io.Writer) error {
 		SelectFileRequest: *cmd,
 	})
 }
+
+// CopyFileRequest copies a file or folder to a new destination on the same
+// location.
+type CopyFileRequest struct {
+	// Location is the location of the file or folder to copy, either `local`
+	// or `sdcard`.
+	Location Location `json:"-"`
+	// Path of the file or folder to copy.
+	Path string `json:"-"`
+	// Destination is the path of the folder to copy the file or folder to,
+	// or its new full path.
+	Destination string `json:"destination"`
+}
+
+// Validate checks the location, the path and the destination of the request.
+func (cmd *CopyFileRequest) Validate() error {
+	return validateDestination(cmd.Location, cmd.Path, cmd.Destination)
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *CopyFileRequest) Do(ctx context.Context, c *Client) (*FileInformation, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	return doFileCommand(ctx, c, cmd.Location, cmd.Path, "copy", cmd)
+}
+
+// MoveFileRequest moves a file or folder to a new destination on the same
+// location.
+type MoveFileRequest struct {
+	// Location is the location of the file or folder to move, either `local`
+	// or `sdcard`.
+	Location Location `json:"-"`
+	// Path of the file or folder to move.
+	Path string `json:"-"`
+	// Destination is the path of the folder to move the file or folder to,
+	// or its new full path.
+	Destination string `json:"destination"`
+}
+
+// Validate checks the location, the path and the destination of the request.
+func (cmd *MoveFileRequest) Validate() error {
+	return validateDestination(cmd.Location, cmd.Path, cmd.Destination)
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *MoveFileRequest) Do(ctx context.Context, c *Client) (*FileInformation, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	return doFileCommand(ctx, c, cmd.Location, cmd.Path, "move", cmd)
+}
+
+// AnalyseFileRequest triggers a new analysis of a GCODE file, e.g. after
+// changing the printer profile. The analysis is done in the background, its
+// result is reported by the `MetadataAnalysisFinished` event and the
+// GCodeAnalysis of the file. Only supported for `local` files, by OctoPrint
+// 1.6 and later.
+type AnalyseFileRequest struct {
+	// Location is the location of the file to analyse, only `local` is
+	// supported.
+	Location Location `json:"-"`
+	// Path of the file to analyse.
+	Path string `json:"-"`
+	// PrinterProfile is the name of the printer profile to analyse the file
+	// for, defaults to the currently selected one.
+	PrinterProfile string `json:"printerProfile,omitempty"`
+}
+
+// Validate checks the location and the path of the request.
+func (cmd *AnalyseFileRequest) Validate() error {
+	return validateFile(cmd.Location, cmd.Path)
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *AnalyseFileRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
+	_, err := doFileCommand(ctx, c, cmd.Location, cmd.Path, "analyse", cmd)
+	return err
+}
+
+// SliceFileRequest slices an STL file into GCODE. The slicing is done in the
+// background, the response contains the information of the file that will be
+// generated.
+type SliceFileRequest struct {
+	// Location is the location of the STL file to slice, only `local` is
+	// supported.
+	Location Location `json:"-"`
+	// Path of the STL file to slice.
+	Path string `json:"-"`
+	// Slicer to use, defaults to the default slicer.
+	Slicer string `json:"slicer,omitempty"`
+	// GCode is the name of the GCODE file to generate, in the same location
+	// as the STL file. Defaults to the name of the STL file with the `.gco`
+	// extension.
+	GCode string `json:"gcode,omitempty"`
+	// Profile is the name of the slicing profile to use, defaults to the
+	// default profile of the slicer.
+	Profile string `json:"profile,omitempty"`
+	// ProfileOverrides overrides values of the slicing profile, e.g.
+	// `layer_height`.
+	ProfileOverrides map[string]interface{} `json:"-"`
+	// PrinterProfile is the name of the printer profile to slice for,
+	// defaults to the currently selected one.
+	PrinterProfile string `json:"printerProfile,omitempty"`
+	// Position is the position of the model on the print bed, in mm. Defaults
+	// to the center of the bed.
+	Position *Position `json:"position,omitempty"`
+	// Select whether to select the generated GCODE file after slicing.
+	Select bool `json:"select"`
+	// Print whether to start printing the generated GCODE file after
+	// slicing, implies select.
+	Print bool `json:"print"`
+}
+
+// Position on the print bed, in mm.
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Validate checks the location, the path and the position of the request.
+func (cmd *SliceFileRequest) Validate() error {
+	if err := validateFile(cmd.Location, cmd.Path); err != nil {
+		return err
+	}
+
+	if cmd.Position != nil && (cmd.Position.X < 0 || cmd.Position.Y < 0) {
+		return fmt.Errorf("invalid position %g,%g, must not be negative", cmd.Position.X, cmd.Position.Y)
+	}
+
+	return nil
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *SliceFileRequest) Do(ctx context.Context, c *Client) (*FileInformation, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	fields, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{}
+	if err := json.Unmarshal(fields, &payload); err != nil {
+		return nil, err
+	}
+
+	for k, v := range cmd.ProfileOverrides {
+		payload["profile."+k] = v
+	}
+
+	return doFileCommand(ctx, c, cmd.Location, cmd.Path, "slice", payload)
+}
+
+// doFileCommand issues a command against a file, with the fields of payload
+// along the command name, and decodes the resulting file information.
+func doFileCommand(
+	ctx context.Context, c *Client, l Location, path, command string, payload interface{},
+) (*FileInformation, error) {
+	fields, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	v := map[string]interface{}{}
+	if err := json.Unmarshal(fields, &v); err != nil {
+		return nil, err
+	}
+
+	v["command"] = command
+
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(v); err != nil {
+		return nil, err
+	}
+
+	b2, err := c.doJSONRequest(ctx, "POST", fileURI(l, path), b, FilesLocationPathPOSTErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &FileInformation{}
+	if len(b2) == 0 {
+		return r, nil
+	}
+
+	if err := c.decode(fileURI(l, path), b2, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func validateLocation(l Location) error {
+	_, err := ParseLocation(string(l))
+	return err
+}
+
+// validateFile checks the location and that the path is not empty, as
+// OctoPrint would address the location itself.
+func validateFile(l Location, path string) error {
+	if err := validateLocation(l); err != nil {
+		return err
+	}
+
+	if strings.Trim(path, "/") == "" {
+		return fmt.Errorf("invalid empty path")
+	}
+
+	return nil
+}
+
+func validateDestination(l Location, path, destination string) error {
+	if err := validateFile(l, path); err != nil {
+		return err
+	}
+
+	if strings.TrimSpace(destination) == "" {
+		return fmt.Errorf("invalid empty destination")
+	}
+
+	return nil
+}
+
+// fileURI returns the URI of the file or folder at path on the given location,
+// escaping every segment of the path.
+func fileURI(l Location, path string) string {
+	return fmt.Sprintf("%s/%s/%s", URIFiles, l, escapePath(path))
+}
+
+func escapePath(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -2,6 +2,12 @@ package octoprint
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,11 +20,11 @@ func TestUploadFileRequest_Do(t *testing.T) {
 	err := r.AddFile("foo.gcode", bytes.NewBufferString("foo"))
 	assert.NoError(t, err)
 
-	state, err := r.Do(cli)
+	state, err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 	assert.Equal(t, "foo.gcode", state.File.Local.Name)
 
-	err = (&DeleteFileRequest{Location: Local, Path: "foo.gcode"}).Do(cli)
+	err = (&DeleteFileRequest{Location: Local, Path: "foo.gcode"}).Do(context.Background(), cli)
 	assert.NoError(t, err)
 }
 
@@ -29,7 +35,7 @@ func TestUploadFileRequest_DoWithFolder(t *testing.T) {
 	err := r.AddFolder("qux")
 	assert.NoError(t, err)
 
-	state, err := r.Do(cli)
+	state, err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 	assert.Equal(t, true, state.Done)
 }
@@ -41,24 +47,24 @@ func TestFilesRequest_Do(t *testing.T) {
 	err := ur.AddFile("foo.gcode", bytes.NewBufferString("foo"))
 	assert.NoError(t, err)
 
-	_, err = ur.Do(cli)
+	_, err = ur.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
-	files, err := (&FilesRequest{}).Do(cli)
+	files, err := (&FilesRequest{}).Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	assert.True(t, len(files.Files) >= 1)
-	err = (&DeleteFileRequest{Location: Local, Path: "foo.gcode"}).Do(cli)
+	err = (&DeleteFileRequest{Location: Local, Path: "foo.gcode"}).Do(context.Background(), cli)
 	assert.NoError(t, err)
 	return
 
 	r := &FileRequest{Location: Local, Filename: "foo.gcode"}
-	file, err := r.Do(cli)
+	file, err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "foo.gcode", file.Name)
 
-	err = (&DeleteFileRequest{Location: Local, Path: "foo.gcode"}).Do(cli)
+	err = (&DeleteFileRequest{Location: Local, Path: "foo.gcode"}).Do(context.Background(), cli)
 	assert.NoError(t, err)
 }
 
@@ -68,19 +74,315 @@ func TestSelectFileRequest_Do(t *testing.T) {
 	ur := &UploadFileRequest{Location: Local}
 	err := ur.AddFile("foo2.gcode", bytes.NewBufferString("foo"))
 	assert.NoError(t, err)
-	_, err = ur.Do(cli)
+	_, err = ur.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	r := &SelectFileRequest{Location: Local, Path: "foo2.gcode"}
-	err = r.Do(cli)
+	err = r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 }
 
 func xxxTestFilesRequest_DoWithLocation(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
-	files, err := (&FilesRequest{Location: SDCard}).Do(cli)
+	files, err := (&FilesRequest{Location: SDCard}).Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	assert.Len(t, files.Files, 0)
 }
+
+func TestUploadFileRequest_DoWithFields(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != URIFiles+"/sdcard" {
+			w.WriteHeader(404)
+			return
+		}
+
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+
+		content, _ := ioutil.ReadAll(f)
+		assert.Equal(t, "foo.gcode", h.Filename)
+		assert.Equal(t, "G28", string(content))
+		assert.Equal(t, "folder", r.FormValue("path"))
+		assert.Equal(t, `{"foo":"bar"}`, r.FormValue("userdata"))
+		assert.Equal(t, "true", r.FormValue("select"))
+		assert.Equal(t, "false", r.FormValue("print"))
+
+		w.WriteHeader(201)
+		w.Write([]byte(`{"files": {"sdcard": {"name": "foo.gcode", "origin": "sdcard"}}, "done": true}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("G28"))
+		pw.Close()
+	}()
+
+	r := &UploadFileRequest{
+		Location: SDCard,
+		Path:     "folder",
+		Select:   true,
+		UserData: map[string]string{"foo": "bar"},
+	}
+
+	err := r.AddFile("foo.gcode", pr)
+	assert.NoError(t, err)
+
+	state, err := r.Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo.gcode", state.File.SDCard.Name)
+	assert.True(t, state.Done)
+}
+
+func TestSelectFileRequest_DoWithPrint(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "/api/files/local/folder/foo%20%23bar.gcode", r.URL.EscapedPath())
+		assert.JSONEq(t, `{"command": "select", "print": true}`, string(b))
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r := &SelectFileRequest{Location: Local, Path: "folder/foo #bar.gcode", Print: true}
+	err := r.Do(context.Background(), cli)
+	assert.NoError(t, err)
+}
+
+func TestFileURI(t *testing.T) {
+	assert.Equal(t, "/api/files/local/foo.gcode", fileURI(Local, "foo.gcode"))
+	assert.Equal(t, "/api/files/sdcard/a/b%3F.gcode", fileURI(SDCard, "/a/b?.gcode"))
+}
+
+func TestMoveFileRequest_Do(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "/api/files/local/foo.gcode", r.URL.Path)
+		assert.JSONEq(t, `{"command": "move", "destination": "folder"}`, string(b))
+
+		w.WriteHeader(201)
+		w.Write([]byte(`{
+			"name": "foo.gcode",
+			"origin": "local",
+			"path": "folder/foo.gcode",
+			"refs": {
+				"resource": "http://example.com/api/files/local/folder/foo.gcode",
+				"download": "http://example.com/downloads/files/local/folder/foo.gcode"
+			}
+		}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r := &MoveFileRequest{Location: Local, Path: "foo.gcode", Destination: "folder"}
+	f, err := r.Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "folder/foo.gcode", f.Path)
+	assert.Equal(t, "http://example.com/api/files/local/folder/foo.gcode", f.Refs.Resource)
+}
+
+func TestDeleteFileRequest_DoConflict(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/api/files/sdcard/folder", r.URL.Path)
+		w.WriteHeader(409)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	err := (&DeleteFileRequest{Location: SDCard, Path: "folder"}).Do(context.Background(), cli)
+	assert.True(t, errors.Is(err, ErrConflict))
+	assert.Equal(t, FilesLocationDeleteErrors[409], err.Error())
+}
+
+func TestCreateFolderRequest_Do(t *testing.T) {
+	var created []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.FormValue("foldername")
+		if parent := r.FormValue("path"); parent != "" {
+			path = parent + "/" + path
+		}
+
+		if path == "a" {
+			w.WriteHeader(409)
+			return
+		}
+
+		created = append(created, path)
+		w.WriteHeader(201)
+		w.Write([]byte(`{"folder": {"name": "c", "path": "a/b/c", "origin": "local"}, "done": true}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r := &CreateFolderRequest{Location: Local, Path: "a/b/c", Parents: true}
+	state, err := r.Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/b", "a/b/c"}, created)
+	assert.Equal(t, "a/b/c", state.Folder.Path)
+
+	for _, path := range []string{"", "/", "a//b"} {
+		_, err := (&CreateFolderRequest{Location: Local, Path: path}).Do(context.Background(), cli)
+		assert.Error(t, err)
+	}
+
+	assert.Len(t, created, 2)
+}
+
+func TestSliceFileRequest_Do(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "/api/files/local/foo.stl", r.URL.Path)
+		assert.JSONEq(t, `{
+			"command": "slice",
+			"slicer": "cura",
+			"gcode": "foo.gcode",
+			"profile": "high_quality",
+			"profile.infill": 75.0,
+			"position": {"x": 100, "y": 50},
+			"select": true,
+			"print": false
+		}`, string(b))
+
+		w.WriteHeader(202)
+		w.Write([]byte(`{"name": "foo.gcode", "origin": "local", "path": "foo.gcode"}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r := &SliceFileRequest{
+		Location:         Local,
+		Path:             "foo.stl",
+		Slicer:           "cura",
+		GCode:            "foo.gcode",
+		Profile:          "high_quality",
+		ProfileOverrides: map[string]interface{}{"infill": 75.0},
+		Position:         &Position{X: 100, Y: 50},
+		Select:           true,
+	}
+
+	f, err := r.Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo.gcode", f.Name)
+}
+
+func TestAnalyseFileRequest_Do(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/api/files/local/foo.gcode", r.URL.Path)
+		assert.JSONEq(t, `{"command": "analyse", "printerProfile": "prusa_mk3"}`, string(b))
+
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r := &AnalyseFileRequest{Location: Local, Path: "foo.gcode", PrinterProfile: "prusa_mk3"}
+	assert.NoError(t, r.Do(context.Background(), cli))
+}
+
+func TestFileRequests_Validate(t *testing.T) {
+	assert.NoError(t, (&FileRequest{Location: SDCard, Filename: "cube.gco"}).Validate())
+	assert.Error(t, (&FileRequest{Location: "usb", Filename: "cube.gco"}).Validate())
+	assert.Error(t, (&FileRequest{Location: Local, Filename: "/"}).Validate())
+	assert.NoError(t, (&FilesRequest{}).Validate())
+	assert.Error(t, (&FilesRequest{Location: "Local"}).Validate())
+	assert.Error(t, (&UploadFileRequest{Location: Local}).Validate())
+	assert.Error(t, (&CreateFolderRequest{Location: Local, Path: "a//b"}).Validate())
+	assert.EqualError(t, (&CreateFolderRequest{Location: SDCard, Path: "a"}).Validate(),
+		`invalid location "sdcard", folders can only be created on local`)
+	assert.Error(t, (&DeleteFileRequest{Location: Local}).Validate())
+	assert.Error(t, (&SelectFileRequest{Path: "foo.gcode"}).Validate())
+	assert.NoError(t, (&MoveFileRequest{Location: Local, Path: "foo.gcode", Destination: "bar"}).Validate())
+	assert.Error(t, (&CopyFileRequest{Location: Local, Path: "foo.gcode"}).Validate())
+	assert.Error(t, (&AnalyseFileRequest{Location: Local}).Validate())
+	assert.Error(t, (&SliceFileRequest{Location: Local, Path: "foo.stl", Position: &Position{X: -1}}).Validate())
+	assert.Error(t, (&DownloadFileRequest{Location: SDCard, Path: "cube.gco"}).Validate())
+	assert.Error(t, (&DownloadFileRequest{Location: Local, Path: "foo.gcode", Offset: -1}).Validate())
+
+	err := (&DeleteFileRequest{Location: Local}).Do(context.Background(), NewClient("http://127.0.0.1:0", ""))
+	assert.EqualError(t, err, "invalid empty path")
+}
+
+// filesListingJSON is a listing returned by OctoPrint 1.8, with files on both
+// locations.
+const filesListingJSON = `{
+	"files": [{
+		"date": 1664443281,
+		"display": "whistle_v2.gcode",
+		"gcodeAnalysis": {
+			"dimensions": {"depth": 47.25, "height": 19.8, "width": 47.59},
+			"estimatedPrintTime": 1188.1234,
+			"filament": {"tool0": {"length": 810.37, "volume": 5.83}}
+		},
+		"hash": "8e16f5ab8b9cd6a5d3af3a8b5f1cb5fc7a1e2b9d",
+		"name": "whistle_v2.gcode",
+		"origin": "local",
+		"path": "whistle_v2.gcode",
+		"prints": {"failure": 0, "success": 1, "last": {"date": 1664450000.1234, "printTime": 1205.5, "success": true}},
+		"refs": {
+			"download": "http://octopi.local/downloads/files/local/whistle_v2.gcode",
+			"resource": "http://octopi.local/api/files/local/whistle_v2.gcode"
+		},
+		"size": 1468987,
+		"type": "machinecode",
+		"typePath": ["machinecode", "gcode"]
+	}, {
+		"children": [{
+			"display": "bracket.stl",
+			"name": "bracket.stl",
+			"origin": "local",
+			"path": "parts/bracket.stl",
+			"type": "model",
+			"typePath": ["model", "stl"]
+		}],
+		"display": "parts",
+		"name": "parts",
+		"origin": "local",
+		"path": "parts",
+		"refs": {"resource": "http://octopi.local/api/files/local/parts"},
+		"type": "folder",
+		"typePath": ["folder"]
+	}, {
+		"display": "cube.gco",
+		"name": "cube.gco",
+		"origin": "sdcard",
+		"path": "cube.gco",
+		"refs": {"resource": "http://octopi.local/api/files/sdcard/cube.gco"},
+		"type": "machinecode",
+		"typePath": ["machinecode", "gcode"]
+	}],
+	"free": 24441745408,
+	"total": 31083073536
+}`
+
+func TestFilesRequest_DoTypePath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(filesListingJSON))
+	}))
+	defer ts.Close()
+
+	r, err := (&FilesRequest{Recursive: true}).Do(context.Background(), NewClient(ts.URL, ""))
+	assert.NoError(t, err)
+	assert.Len(t, r.Files, 3)
+
+	assert.Equal(t, []string{"machinecode", "gcode"}, r.Files[0].TypePath)
+	assert.False(t, r.Files[0].IsFolder())
+	assert.Equal(t, int64(1664450000), r.Files[0].Prints.Last.Date.Unix())
+
+	assert.Equal(t, []string{"folder"}, r.Files[1].TypePath)
+	assert.True(t, r.Files[1].IsFolder())
+	assert.Len(t, r.Files[1].Children, 1)
+	assert.Equal(t, []string{"model", "stl"}, r.Files[1].Children[0].TypePath)
+
+	assert.Equal(t, SDCard, r.Files[2].Origin)
+	assert.Equal(t, []string{"machinecode", "gcode"}, r.Files[2].TypePath)
+}
@@ -0,0 +1,27 @@
+package octoprint
+
+import (
+	"context"
+)
+
+const URIFirmwareCheck = "/api/plugin/firmware_check"
+
+// FirmwareCheckRequest retrieves the warnings raised by the bundled firmware
+// check plugin about the firmware of the connected printer, e.g. firmwares
+// known to have disabled thermal runaway protection.
+type FirmwareCheckRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *FirmwareCheckRequest) Do(ctx context.Context, c *Client) (*FirmwareCheckResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIFirmwareCheck, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &FirmwareCheckResponse{}
+	if err := c.decode(URIFirmwareCheck, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
@@ -0,0 +1,41 @@
+package octoprint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFirmwareCheckRequest_Do(t *testing.T) {
+	body := `{"warnings": {
+		"firmware-unsafe": {"message": "Firmware without thermal runaway protection", "severity": "critical", "url": "https://faq.octoprint.org/warning-firmware-unsafe"},
+		"firmware-broken": {"message": "Broken firmware", "severity": "warning"}
+	}}`
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&FirmwareCheckRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Len(t, r.Warnings, 2)
+	assert.Equal(t, "firmware-broken", r.Warnings[0].Type)
+	assert.Equal(t, "firmware-unsafe", r.Warnings[1].Type)
+	assert.True(t, r.Critical())
+
+	body = `{"warnings": [{"type": "firmware-broken", "message": "Broken firmware", "severity": "warning"}]}`
+	r, err = (&FirmwareCheckRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Len(t, r.Warnings, 1)
+	assert.False(t, r.Critical())
+
+	body = `{}`
+	r, err = (&FirmwareCheckRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Len(t, r.Warnings, 0)
+}
@@ -0,0 +1,358 @@
+package octoprint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPrinterExists is returned by Fleet.Add when the name is already in use.
+var ErrPrinterExists = errors.New("Printer already exists in the fleet")
+
+// Fleet manages a set of named printers, watching the state of every printer
+// while Run is running and answering aggregate queries from the last state
+// polled. It is safe for concurrent use.
+type Fleet struct {
+	// Interval is the interval between polls of every printer, zero means
+	// DefaultWatchInterval.
+	Interval time.Duration
+	// OnStateChange is called when the state of a printer changes.
+	OnStateChange func(name string, old, new PrinterState)
+	// OnError is called when a poll of a printer fails.
+	OnError func(name string, err error)
+	// NewHealthChecker, if set, returns the HealthChecker used to check the
+	// health of every printer, reported in PrinterStatus.Health.
+	NewHealthChecker func() *HealthChecker
+	// OnHealthChange is called when the health state of a printer changes.
+	OnHealthChange func(name string, old, new HealthState)
+
+	mu       sync.RWMutex
+	printers map[string]*fleetPrinter
+	ctx      context.Context
+}
+
+// fleetPrinter is a printer of a fleet and its last polled status.
+type fleetPrinter struct {
+	name   string
+	c      *Client
+	status PrinterStatus
+	cancel context.CancelFunc
+}
+
+// PrinterStatus is the last polled status of a printer of a fleet.
+type PrinterStatus struct {
+	// State is the state of the printer, the zero PrinterState while the
+	// printer is not connected.
+	State PrinterState
+	// Temperatures of the printer, by tool, e.g. `tool0` or `bed`.
+	Temperatures map[string]TemperatureData
+	// Job is the current job, nil until polled.
+	Job *JobResponse
+	// Err is the error of the last failed poll, cleared by the next
+	// successful poll of the printer state.
+	Err error
+	// Updated is the time of the last successful poll of the printer state.
+	Updated time.Time
+	// Health is the health of the printer, only checked if the Fleet has a
+	// NewHealthChecker.
+	Health Health
+}
+
+// IsIdle returns true if the printer is ready to start a new print.
+func (s *PrinterStatus) IsIdle() bool {
+	f := s.State.Flags
+	return f.Ready && !f.Printing && !f.Paused && !f.Pausing && !f.Cancelling &&
+		!f.Resuming && !f.Finishing && !f.Error
+}
+
+// IsPrinting returns true if the printer is printing, including paused prints.
+func (s *PrinterStatus) IsPrinting() bool {
+	f := s.State.Flags
+	return f.Printing || f.Paused || f.Pausing || f.Resuming || f.Finishing
+}
+
+// NewFleet returns a new empty Fleet.
+func NewFleet() *Fleet {
+	return &Fleet{printers: make(map[string]*fleetPrinter)}
+}
+
+// Add adds a printer to the fleet, it is watched immediately if Run is
+// running.
+func (f *Fleet) Add(name string, c *Client) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.printers[name]; ok {
+		return fmt.Errorf("%w: %s", ErrPrinterExists, name)
+	}
+
+	p := &fleetPrinter{name: name, c: c}
+	f.printers[name] = p
+	if f.ctx != nil {
+		f.watch(p)
+	}
+
+	return nil
+}
+
+// Remove removes a printer from the fleet, stopping its watcher.
+func (f *Fleet) Remove(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	p, ok := f.printers[name]
+	if !ok {
+		return
+	}
+
+	if p.cancel != nil {
+		p.cancel()
+	}
+
+	delete(f.printers, name)
+}
+
+// Client returns the client of the given printer.
+func (f *Fleet) Client(name string) (*Client, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	p, ok := f.printers[name]
+	if !ok {
+		return nil, false
+	}
+
+	return p.c, true
+}
+
+// Names returns the names of the printers, sorted.
+func (f *Fleet) Names() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	names := make([]string, 0, len(f.printers))
+	for name := range f.printers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Run watches all the printers until ctx is done, returning ctx.Err().
+func (f *Fleet) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	f.mu.Lock()
+	f.ctx = ctx
+	for _, p := range f.printers {
+		f.watch(p)
+	}
+
+	f.mu.Unlock()
+
+	<-ctx.Done()
+
+	f.mu.Lock()
+	f.ctx = nil
+	for _, p := range f.printers {
+		p.cancel = nil
+	}
+
+	f.mu.Unlock()
+	return ctx.Err()
+}
+
+// watch starts the watcher of p, must be called with the lock held.
+func (f *Fleet) watch(p *fleetPrinter) {
+	ctx, cancel := context.WithCancel(f.ctx)
+	p.cancel = cancel
+
+	w := &Watcher{
+		PrinterInterval: f.Interval,
+		JobInterval:     f.Interval,
+		OnStateChange: func(old, new PrinterState) {
+			f.update(p, func(s *PrinterStatus) { s.State = new })
+			if f.OnStateChange != nil {
+				f.OnStateChange(p.name, old, new)
+			}
+		},
+		OnTemperature: func(temps map[string]TemperatureData) {
+			f.update(p, func(s *PrinterStatus) { s.Temperatures = temps })
+		},
+		OnProgress: func(job *JobResponse) {
+			f.update(p, func(s *PrinterStatus) { s.Job = job })
+		},
+		OnPoll: func() {
+			f.update(p, func(s *PrinterStatus) {
+				s.Err = nil
+				s.Updated = time.Now()
+			})
+		},
+		OnError: func(err error) {
+			f.mu.Lock()
+			p.status.Err = err
+			f.mu.Unlock()
+
+			if f.OnError != nil {
+				f.OnError(p.name, err)
+			}
+		},
+	}
+
+	go w.Run(ctx, p.c)
+
+	if f.NewHealthChecker != nil {
+		go f.checkHealth(ctx, p)
+	}
+}
+
+// checkHealth checks the health of p until ctx is done.
+func (f *Fleet) checkHealth(ctx context.Context, p *fleetPrinter) {
+	h := f.NewHealthChecker()
+	onStateChange, onCheck := h.OnStateChange, h.OnCheck
+	h.OnStateChange = func(old, new HealthState) {
+		if onStateChange != nil {
+			onStateChange(old, new)
+		}
+
+		if f.OnHealthChange != nil {
+			f.OnHealthChange(p.name, old, new)
+		}
+	}
+
+	h.OnCheck = func(health Health) {
+		f.update(p, func(s *PrinterStatus) { s.Health = health })
+		if onCheck != nil {
+			onCheck(health)
+		}
+	}
+
+	h.Run(ctx, p.c)
+}
+
+func (f *Fleet) update(p *fleetPrinter, fn func(s *PrinterStatus)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fn(&p.status)
+}
+
+// Status returns the last polled status of the given printer.
+func (f *Fleet) Status(name string) (PrinterStatus, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	p, ok := f.printers[name]
+	if !ok {
+		return PrinterStatus{}, false
+	}
+
+	return p.status, true
+}
+
+// Idle returns the names of the printers ready to start a new print, sorted.
+func (f *Fleet) Idle() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var names []string
+	for name, p := range f.printers {
+		if p.status.IsIdle() {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Printing returns the current jobs of the printers printing, by printer.
+func (f *Fleet) Printing() map[string]*JobResponse {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	jobs := make(map[string]*JobResponse)
+	for name, p := range f.printers {
+		if p.status.IsPrinting() && p.status.Job != nil {
+			jobs[name] = p.status.Job
+		}
+	}
+
+	return jobs
+}
+
+// Temperatures returns the temperatures of all the printers, by printer and
+// tool.
+func (f *Fleet) Temperatures() map[string]map[string]TemperatureData {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	temps := make(map[string]map[string]TemperatureData)
+	for name, p := range f.printers {
+		if p.status.Temperatures != nil {
+			temps[name] = p.status.Temperatures
+		}
+	}
+
+	return temps
+}
+
+// Broadcast calls fn for every printer concurrently, e.g. to send the same
+// command to all of them, and waits for all the calls to return. If any call
+// fails a FleetError is returned.
+func (f *Fleet) Broadcast(ctx context.Context, fn func(ctx context.Context, name string, c *Client) error) error {
+	f.mu.RLock()
+	printers := make([]*fleetPrinter, 0, len(f.printers))
+	for _, p := range f.printers {
+		printers = append(printers, p)
+	}
+
+	f.mu.RUnlock()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := FleetError{}
+	for _, p := range printers {
+		wg.Add(1)
+		go func(p *fleetPrinter) {
+			defer wg.Done()
+			if err := fn(ctx, p.name, p.c); err != nil {
+				mu.Lock()
+				errs[p.name] = err
+				mu.Unlock()
+			}
+		}(p)
+	}
+
+	wg.Wait()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// FleetError is returned by Fleet.Broadcast with the errors of the printers
+// that failed, by printer.
+type FleetError map[string]error
+
+func (e FleetError) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	msgs := make([]string, len(names))
+	for i, name := range names {
+		msgs[i] = fmt.Sprintf("%s: %s", name, e[name])
+	}
+
+	return strings.Join(msgs, "; ")
+}
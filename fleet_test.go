@@ -0,0 +1,183 @@
+package octoprint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFleetTestServer(printing bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case URIVersion:
+			w.Write([]byte(`{"api": "0.1", "server": "1.5.0"}`))
+		case URIPrinter:
+			if printing {
+				w.Write([]byte(`{
+					"state": {"text": "Printing", "flags": {"operational": true, "printing": true}},
+					"temperature": {"tool0": {"actual": 210, "target": 210}}
+				}`))
+				return
+			}
+
+			w.Write([]byte(`{
+				"state": {"text": "Operational", "flags": {"operational": true, "ready": true}},
+				"temperature": {"tool0": {"actual": 25, "target": 0}}
+			}`))
+		case JobTool:
+			if r.Method == "POST" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			if printing {
+				w.Write([]byte(`{"job": {"file": {"name": "cube.gcode"}}, "progress": {"completion": 50}, "state": "Printing"}`))
+				return
+			}
+
+			w.Write([]byte(`{"state": "Operational"}`))
+		}
+	}))
+}
+
+func TestFleet(t *testing.T) {
+	idle := newFleetTestServer(false)
+	defer idle.Close()
+
+	printing := newFleetTestServer(true)
+	defer printing.Close()
+
+	f := NewFleet()
+	f.Interval = time.Millisecond
+	assert.NoError(t, f.Add("idle", NewClient(idle.URL, "")))
+	assert.True(t, errors.Is(f.Add("idle", NewClient(idle.URL, "")), ErrPrinterExists))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- f.Run(ctx) }()
+
+	// printers added while running are watched too.
+	assert.NoError(t, f.Add("printing", NewClient(printing.URL, "")))
+	assert.Equal(t, []string{"idle", "printing"}, f.Names())
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) && (len(f.Idle()) == 0 || len(f.Printing()) == 0) {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	assert.Equal(t, []string{"idle"}, f.Idle())
+
+	jobs := f.Printing()
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, "cube.gcode", jobs["printing"].Job.File.Name)
+
+	temps := f.Temperatures()
+	assert.Equal(t, 25., temps["idle"]["tool0"].Actual)
+	assert.Equal(t, 210., temps["printing"]["tool0"].Actual)
+
+	s, ok := f.Status("printing")
+	assert.True(t, ok)
+	assert.Equal(t, "Printing", s.State.Text)
+	assert.False(t, s.Updated.IsZero())
+
+	f.Remove("idle")
+	_, ok = f.Client("idle")
+	assert.False(t, ok)
+}
+
+func TestFleet_TransientError(t *testing.T) {
+	var mu sync.Mutex
+	var polls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == JobTool {
+			w.Write([]byte(`{"state": "Operational"}`))
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		polls++
+		if polls == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Write([]byte(`{"state": {"text": "Operational", "flags": {"operational": true, "ready": true}}}`))
+	}))
+	defer ts.Close()
+
+	f := NewFleet()
+	f.Interval = time.Millisecond
+	assert.NoError(t, f.Add("prusa", NewClient(ts.URL, "")))
+
+	var errs int
+	f.OnError = func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs++
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- f.Run(ctx) }()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := polls
+		mu.Unlock()
+
+		if n > 3 {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	// the state did not change, but the error is cleared by the next poll.
+	s, _ := f.Status("prusa")
+	assert.NoError(t, s.Err)
+	assert.True(t, time.Since(s.Updated) < time.Second)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, errs)
+}
+
+func TestFleet_Broadcast(t *testing.T) {
+	ts := newFleetTestServer(true)
+	defer ts.Close()
+
+	f := NewFleet()
+	assert.NoError(t, f.Add("foo", NewClient(ts.URL, "")))
+	assert.NoError(t, f.Add("bar", NewClient(ts.URL, "")))
+	assert.NoError(t, f.Add("qux", NewClient("http://127.0.0.1:1", "")))
+
+	err := f.Broadcast(context.Background(), func(ctx context.Context, name string, c *Client) error {
+		return (&PauseRequest{Action: Pause}).Do(ctx, c)
+	})
+
+	var errs FleetError
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 1)
+	assert.Error(t, errs["qux"])
+
+	err = f.Broadcast(context.Background(), func(ctx context.Context, name string, c *Client) error {
+		return nil
+	})
+
+	assert.NoError(t, err)
+}
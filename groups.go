@@ -0,0 +1,152 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const URIGroups = "/api/access/groups"
+
+var (
+	GroupsErrors = statusMapping{
+		400: "The group cannot be modified, e.g. it is not removable or changeable",
+		403: "The user is not an admin",
+		404: "The group does not exist",
+	}
+	GroupCreateErrors = statusMapping{
+		400: "Key or name is missing or the request is otherwise invalid",
+		403: "The user is not an admin",
+		409: "A group with the same key already exists",
+	}
+)
+
+// GroupListRequest retrieves the list of permission groups.
+type GroupListRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *GroupListRequest) Do(ctx context.Context, c *Client) (*GroupsResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIGroups, nil, GroupsErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeGroupsResponse(c, URIGroups, b)
+}
+
+// GroupRequest retrieves a single permission group.
+type GroupRequest struct {
+	// Key of the group to retrieve.
+	Key string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *GroupRequest) Do(ctx context.Context, c *Client) (*Group, error) {
+	uri := fmt.Sprintf("%s/%s", URIGroups, cmd.Key)
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, GroupsErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Group{}
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// GroupCreateRequest adds a new permission group. Returns the updated list of
+// groups.
+type GroupCreateRequest struct {
+	// Key is the identifier of the new group.
+	Key string `json:"key"`
+	// Name is the display name of the new group.
+	Name string `json:"name"`
+	// Description of the group.
+	Description string `json:"description,omitempty"`
+	// Permissions keys of the permissions assigned to the group.
+	Permissions []string `json:"permissions"`
+	// Subgroups keys of the groups included in the group.
+	Subgroups []string `json:"subgroups,omitempty"`
+	// Default whether the group is assigned to new users by default.
+	Default bool `json:"default"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *GroupCreateRequest) Do(ctx context.Context, c *Client) (*GroupsResponse, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	b2, err := c.doJSONRequest(ctx, "POST", URIGroups, b, GroupCreateErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeGroupsResponse(c, URIGroups, b2)
+}
+
+// GroupUpdateRequest updates an existing permission group, only the provided
+// fields are changed. Returns the updated list of groups.
+type GroupUpdateRequest struct {
+	// Key of the group to update.
+	Key string `json:"-"`
+	// Description of the group.
+	Description *string `json:"description,omitempty"`
+	// Permissions keys of the permissions assigned to the group.
+	Permissions []string `json:"permissions,omitempty"`
+	// Subgroups keys of the groups included in the group.
+	Subgroups []string `json:"subgroups,omitempty"`
+	// Default whether the group is assigned to new users by default.
+	Default *bool `json:"default,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *GroupUpdateRequest) Do(ctx context.Context, c *Client) (*GroupsResponse, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	uri := fmt.Sprintf("%s/%s", URIGroups, cmd.Key)
+	b2, err := c.doJSONRequest(ctx, "PUT", uri, b, GroupsErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeGroupsResponse(c, uri, b2)
+}
+
+// GroupDeleteRequest deletes a permission group, only removable groups can be
+// deleted. Returns the updated list of groups.
+type GroupDeleteRequest struct {
+	// Key of the group to delete.
+	Key string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *GroupDeleteRequest) Do(ctx context.Context, c *Client) (*GroupsResponse, error) {
+	uri := fmt.Sprintf("%s/%s", URIGroups, cmd.Key)
+	b, err := c.doJSONRequest(ctx, "DELETE", uri, nil, GroupsErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeGroupsResponse(c, uri, b)
+}
+
+func decodeGroupsResponse(c *Client, uri string, b []byte) (*GroupsResponse, error) {
+	r := &GroupsResponse{}
+	if len(b) == 0 {
+		return r, nil
+	}
+
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
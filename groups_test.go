@@ -0,0 +1,50 @@
+package octoprint
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupCreateRequest_Do(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+
+		w.Write([]byte(`{"groups": [{
+			"key": "operators",
+			"name": "Operators",
+			"permissions": ["STATUS", "PRINT"],
+			"subgroups": [],
+			"default": false,
+			"removable": true,
+			"changeable": true,
+			"toggleable": true
+		}]}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&GroupCreateRequest{
+		Key:         "operators",
+		Name:        "Operators",
+		Permissions: []string{PermissionStatus, PermissionPrint},
+	}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"key": "operators",
+		"name": "Operators",
+		"permissions": ["STATUS", "PRINT"],
+		"default": false
+	}`, body)
+
+	assert.Len(t, r.Groups, 1)
+	assert.Equal(t, []string{"STATUS", "PRINT"}, r.Groups[0].Permissions)
+	assert.True(t, r.Groups[0].Removable)
+}
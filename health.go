@@ -0,0 +1,292 @@
+package octoprint
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHealthInterval is the default interval between checks of a
+	// HealthChecker.
+	DefaultHealthInterval = 10 * time.Second
+	// DefaultHealthWindow is the default amount of checks used to compute
+	// the latency and the error rate.
+	DefaultHealthWindow = 10
+	// DefaultDegradedLatency is the default average latency from which a
+	// server is degraded.
+	DefaultDegradedLatency = time.Second
+	// DefaultDegradedErrorRate is the default error rate from which a server
+	// is degraded.
+	DefaultDegradedErrorRate = 0.2
+	// DefaultUnreachableAfter is the default amount of consecutive failed
+	// checks after which a server is unreachable.
+	DefaultUnreachableAfter = 3
+	// DefaultRecoverAfter is the default amount of consecutive checks
+	// required to move to a better state.
+	DefaultRecoverAfter = 3
+)
+
+// HealthState is the health of an OctoPrint server.
+type HealthState string
+
+const (
+	// HealthUnknown is the state before the first check.
+	HealthUnknown HealthState = "unknown"
+	// Healthy servers answer timely and without errors.
+	Healthy HealthState = "healthy"
+	// Degraded servers are slow or fail some of the checks.
+	Degraded HealthState = "degraded"
+	// Unreachable servers fail UnreachableAfter consecutive checks.
+	Unreachable HealthState = "unreachable"
+)
+
+// rank orders the states from the best to the worst.
+func (s HealthState) rank() int {
+	switch s {
+	case Healthy:
+		return 1
+	case Degraded:
+		return 2
+	case Unreachable:
+		return 3
+	}
+
+	return 0
+}
+
+// Health is the result of the checks of a HealthChecker.
+type Health struct {
+	// State is the health state, after applying the hysteresis.
+	State HealthState
+	// Latency is the average latency of the successful checks of the
+	// window.
+	Latency time.Duration
+	// ErrorRate is the ratio of failed checks of the window, from 0 to 1.
+	ErrorRate float64
+	// Err is the error of the last check, nil if it succeeded.
+	Err error
+	// Checked is the time of the last check.
+	Checked time.Time
+}
+
+// HealthChecker checks periodically the health of an OctoPrint server,
+// requesting `/api/version`, and classifies it as healthy, degraded or
+// unreachable from the latency and the error rate of the last checks.
+//
+// Moving to a worse state is immediate, while moving to a better one requires
+// RecoverAfter consecutive checks evaluating to it, so the state doesn't flap
+// around the thresholds. The zero value uses the default thresholds.
+type HealthChecker struct {
+	// Interval between checks, zero means DefaultHealthInterval.
+	Interval time.Duration
+	// Timeout of every check, zero means Interval.
+	Timeout time.Duration
+	// Window is the amount of checks used to compute the latency and the
+	// error rate, zero means DefaultHealthWindow.
+	Window int
+	// DegradedLatency is the average latency from which the server is
+	// degraded, zero means DefaultDegradedLatency.
+	DegradedLatency time.Duration
+	// DegradedErrorRate is the error rate from which the server is degraded,
+	// zero means DefaultDegradedErrorRate.
+	DegradedErrorRate float64
+	// UnreachableAfter is the amount of consecutive failed checks after
+	// which the server is unreachable, zero means DefaultUnreachableAfter.
+	UnreachableAfter int
+	// RecoverAfter is the amount of consecutive checks required to move to a
+	// better state, zero means DefaultRecoverAfter.
+	RecoverAfter int
+
+	// OnStateChange is called when the state changes.
+	OnStateChange func(old, new HealthState)
+	// OnCheck is called after every check, after OnStateChange.
+	OnCheck func(h Health)
+
+	mu        sync.Mutex
+	samples   []healthSample
+	failures  int
+	candidate HealthState
+	streak    int
+	health    Health
+}
+
+type healthSample struct {
+	latency time.Duration
+	failed  bool
+}
+
+// Run checks the server every Interval until ctx is done, returning
+// ctx.Err().
+func (h *HealthChecker) Run(ctx context.Context, c *Client) error {
+	interval := h.Interval
+	if interval <= 0 {
+		interval = DefaultHealthInterval
+	}
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		h.Check(ctx, c)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Check checks the server once, updating and returning the health. Checks
+// interrupted by ctx are not recorded.
+func (h *HealthChecker) Check(ctx context.Context, c *Client) Health {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = h.Interval
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultHealthInterval
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, timeout)
+	start := time.Now()
+	_, err := (&VersionRequest{}).Do(cctx, c)
+	latency := time.Since(start)
+	cancel()
+
+	// the check was interrupted, it says nothing about the server.
+	if ctx.Err() != nil {
+		return h.Health()
+	}
+
+	return h.Record(latency, err)
+}
+
+// Record records the result of a check done by other means, e.g. a regular
+// request, updating and returning the health.
+func (h *HealthChecker) Record(latency time.Duration, err error) Health {
+	h.mu.Lock()
+	old := h.health.State
+	if old == "" {
+		old = HealthUnknown
+	}
+
+	h.record(latency, err)
+	health := h.health
+	h.mu.Unlock()
+
+	if health.State != old && h.OnStateChange != nil {
+		h.OnStateChange(old, health.State)
+	}
+
+	if h.OnCheck != nil {
+		h.OnCheck(health)
+	}
+
+	return health
+}
+
+func (h *HealthChecker) record(latency time.Duration, err error) {
+	window := h.Window
+	if window <= 0 {
+		window = DefaultHealthWindow
+	}
+
+	h.samples = append(h.samples, healthSample{latency: latency, failed: err != nil})
+	if len(h.samples) > window {
+		h.samples = h.samples[len(h.samples)-window:]
+	}
+
+	h.failures++
+	if err == nil {
+		h.failures = 0
+	}
+
+	var ok int
+	var total time.Duration
+	for _, s := range h.samples {
+		if !s.failed {
+			ok++
+			total += s.latency
+		}
+	}
+
+	h.health.Latency = 0
+	if ok > 0 {
+		h.health.Latency = total / time.Duration(ok)
+	}
+
+	h.health.ErrorRate = float64(len(h.samples)-ok) / float64(len(h.samples))
+	h.health.Err = err
+	h.health.Checked = time.Now()
+	h.transition(h.evaluate())
+}
+
+// evaluate returns the state of the current window, without hysteresis.
+func (h *HealthChecker) evaluate() HealthState {
+	unreachable := h.UnreachableAfter
+	if unreachable <= 0 {
+		unreachable = DefaultUnreachableAfter
+	}
+
+	latency := h.DegradedLatency
+	if latency <= 0 {
+		latency = DefaultDegradedLatency
+	}
+
+	rate := h.DegradedErrorRate
+	if rate <= 0 {
+		rate = DefaultDegradedErrorRate
+	}
+
+	switch {
+	case h.failures >= unreachable:
+		return Unreachable
+	case h.health.ErrorRate >= rate || h.health.Latency >= latency:
+		return Degraded
+	}
+
+	return Healthy
+}
+
+// transition applies the hysteresis, moving to a better state only after
+// RecoverAfter consecutive checks evaluating to it or better.
+func (h *HealthChecker) transition(s HealthState) {
+	current := h.health.State
+	if current == "" || current == HealthUnknown || s.rank() >= current.rank() {
+		h.health.State = s
+		h.candidate, h.streak = "", 0
+		return
+	}
+
+	needed := h.RecoverAfter
+	if needed <= 0 {
+		needed = DefaultRecoverAfter
+	}
+
+	// the candidate is the worst state evaluated during the streak.
+	if h.candidate == "" || s.rank() > h.candidate.rank() {
+		h.candidate = s
+	}
+
+	h.streak++
+	if h.streak >= needed {
+		h.health.State = h.candidate
+		h.candidate, h.streak = "", 0
+	}
+}
+
+// Health returns the current health, HealthUnknown before the first check.
+func (h *HealthChecker) Health() Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	health := h.health
+	if health.State == "" {
+		health.State = HealthUnknown
+	}
+
+	return health
+}
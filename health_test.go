@@ -0,0 +1,151 @@
+package octoprint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthChecker_Record(t *testing.T) {
+	var changes []string
+	h := &HealthChecker{Window: 5, DegradedLatency: 100 * time.Millisecond, RecoverAfter: 2}
+	h.OnStateChange = func(old, new HealthState) {
+		changes = append(changes, string(old)+">"+string(new))
+	}
+
+	assert.Equal(t, HealthUnknown, h.Health().State)
+
+	fail := errors.New("connection refused")
+	for _, tc := range []struct {
+		latency time.Duration
+		err     error
+		state   HealthState
+	}{
+		{10 * time.Millisecond, nil, Healthy},
+		{10 * time.Millisecond, nil, Healthy},
+		// a slow check raises the average latency over the threshold.
+		{500 * time.Millisecond, nil, Degraded},
+		{10 * time.Millisecond, nil, Degraded},
+		{0, fail, Degraded},
+		{0, fail, Degraded},
+		// the third consecutive failure makes it unreachable.
+		{0, fail, Unreachable},
+		// recovering requires two checks, to the worst state evaluated.
+		{10 * time.Millisecond, nil, Unreachable},
+		{10 * time.Millisecond, nil, Degraded},
+		{10 * time.Millisecond, nil, Degraded},
+		{10 * time.Millisecond, nil, Degraded},
+		{10 * time.Millisecond, nil, Degraded},
+		{10 * time.Millisecond, nil, Healthy},
+	} {
+		health := h.Record(tc.latency, tc.err)
+		assert.Equal(t, tc.state, health.State)
+		assert.Equal(t, tc.err, health.Err)
+	}
+
+	assert.Equal(t, []string{
+		"unknown>healthy", "healthy>degraded", "degraded>unreachable",
+		"unreachable>degraded", "degraded>healthy",
+	}, changes)
+
+	health := h.Health()
+	assert.Equal(t, 10*time.Millisecond, health.Latency)
+	assert.Equal(t, 0., health.ErrorRate)
+}
+
+func TestHealthChecker_RecordFirstFailure(t *testing.T) {
+	h := &HealthChecker{}
+
+	fail := errors.New("connection refused")
+	assert.Equal(t, Degraded, h.Record(0, fail).State)
+	assert.Equal(t, Degraded, h.Record(0, fail).State)
+	assert.Equal(t, Unreachable, h.Record(0, fail).State)
+}
+
+func TestHealthChecker_Check(t *testing.T) {
+	var mu sync.Mutex
+	var fail bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, URIVersion, r.URL.Path)
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte(`{"api": "0.1", "server": "1.5.0"}`))
+	}))
+	defer ts.Close()
+
+	h := &HealthChecker{}
+	health := h.Check(context.Background(), NewClient(ts.URL, ""))
+	assert.Equal(t, Healthy, health.State)
+	assert.NoError(t, health.Err)
+	assert.False(t, health.Checked.IsZero())
+
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+
+	health = h.Check(context.Background(), NewClient(ts.URL, ""))
+	assert.Equal(t, Degraded, health.State)
+	assert.Error(t, health.Err)
+	assert.Equal(t, 0.5, health.ErrorRate)
+
+	// interrupted checks are not recorded.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, health, h.Check(ctx, NewClient(ts.URL, "")))
+}
+
+func TestFleet_Health(t *testing.T) {
+	ts := newFleetTestServer(false)
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var changes []HealthState
+	f := NewFleet()
+	f.Interval = time.Millisecond
+	f.NewHealthChecker = func() *HealthChecker {
+		return &HealthChecker{Interval: time.Millisecond, Timeout: time.Second}
+	}
+
+	f.OnHealthChange = func(name string, old, new HealthState) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, new)
+	}
+
+	assert.NoError(t, f.Add("prusa", NewClient(ts.URL, "")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- f.Run(ctx) }()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if s, _ := f.Status("prusa"); !s.Health.Checked.IsZero() {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-done)
+
+	s, _ := f.Status("prusa")
+	assert.Equal(t, Healthy, s.Health.State)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []HealthState{Healthy}, changes)
+}
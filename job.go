@@ -2,63 +2,120 @@ package octoprint
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 )
 
 const JobTool = "/api/job"
 
-var JobToolErrors = statusMapping{
-	409: "Printer is not operational or the current print job state does not match the preconditions for the command.",
-}
+var (
+	JobToolErrors = statusMapping{
+		409: "Printer is not operational or the current print job state does not match the preconditions for the command.",
+	}
+	StartErrors = statusMapping{
+		409: "Printer is not operational, no file is selected or a print job is already active",
+	}
+	CancelErrors = statusMapping{
+		409: "Printer is not operational or no print job is active",
+	}
+	RestartErrors = statusMapping{
+		409: "Printer is not operational, no print job is active or the print job is not paused",
+	}
+	PauseErrors = statusMapping{
+		409: "Printer is not operational or no print job is active",
+	}
+)
 
 // JobRequest retrieve information about the current job (if there is one).
 type JobRequest struct{}
 
 // Do sends an API request and returns the API response.
-func (cmd *JobRequest) Do(c *Client) (*JobResponse, error) {
-	b, err := c.doJSONRequest("GET", JobTool, nil, nil)
+func (cmd *JobRequest) Do(ctx context.Context, c *Client) (*JobResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", JobTool, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &JobResponse{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(JobTool, b, r); err != nil {
 		return nil, err
 	}
 
 	return r, err
 }
 
+// JobCommand is a command sent to the job API.
+type JobCommand string
+
+const (
+	// StartCommand starts the print of the selected file.
+	StartCommand JobCommand = "start"
+	// CancelCommand cancels the current job.
+	CancelCommand JobCommand = "cancel"
+	// RestartCommand restarts the current job from the beginning.
+	RestartCommand JobCommand = "restart"
+	// PauseCommand pauses, resumes or toggles the current job.
+	PauseCommand JobCommand = "pause"
+)
+
+// String returns the name of the command.
+func (c JobCommand) String() string {
+	return string(c)
+}
+
+// IsValid returns true if the command is one of the job commands.
+func (c JobCommand) IsValid() bool {
+	switch c {
+	case StartCommand, CancelCommand, RestartCommand, PauseCommand:
+		return true
+	}
+
+	return false
+}
+
+// ParseJobCommand returns the job command with the given name.
+func ParseJobCommand(s string) (JobCommand, error) {
+	c := JobCommand(s)
+	if !c.IsValid() {
+		return "", fmt.Errorf("invalid job command %q, must be start, cancel, restart or pause", s)
+	}
+
+	return c, nil
+}
+
 // StartRequest starts the print of the currently selected file.
 type StartRequest struct{}
 
 // Do sends an API request and returns an error if any.
-func (cmd *StartRequest) Do(c *Client) error {
-	payload := map[string]string{"command": "start"}
+func (cmd *StartRequest) Do(ctx context.Context, c *Client) error {
+	payload := map[string]JobCommand{"command": StartCommand}
 
 	b := bytes.NewBuffer(nil)
 	if err := json.NewEncoder(b).Encode(payload); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", JobTool, b, JobToolErrors)
+	_, err := c.doJSONRequest(ctx, "POST", JobTool, b, StartErrors)
 	return err
 }
 
-// CancelRequest cancels the current print job.
+// CancelRequest cancels the current print job. It's never delayed by the
+// client rate limit.
 type CancelRequest struct{}
 
 // Do sends an API request and returns an error if any.
-func (cmd *CancelRequest) Do(c *Client) error {
-	payload := map[string]string{"command": "cancel"}
+func (cmd *CancelRequest) Do(ctx context.Context, c *Client) error {
+	payload := map[string]JobCommand{"command": CancelCommand}
 
 	b := bytes.NewBuffer(nil)
 	if err := json.NewEncoder(b).Encode(payload); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", JobTool, b, JobToolErrors)
+	ctx = WithoutRateLimit(ctx)
+	_, err := c.doJSONRequest(ctx, "POST", JobTool, b, CancelErrors)
 	return err
 }
 
@@ -68,15 +125,15 @@ func (cmd *CancelRequest) Do(c *Client) error {
 type RestartRequest struct{}
 
 // Do sends an API request and returns an error if any.
-func (cmd *RestartRequest) Do(c *Client) error {
-	payload := map[string]string{"command": "restart"}
+func (cmd *RestartRequest) Do(ctx context.Context, c *Client) error {
+	payload := map[string]JobCommand{"command": RestartCommand}
 
 	b := bytes.NewBuffer(nil)
 	if err := json.NewEncoder(b).Encode(payload); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", JobTool, b, JobToolErrors)
+	_, err := c.doJSONRequest(ctx, "POST", JobTool, b, RestartErrors)
 	return err
 }
 
@@ -92,32 +149,66 @@ const (
 	Toggle PauseAction = "toggle"
 )
 
+// String returns the name of the action.
+func (a PauseAction) String() string {
+	return string(a)
+}
+
+// IsValid returns true if the action is pause, resume or toggle.
+func (a PauseAction) IsValid() bool {
+	return a == Pause || a == Resume || a == Toggle
+}
+
+// ParsePauseAction returns the pause action with the given name.
+func ParsePauseAction(s string) (PauseAction, error) {
+	a := PauseAction(s)
+	if !a.IsValid() {
+		return "", fmt.Errorf("invalid pause action %q, must be pause, resume or toggle", s)
+	}
+
+	return a, nil
+}
+
 // PauseRequest pauses/resumes/toggles the current print job.
 type PauseRequest struct {
 	// Action specifies which action to take.
 	// In order to stay backwards compatible to earlier iterations of this API,
 	// the default action to take if no action parameter is supplied is to
 	// toggle the print job status.
-	Action PauseAction `json:"action"`
+	Action PauseAction `json:"action,omitempty"`
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *PauseRequest) Do(c *Client) error {
+func (cmd *PauseRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", JobTool, b, JobToolErrors)
+	_, err := c.doJSONRequest(ctx, "POST", JobTool, b, PauseErrors)
+	return err
+}
+
+// Validate checks the action of the request, empty meaning Toggle.
+func (cmd *PauseRequest) Validate() error {
+	if cmd.Action == "" {
+		return nil
+	}
+
+	_, err := ParsePauseAction(string(cmd.Action))
 	return err
 }
 
 func (cmd *PauseRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
-		Command string `json:"command"`
+		Command JobCommand `json:"command"`
 		PauseRequest
 	}{
-		Command:      "pause",
+		Command:      PauseCommand,
 		PauseRequest: *cmd,
 	})
 }
@@ -0,0 +1,156 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobCommands_DoConflict(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(409)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+
+	err := (&StartRequest{}).Do(context.Background(), cli)
+	assert.JSONEq(t, `{"command": "start"}`, body)
+	assert.True(t, errors.Is(err, ErrConflict))
+	assert.Equal(t, StartErrors[409], err.Error())
+
+	err = (&CancelRequest{}).Do(context.Background(), cli)
+	assert.JSONEq(t, `{"command": "cancel"}`, body)
+	assert.Equal(t, CancelErrors[409], err.Error())
+
+	err = (&RestartRequest{}).Do(context.Background(), cli)
+	assert.JSONEq(t, `{"command": "restart"}`, body)
+	assert.Equal(t, RestartErrors[409], err.Error())
+
+	err = (&PauseRequest{Action: Toggle}).Do(context.Background(), cli)
+	assert.JSONEq(t, `{"command": "pause", "action": "toggle"}`, body)
+	assert.True(t, errors.Is(err, ErrConflict))
+
+	err = (&PauseRequest{Action: Resume}).Do(context.Background(), cli)
+	assert.JSONEq(t, `{"command": "pause", "action": "resume"}`, body)
+	assert.Equal(t, PauseErrors[409], err.Error())
+}
+
+func TestJobRequest_DoTimes(t *testing.T) {
+	var printing bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if printing {
+			w.Write([]byte(`{
+				"job": {"file": {"name": "cube.gcode"}, "estimatedPrintTime": 1188.4, "lastPrintTime": null},
+				"progress": {"completion": 12.5, "printTime": 90.5, "printTimeLeft": 1097.9, "printTimeLeftOrigin": "mixed-analysis"},
+				"state": "Printing"
+			}`))
+			return
+		}
+
+		w.Write([]byte(`{
+			"job": {"file": {"name": null}, "estimatedPrintTime": null, "lastPrintTime": null},
+			"progress": {"completion": null, "printTime": null, "printTimeLeft": null},
+			"state": "Operational"
+		}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&JobRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Nil(t, r.Job.EstimatedPrintTime)
+	assert.Nil(t, r.Progress.PrintTime)
+	assert.Nil(t, r.Progress.PrintTimeLeft)
+	assert.Empty(t, r.Progress.PrintTimeLeftOrigin)
+	assert.Equal(t, time.Duration(0), r.Progress.PrintDuration())
+
+	printing = true
+	r, err = (&JobRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, 1188.4, *r.Job.EstimatedPrintTime)
+	assert.Equal(t, 1188400*time.Millisecond, r.Job.EstimatedPrintDuration())
+	assert.Nil(t, r.Job.LastPrintTime)
+	assert.Equal(t, time.Duration(0), r.Job.LastPrintDuration())
+	assert.Equal(t, 90500*time.Millisecond, r.Progress.PrintDuration())
+	assert.Equal(t, 1097900*time.Millisecond, r.Progress.PrintTimeLeftDuration())
+	assert.Equal(t, PrintTimeLeftMixedAnalysis, r.Progress.PrintTimeLeftOrigin)
+}
+
+func TestJobRequest_DoMultipleTools(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"job": {
+				"file": {"name": "dual.gcode", "origin": "local"},
+				"estimatedPrintTime": 4210.5,
+				"averagePrintTime": 4388.2,
+				"lastPrintTime": 4401.9,
+				"filament": {
+					"tool0": {"length": 3120.4, "volume": 7.5},
+					"tool1": {"length": 845.1, "volume": 2.03}
+				},
+				"user": "maria",
+				"prusaslicerthumbnails": {"thumbnail": "plugin/prusaslicerthumbnails/thumbnail/dual.png"}
+			},
+			"plugins": {"printtimegenius": {"version": "2.3.1"}},
+			"progress": {"completion": 0.0, "printTime": 0, "printTimeLeft": 4210},
+			"state": "Printing"
+		}`))
+	}))
+	defer ts.Close()
+
+	r, err := (&JobRequest{}).Do(context.Background(), NewClient(ts.URL, ""))
+	assert.NoError(t, err)
+	assert.Equal(t, "maria", r.Job.User)
+	assert.Equal(t, 4388200*time.Millisecond, r.Job.AveragePrintDuration())
+	assert.Len(t, r.Job.Filament, 2)
+	assert.Equal(t, 3120.4, r.Job.Filament["tool0"].Length)
+	assert.Equal(t, 845.1, r.Job.Filament["tool1"].Length)
+	assert.Equal(t, 2.03, r.Job.Filament["tool1"].Volume)
+
+	// fields added by plugins are kept.
+	assert.Len(t, r.Extra, 1)
+	assert.JSONEq(t, `{"printtimegenius": {"version": "2.3.1"}}`, string(r.Extra["plugins"]))
+	assert.Len(t, r.Job.Extra, 1)
+	assert.Contains(t, string(r.Job.Extra["prusaslicerthumbnails"]), "dual.png")
+	assert.Nil(t, r.Job.File.Extra)
+	assert.Contains(t, string(r.Raw), `"printtimegenius"`)
+}
+
+func TestParseJobCommand(t *testing.T) {
+	c, err := ParseJobCommand("restart")
+	assert.NoError(t, err)
+	assert.Equal(t, RestartCommand, c)
+	assert.Equal(t, "restart", c.String())
+
+	_, err = ParseJobCommand("resume")
+	assert.Error(t, err)
+}
+
+func TestParsePauseAction(t *testing.T) {
+	a, err := ParsePauseAction("resume")
+	assert.NoError(t, err)
+	assert.Equal(t, Resume, a)
+	assert.Equal(t, "resume", a.String())
+
+	_, err = ParsePauseAction("")
+	assert.EqualError(t, err, `invalid pause action "", must be pause, resume or toggle`)
+
+	// an empty action in a request is left out, meaning toggle.
+	cmd := &PauseRequest{}
+	assert.NoError(t, cmd.Validate())
+
+	b := bytes.NewBuffer(nil)
+	assert.NoError(t, cmd.encode(b))
+	assert.JSONEq(t, `{"command": "pause"}`, b.String())
+}
@@ -0,0 +1,130 @@
+package octoprint
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// DefaultRestartGrace is the default time the server can be unreachable, or
+// disconnected from the printer, before a monitored print is considered
+// failed, enough for a restart of OctoPrint.
+const DefaultRestartGrace = 2 * time.Minute
+
+// ErrPrintFailed is returned by JobMonitor.Run when the print fails or is
+// cancelled.
+var ErrPrintFailed = errors.New("Print job failed or cancelled")
+
+// JobMonitor tracks a print job from start to completion, polling the job
+// state and calling the lifecycle callbacks. A print already running when the
+// monitor starts is reported as started.
+//
+// Failed polls and disconnections, e.g. while OctoPrint restarts, are
+// tolerated during RestartGrace: prints from the SD card keep running while
+// the server is down and are tracked again once it is back.
+type JobMonitor struct {
+	// Interval is the interval between polls, zero means
+	// DefaultWatchInterval.
+	Interval time.Duration
+	// RestartGrace is the time the server can be unreachable, or
+	// disconnected from the printer, before the print is considered failed,
+	// zero means DefaultRestartGrace.
+	RestartGrace time.Duration
+
+	// OnStarted is called when the print starts.
+	OnStarted func(job *JobResponse)
+	// OnPaused is called when the print is paused.
+	OnPaused func(job *JobResponse)
+	// OnResumed is called when the print is resumed.
+	OnResumed func(job *JobResponse)
+	// OnDone is called with the final state when the print completes.
+	OnDone func(job *JobResponse)
+	// OnFailed is called with the final state when the print fails or is
+	// cancelled.
+	OnFailed func(job *JobResponse)
+	// OnError is called when a poll fails, the monitor keeps polling.
+	OnError func(err error)
+}
+
+// Run blocks until the print job is done or failed, returning its final state.
+// ErrPrintFailed is returned if it failed or was cancelled, and ctx.Err() if
+// ctx is done before.
+func (m *JobMonitor) Run(ctx context.Context, c *Client) (*JobResponse, error) {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	grace := m.RestartGrace
+	if grace <= 0 {
+		grace = DefaultRestartGrace
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *JobResponse
+	var paused bool
+	var lostSince time.Time
+	for {
+		r, err := (&JobRequest{}).Do(ctx, c)
+		if ctx.Err() != nil {
+			return last, ctx.Err()
+		}
+
+		switch {
+		case err != nil || r.IsOffline():
+			if err != nil && m.OnError != nil {
+				m.OnError(err)
+			}
+
+			if last == nil {
+				break
+			}
+
+			if lostSince.IsZero() {
+				lostSince = time.Now()
+			} else if time.Since(lostSince) > grace {
+				callJob(m.OnFailed, last)
+				return last, ErrPrintFailed
+			}
+		case r.IsActive():
+			lostSince = time.Time{}
+			isPaused := r.IsPaused()
+			switch {
+			case last == nil:
+				callJob(m.OnStarted, r)
+				if isPaused {
+					callJob(m.OnPaused, r)
+				}
+			case isPaused && !paused:
+				callJob(m.OnPaused, r)
+			case !isPaused && paused:
+				callJob(m.OnResumed, r)
+			}
+
+			last, paused = r, isPaused
+		case last != nil:
+			if r.Progress.Completion >= 100 && !strings.HasPrefix(r.State, "Error") {
+				callJob(m.OnDone, r)
+				return r, nil
+			}
+
+			callJob(m.OnFailed, r)
+			return r, ErrPrintFailed
+		}
+
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func callJob(fn func(*JobResponse), r *JobResponse) {
+	if fn != nil {
+		fn(r)
+	}
+}
@@ -0,0 +1,99 @@
+package octoprint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newJobServer(responses ...string) *httptest.Server {
+	var polls int
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := responses[len(responses)-1]
+		if polls < len(responses) {
+			resp = responses[polls]
+		}
+
+		polls++
+		if resp == "" {
+			w.WriteHeader(502)
+			return
+		}
+
+		w.Write([]byte(resp))
+	}))
+}
+
+func TestJobMonitor_Run(t *testing.T) {
+	ts := newJobServer(
+		`{"state": "Operational", "progress": {"completion": null}}`,
+		`{"state": "Starting", "progress": {"completion": 0}}`,
+		`{"state": "Printing", "progress": {"completion": 10}}`,
+		`{"state": "Paused", "progress": {"completion": 20}}`,
+		``,
+		`{"state": "Offline", "progress": {"completion": null}}`,
+		`{"state": "Printing from SD", "progress": {"completion": 50}}`,
+		`{"state": "Finishing", "progress": {"completion": 99}}`,
+		`{"state": "Operational", "progress": {"completion": 100}}`,
+	)
+	defer ts.Close()
+
+	var calls []string
+	record := func(name string) func(*JobResponse) {
+		return func(*JobResponse) { calls = append(calls, name) }
+	}
+
+	var errs int
+	m := &JobMonitor{
+		Interval:  time.Millisecond,
+		OnStarted: record("started"),
+		OnPaused:  record("paused"),
+		OnResumed: record("resumed"),
+		OnDone:    record("done"),
+		OnFailed:  record("failed"),
+		OnError:   func(error) { errs++ },
+	}
+
+	r, err := m.Run(context.Background(), NewClient(ts.URL, ""))
+	assert.NoError(t, err)
+	assert.Equal(t, 100., r.Progress.Completion)
+	assert.Equal(t, []string{"started", "paused", "resumed", "done"}, calls)
+	assert.Equal(t, 1, errs)
+}
+
+func TestJobMonitor_RunCancelled(t *testing.T) {
+	ts := newJobServer(
+		`{"state": "Printing", "progress": {"completion": 10}}`,
+		`{"state": "Cancelling", "progress": {"completion": 12}}`,
+		`{"state": "Operational", "progress": {"completion": 12}}`,
+	)
+	defer ts.Close()
+
+	var failed *JobResponse
+	m := &JobMonitor{
+		Interval: time.Millisecond,
+		OnFailed: func(r *JobResponse) { failed = r },
+	}
+
+	r, err := m.Run(context.Background(), NewClient(ts.URL, ""))
+	assert.Equal(t, ErrPrintFailed, err)
+	assert.Equal(t, r, failed)
+	assert.Equal(t, 12., r.Progress.Completion)
+}
+
+func TestJobMonitor_RunRestartGrace(t *testing.T) {
+	ts := newJobServer(
+		`{"state": "Printing", "progress": {"completion": 10}}`,
+		``,
+	)
+	defer ts.Close()
+
+	m := &JobMonitor{Interval: time.Millisecond, RestartGrace: 10 * time.Millisecond}
+	r, err := m.Run(context.Background(), NewClient(ts.URL, ""))
+	assert.Equal(t, ErrPrintFailed, err)
+	assert.Equal(t, "Printing", r.State)
+}
@@ -0,0 +1,53 @@
+package octoprint
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Logger is the interface used by the client to report what is going on, such
+// as requests being sent, retries or decoding problems. Its method set matches
+// the one of *slog.Logger, so one can be used directly, keyvals being
+// alternating keys and values.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// WithLogger sets the logger used by the client, by default nothing is logged.
+// API keys are never logged.
+func WithLogger(l Logger) Option {
+	return func(c *Client) {
+		c.log = l
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+const redacted = "REDACTED"
+
+// redactURL returns the string representation of u with any credentials, the
+// password of the user info and the `apikey` query parameter, removed.
+func redactURL(u *url.URL) string {
+	r := *u
+	if _, ok := r.User.Password(); ok {
+		r.User = url.UserPassword(r.User.Username(), redacted)
+	}
+
+	q := r.Query()
+	for k := range q {
+		if strings.EqualFold(k, "apikey") {
+			q.Set(k, redacted)
+			r.RawQuery = q.Encode()
+		}
+	}
+
+	return r.String()
+}
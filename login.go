@@ -0,0 +1,119 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+)
+
+const (
+	URILogin  = "/api/login"
+	URILogout = "/api/logout"
+)
+
+var LoginErrors = statusMapping{
+	401: "Username/password mismatch or unknown user",
+	403: "Deactivated account",
+}
+
+// LoginRequest creates a login session. The session cookie returned by the
+// server is persisted by the client and sent with every following request.
+//
+// If Passive is set, the login uses the currently active session or the
+// remember me cookie, if any, instead of the provided credentials.
+type LoginRequest struct {
+	// Username to log in with.
+	Username string `json:"user,omitempty"`
+	// Password of the user.
+	Password string `json:"pass,omitempty"`
+	// Remember whether to set a remember me cookie on the session, allowing
+	// further passive logins.
+	Remember bool `json:"remember,omitempty"`
+	// Passive whether to perform a passive login.
+	Passive bool `json:"passive,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *LoginRequest) Do(ctx context.Context, c *Client) (*LoginResponse, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	if err := c.ensureCookieJar(); err != nil {
+		return nil, err
+	}
+
+	b2, err := c.doJSONRequest(ctx, "POST", URILogin, b, LoginErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &LoginResponse{}
+	if err := c.decode(URILogin, b2, r); err != nil {
+		return nil, err
+	}
+
+	c.setSession(r.Name, r.Session)
+	return r, err
+}
+
+// LogoutRequest ends the current login session.
+type LogoutRequest struct{}
+
+// Do sends an API request and returns an error if any.
+func (cmd *LogoutRequest) Do(ctx context.Context, c *Client) error {
+	_, err := c.doJSONRequest(ctx, "POST", URILogout, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	c.setSession("", "")
+	return nil
+}
+
+// Session returns the user and the session identifier of the current login
+// session, both empty if the client is not logged in.
+func (c *Client) Session() (user, session string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.user, c.session
+}
+
+func (c *Client) setSession(user, session string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.user, c.session = user, session
+}
+
+// ensureCookieJar makes sure the http.Client persists cookies, without
+// modifying an http.Client provided with WithHTTPClient.
+func (c *Client) ensureCookieJar() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.c.Jar != nil {
+		return nil
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+
+	hc := *c.c
+	hc.Jar = jar
+	c.c = &hc
+	return nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.c
+}
@@ -0,0 +1,62 @@
+package octoprint
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginRequest_Do(t *testing.T) {
+	var cookie string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case URILogin:
+			var payload map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&payload)
+			if payload["user"] != "foo" || payload["pass"] != "bar" {
+				w.WriteHeader(401)
+				return
+			}
+
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "qux"})
+			w.Write([]byte(`{"name": "foo", "active": true, "session": "qux", "groups": ["users"]}`))
+		case URILogout:
+			w.WriteHeader(204)
+		default:
+			if c, err := r.Cookie("session"); err == nil {
+				cookie = c.Value
+			}
+
+			w.Write([]byte(`{"api": "0.1", "server": "1.3.9"}`))
+		}
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	_, err := (&LoginRequest{Username: "foo", Password: "baz"}).Do(context.Background(), cli)
+	assert.Error(t, err)
+
+	r, err := (&LoginRequest{Username: "foo", Password: "bar"}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", r.Name)
+	assert.Equal(t, []string{"users"}, r.Groups)
+
+	user, session := cli.Session()
+	assert.Equal(t, "foo", user)
+	assert.Equal(t, "qux", session)
+
+	_, err = (&VersionRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "qux", cookie)
+
+	err = (&LogoutRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+
+	user, session = cli.Session()
+	assert.Equal(t, "", user)
+	assert.Equal(t, "", session)
+}
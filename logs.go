@@ -0,0 +1,116 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+const (
+	URILogs          = "/plugin/logging/logs"
+	URILoggingSetup  = "/plugin/logging/setup"
+	URILoggingLevels = "/plugin/logging/setup/levels"
+	URIDownloadLogs  = "/downloads/logs"
+)
+
+var LogsErrors = statusMapping{
+	403: "The user is not allowed to manage the logs",
+	404: "The log file was not found",
+}
+
+// LogsRequest retrieves the list of log files available on the server, using
+// the bundled logging plugin.
+type LogsRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *LogsRequest) Do(ctx context.Context, c *Client) (*LogsResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URILogs, nil, LogsErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &LogsResponse{}
+	if err := c.decode(URILogs, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// LogDownloadRequest downloads a log file, streaming its content to the given
+// writer.
+type LogDownloadRequest struct {
+	// Filename of the log file to download, e.g. `octoprint.log`.
+	Filename string
+	// Progress is called while downloading the file. Optional.
+	Progress ProgressFunc
+}
+
+// Do sends an API request writing the content of the log file to w, and
+// returns the amount of bytes written, even on error.
+func (cmd *LogDownloadRequest) Do(ctx context.Context, c *Client, w io.Writer) (int64, error) {
+	uri := fmt.Sprintf("%s/%s", URIDownloadLogs, url.PathEscape(cmd.Filename))
+	return c.download(ctx, uri, LogsErrors, w, cmd.Progress)
+}
+
+// LogDeleteRequest deletes a log file.
+type LogDeleteRequest struct {
+	// Filename of the log file to delete.
+	Filename string
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *LogDeleteRequest) Do(ctx context.Context, c *Client) error {
+	uri := fmt.Sprintf("%s/%s", URILogs, url.PathEscape(cmd.Filename))
+	_, err := c.doJSONRequest(ctx, "DELETE", uri, nil, LogsErrors)
+	return err
+}
+
+// LoggingSetupRequest retrieves the available loggers and the levels configured
+// for them.
+type LoggingSetupRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *LoggingSetupRequest) Do(ctx context.Context, c *Client) (*LoggingSetup, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URILoggingSetup, nil, LogsErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &LoggingSetup{}
+	if err := c.decode(URILoggingSetup, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// LoggingLevelsRequest sets the level of the given loggers, loggers not
+// included keep their current level.
+type LoggingLevelsRequest struct {
+	// Levels to set, by logger name, e.g. `{"octoprint.server": "DEBUG"}`.
+	// An empty level resets the logger to its default level.
+	Levels map[string]string
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *LoggingLevelsRequest) Do(ctx context.Context, c *Client) error {
+	levels := make(map[string]interface{}, len(cmd.Levels))
+	for logger, level := range cmd.Levels {
+		levels[logger] = level
+		if level == "" {
+			levels[logger] = nil
+		}
+	}
+
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(levels); err != nil {
+		return err
+	}
+
+	_, err := c.doJSONRequest(ctx, "PUT", URILoggingLevels, b, LogsErrors)
+	return err
+}
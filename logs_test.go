@@ -0,0 +1,90 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogsRequest_Do(t *testing.T) {
+	var method, path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.EscapedPath()
+		switch {
+		case r.URL.Path == URILogs:
+			w.Write([]byte(`{
+				"files": [{
+					"name": "octoprint.log",
+					"date": 1393158814,
+					"size": 43712,
+					"refs": {
+						"resource": "http://example.com/plugin/logging/logs/octoprint.log",
+						"download": "http://example.com/downloads/logs/octoprint.log"
+					}
+				}],
+				"free": 12345,
+				"total": 54321
+			}`))
+		case r.URL.Path == URIDownloadLogs+"/octoprint.log":
+			w.Write([]byte("2019-01-01 00:00:00 - octoprint.startup - INFO"))
+		default:
+			w.WriteHeader(204)
+		}
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&LogsRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Len(t, r.Files, 1)
+	assert.Equal(t, uint64(43712), r.Files[0].Size)
+	assert.Equal(t, int64(1393158814), r.Files[0].Date.Unix())
+	assert.Equal(t, uint64(12345), r.Free)
+
+	buf := bytes.NewBuffer(nil)
+	n, err := (&LogDownloadRequest{Filename: "octoprint.log"}).Do(context.Background(), cli, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.Equal(t, "2019-01-01 00:00:00 - octoprint.startup - INFO", buf.String())
+
+	err = (&LogDeleteRequest{Filename: "serial log.log"}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE", method)
+	assert.Equal(t, URILogs+"/serial%20log.log", path)
+}
+
+func TestLoggingLevelsRequest_Do(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write([]byte(`{
+				"loggers": ["octoprint", "octoprint.server"],
+				"levels": {"octoprint.server": "DEBUG"}
+			}`))
+			return
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	s, err := (&LoggingSetupRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Len(t, s.Loggers, 2)
+	assert.Equal(t, "DEBUG", s.Levels["octoprint.server"])
+
+	err = (&LoggingLevelsRequest{Levels: map[string]string{
+		"octoprint.server":  "",
+		"octoprint.plugins": "INFO",
+	}}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"octoprint.server": null, "octoprint.plugins": "INFO"}`, body)
+}
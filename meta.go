@@ -0,0 +1,58 @@
+package octoprint
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ResponseMeta is the metadata of the final HTTP response received for a
+// request, filled by the client when the request context carries it, see
+// WithResponseMeta.
+type ResponseMeta struct {
+	// Method is the HTTP method of the request.
+	Method string
+	// URL is the URL of the request, with the credentials redacted.
+	URL string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Header is the header of the response.
+	Header http.Header
+	// Latency is the time elapsed from sending the request to reading the
+	// response, of the final attempt.
+	Latency time.Duration
+	// Attempts is the amount of attempts made, including the ones failing
+	// without response, greater than one if the request was retried.
+	Attempts int
+}
+
+type responseMetaKey struct{}
+
+// WithResponseMeta returns a copy of ctx that makes the client fill meta with
+// the final response of the requests sent with it, even if they fail with an
+// APIError, allowing the callers to inspect the headers or the latency:
+//
+//	meta := &octoprint.ResponseMeta{}
+//	r, err := (&octoprint.JobRequest{}).Do(octoprint.WithResponseMeta(ctx, meta), c)
+//	etag := meta.Header.Get("ETag")
+//
+// The meta is overwritten by every request sent with the returned context, so
+// it must not be shared by concurrent requests.
+func WithResponseMeta(ctx context.Context, meta *ResponseMeta) context.Context {
+	return context.WithValue(ctx, responseMetaKey{}, meta)
+}
+
+// ResponseMetaFromContext returns the ResponseMeta carried by ctx, if any.
+func ResponseMetaFromContext(ctx context.Context) (*ResponseMeta, bool) {
+	meta, ok := ctx.Value(responseMetaKey{}).(*ResponseMeta)
+	return meta, ok && meta != nil
+}
+
+// record records the response of an attempt of req.
+func (m *ResponseMeta) record(req *http.Request, resp *http.Response, latency time.Duration) {
+	m.Method = req.Method
+	m.URL = redactURL(req.URL)
+	m.StatusCode = resp.StatusCode
+	m.Header = resp.Header
+	m.Latency = latency
+}
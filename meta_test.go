@@ -0,0 +1,56 @@
+package octoprint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithResponseMeta(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(503)
+			return
+		}
+
+		w.Header().Set("ETag", `"42"`)
+		if calls == 3 {
+			w.WriteHeader(409)
+			return
+		}
+
+		w.Write([]byte(`{"api": "0.1", "server": "1.3.9"}`))
+	}))
+	defer ts.Close()
+
+	meta := &ResponseMeta{}
+	ctx := WithResponseMeta(context.Background(), meta)
+	cli := NewClient(ts.URL, "", WithRetryPolicy(testRetryPolicy))
+
+	_, err := (&VersionRequest{}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", meta.Method)
+	assert.Equal(t, ts.URL+URIVersion, meta.URL)
+	assert.Equal(t, 200, meta.StatusCode)
+	assert.Equal(t, `"42"`, meta.Header.Get("ETag"))
+	assert.Equal(t, 2, meta.Attempts)
+	assert.True(t, meta.Latency > 0)
+
+	// the meta is overwritten, also by the failed requests.
+	_, err = (&VersionRequest{}).Do(ctx, cli)
+	assert.Error(t, err)
+	assert.Equal(t, 409, meta.StatusCode)
+	assert.Equal(t, 1, meta.Attempts)
+
+	got, ok := ResponseMetaFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, meta, got)
+
+	_, ok = ResponseMetaFromContext(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,27 @@
+package octoprint
+
+import "net/http"
+
+// RoundTripFunc sends an HTTP request to OctoPrint and returns its response.
+type RoundTripFunc func(*http.Request) (*http.Response, error)
+
+// Middleware wraps a RoundTripFunc, allowing to inspect or modify requests and
+// responses around every API call, e.g. for logging, metrics or header
+// rewriting.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Use appends the given middlewares to the client. Middlewares are executed in
+// the order they were added, the first one being the outermost, and wrap every
+// single attempt of a request.
+func (c *Client) Use(mw ...Middleware) {
+	c.middlewares = append(c.middlewares, mw...)
+}
+
+func (c *Client) roundTrip() RoundTripFunc {
+	next := RoundTripFunc(c.httpClient().Do)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		next = c.middlewares[i](next)
+	}
+
+	return next
+}
@@ -0,0 +1,45 @@
+package octoprint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Use(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen", r.Header.Get("X-Forwarded-User"))
+		w.Write([]byte(`{"api": "0.1", "server": "1.3.9"}`))
+	}))
+	defer ts.Close()
+
+	var order []string
+	var seen string
+
+	cli := NewClient(ts.URL, "")
+	cli.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			order = append(order, "outer")
+			r.Header.Set("X-Forwarded-User", "foo")
+			return next(r)
+		}
+	}, func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			order = append(order, "inner")
+			resp, err := next(r)
+			if err == nil {
+				seen = resp.Header.Get("X-Seen")
+			}
+
+			return resp, err
+		}
+	})
+
+	_, err := (&VersionRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"outer", "inner"}, order)
+	assert.Equal(t, "foo", seen)
+}
@@ -0,0 +1,352 @@
+// Package mirror mirrors a local directory tree into the local storage of
+// OctoPrint, one way: the missing folders are created, the new and changed
+// files uploaded and, optionally, the files not present locally deleted.
+//
+//	m := mirror.New(c, "/home/me/gcodes")
+//	m.Delete = true
+//	changes, err := m.Sync(ctx)
+//
+// The files are compared by size and hash. The names of the files must be
+// valid OctoPrint names, since OctoPrint may rename the uploaded files
+// otherwise, e.g. replacing the spaces, making them always look missing.
+package mirror
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+// Action is the kind of a change applied to OctoPrint.
+type Action string
+
+const (
+	// Mkdir creates a folder.
+	Mkdir Action = "mkdir"
+	// Upload uploads a new or changed file.
+	Upload Action = "upload"
+	// Delete deletes a file or folder, including its content.
+	Delete Action = "delete"
+)
+
+// Change is a change applied to OctoPrint.
+type Change struct {
+	// Action of the change.
+	Action Action
+	// Path of the file or folder in OctoPrint, relative to the location.
+	Path string
+	// Local is the path of the local file, only for uploads.
+	Local string
+	// Size of the file to upload.
+	Size int64
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("%s %s", c.Action, c.Path)
+}
+
+// Mirror mirrors a local directory into OctoPrint.
+type Mirror struct {
+	// Client is the client of the OctoPrint server.
+	Client *octoprint.Client
+	// Dir is the local directory mirrored.
+	Dir string
+	// Path is the folder in OctoPrint mirroring Dir, the root of the
+	// location if empty. Created if it doesn't exist.
+	Path string
+	// Delete whether to delete the files and folders not present in Dir.
+	Delete bool
+	// DryRun whether to only return the changes, without applying them.
+	DryRun bool
+	// Filter, if set, is called with the relative slash separated path of
+	// every local file and folder, skipping the ones returning false. Hidden
+	// files and folders are always skipped.
+	Filter func(path string, fi os.FileInfo) bool
+	// OnChange is called before applying every change.
+	OnChange func(c *Change)
+	// Progress is called while uploading the files.
+	Progress func(c *Change, transferred, total int64)
+}
+
+// New returns a Mirror of dir into the root of the local storage.
+func New(c *octoprint.Client, dir string) *Mirror {
+	return &Mirror{Client: c, Dir: dir}
+}
+
+// Sync applies the changes required to mirror Dir, returning the changes
+// applied, or only planned if DryRun is set. It stops at the first error,
+// returning the changes applied so far.
+func (m *Mirror) Sync(ctx context.Context) ([]*Change, error) {
+	changes, err := m.Plan(ctx)
+	if err != nil || m.DryRun {
+		return changes, err
+	}
+
+	for i, c := range changes {
+		if m.OnChange != nil {
+			m.OnChange(c)
+		}
+
+		if err := m.apply(ctx, c); err != nil {
+			return changes[:i], fmt.Errorf("error applying %s: %w", c, err)
+		}
+	}
+
+	return changes, nil
+}
+
+// Plan returns the changes required to mirror Dir: the files and folders to
+// delete, the folders to create, parents first, and the files to upload.
+func (m *Mirror) Plan(ctx context.Context) ([]*Change, error) {
+	local, err := m.local()
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := m.remote(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var mkdirs, uploads, deletes []*Change
+	if m.Path != "" && remote == nil {
+		mkdirs = append(mkdirs, &Change{Action: Mkdir, Path: m.remotePath("")})
+	}
+
+	for _, rel := range localPaths(local) {
+		l, r := local[rel], remote[rel]
+		switch {
+		case l.IsDir() && r != nil && r.IsFolder():
+		case l.IsDir():
+			mkdirs = append(mkdirs, &Change{Action: Mkdir, Path: m.remotePath(rel)})
+		case r != nil && !r.IsFolder():
+			changed, err := m.changed(rel, l, r)
+			if err != nil {
+				return nil, err
+			}
+
+			if !changed {
+				continue
+			}
+
+			fallthrough
+		default:
+			uploads = append(uploads, &Change{
+				Action: Upload,
+				Path:   m.remotePath(rel),
+				Local:  filepath.Join(m.Dir, filepath.FromSlash(rel)),
+				Size:   l.Size(),
+			})
+		}
+	}
+
+	if m.Delete {
+		var deleted []string
+		for _, rel := range remotePaths(remote) {
+			if l, ok := local[rel]; ok && l.IsDir() == remote[rel].IsFolder() {
+				continue
+			}
+
+			// the content of the deleted folders is deleted with them.
+			if hasParent(deleted, rel) {
+				continue
+			}
+
+			deleted = append(deleted, rel)
+			deletes = append(deletes, &Change{Action: Delete, Path: m.remotePath(rel)})
+		}
+	}
+
+	// the deletes go first, since a folder replacing a file, or the other way
+	// around, requires deleting the old one.
+	changes := append(deletes, mkdirs...)
+	return append(changes, uploads...), nil
+}
+
+func (m *Mirror) apply(ctx context.Context, c *Change) error {
+	switch c.Action {
+	case Mkdir:
+		// the folders are created parents first, only the parents of Path may
+		// be missing.
+		_, err := (&octoprint.CreateFolderRequest{
+			Location: octoprint.Local, Path: c.Path, Parents: c.Path == m.remotePath(""),
+		}).Do(ctx, m.Client)
+
+		return err
+	case Upload:
+		f, err := os.Open(c.Local)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		dir, name := path.Split(c.Path)
+		r := &octoprint.UploadFileRequest{Location: octoprint.Local, Path: strings.TrimSuffix(dir, "/")}
+		if m.Progress != nil {
+			r.Progress = func(transferred, total int64) { m.Progress(c, transferred, total) }
+		}
+
+		if err := r.AddFile(name, f); err != nil {
+			return err
+		}
+
+		_, err = r.Do(ctx, m.Client)
+		return err
+	case Delete:
+		return (&octoprint.DeleteFileRequest{Location: octoprint.Local, Path: c.Path}).Do(ctx, m.Client)
+	}
+
+	return fmt.Errorf("unknown action %q", c.Action)
+}
+
+// local returns the files and folders of Dir by relative slash separated path.
+func (m *Mirror) local() (map[string]os.FileInfo, error) {
+	files := make(map[string]os.FileInfo)
+	err := filepath.Walk(m.Dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(m.Dir, p)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(fi.Name(), ".") || (m.Filter != nil && !m.Filter(rel, fi)) {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if fi.IsDir() || fi.Mode().IsRegular() {
+			files[rel] = fi
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+// remote returns the files and folders of Path by relative path, nil if Path
+// doesn't exist.
+func (m *Mirror) remote(ctx context.Context) (map[string]*octoprint.FileInformation, error) {
+	var root []*octoprint.FileInformation
+	if m.Path == "" {
+		r, err := (&octoprint.FilesRequest{Location: octoprint.Local, Recursive: true}).Do(ctx, m.Client)
+		if err != nil {
+			return nil, err
+		}
+
+		root = r.Files
+	} else {
+		f, err := (&octoprint.FileRequest{
+			Location: octoprint.Local, Filename: m.remotePath(""), Recursive: true,
+		}).Do(ctx, m.Client)
+
+		if errors.Is(err, octoprint.ErrNotFound) {
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !f.IsFolder() {
+			return nil, fmt.Errorf("%q is not a folder", m.Path)
+		}
+
+		root = f.Children
+	}
+
+	prefix := m.remotePath("")
+	files := make(map[string]*octoprint.FileInformation)
+	for _, f := range root {
+		f.Walk(func(f *octoprint.FileInformation) error {
+			rel := strings.TrimPrefix(strings.TrimPrefix(f.Path, prefix), "/")
+			files[rel] = f
+			return nil
+		})
+	}
+
+	return files, nil
+}
+
+// changed returns whether the local file differs from the remote one.
+func (m *Mirror) changed(rel string, l os.FileInfo, r *octoprint.FileInformation) (bool, error) {
+	if uint64(l.Size()) != r.Size {
+		return true, nil
+	}
+
+	var h hash.Hash
+	switch len(r.Hash) {
+	case hex.EncodedLen(sha1.Size):
+		h = sha1.New()
+	case hex.EncodedLen(md5.Size):
+		h = md5.New()
+	default:
+		return false, nil
+	}
+
+	f, err := os.Open(filepath.Join(m.Dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return false, err
+	}
+
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return false, err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)) != strings.ToLower(r.Hash), nil
+}
+
+func (m *Mirror) remotePath(rel string) string {
+	return strings.Trim(path.Join(strings.Trim(m.Path, "/"), rel), "/")
+}
+
+func hasParent(folders []string, p string) bool {
+	for _, f := range folders {
+		if strings.HasPrefix(p, f+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func localPaths(files map[string]os.FileInfo) []string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+func remotePaths(files map[string]*octoprint.FileInformation) []string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
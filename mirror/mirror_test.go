@@ -0,0 +1,168 @@
+package mirror
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/stretchr/testify/assert"
+)
+
+const filesJSON = `{"files": [
+	{"name": "cube.gcode", "path": "cube.gcode", "type": "machinecode", "typePath": ["machinecode", "gcode"],
+	 "size": 4, "hash": "55f7d1f71091501a0205fba94258266031264150"},
+	{"name": "benchy.gcode", "path": "benchy.gcode", "type": "machinecode", "typePath": ["machinecode", "gcode"],
+	 "size": 6, "hash": "7698c0580e66f7b2d7025c62cf280c33edc5b511"},
+	{"name": "old.gcode", "path": "old.gcode", "type": "machinecode", "typePath": ["machinecode", "gcode"], "size": 3},
+	{"name": "old", "path": "old", "type": "folder", "typePath": ["folder"], "children": [
+		{"name": "a.gcode", "path": "old/a.gcode", "type": "machinecode", "typePath": ["machinecode", "gcode"], "size": 1}
+	]},
+	{"name": "parts", "path": "parts", "type": "folder", "typePath": ["folder"], "children": []}
+]}`
+
+func localDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "mirror")
+	assert.NoError(t, err)
+
+	for name, content := range map[string]string{
+		"cube.gcode":      "cube",
+		"benchy.gcode":    "benchy2",
+		"parts/x.gcode":   "x",
+		"new/y.gcode":     "y",
+		".hidden/z.gcode": "z",
+		".DS_Store":       "",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		assert.NoError(t, ioutil.WriteFile(p, []byte(content), 0644))
+	}
+
+	return dir
+}
+
+func TestMirror_Plan(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/files/local", r.URL.Path)
+		w.Write([]byte(filesJSON))
+	}))
+	defer ts.Close()
+
+	dir := localDir(t)
+	defer os.RemoveAll(dir)
+
+	m := New(octoprint.NewClient(ts.URL, ""), dir)
+	changes, err := m.Plan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"mkdir new",
+		"upload benchy.gcode",
+		"upload new/y.gcode",
+		"upload parts/x.gcode",
+	}, changesToStrings(changes))
+
+	m.Delete = true
+	changes, err = m.Plan(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"delete old",
+		"delete old.gcode",
+		"mkdir new",
+		"upload benchy.gcode",
+		"upload new/y.gcode",
+		"upload parts/x.gcode",
+	}, changesToStrings(changes))
+
+	assert.Equal(t, filepath.Join(dir, "benchy.gcode"), changes[3].Local)
+	assert.Equal(t, int64(7), changes[3].Size)
+}
+
+func TestMirror_Sync(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case "GET":
+			if r.URL.Path == "/api/files/local/prints" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			w.Write([]byte(filesJSON))
+			return
+		case "POST":
+			assert.NoError(t, r.ParseMultipartForm(1<<20))
+			req := "POST path=" + r.FormValue("path")
+			if folder := r.FormValue("foldername"); folder != "" {
+				req += " folder=" + folder
+			}
+
+			if _, h, err := r.FormFile("file"); err == nil {
+				req += " file=" + h.Filename
+			}
+
+			requests = append(requests, req)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"done": true}`))
+		default:
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer ts.Close()
+
+	dir := localDir(t)
+	defer os.RemoveAll(dir)
+	os.RemoveAll(filepath.Join(dir, "cube.gcode"))
+	os.RemoveAll(filepath.Join(dir, "benchy.gcode"))
+
+	var progress int64
+	m := New(octoprint.NewClient(ts.URL, ""), dir)
+	m.Path = "prints"
+	m.Progress = func(c *Change, transferred, total int64) {
+		progress = transferred
+	}
+
+	m.DryRun = true
+	changes, err := m.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, changes, 5)
+	assert.Len(t, requests, 0)
+
+	m.DryRun = false
+	changes, err = m.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"mkdir prints",
+		"mkdir prints/new",
+		"mkdir prints/parts",
+		"upload prints/new/y.gcode",
+		"upload prints/parts/x.gcode",
+	}, changesToStrings(changes))
+
+	assert.Equal(t, []string{
+		"POST path= folder=prints",
+		"POST path=prints folder=new",
+		"POST path=prints folder=parts",
+		"POST path=prints/new file=y.gcode",
+		"POST path=prints/parts file=x.gcode",
+	}, requests)
+	assert.Equal(t, int64(1), progress)
+}
+
+func changesToStrings(changes []*Change) []string {
+	var r []string
+	for _, c := range changes {
+		r = append(r, c.String())
+	}
+
+	return r
+}
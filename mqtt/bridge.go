@@ -0,0 +1,239 @@
+// Package mqtt bridges a printer to an MQTT broker, publishing its state,
+// temperatures, job progress and events, and executing the commands received,
+// for home automation integrations.
+//
+//	broker, err := mqtt.Dial(ctx, "localhost:1883", mqtt.WithClientID("octoprint"))
+//	b := &mqtt.Bridge{Broker: broker, Prefix: "octoprint/prusa"}
+//	err = b.Run(ctx, c)
+//
+// With the default topics the state is published to `<prefix>/state`, the
+// temperatures to `<prefix>/temperature`, the progress to `<prefix>/progress`
+// and the events to `<prefix>/event/<type>`, all of them as JSON. The commands
+// are received at `<prefix>/command/<command>`: `pause`, `resume` and `cancel`
+// with an empty payload, `temperature` with the targets by tool as JSON, e.g.
+// `{"tool0": 210, "bed": 60}`, and `gcode` with one command per line.
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/mcuadros/go-octoprint/push"
+)
+
+// DefaultPrefix is the default prefix of the topics.
+const DefaultPrefix = "octoprint"
+
+// Broker is an MQTT broker connection, implemented by Client. Other clients,
+// e.g. supporting TLS or higher QoS, can be used with a small adapter.
+type Broker interface {
+	// Publish publishes the payload to the topic.
+	Publish(topic string, payload []byte, retain bool) error
+	// Subscribe calls fn with the messages of the topics matching filter.
+	Subscribe(filter string, fn func(topic string, payload []byte)) error
+}
+
+// Events is a source of events, implemented by *push.Client.
+type Events interface {
+	Subscribe(ctx context.Context, events ...push.EventType) (<-chan push.Event, error)
+}
+
+// Topics are the topics of a bridge, relative to its prefix.
+type Topics struct {
+	// State is the topic of the state of the printer.
+	State string
+	// Temperature is the topic of the temperatures of the printer.
+	Temperature string
+	// Progress is the topic of the progress of the current job.
+	Progress string
+	// Event is the parent topic of the events, published by type.
+	Event string
+	// Command is the parent topic of the commands.
+	Command string
+}
+
+// DefaultTopics are the default topics of a bridge.
+var DefaultTopics = Topics{
+	State:       "state",
+	Temperature: "temperature",
+	Progress:    "progress",
+	Event:       "event",
+	Command:     "command",
+}
+
+// Bridge publishes the state of a printer, polled with an octoprint.Watcher,
+// and the events of the push API to an MQTT broker, executing the commands
+// received.
+type Bridge struct {
+	// Broker is the connection to the broker.
+	Broker Broker
+	// Prefix of the topics, DefaultPrefix if empty.
+	Prefix string
+	// Topics are the topics used, DefaultTopics if zero.
+	Topics Topics
+	// Retain whether the state, temperature and progress are published as
+	// retained messages.
+	Retain bool
+	// Interval is the interval between polls of the printer, zero means
+	// octoprint.DefaultWatchInterval.
+	Interval time.Duration
+	// Events is the source of the events published, no events are published
+	// if nil.
+	Events Events
+	// OnError is called when a poll, publication or command fails.
+	OnError func(err error)
+}
+
+// Run runs the bridge until ctx is done, returning ctx.Err(), or the error of
+// the subscription to the commands or events.
+func (b *Bridge) Run(ctx context.Context, c *octoprint.Client) error {
+	topics := b.topics()
+	err := b.Broker.Subscribe(b.topic(topics.Command, "#"), func(topic string, payload []byte) {
+		command := strings.TrimPrefix(topic, b.topic(topics.Command)+"/")
+		if err := b.command(ctx, c, command, payload); err != nil {
+			b.error(fmt.Errorf("error executing command %q: %w", command, err))
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if b.Events != nil {
+		events, err := b.Events.Subscribe(ctx)
+		if err != nil {
+			return err
+		}
+
+		go b.publishEvents(events)
+	}
+
+	w := &octoprint.Watcher{
+		PrinterInterval: b.Interval,
+		JobInterval:     b.Interval,
+		OnStateChange: func(old, new octoprint.PrinterState) {
+			b.publish(b.topic(topics.State), new)
+		},
+		OnTemperature: func(temps map[string]octoprint.TemperatureData) {
+			b.publish(b.topic(topics.Temperature), temps)
+		},
+		OnProgress: func(job *octoprint.JobResponse) {
+			b.publish(b.topic(topics.Progress), job)
+		},
+		OnError: b.error,
+	}
+
+	return w.Run(ctx, c)
+}
+
+func (b *Bridge) publishEvents(events <-chan push.Event) {
+	topics := b.topics()
+	for e := range events {
+		payload := e.Payload
+		if len(payload) == 0 {
+			payload = []byte("null")
+		}
+
+		if err := b.Broker.Publish(b.topic(topics.Event, string(e.Type)), payload, false); err != nil {
+			b.error(err)
+		}
+	}
+}
+
+func (b *Bridge) publish(topic string, v interface{}) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		b.error(err)
+		return
+	}
+
+	if err := b.Broker.Publish(topic, payload, b.Retain); err != nil {
+		b.error(err)
+	}
+}
+
+func (b *Bridge) command(ctx context.Context, c *octoprint.Client, command string, payload []byte) error {
+	switch command {
+	case "pause":
+		return (&octoprint.PauseRequest{Action: octoprint.Pause}).Do(ctx, c)
+	case "resume":
+		return (&octoprint.PauseRequest{Action: octoprint.Resume}).Do(ctx, c)
+	case "cancel":
+		return (&octoprint.CancelRequest{}).Do(ctx, c)
+	case "temperature":
+		return setTemperatures(ctx, c, payload)
+	case "gcode":
+		var commands []string
+		for _, line := range strings.Split(string(payload), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				commands = append(commands, line)
+			}
+		}
+
+		if len(commands) == 0 {
+			return fmt.Errorf("invalid payload, no commands")
+		}
+
+		return (&octoprint.CommandRequest{Commands: commands}).Do(ctx, c)
+	default:
+		return fmt.Errorf("unknown command")
+	}
+}
+
+func setTemperatures(ctx context.Context, c *octoprint.Client, payload []byte) error {
+	var targets map[string]float64
+	if err := json.Unmarshal(payload, &targets); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+
+	tools := make(map[string]float64)
+	for tool, target := range targets {
+		if !strings.HasPrefix(tool, "tool") && tool != "bed" {
+			return fmt.Errorf("invalid tool %q, expecting tool{n} or bed", tool)
+		}
+
+		if tool != "bed" {
+			tools[tool] = target
+		}
+	}
+
+	if len(tools) != 0 {
+		if err := (&octoprint.ToolTargetRequest{Targets: tools}).Do(ctx, c); err != nil {
+			return err
+		}
+	}
+
+	if bed, ok := targets["bed"]; ok {
+		return (&octoprint.BedTargetRequest{Target: bed}).Do(ctx, c)
+	}
+
+	return nil
+}
+
+func (b *Bridge) topics() Topics {
+	if b.Topics == (Topics{}) {
+		return DefaultTopics
+	}
+
+	return b.Topics
+}
+
+// topic returns the full topic of the given levels, below the prefix.
+func (b *Bridge) topic(levels ...string) string {
+	prefix := b.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	return strings.Join(append([]string{strings.TrimSuffix(prefix, "/")}, levels...), "/")
+}
+
+func (b *Bridge) error(err error) {
+	if b.OnError != nil && err != nil {
+		b.OnError(err)
+	}
+}
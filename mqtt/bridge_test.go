@@ -0,0 +1,130 @@
+package mqtt
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/mcuadros/go-octoprint/push"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeBroker struct {
+	mu        sync.Mutex
+	published map[string]string
+	handlers  map[string]func(topic string, payload []byte)
+}
+
+func (b *fakeBroker) Publish(topic string, payload []byte, retain bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published[topic] = string(payload)
+	return nil
+}
+
+func (b *fakeBroker) Subscribe(filter string, fn func(topic string, payload []byte)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[filter] = fn
+	return nil
+}
+
+func (b *fakeBroker) get(topic string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.published[topic]
+}
+
+type fakeEvents chan push.Event
+
+func (e fakeEvents) Subscribe(ctx context.Context, events ...push.EventType) (<-chan push.Event, error) {
+	return e, nil
+}
+
+func TestBridge(t *testing.T) {
+	var mu sync.Mutex
+	var commands []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			body, _ := ioutil.ReadAll(r.Body)
+			mu.Lock()
+			commands = append(commands, r.URL.Path+" "+strings.TrimSpace(string(body)))
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		switch r.URL.Path {
+		case octoprint.URIPrinter:
+			w.Write([]byte(`{
+				"state": {"text": "Printing", "flags": {"printing": true}},
+				"temperature": {"tool0": {"actual": 210, "target": 210}}
+			}`))
+		case octoprint.JobTool:
+			w.Write([]byte(`{"progress": {"completion": 42}}`))
+		}
+	}))
+	defer ts.Close()
+
+	broker := &fakeBroker{
+		published: make(map[string]string),
+		handlers:  make(map[string]func(topic string, payload []byte)),
+	}
+
+	events := make(fakeEvents, 1)
+	events <- push.Event{Type: push.EventPrintStarted, Payload: []byte(`{"name":"cube.gcode"}`)}
+	close(events)
+
+	var errs []error
+	b := &Bridge{
+		Broker:   broker,
+		Prefix:   "home/prusa",
+		Interval: time.Millisecond,
+		Events:   events,
+		OnError:  func(err error) { errs = append(errs, err) },
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error)
+	go func() { done <- b.Run(ctx, octoprint.NewClient(ts.URL, "")) }()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) && (broker.get("home/prusa/progress") == "" ||
+		broker.get("home/prusa/event/PrintStarted") == "") {
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Contains(t, broker.get("home/prusa/state"), `"text":"Printing"`)
+	assert.Contains(t, broker.get("home/prusa/temperature"), `"tool0":{"actual":210`)
+	assert.Contains(t, broker.get("home/prusa/progress"), `"completion":42`)
+	assert.Equal(t, `{"name":"cube.gcode"}`, broker.get("home/prusa/event/PrintStarted"))
+
+	broker.mu.Lock()
+	handler := broker.handlers["home/prusa/command/#"]
+	broker.mu.Unlock()
+
+	handler("home/prusa/command/pause", nil)
+	handler("home/prusa/command/temperature", []byte(`{"tool0": 200, "bed": 55}`))
+	handler("home/prusa/command/gcode", []byte("G28\n\nM84\n"))
+	handler("home/prusa/command/explode", nil)
+
+	assert.Equal(t, context.DeadlineExceeded, <-done)
+
+	assert.Equal(t, []string{
+		`/api/job {"command":"pause","action":"pause"}`,
+		`/api/printer/tool {"command":"target","targets":{"tool0":200}}`,
+		`/api/printer/bed {"command":"target","target":55}`,
+		`/api/printer/command {"commands":["G28","M84"]}`,
+	}, commands)
+
+	assert.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], `error executing command "explode": unknown command`)
+}
@@ -0,0 +1,452 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultKeepAlive is the default keep alive interval of the connection.
+const DefaultKeepAlive = 60 * time.Second
+
+// ErrClosed is returned when using a closed client.
+var ErrClosed = errors.New("MQTT connection closed")
+
+// packet types.
+const (
+	packetConnect    = 1
+	packetConnAck    = 2
+	packetPublish    = 3
+	packetPubAck     = 4
+	packetSubscribe  = 8
+	packetSubAck     = 9
+	packetPingReq    = 12
+	packetDisconnect = 14
+)
+
+// Option is an option of Dial.
+type Option func(*Client)
+
+// WithClientID sets the client identifier, if not set the broker assigns one.
+func WithClientID(id string) Option {
+	return func(c *Client) {
+		c.clientID = id
+	}
+}
+
+// WithCredentials sets the user name and password sent to the broker.
+func WithCredentials(user, password string) Option {
+	return func(c *Client) {
+		c.user, c.password = user, password
+	}
+}
+
+// WithKeepAlive sets the keep alive interval, DefaultKeepAlive by default.
+func WithKeepAlive(d time.Duration) Option {
+	return func(c *Client) {
+		c.keepAlive = d
+	}
+}
+
+// Client is a minimal MQTT 3.1.1 client, publishing and subscribing with QoS
+// 0. It implements Broker.
+type Client struct {
+	clientID  string
+	user      string
+	password  string
+	keepAlive time.Duration
+
+	conn net.Conn
+	r    *bufio.Reader
+
+	wmu sync.Mutex
+
+	mu       sync.Mutex
+	handlers []handler
+	acks     map[uint16]chan byte
+	lastID   uint16
+	err      error
+	closed   chan struct{}
+
+	// pending are the messages received, dispatched in order by dispatch,
+	// so the goroutine reading the connection never blocks on a write or a
+	// handler.
+	pending  []message
+	received chan struct{}
+}
+
+type message struct {
+	topic   string
+	payload []byte
+	// ack is the identifier to acknowledge of a QoS 1 message, if any.
+	ack []byte
+}
+
+type handler struct {
+	filter string
+	fn     func(topic string, payload []byte)
+}
+
+// Dial connects to the MQTT broker at addr, e.g. `localhost:1883`.
+func Dial(ctx context.Context, addr string, opts ...Option) (*Client, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := newClient(ctx, conn, opts...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func newClient(ctx context.Context, conn net.Conn, opts ...Option) (*Client, error) {
+	c := &Client{
+		keepAlive: DefaultKeepAlive,
+		conn:      conn,
+		r:         bufio.NewReader(conn),
+		acks:      make(map[uint16]chan byte),
+		closed:    make(chan struct{}),
+		received:  make(chan struct{}, 1),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+
+	conn.SetDeadline(time.Time{})
+	go c.read()
+	go c.dispatch()
+	if c.keepAlive > 0 {
+		go c.ping()
+	}
+
+	return c, nil
+}
+
+func (c *Client) connect() error {
+	var flags byte = 0x02 // clean session
+	payload := encodeString(c.clientID)
+	if c.user != "" {
+		flags |= 0x80
+		payload = append(payload, encodeString(c.user)...)
+	}
+
+	if c.password != "" {
+		flags |= 0x40
+		payload = append(payload, encodeString(c.password)...)
+	}
+
+	keepAlive := uint16(c.keepAlive / time.Second)
+	b := append(encodeString("MQTT"), 4, flags)
+	b = append(b, byte(keepAlive>>8), byte(keepAlive))
+	if err := c.write(packetConnect<<4, append(b, payload...)); err != nil {
+		return err
+	}
+
+	typ, body, err := c.readPacket()
+	if err != nil {
+		return err
+	}
+
+	if typ>>4 != packetConnAck || len(body) != 2 {
+		return fmt.Errorf("unexpected MQTT packet %d, expecting CONNACK", typ>>4)
+	}
+
+	if body[1] != 0 {
+		return fmt.Errorf("MQTT connection refused, return code %d", body[1])
+	}
+
+	return nil
+}
+
+// Publish publishes the payload to topic, with QoS 0.
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	var flags byte
+	if retain {
+		flags = 0x01
+	}
+
+	return c.write(packetPublish<<4|flags, append(encodeString(topic), payload...))
+}
+
+// Subscribe subscribes to the topics matching filter, which may contain the
+// `+` and `#` wildcards, calling fn with every message received. The handlers
+// are called in order from a single goroutine, other than the one reading the
+// connection.
+func (c *Client) Subscribe(filter string, fn func(topic string, payload []byte)) error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+
+	c.lastID++
+	if c.lastID == 0 {
+		c.lastID++
+	}
+
+	id := c.lastID
+	ack := make(chan byte, 1)
+	c.acks[id] = ack
+	c.handlers = append(c.handlers, handler{filter: filter, fn: fn})
+	c.mu.Unlock()
+
+	b := []byte{byte(id >> 8), byte(id)}
+	b = append(b, encodeString(filter)...)
+	if err := c.write(packetSubscribe<<4|0x02, append(b, 0)); err != nil {
+		return err
+	}
+
+	select {
+	case code := <-ack:
+		if code == 0x80 {
+			return fmt.Errorf("MQTT subscription to %q refused", filter)
+		}
+
+		return nil
+	case <-c.closed:
+		return c.Err()
+	}
+}
+
+// Err returns the error that closed the connection, if any.
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close disconnects from the broker.
+func (c *Client) Close() error {
+	c.write(packetDisconnect<<4, nil)
+	c.fail(ErrClosed)
+	return c.conn.Close()
+}
+
+func (c *Client) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return
+	}
+
+	c.err = err
+	close(c.closed)
+}
+
+func (c *Client) read() {
+	for {
+		typ, body, err := c.readPacket()
+		if err != nil {
+			c.fail(err)
+			c.conn.Close()
+			return
+		}
+
+		switch typ >> 4 {
+		case packetPublish:
+			c.enqueue(typ, body)
+		case packetSubAck:
+			if len(body) < 3 {
+				continue
+			}
+
+			id := binary.BigEndian.Uint16(body)
+			c.mu.Lock()
+			ack, ok := c.acks[id]
+			delete(c.acks, id)
+			c.mu.Unlock()
+
+			if ok {
+				ack <- body[2]
+			}
+		}
+	}
+}
+
+func (c *Client) enqueue(typ byte, body []byte) {
+	if len(body) < 2 {
+		return
+	}
+
+	n := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+n {
+		return
+	}
+
+	m := message{topic: string(body[2 : 2+n]), payload: body[2+n:]}
+	if qos := typ >> 1 & 0x03; qos > 0 {
+		if len(m.payload) < 2 {
+			return
+		}
+
+		if qos == 1 {
+			m.ack = m.payload[:2]
+		}
+
+		m.payload = m.payload[2:]
+	}
+
+	c.mu.Lock()
+	c.pending = append(c.pending, m)
+	c.mu.Unlock()
+
+	select {
+	case c.received <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Client) dispatch() {
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-c.received:
+		}
+
+		c.mu.Lock()
+		pending, handlers := c.pending, append([]handler(nil), c.handlers...)
+		c.pending = nil
+		c.mu.Unlock()
+
+		for _, m := range pending {
+			if m.ack != nil {
+				if err := c.write(packetPubAck<<4, m.ack); err != nil {
+					return
+				}
+			}
+
+			for _, h := range handlers {
+				if matchTopic(h.filter, m.topic) {
+					h.fn(m.topic, m.payload)
+				}
+			}
+		}
+	}
+}
+
+func (c *Client) ping() {
+	ticker := time.NewTicker(c.keepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+		case <-ticker.C:
+			if err := c.write(packetPingReq<<4, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *Client) write(header byte, body []byte) error {
+	b := []byte{header}
+	b = append(b, encodeLength(len(body))...)
+	b = append(b, body...)
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if _, err := c.conn.Write(b); err != nil {
+		if cerr := c.Err(); cerr != nil {
+			return cerr
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *Client) readPacket() (byte, []byte, error) {
+	typ, err := c.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var length, shift int
+	for i := 0; ; i++ {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if i == 4 {
+			return 0, nil, fmt.Errorf("invalid MQTT packet length")
+		}
+
+		length |= int(b&0x7F) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r, body); err != nil {
+		return 0, nil, err
+	}
+
+	return typ, body, nil
+}
+
+func encodeString(s string) []byte {
+	return append([]byte{byte(len(s) >> 8), byte(len(s))}, s...)
+}
+
+func encodeLength(n int) []byte {
+	var b []byte
+	for {
+		d := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			d |= 0x80
+		}
+
+		b = append(b, d)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+// matchTopic returns true if the topic matches the filter, with the `+` single
+// level and `#` multi level wildcards.
+func matchTopic(filter, topic string) bool {
+	f, t := strings.Split(filter, "/"), strings.Split(topic, "/")
+	for i, level := range f {
+		if level == "#" {
+			return true
+		}
+
+		if i >= len(t) || (level != "+" && level != t[i]) {
+			return false
+		}
+	}
+
+	return len(f) == len(t)
+}
@@ -0,0 +1,161 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPacket struct {
+	typ  byte
+	body []byte
+}
+
+func readTestPacket(r *bufio.Reader) (*testPacket, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	var length, shift int
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		length |= int(b&0x7F) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			break
+		}
+	}
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	return &testPacket{typ: typ, body: body}, err
+}
+
+func writeTestPacket(w io.Writer, typ byte, body []byte) {
+	b := append([]byte{typ}, encodeLength(len(body))...)
+	w.Write(append(b, body...))
+}
+
+// pipe returns both ends of a TCP loopback connection.
+func pipe(t *testing.T) (client, server net.Conn) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer l.Close()
+
+	accepted := make(chan net.Conn)
+	go func() {
+		conn, _ := l.Accept()
+		accepted <- conn
+	}()
+
+	client, err = net.Dial("tcp", l.Addr().String())
+	assert.NoError(t, err)
+	return client, <-accepted
+}
+
+func TestClient(t *testing.T) {
+	client, server := pipe(t)
+	defer server.Close()
+
+	published := make(chan *testPacket, 1)
+	go func() {
+		r := bufio.NewReader(server)
+		p, err := readTestPacket(r)
+		if err != nil {
+			return
+		}
+
+		assert.Equal(t, byte(packetConnect<<4), p.typ)
+		assert.Equal(t, append(encodeString("MQTT"), 4, 0xC2, 0, 30), p.body[:10])
+		assert.Equal(t, "\x00\x03foo\x00\x04user\x00\x04pass", string(p.body[10:]))
+		writeTestPacket(server, packetConnAck<<4, []byte{0, 0})
+
+		for {
+			p, err := readTestPacket(r)
+			if err != nil {
+				return
+			}
+
+			switch p.typ >> 4 {
+			case packetSubscribe:
+				assert.Equal(t, byte(0x82), p.typ)
+				writeTestPacket(server, packetSubAck<<4, []byte{p.body[0], p.body[1], 0})
+
+				// QoS 1 message, acknowledged by the client.
+				msg := append(encodeString("octoprint/command/pause"), 0, 7)
+				writeTestPacket(server, packetPublish<<4|0x02, append(msg, "now"...))
+				writeTestPacket(server, packetPublish<<4, append(encodeString("other/topic"), "x"...))
+			case packetPubAck:
+				assert.Equal(t, []byte{0, 7}, p.body)
+			case packetPublish:
+				published <- p
+			}
+		}
+	}()
+
+	c, err := newClient(context.Background(), client,
+		WithClientID("foo"), WithCredentials("user", "pass"), WithKeepAlive(30e9),
+	)
+	assert.NoError(t, err)
+	defer c.Close()
+
+	received := make(chan string, 2)
+	err = c.Subscribe("octoprint/command/+", func(topic string, payload []byte) {
+		received <- topic + " " + string(payload)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "octoprint/command/pause now", <-received)
+
+	assert.NoError(t, c.Publish("octoprint/state", []byte(`{}`), true))
+	p := <-published
+	assert.Equal(t, byte(packetPublish<<4|0x01), p.typ)
+	assert.Equal(t, append(encodeString("octoprint/state"), "{}"...), p.body)
+}
+
+func TestClient_Refused(t *testing.T) {
+	client, server := pipe(t)
+	defer server.Close()
+
+	go func() {
+		if _, err := readTestPacket(bufio.NewReader(server)); err == nil {
+			writeTestPacket(server, packetConnAck<<4, []byte{0, 5})
+		}
+	}()
+
+	_, err := newClient(context.Background(), client)
+	assert.EqualError(t, err, "MQTT connection refused, return code 5")
+}
+
+func TestMatchTopic(t *testing.T) {
+	for _, tc := range []struct {
+		filter, topic string
+		match         bool
+	}{
+		{"a/b", "a/b", true},
+		{"a/b", "a/c", false},
+		{"a/+", "a/b", true},
+		{"a/+", "a/b/c", false},
+		{"a/#", "a/b/c", true},
+		{"a/#", "a", true},
+		{"#", "a/b", true},
+		{"a/b/c", "a/b", false},
+	} {
+		assert.Equal(t, tc.match, matchTopic(tc.filter, tc.topic), tc.filter+" "+tc.topic)
+	}
+}
+
+func TestEncodeLength(t *testing.T) {
+	assert.Equal(t, []byte{0}, encodeLength(0))
+	assert.Equal(t, []byte{127}, encodeLength(127))
+	assert.Equal(t, []byte{0x80, 0x01}, encodeLength(128))
+	assert.Equal(t, []byte{0xFF, 0x7F}, encodeLength(16383))
+}
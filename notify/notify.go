@@ -0,0 +1,211 @@
+// Package notify sends notifications of the relevant events of a printer, such
+// as finished or failed prints, through pluggable notifiers.
+//
+//	d := &notify.Dispatcher{
+//		Printer: "prusa",
+//		Notifiers: []notify.Notifier{
+//			&notify.Webhook{URL: "https://chat.example.com/hooks/printer"},
+//			&notify.SMTP{Addr: "smtp.example.com:587", From: "octoprint@example.com", To: []string{"me@example.com"}},
+//		},
+//	}
+//
+//	err := d.Run(ctx, p) // a connected *push.Client
+package notify
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/mcuadros/go-octoprint/push"
+)
+
+// Kind is the kind of a notification.
+type Kind string
+
+const (
+	// PrintDone is notified when a print finishes successfully.
+	PrintDone Kind = "print_done"
+	// PrintFailed is notified when a print fails or is cancelled.
+	PrintFailed Kind = "print_failed"
+	// FilamentRunout is notified when the print is paused to change the
+	// filament, e.g. by a runout sensor or an M600 command.
+	FilamentRunout Kind = "filament_runout"
+	// ThermalWarning is notified when the firmware reports a thermal error,
+	// e.g. a thermal runaway or a heating failure.
+	ThermalWarning Kind = "thermal_warning"
+)
+
+// Notification is a notification of an event of a printer.
+type Notification struct {
+	// Kind of the notification.
+	Kind Kind `json:"kind"`
+	// Printer is the name of the printer, if set in the Dispatcher.
+	Printer string `json:"printer,omitempty"`
+	// Title is a short summary, e.g. to be used as subject.
+	Title string `json:"title"`
+	// Message is the human readable description.
+	Message string `json:"message"`
+	// Time the event was received.
+	Time time.Time `json:"time"`
+	// Event is the event notified.
+	Event push.Event `json:"event"`
+}
+
+// Notifier sends notifications.
+type Notifier interface {
+	// Notify sends the notification.
+	Notify(ctx context.Context, n *Notification) error
+}
+
+// NotifierFunc is an adapter to use a function as Notifier.
+type NotifierFunc func(ctx context.Context, n *Notification) error
+
+// Notify calls fn(ctx, n).
+func (fn NotifierFunc) Notify(ctx context.Context, n *Notification) error {
+	return fn(ctx, n)
+}
+
+// Events is a source of events, implemented by *push.Client.
+type Events interface {
+	Subscribe(ctx context.Context, events ...push.EventType) (<-chan push.Event, error)
+}
+
+// Dispatcher sends the notifications of the events received to the notifiers.
+type Dispatcher struct {
+	// Printer is the name of the printer, included in the notifications.
+	Printer string
+	// Notifiers receiving the notifications.
+	Notifiers []Notifier
+	// Kinds are the kinds of notifications sent, all of them if empty.
+	Kinds []Kind
+	// OnError is called when a notifier fails.
+	OnError func(err error)
+}
+
+// Run sends the notifications of the events received from events until ctx is
+// done, returning ctx.Err(), or the subscription ends, returning nil. All the
+// notifiers are called concurrently for every notification.
+func (d *Dispatcher) Run(ctx context.Context, events Events) error {
+	ch, err := events.Subscribe(ctx,
+		push.EventPrintDone, push.EventPrintFailed, push.EventPrintCancelled,
+		push.EventFilamentChange, push.EventError,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			n, ok := NewNotification(e)
+			if !ok || !d.wants(n.Kind) {
+				continue
+			}
+
+			n.Printer = d.Printer
+			d.Notify(ctx, n)
+		}
+	}
+}
+
+// Notify sends a notification to all the notifiers concurrently, waiting for
+// them to return.
+func (d *Dispatcher) Notify(ctx context.Context, n *Notification) {
+	var wg sync.WaitGroup
+	for _, notifier := range d.Notifiers {
+		wg.Add(1)
+		go func(notifier Notifier) {
+			defer wg.Done()
+			if err := notifier.Notify(ctx, n); err != nil && d.OnError != nil {
+				d.OnError(fmt.Errorf("error sending %s notification: %w", n.Kind, err))
+			}
+		}(notifier)
+	}
+
+	wg.Wait()
+}
+
+func (d *Dispatcher) wants(k Kind) bool {
+	if len(d.Kinds) == 0 {
+		return true
+	}
+
+	for _, kind := range d.Kinds {
+		if kind == k {
+			return true
+		}
+	}
+
+	return false
+}
+
+var thermalRegexp = regexp.MustCompile(`(?i)thermal|heating failed|mintemp|maxtemp|temperature`)
+
+// NewNotification returns the notification of an event, false if the event is
+// not notified. Only the errors reported by the firmware related to the
+// temperature are notified, as ThermalWarning.
+func NewNotification(e push.Event) (*Notification, bool) {
+	n := &Notification{Time: time.Now(), Event: e}
+
+	p, err := e.DecodePayload()
+	if err != nil {
+		return nil, false
+	}
+
+	switch payload := p.(type) {
+	case *push.PrintPayload:
+		name := payload.Name
+		if name == "" {
+			name = payload.Path
+		}
+
+		switch e.Type {
+		case push.EventPrintDone:
+			n.Kind = PrintDone
+			n.Title = fmt.Sprintf("Print done: %s", name)
+			n.Message = fmt.Sprintf("%s printed in %s.", name, duration(payload.Time))
+		case push.EventPrintFailed, push.EventPrintCancelled:
+			n.Kind = PrintFailed
+			n.Title = fmt.Sprintf("Print failed: %s", name)
+			n.Message = fmt.Sprintf("%s failed after %s.", name, duration(payload.Time))
+			if e.Type == push.EventPrintCancelled || payload.Reason == "cancelled" {
+				n.Title = fmt.Sprintf("Print cancelled: %s", name)
+				n.Message = fmt.Sprintf("%s cancelled after %s.", name, duration(payload.Time))
+			}
+		default:
+			return nil, false
+		}
+	case *push.ErrorPayload:
+		if !thermalRegexp.MatchString(payload.Error) {
+			return nil, false
+		}
+
+		n.Kind = ThermalWarning
+		n.Title = "Thermal warning"
+		n.Message = fmt.Sprintf("The printer reported an error: %s", payload.Error)
+	default:
+		if e.Type != push.EventFilamentChange {
+			return nil, false
+		}
+
+		n.Kind = FilamentRunout
+		n.Title = "Filament change required"
+		n.Message = "The print is paused waiting for a filament change."
+	}
+
+	return n, true
+}
+
+func duration(seconds float64) time.Duration {
+	return (time.Duration(seconds) * time.Second).Round(time.Second)
+}
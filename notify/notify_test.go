@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mcuadros/go-octoprint/push"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEvents chan push.Event
+
+func (e fakeEvents) Subscribe(ctx context.Context, events ...push.EventType) (<-chan push.Event, error) {
+	return e, nil
+}
+
+func TestNewNotification(t *testing.T) {
+	for _, tc := range []struct {
+		event push.Event
+		kind  Kind
+		title string
+	}{
+		{push.Event{Type: push.EventPrintDone, Payload: []byte(`{"name": "cube.gcode", "time": 3723.4}`)},
+			PrintDone, "Print done: cube.gcode"},
+		{push.Event{Type: push.EventPrintFailed, Payload: []byte(`{"name": "cube.gcode", "reason": "error"}`)},
+			PrintFailed, "Print failed: cube.gcode"},
+		{push.Event{Type: push.EventPrintCancelled, Payload: []byte(`{"path": "a/cube.gcode"}`)},
+			PrintFailed, "Print cancelled: a/cube.gcode"},
+		{push.Event{Type: push.EventFilamentChange},
+			FilamentRunout, "Filament change required"},
+		{push.Event{Type: push.EventError, Payload: []byte(`{"error": "Thermal Runaway, system stopped! Heater_ID: 0"}`)},
+			ThermalWarning, "Thermal warning"},
+		{push.Event{Type: push.EventError, Payload: []byte(`{"error": "Too many consecutive timeouts"}`)},
+			"", ""},
+		{push.Event{Type: push.EventPrintStarted, Payload: []byte(`{"name": "cube.gcode"}`)},
+			"", ""},
+	} {
+		n, ok := NewNotification(tc.event)
+		assert.Equal(t, tc.kind != "", ok, string(tc.event.Type))
+		if !ok {
+			continue
+		}
+
+		assert.Equal(t, tc.kind, n.Kind)
+		assert.Equal(t, tc.title, n.Title)
+	}
+
+	n, _ := NewNotification(push.Event{Type: push.EventPrintDone, Payload: []byte(`{"name": "cube.gcode", "time": 3723.4}`)})
+	assert.Equal(t, "cube.gcode printed in 1h2m3s.", n.Message)
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	var received []Notification
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n Notification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		assert.Equal(t, "Bearer foo", r.Header.Get("Authorization"))
+		received = append(received, n)
+	}))
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var errs []error
+	d := &Dispatcher{
+		Printer: "prusa",
+		Kinds:   []Kind{PrintDone, ThermalWarning},
+		Notifiers: []Notifier{
+			&Webhook{URL: ts.URL, Header: http.Header{"Authorization": {"Bearer foo"}}},
+			NotifierFunc(func(ctx context.Context, n *Notification) error {
+				return errors.New("unavailable")
+			}),
+		},
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		},
+	}
+
+	events := make(fakeEvents, 3)
+	events <- push.Event{Type: push.EventPrintDone, Payload: []byte(`{"name": "cube.gcode"}`)}
+	events <- push.Event{Type: push.EventPrintFailed, Payload: []byte(`{"name": "cube.gcode"}`)}
+	events <- push.Event{Type: push.EventError, Payload: []byte(`{"error": "Heating failed"}`)}
+	close(events)
+
+	assert.NoError(t, d.Run(context.Background(), events))
+	assert.Len(t, received, 2)
+	assert.Equal(t, PrintDone, received[0].Kind)
+	assert.Equal(t, "prusa", received[0].Printer)
+	assert.Equal(t, push.EventPrintDone, received[0].Event.Type)
+	assert.Equal(t, ThermalWarning, received[1].Kind)
+
+	assert.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "error sending print_done notification: unavailable")
+}
+
+func TestSMTP_Notify(t *testing.T) {
+	defer func(fn func(string, smtp.Auth, string, []string, []byte) error) { sendMail = fn }(sendMail)
+
+	var msg string
+	sendMail = func(addr string, a smtp.Auth, from string, to []string, b []byte) error {
+		assert.Equal(t, "smtp.example.com:587", addr)
+		assert.Equal(t, "octoprint@example.com", from)
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, to)
+		msg = string(b)
+		return nil
+	}
+
+	s := &SMTP{
+		Addr: "smtp.example.com:587",
+		From: "octoprint@example.com",
+		To:   []string{"a@example.com", "b@example.com"},
+	}
+
+	n := &Notification{Kind: PrintDone, Printer: "prusa", Title: "Print done: cube.gcode", Message: "Done."}
+	assert.NoError(t, s.Notify(context.Background(), n))
+	assert.Contains(t, msg, "Subject: [OctoPrint prusa] Print done: cube.gcode\r\n")
+	assert.Contains(t, msg, "To: a@example.com, b@example.com\r\n")
+	assert.True(t, strings.HasSuffix(msg, "\r\n\r\nDone.\r\n"))
+
+	assert.Error(t, (&SMTP{}).Notify(context.Background(), n))
+}
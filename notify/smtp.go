@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// sendMail sends the emails, replaced by the tests.
+var sendMail = smtp.SendMail
+
+// SMTP is a Notifier sending the notifications by email.
+type SMTP struct {
+	// Addr is the address of the SMTP server, e.g. `smtp.example.com:587`.
+	// STARTTLS is used if supported by the server.
+	Addr string
+	// Auth is the authentication, e.g. smtp.PlainAuth, none if nil.
+	Auth smtp.Auth
+	// From is the address of the sender.
+	From string
+	// To are the addresses of the recipients.
+	To []string
+}
+
+// Notify sends the notification by email. The context is only checked before
+// sending, net/smtp doesn't support cancellation.
+func (s *SMTP) Notify(ctx context.Context, n *Notification) error {
+	if len(s.To) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return sendMail(s.Addr, s.Auth, s.From, s.To, s.message(n))
+}
+
+func (s *SMTP) message(n *Notification) []byte {
+	subject := "[OctoPrint] " + n.Title
+	if n.Printer != "" {
+		subject = fmt.Sprintf("[OctoPrint %s] %s", n.Printer, n.Title)
+	}
+
+	b := bytes.NewBuffer(nil)
+	fmt.Fprintf(b, "From: %s\r\n", s.From)
+	fmt.Fprintf(b, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(b, "Date: %s\r\n", n.Time.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(n.Message, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
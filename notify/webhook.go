@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+// Webhook is a Notifier POSTing the notifications as JSON to a URL. For signed
+// deliveries with retries see the webhook package.
+type Webhook struct {
+	// URL of the endpoint.
+	URL string
+	// Header are additional headers sent, e.g. `Authorization`.
+	Header http.Header
+	// Client is the HTTP client used, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// Notify POSTs the notification to the URL.
+func (w *Webhook) Notify(ctx context.Context, n *Notification) error {
+	b, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+
+	for k, v := range w.Header {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("go-octoprint/%s", octoprint.Version))
+
+	c := w.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
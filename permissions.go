@@ -0,0 +1,26 @@
+package octoprint
+
+import (
+	"context"
+)
+
+const URIPermissions = "/api/access/permissions"
+
+// PermissionsRequest retrieves all the permissions available on the server,
+// including the ones registered by plugins.
+type PermissionsRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *PermissionsRequest) Do(ctx context.Context, c *Client) (*PermissionsResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIPermissions, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PermissionsResponse{}
+	if err := c.decode(URIPermissions, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
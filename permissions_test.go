@@ -0,0 +1,35 @@
+package octoprint
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionsResponse(t *testing.T) {
+	js := []byte(`{"permissions": [{
+		"key": "ADMIN",
+		"name": "Admin",
+		"description": "Admin is allowed to do everything",
+		"dangerous": true,
+		"default_groups": ["admins"],
+		"needs": {"role": ["admin"]}
+	}, {
+		"key": "STATUS",
+		"name": "Status",
+		"dangerous": false,
+		"default_groups": ["users", "guests"],
+		"needs": {"role": ["status"]}
+	}]}`)
+
+	r := &PermissionsResponse{}
+	err := json.Unmarshal(js, r)
+	assert.NoError(t, err)
+
+	assert.Len(t, r.Permissions, 2)
+	assert.True(t, r.Find(PermissionAdmin).Dangerous)
+	assert.Equal(t, []string{"users", "guests"}, r.Find(PermissionStatus).DefaultGroups)
+	assert.Equal(t, []string{"status"}, r.Find(PermissionStatus).Needs["role"])
+	assert.Nil(t, r.Find(PermissionPrint))
+}
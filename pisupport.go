@@ -0,0 +1,50 @@
+package octoprint
+
+import (
+	"context"
+)
+
+const URIPiSupport = "/api/plugin/pi_support"
+
+// PiSupportRequest retrieves the information reported by the bundled pi_support
+// plugin, only available when OctoPrint runs on a Raspberry Pi.
+type PiSupportRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *PiSupportRequest) Do(ctx context.Context, c *Client) (*PiSupportResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIPiSupport, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PiSupportResponse{}
+	if err := c.decode(URIPiSupport, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// ThrottleFlag is a bit of the throttle state reported by the Raspberry Pi
+// firmware (`vcgencmd get_throttled`).
+type ThrottleFlag int64
+
+const (
+	// UnderVoltage the supply voltage is currently too low.
+	UnderVoltage ThrottleFlag = 1 << 0
+	// FrequencyCapped the ARM frequency is currently capped.
+	FrequencyCapped ThrottleFlag = 1 << 1
+	// Throttled the CPU is currently throttled.
+	Throttled ThrottleFlag = 1 << 2
+	// SoftTemperatureLimit the soft temperature limit is currently active.
+	SoftTemperatureLimit ThrottleFlag = 1 << 3
+	// PastUnderVoltage the supply voltage was too low since boot.
+	PastUnderVoltage ThrottleFlag = 1 << 16
+	// PastFrequencyCapped the ARM frequency was capped since boot.
+	PastFrequencyCapped ThrottleFlag = 1 << 17
+	// PastThrottled the CPU was throttled since boot.
+	PastThrottled ThrottleFlag = 1 << 18
+	// PastSoftTemperatureLimit the soft temperature limit was active since
+	// boot.
+	PastSoftTemperatureLimit ThrottleFlag = 1 << 19
+)
@@ -0,0 +1,40 @@
+package octoprint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPiSupportRequest_Do(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"model": "Raspberry Pi 3 Model B Rev 1.2",
+			"octopi_version": "0.18.0",
+			"throttle_state": {
+				"current_undervoltage": true,
+				"past_undervoltage": true,
+				"current_overheat": false,
+				"past_overheat": false,
+				"current_issue": true,
+				"past_issue": true,
+				"raw_value": 327685
+			}
+		}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&PiSupportRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.18.0", r.OctoPiVersion)
+	assert.True(t, r.ThrottleState.CurrentUndervoltage)
+	assert.True(t, r.ThrottleState.Has(UnderVoltage))
+	assert.True(t, r.ThrottleState.Has(Throttled))
+	assert.True(t, r.ThrottleState.Has(PastUnderVoltage))
+	assert.True(t, r.ThrottleState.Has(PastThrottled))
+	assert.False(t, r.ThrottleState.Has(FrequencyCapped))
+}
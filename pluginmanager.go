@@ -0,0 +1,188 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	URIPluginManager           = "/api/plugin/pluginmanager"
+	URIPluginManagerPlugins    = "/plugin/pluginmanager/plugins"
+	URIPluginManagerRepository = "/plugin/pluginmanager/repository"
+)
+
+var PluginManagerErrors = statusMapping{
+	400: "The plugin or the command parameters are invalid",
+	403: "The user is not allowed to manage plugins",
+	404: "The plugin is not installed",
+	409: "The printer is printing or the plugin can't be managed",
+}
+
+// PluginListRequest retrieves the installed plugins, using the bundled plugin
+// manager plugin.
+type PluginListRequest struct {
+	// Refresh whether to refresh the plugin list, bypassing the cache of the
+	// server.
+	Refresh bool
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *PluginListRequest) Do(ctx context.Context, c *Client) (*PluginListResponse, error) {
+	uri := URIPluginManagerPlugins
+	if cmd.Refresh {
+		uri = fmt.Sprintf("%s?refresh=true", uri)
+	}
+
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, PluginManagerErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PluginListResponse{}
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// PluginRepositoryRequest retrieves the plugins available on the plugin
+// repository.
+type PluginRepositoryRequest struct {
+	// Refresh whether to refresh the repository, bypassing the cache of the
+	// server.
+	Refresh bool
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *PluginRepositoryRequest) Do(ctx context.Context, c *Client) (*PluginRepository, error) {
+	uri := URIPluginManagerRepository
+	if cmd.Refresh {
+		uri = fmt.Sprintf("%s?refresh=true", uri)
+	}
+
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, PluginManagerErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := struct {
+		Repository *PluginRepository `json:"repository"`
+	}{}
+
+	if err := c.decode(uri, b, &r); err != nil {
+		return nil, err
+	}
+
+	if r.Repository == nil {
+		return &PluginRepository{}, nil
+	}
+
+	return r.Repository, err
+}
+
+// PluginInstallRequest installs a plugin, either from an archive URL or from
+// the plugin repository.
+type PluginInstallRequest struct {
+	// URL of the archive of the plugin to install.
+	URL string `json:"url"`
+	// ID is the identifier of the plugin on the repository, used to look up
+	// its archive URL if URL is empty.
+	ID string `json:"-"`
+	// DependencyLinks whether to follow the dependency links of the plugin.
+	DependencyLinks bool `json:"dependency_links,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *PluginInstallRequest) Do(ctx context.Context, c *Client) (*PluginCommandResponse, error) {
+	payload := *cmd
+	if payload.URL == "" {
+		repo, err := (&PluginRepositoryRequest{}).Do(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+
+		p := repo.Find(cmd.ID)
+		if p == nil {
+			return nil, fmt.Errorf("plugin %q not found on the repository", cmd.ID)
+		}
+
+		payload.URL = p.Archive
+		payload.DependencyLinks = payload.DependencyLinks || p.FollowDependencyLinks
+	}
+
+	return doPluginCommand(ctx, c, struct {
+		Command string `json:"command"`
+		PluginInstallRequest
+	}{"install", payload})
+}
+
+// PluginUninstallRequest uninstalls a plugin.
+type PluginUninstallRequest struct {
+	// Plugin is the identifier of the plugin to uninstall.
+	Plugin string `json:"plugin"`
+	// Cleanup whether to also remove the settings and data of the plugin.
+	Cleanup bool `json:"cleanup,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *PluginUninstallRequest) Do(ctx context.Context, c *Client) (*PluginCommandResponse, error) {
+	return doPluginCommand(ctx, c, struct {
+		Command string `json:"command"`
+		PluginUninstallRequest
+	}{"uninstall", *cmd})
+}
+
+// PluginEnableRequest enables a disabled plugin.
+type PluginEnableRequest struct {
+	// Plugin is the identifier of the plugin to enable.
+	Plugin string `json:"plugin"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *PluginEnableRequest) Do(ctx context.Context, c *Client) (*PluginCommandResponse, error) {
+	return doPluginCommand(ctx, c, struct {
+		Command string `json:"command"`
+		PluginEnableRequest
+	}{"enable", *cmd})
+}
+
+// PluginDisableRequest disables an enabled plugin.
+type PluginDisableRequest struct {
+	// Plugin is the identifier of the plugin to disable.
+	Plugin string `json:"plugin"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *PluginDisableRequest) Do(ctx context.Context, c *Client) (*PluginCommandResponse, error) {
+	return doPluginCommand(ctx, c, struct {
+		Command string `json:"command"`
+		PluginDisableRequest
+	}{"disable", *cmd})
+}
+
+func doPluginCommand(ctx context.Context, c *Client, payload interface{}) (*PluginCommandResponse, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(payload); err != nil {
+		return nil, err
+	}
+
+	ctx = WithTimeoutClass(ctx, TransferClass)
+	b2, err := c.doJSONRequest(ctx, "POST", URIPluginManager, b, PluginManagerErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PluginCommandResponse{}
+	if len(b2) == 0 {
+		return r, nil
+	}
+
+	if err := c.decode(URIPluginManager, b2, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
@@ -0,0 +1,90 @@
+package octoprint
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginListRequest_Do(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"plugins": [{
+			"key": "bedlevelvisualizer",
+			"name": "Bed Visualizer",
+			"version": "1.1.0",
+			"bundled": false,
+			"managable": true,
+			"enabled": true,
+			"pending_disable": true,
+			"origin": "entry_point",
+			"notifications": [{"text": "Broken on 1.8", "important": true, "versions": ["1.1.0"]}]
+		}]}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&PluginListRequest{Refresh: true}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "refresh=true", query)
+
+	p := r.Find("bedlevelvisualizer")
+	assert.True(t, p.Managable)
+	assert.True(t, p.PendingDisable)
+	assert.True(t, p.Notifications[0].Important)
+	assert.Nil(t, r.Find("missing"))
+}
+
+func TestPluginCommands_Do(t *testing.T) {
+	var payload map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == URIPluginManagerRepository {
+			w.Write([]byte(`{"repository": {"available": true, "plugins": [{
+				"id": "themeify",
+				"title": "Themeify",
+				"archive": "https://example.com/themeify.zip",
+				"follow_dependency_links": true,
+				"is_compatible": {"octoprint": true, "os": true, "python": true}
+			}]}}`))
+			return
+		}
+
+		payload = nil
+		json.NewDecoder(r.Body).Decode(&payload)
+		w.Write([]byte(`{"result": true, "needs_restart": true, "plugin": {"key": "themeify"}}`))
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	cli := NewClient(ts.URL, "")
+
+	r, err := (&PluginInstallRequest{ID: "themeify"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.True(t, r.NeedsRestart)
+	assert.Equal(t, "themeify", r.Plugin.Key)
+	assert.Equal(t, map[string]interface{}{
+		"command":          "install",
+		"url":              "https://example.com/themeify.zip",
+		"dependency_links": true,
+	}, payload)
+
+	_, err = (&PluginInstallRequest{ID: "missing"}).Do(ctx, cli)
+	assert.Error(t, err)
+
+	_, err = (&PluginUninstallRequest{Plugin: "themeify", Cleanup: true}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"command": "uninstall", "plugin": "themeify", "cleanup": true}, payload)
+
+	_, err = (&PluginEnableRequest{Plugin: "themeify"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "enable", payload["command"])
+
+	_, err = (&PluginDisableRequest{Plugin: "themeify"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "disable", payload["command"])
+}
@@ -2,9 +2,12 @@ package octoprint
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
+	"regexp"
 	"strings"
 )
 
@@ -13,6 +16,7 @@ const (
 	URIPrintHead     = "/api/printer/printhead"
 	URIPrintTool     = "/api/printer/tool"
 	URIPrintBed      = "/api/printer/bed"
+	URIPrintChamber  = "/api/printer/chamber"
 	URIPrintSD       = "/api/printer/sd"
 	URICommand       = "/api/printer/command"
 	URICommandCustom = "/api/printer/command/custom"
@@ -33,6 +37,9 @@ var (
 	PrintBedErrors = statusMapping{
 		409: "Printer is not operational or the selected printer profile does not have a heated bed.",
 	}
+	PrintChamberErrors = statusMapping{
+		409: "Printer is not operational or the selected printer profile does not have a heated chamber.",
+	}
 	PrintSDErrors = statusMapping{
 		404: "SD support has been disabled in OctoPrint’s settings.",
 		409: "SD card has not been initialized.",
@@ -41,9 +48,11 @@ var (
 
 // StateRequest retrieves the current state of the printer.
 type StateRequest struct {
-	// History if true retrieve the temperature history.
+	// History if true retrieve the temperature history, decoded into
+	// TemperatureState.History.
 	History bool
-	// Limit limtis amount of returned history data points.
+	// Limit limits amount of returned history data points, only used if
+	// History is true. Zero means no limit.
 	Limit int
 	// Exclude list of fields to exclude from the response (e.g. if not
 	// needed by the client). Valid values to supply here are `temperature`,
@@ -52,18 +61,24 @@ type StateRequest struct {
 }
 
 // Do sends an API request and returns the API response.
-func (cmd *StateRequest) Do(c *Client) (*FullStateResponse, error) {
-	uri := fmt.Sprintf("%s?history=%t&limit=%d&exclude=%s", URIPrinter,
-		cmd.History, cmd.Limit, strings.Join(cmd.Exclude, ","),
-	)
+func (cmd *StateRequest) Do(ctx context.Context, c *Client) (*FullStateResponse, error) {
+	q := historyQuery(cmd.History, cmd.Limit)
+	if len(cmd.Exclude) != 0 {
+		q.Set("exclude", strings.Join(cmd.Exclude, ","))
+	}
+
+	uri := URIPrinter
+	if len(q) != 0 {
+		uri = fmt.Sprintf("%s?%s", uri, q.Encode())
+	}
 
-	b, err := c.doJSONRequest("GET", uri, nil, PrintErrors)
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, PrintErrors)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &FullStateResponse{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(uri, b, r); err != nil {
 		return nil, err
 	}
 
@@ -75,13 +90,13 @@ func (cmd *StateRequest) Do(c *Client) (*FullStateResponse, error) {
 type PrintHeadJogRequest struct {
 	// X is the amount distance to travel in mm or coordinate to jog print head
 	// on x axis.
-	X int `json:"x,omitempty"`
+	X float64 `json:"x,omitempty"`
 	// Y is the amount distance to travel in mm or coordinate to jog print head
 	// on y axis.
-	Y int `json:"y,omitempty"`
+	Y float64 `json:"y,omitempty"`
 	// Z is the amount distance to travel in mm.or coordinate to jog print head
 	// on x axis.
-	Z int `json:"z,omitempty"`
+	Z float64 `json:"z,omitempty"`
 	// Absolute is whether to move relative to current position (provided axes
 	// values are relative amounts) or to absolute position (provided axes
 	// values are coordinates)
@@ -92,17 +107,30 @@ type PrintHeadJogRequest struct {
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *PrintHeadJogRequest) Do(c *Client) error {
+func (cmd *PrintHeadJogRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URIPrintHead, b, PrintHeadJobErrors)
+	_, err := c.doJSONRequest(ctx, "POST", URIPrintHead, b, PrintHeadJobErrors)
 
 	return err
 }
 
+// Validate checks the speed of the request.
+func (cmd *PrintHeadJogRequest) Validate() error {
+	if cmd.Speed < 0 {
+		return fmt.Errorf("invalid speed %d, must not be negative", cmd.Speed)
+	}
+
+	return nil
+}
+
 func (cmd *PrintHeadJogRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
 		Command string `json:"command"`
@@ -120,16 +148,35 @@ type PrintHeadHomeRequest struct {
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *PrintHeadHomeRequest) Do(c *Client) error {
+func (cmd *PrintHeadHomeRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URIPrintHead, b, PrintHeadJobErrors)
+	_, err := c.doJSONRequest(ctx, "POST", URIPrintHead, b, PrintHeadJobErrors)
 	return err
 }
 
+// Validate checks the axes of the request.
+func (cmd *PrintHeadHomeRequest) Validate() error {
+	if len(cmd.Axes) == 0 {
+		return fmt.Errorf("at least one axis must be provided")
+	}
+
+	for _, a := range cmd.Axes {
+		if !a.IsValid() {
+			return fmt.Errorf("invalid axis %q, must be x, y or z", a)
+		}
+	}
+
+	return nil
+}
+
 func (cmd *PrintHeadHomeRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
 		Command string `json:"command"`
@@ -140,6 +187,59 @@ func (cmd *PrintHeadHomeRequest) encode(w io.Writer) error {
 	})
 }
 
+// SetAxis sets the amount to travel, or the coordinate, on the given axis.
+func (cmd *PrintHeadJogRequest) SetAxis(a Axis, amount float64) {
+	switch a {
+	case XAxis:
+		cmd.X = amount
+	case YAxis:
+		cmd.Y = amount
+	case ZAxis:
+		cmd.Z = amount
+	}
+}
+
+// PrintHeadFeedrateRequest changes the feedrate factor to apply to the
+// movements of the axes.
+type PrintHeadFeedrateRequest struct {
+	// Factor is the new factor, percentage as integer, between 50 and 200%.
+	Factor int `json:"factor"`
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *PrintHeadFeedrateRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
+	b := bytes.NewBuffer(nil)
+	if err := cmd.encode(b); err != nil {
+		return err
+	}
+
+	_, err := c.doJSONRequest(ctx, "POST", URIPrintHead, b, PrintHeadJobErrors)
+	return err
+}
+
+// Validate checks the factor of the request.
+func (cmd *PrintHeadFeedrateRequest) Validate() error {
+	if cmd.Factor < 50 || cmd.Factor > 200 {
+		return fmt.Errorf("invalid feed rate factor %d, must be between 50 and 200", cmd.Factor)
+	}
+
+	return nil
+}
+
+func (cmd *PrintHeadFeedrateRequest) encode(w io.Writer) error {
+	return json.NewEncoder(w).Encode(struct {
+		Command string `json:"command"`
+		PrintHeadFeedrateRequest
+	}{
+		Command:                  "feedrate",
+		PrintHeadFeedrateRequest: *cmd,
+	})
+}
+
 // ToolStateRequest retrieves the current temperature data (actual, target and
 // offset) plus optionally a (limited) history (actual, target, timestamp) for
 // all of the printer’s available tools.
@@ -151,15 +251,19 @@ type ToolStateRequest struct {
 }
 
 // Do sends an API request and returns the API response.
-func (cmd *ToolStateRequest) Do(c *Client) (*TemperatureState, error) {
-	uri := fmt.Sprintf("%s?history=%t&limit=%d", URIPrintTool, cmd.History, cmd.Limit)
-	b, err := c.doJSONRequest("GET", uri, nil, nil)
+func (cmd *ToolStateRequest) Do(ctx context.Context, c *Client) (*TemperatureState, error) {
+	uri := URIPrintTool
+	if q := historyQuery(cmd.History, cmd.Limit); len(q) != 0 {
+		uri = fmt.Sprintf("%s?%s", uri, q.Encode())
+	}
+
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &TemperatureState{}
-	if err := json.Unmarshal(b, &r); err != nil {
+	if err := c.decode(uri, b, &r); err != nil {
 		return nil, err
 	}
 
@@ -174,16 +278,26 @@ type ToolTargetRequest struct {
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *ToolTargetRequest) Do(c *Client) error {
+func (cmd *ToolTargetRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URIPrintTool, b, PrintToolErrors)
+	_, err := c.doJSONRequest(ctx, "POST", URIPrintTool, b, PrintToolErrors)
 	return err
 }
 
+// Validate checks the tool keys of the request and that the targets are not
+// negative.
+func (cmd *ToolTargetRequest) Validate() error {
+	return validateToolMap(cmd.Targets, validateTarget)
+}
+
 func (cmd *ToolTargetRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
 		Command string `json:"command"`
@@ -202,16 +316,26 @@ type ToolOffsetRequest struct {
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *ToolOffsetRequest) Do(c *Client) error {
+func (cmd *ToolOffsetRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URIPrintTool, b, PrintToolErrors)
+	_, err := c.doJSONRequest(ctx, "POST", URIPrintTool, b, PrintToolErrors)
 	return err
 }
 
+// Validate checks the tool keys of the request and that the offsets are
+// within the range accepted by OctoPrint, ±MaxTemperatureOffset.
+func (cmd *ToolOffsetRequest) Validate() error {
+	return validateToolMap(cmd.Offsets, validateOffset)
+}
+
 func (cmd *ToolOffsetRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
 		Command string `json:"command"`
@@ -227,20 +351,33 @@ func (cmd *ToolOffsetRequest) encode(w io.Writer) error {
 type ToolExtrudeRequest struct {
 	// Amount is the amount of filament to extrude in mm. May be negative to
 	// retract.
-	Amount int `json:"amount"`
+	Amount float64 `json:"amount"`
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *ToolExtrudeRequest) Do(c *Client) error {
+func (cmd *ToolExtrudeRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URIPrintTool, b, PrintToolErrors)
+	_, err := c.doJSONRequest(ctx, "POST", URIPrintTool, b, PrintToolErrors)
 	return err
 }
 
+// Validate checks the amount of the request.
+func (cmd *ToolExtrudeRequest) Validate() error {
+	if cmd.Amount == 0 {
+		return fmt.Errorf("invalid extrusion amount, must be different than zero")
+	}
+
+	return nil
+}
+
 func (cmd *ToolExtrudeRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
 		Command string `json:"command"`
@@ -259,16 +396,25 @@ type ToolSelectRequest struct {
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *ToolSelectRequest) Do(c *Client) error {
+func (cmd *ToolSelectRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URIPrintTool, b, PrintToolErrors)
+	_, err := c.doJSONRequest(ctx, "POST", URIPrintTool, b, PrintToolErrors)
 	return err
 }
 
+// Validate checks the tool of the request.
+func (cmd *ToolSelectRequest) Validate() error {
+	return validateTool(cmd.Tool)
+}
+
 func (cmd *ToolSelectRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
 		Command string `json:"command"`
@@ -287,16 +433,29 @@ type ToolFlowrateRequest struct {
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *ToolFlowrateRequest) Do(c *Client) error {
+func (cmd *ToolFlowrateRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URIPrintTool, b, PrintToolErrors)
+	_, err := c.doJSONRequest(ctx, "POST", URIPrintTool, b, PrintToolErrors)
 	return err
 }
 
+// Validate checks the factor of the request.
+func (cmd *ToolFlowrateRequest) Validate() error {
+	if cmd.Factor < 75 || cmd.Factor > 125 {
+		return fmt.Errorf("invalid flow rate factor %d, must be between 75 and 125", cmd.Factor)
+	}
+
+	return nil
+}
+
 func (cmd *ToolFlowrateRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
 		Command string `json:"command"`
@@ -322,15 +481,49 @@ type BedStateRequest struct {
 }
 
 // Do sends an API request and returns the API response.
-func (cmd *BedStateRequest) Do(c *Client) (*TemperatureState, error) {
-	uri := fmt.Sprintf("%s?history=%t&limit=%d", URIPrintBed, cmd.History, cmd.Limit)
-	b, err := c.doJSONRequest("GET", uri, nil, PrintBedErrors)
+func (cmd *BedStateRequest) Do(ctx context.Context, c *Client) (*TemperatureState, error) {
+	uri := URIPrintBed
+	if q := historyQuery(cmd.History, cmd.Limit); len(q) != 0 {
+		uri = fmt.Sprintf("%s?%s", uri, q.Encode())
+	}
+
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, PrintBedErrors)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &TemperatureState{}
-	if err := json.Unmarshal(b, &r); err != nil {
+	if err := c.decode(uri, b, &r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// ChamberStateRequest retrieves the current temperature data (actual, target
+// and offset) plus optionally a (limited) history (actual, target, timestamp)
+// for the printer’s heated chamber.
+type ChamberStateRequest struct {
+	// History if true retrieve the temperature history.
+	History bool
+	// Limit limits amount of returned history data points.
+	Limit int
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *ChamberStateRequest) Do(ctx context.Context, c *Client) (*TemperatureState, error) {
+	uri := URIPrintChamber
+	if q := historyQuery(cmd.History, cmd.Limit); len(q) != 0 {
+		uri = fmt.Sprintf("%s?%s", uri, q.Encode())
+	}
+
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, PrintChamberErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &TemperatureState{}
+	if err := c.decode(uri, b, &r); err != nil {
 		return nil, err
 	}
 
@@ -344,16 +537,25 @@ type BedTargetRequest struct {
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *BedTargetRequest) Do(c *Client) error {
+func (cmd *BedTargetRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URIPrintBed, b, PrintBedErrors)
+	_, err := c.doJSONRequest(ctx, "POST", URIPrintBed, b, PrintBedErrors)
 	return err
 }
 
+// Validate checks that the target is not negative.
+func (cmd *BedTargetRequest) Validate() error {
+	return validateTarget("bed", cmd.Target)
+}
+
 func (cmd *BedTargetRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
 		Command string `json:"command"`
@@ -367,20 +569,30 @@ func (cmd *BedTargetRequest) encode(w io.Writer) error {
 // BedOffsetRequest sets the given temperature offset on the printer’s bed.
 type BedOffsetRequest struct {
 	// Offset is offset to set.
-	Offset int `json:"offset"`
+	Offset float64 `json:"offset"`
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *BedOffsetRequest) Do(c *Client) error {
+func (cmd *BedOffsetRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := cmd.encode(b); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URIPrintTool, b, PrintToolErrors)
+	_, err := c.doJSONRequest(ctx, "POST", URIPrintBed, b, PrintBedErrors)
 	return err
 }
 
+// Validate checks that the offset is within the range accepted by OctoPrint,
+// ±MaxTemperatureOffset.
+func (cmd *BedOffsetRequest) Validate() error {
+	return validateOffset("bed", cmd.Offset)
+}
+
 func (cmd *BedOffsetRequest) encode(w io.Writer) error {
 	return json.NewEncoder(w).Encode(struct {
 		Command string `json:"command"`
@@ -391,37 +603,111 @@ func (cmd *BedOffsetRequest) encode(w io.Writer) error {
 	})
 }
 
+// GcodeBatchSize is the maximum number of lines sent on every request by
+// Client.SendGcode.
+var GcodeBatchSize = 50
+
 // CommandRequest sends any command to the printer via the serial interface.
 // Should be used with some care as some commands can interfere with or even
 // stop a running print job.
 type CommandRequest struct {
-	// Commands list of commands to send to the printer.
-	Commands []string `json:"commands"`
+	// Command single command to send to the printer, mutually exclusive
+	// with Commands.
+	Command string `json:"command,omitempty"`
+	// Commands list of commands to send to the printer, mutually exclusive
+	// with Command.
+	Commands []string `json:"commands,omitempty"`
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *CommandRequest) Do(c *Client) error {
+func (cmd *CommandRequest) Do(ctx context.Context, c *Client) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+
 	b := bytes.NewBuffer(nil)
 	if err := json.NewEncoder(b).Encode(cmd); err != nil {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", URICommand, b, nil)
+	_, err := c.doJSONRequest(ctx, "POST", URICommand, b, PrintErrors)
 	return err
 }
 
+// Validate checks that the request has at least one command, that none of
+// them is empty, and that Command and Commands are not both set.
+func (cmd *CommandRequest) Validate() error {
+	if len(cmd.Commands) == 0 && strings.TrimSpace(cmd.Command) == "" {
+		return fmt.Errorf("at least one command must be provided")
+	}
+
+	if len(cmd.Commands) != 0 && cmd.Command != "" {
+		return fmt.Errorf("command and commands are mutually exclusive")
+	}
+
+	for _, command := range cmd.Commands {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("invalid empty command")
+		}
+	}
+
+	return nil
+}
+
+// SendGcode sends the given G-code to the printer. Every line may contain
+// several commands separated by newlines, empty lines and comments are
+// skipped. Long scripts are sent in batches of GcodeBatchSize commands, if a
+// batch fails the remaining ones are not sent.
+func (c *Client) SendGcode(ctx context.Context, lines ...string) error {
+	commands := splitGcode(lines)
+	for len(commands) > 0 {
+		n := len(commands)
+		if GcodeBatchSize > 0 && n > GcodeBatchSize {
+			n = GcodeBatchSize
+		}
+
+		if err := (&CommandRequest{Commands: commands[:n]}).Do(ctx, c); err != nil {
+			return err
+		}
+
+		commands = commands[n:]
+	}
+
+	return nil
+}
+
+func splitGcode(lines []string) []string {
+	var commands []string
+	for _, l := range lines {
+		for _, cmd := range strings.Split(l, "\n") {
+			if i := strings.Index(cmd, ";"); i >= 0 {
+				cmd = cmd[:i]
+			}
+
+			cmd = strings.TrimSpace(cmd)
+			if cmd == "" {
+				continue
+			}
+
+			commands = append(commands, cmd)
+		}
+	}
+
+	return commands
+}
+
 // CustomCommandsRequest retrieves all configured system controls.
 type CustomCommandsRequest struct{}
 
 // Do sends an API request and returns the API response.
-func (cmd *CustomCommandsRequest) Do(c *Client) (*CustomCommandsResponse, error) {
-	b, err := c.doJSONRequest("GET", URICommandCustom, nil, nil)
+func (cmd *CustomCommandsRequest) Do(ctx context.Context, c *Client) (*CustomCommandsResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URICommandCustom, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &CustomCommandsResponse{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(URICommandCustom, b, r); err != nil {
 		return nil, err
 	}
 
@@ -433,14 +719,14 @@ func (cmd *CustomCommandsRequest) Do(c *Client) (*CustomCommandsResponse, error)
 type SDStateRequest struct{}
 
 // Do sends an API request and returns the API response.
-func (cmd *SDStateRequest) Do(c *Client) (*SDState, error) {
-	b, err := c.doJSONRequest("GET", URIPrintSD, nil, PrintSDErrors)
+func (cmd *SDStateRequest) Do(ctx context.Context, c *Client) (*SDState, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIPrintSD, nil, PrintSDErrors)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &SDState{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(URIPrintSD, b, r); err != nil {
 		return nil, err
 	}
 
@@ -453,16 +739,16 @@ func (cmd *SDStateRequest) Do(c *Client) (*SDState, error) {
 type SDInitRequest struct{}
 
 // Do sends an API request and returns an error if any.
-func (cmd *SDInitRequest) Do(c *Client) error {
-	return doCommandRequest(c, URIPrintSD, "init", PrintSDErrors)
+func (cmd *SDInitRequest) Do(ctx context.Context, c *Client) error {
+	return doCommandRequest(ctx, c, URIPrintSD, "init", PrintSDErrors)
 }
 
 // SDRefreshRequest Refreshes the list of files stored on the printer’s SD card.
 type SDRefreshRequest struct{}
 
 // Do sends an API request and returns an error if any.
-func (cmd *SDRefreshRequest) Do(c *Client) error {
-	return doCommandRequest(c, URIPrintSD, "refresh", PrintSDErrors)
+func (cmd *SDRefreshRequest) Do(ctx context.Context, c *Client) error {
+	return doCommandRequest(ctx, c, URIPrintSD, "refresh", PrintSDErrors)
 }
 
 // SDReleaseRequest releases the SD card from the printer. The reverse operation
@@ -471,13 +757,104 @@ func (cmd *SDRefreshRequest) Do(c *Client) error {
 type SDReleaseRequest struct{}
 
 // Do sends an API request and returns an error if any.
-func (cmd *SDReleaseRequest) Do(c *Client) error {
-	return doCommandRequest(c, URIPrintSD, "release", PrintSDErrors)
+func (cmd *SDReleaseRequest) Do(ctx context.Context, c *Client) error {
+	return doCommandRequest(ctx, c, URIPrintSD, "release", PrintSDErrors)
+}
+
+// SDFilesRequest retrieves the list of files stored on the printer’s SD card.
+// The SD card needs to be initialized, see SDInitRequest. The listing is
+// cached by OctoPrint, use SDRefreshRequest to update it.
+type SDFilesRequest struct {
+	// Filter if set only returns files of the given type, e.g. `machinecode`.
+	Filter string
+}
+
+// Do sends an API request and returns the files stored on the SD card. For
+// these files Name is the short name reported by the printer (usually in 8.3
+// format) and Display the long name, when the printer reports it.
+func (cmd *SDFilesRequest) Do(ctx context.Context, c *Client) ([]*FileInformation, error) {
+	r, err := (&FilesRequest{Location: SDCard, Filter: cmd.Filter}).Do(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Files, nil
+}
+
+var toolRegexp = regexp.MustCompile(`^tool\d+$`)
+
+// ToolKey returns the key identifying the tool with the given index, in the
+// format tool{n}, as used by the tool requests.
+func ToolKey(n int) string {
+	return fmt.Sprintf("tool%d", n)
+}
+
+func validateTool(tool string) error {
+	if !toolRegexp.MatchString(tool) {
+		return fmt.Errorf("invalid tool %q, must match the format tool{n}", tool)
+	}
+
+	return nil
+}
+
+// MaxTemperatureOffset is the maximum absolute temperature offset accepted by
+// OctoPrint.
+const MaxTemperatureOffset = 50
+
+func validateToolMap(m map[string]float64, validate func(tool string, v float64) error) error {
+	if len(m) == 0 {
+		return fmt.Errorf("at least one tool must be provided")
+	}
+
+	for tool, v := range m {
+		if err := validateTool(tool); err != nil {
+			return err
+		}
+
+		if err := validate(tool, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateTarget(tool string, target float64) error {
+	if target < 0 {
+		return fmt.Errorf("invalid target %g of %s, must not be negative", target, tool)
+	}
+
+	return nil
+}
+
+func validateOffset(tool string, offset float64) error {
+	if offset < -MaxTemperatureOffset || offset > MaxTemperatureOffset {
+		return fmt.Errorf("invalid offset %g of %s, must be between -%d and %d",
+			offset, tool, MaxTemperatureOffset, MaxTemperatureOffset)
+	}
+
+	return nil
+}
+
+// historyQuery returns the query of the temperature history, empty if history
+// is false.
+func historyQuery(history bool, limit int) url.Values {
+	q := url.Values{}
+	if !history {
+		return q
+	}
+
+	q.Set("history", "true")
+	if limit > 0 {
+		q.Set("limit", fmt.Sprintf("%d", limit))
+	}
+
+	return q
 }
 
 // doCommandRequest can be used in any operation where the only required field
 // is the `command` field.
-func doCommandRequest(c *Client, uri, command string, m statusMapping) error {
+func doCommandRequest(ctx context.Context, c *Client, uri, command string, m statusMapping) error {
 	v := map[string]string{"command": command}
 
 	b := bytes.NewBuffer(nil)
@@ -485,6 +862,6 @@ func doCommandRequest(c *Client, uri, command string, m statusMapping) error {
 		return err
 	}
 
-	_, err := c.doJSONRequest("POST", uri, b, m)
+	_, err := c.doJSONRequest(ctx, "POST", uri, b, m)
 	return err
 }
@@ -1,6 +1,11 @@
 package octoprint
 
 import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -11,7 +16,7 @@ func TestStateRequest_Do(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
 	r := &StateRequest{}
-	state, err := r.Do(cli)
+	state, err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "Operational", state.State.Text)
@@ -23,7 +28,7 @@ func TestStateRequest_DoWithHistory(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
 	r := &StateRequest{History: true}
-	state, err := r.Do(cli)
+	state, err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "Operational", state.State.Text)
@@ -35,7 +40,7 @@ func TestStateRequest_DoWithExclude(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
 	r := &StateRequest{Exclude: []string{"temperature"}}
-	state, err := r.Do(cli)
+	state, err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	assert.Equal(t, "Operational", state.State.Text)
@@ -46,12 +51,12 @@ func TestSDInitRequest_Do(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
 	r := &SDInitRequest{}
-	err := r.Do(cli)
+	err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	time.Sleep(50 * time.Millisecond)
 
-	state, err := (&SDStateRequest{}).Do(cli)
+	state, err := (&SDStateRequest{}).Do(context.Background(), cli)
 	assert.NoError(t, err)
 	assert.True(t, state.Ready)
 }
@@ -60,10 +65,10 @@ func TestSDReleaseRequest_Do(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
 	r := &SDReleaseRequest{}
-	err := r.Do(cli)
+	err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
-	state, err := (&SDStateRequest{}).Do(cli)
+	state, err := (&SDStateRequest{}).Do(context.Background(), cli)
 	assert.NoError(t, err)
 	assert.False(t, state.Ready)
 }
@@ -72,10 +77,10 @@ func TestSDRefreshRequest_Do(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
 	r := &SDRefreshRequest{}
-	err := r.Do(cli)
+	err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
-	state, err := (&SDStateRequest{}).Do(cli)
+	state, err := (&SDStateRequest{}).Do(context.Background(), cli)
 	assert.NoError(t, err)
 	assert.False(t, state.Ready)
 }
@@ -84,7 +89,7 @@ func TestCustomCommandsRequest_Do(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
 	r := &CustomCommandsRequest{}
-	s, err := r.Do(cli)
+	s, err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	assert.Len(t, s.Controls, 1)
@@ -93,3 +98,240 @@ func TestCustomCommandsRequest_Do(t *testing.T) {
 	assert.Equal(t, s.Controls[0].Children[0].Name, "Move X (static)")
 	assert.Len(t, s.Controls[0].Children[0].Commands, 3)
 }
+
+func TestPrintHeadRequests_Encode(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	jog := &PrintHeadJogRequest{Speed: 30}
+	jog.SetAxis(XAxis, 10.5)
+	jog.SetAxis(ZAxis, -0.1)
+	assert.NoError(t, jog.encode(b))
+	assert.JSONEq(t, `{"command": "jog", "x": 10.5, "z": -0.1, "absolute": false, "speed": 30}`, b.String())
+
+	b.Reset()
+	home := &PrintHeadHomeRequest{Axes: []Axis{XAxis, YAxis}}
+	assert.NoError(t, home.encode(b))
+	assert.JSONEq(t, `{"command": "home", "axes": ["x", "y"]}`, b.String())
+
+	b.Reset()
+	feedrate := &PrintHeadFeedrateRequest{Factor: 150}
+	assert.NoError(t, feedrate.encode(b))
+	assert.JSONEq(t, `{"command": "feedrate", "factor": 150}`, b.String())
+}
+
+func TestToolRequests_Validate(t *testing.T) {
+	assert.NoError(t, (&ToolTargetRequest{Targets: map[string]float64{
+		ToolKey(0): 200, ToolKey(1): 210,
+	}}).Validate())
+	assert.Error(t, (&ToolTargetRequest{Targets: map[string]float64{"bed": 60}}).Validate())
+	assert.Error(t, (&ToolTargetRequest{}).Validate())
+	assert.Error(t, (&ToolOffsetRequest{Offsets: map[string]float64{"tool": 5}}).Validate())
+	assert.Error(t, (&ToolTargetRequest{Targets: map[string]float64{"tool0": -1}}).Validate())
+	assert.NoError(t, (&ToolOffsetRequest{Offsets: map[string]float64{"tool0": -50}}).Validate())
+	assert.Error(t, (&ToolOffsetRequest{Offsets: map[string]float64{"tool0": 50.5}}).Validate())
+	assert.NoError(t, (&ToolSelectRequest{Tool: "tool12"}).Validate())
+	assert.Error(t, (&ToolSelectRequest{Tool: "Tool1"}).Validate())
+	assert.NoError(t, (&ToolExtrudeRequest{Amount: -2.5}).Validate())
+	assert.Error(t, (&ToolExtrudeRequest{}).Validate())
+	assert.NoError(t, (&ToolFlowrateRequest{Factor: 100}).Validate())
+	assert.Error(t, (&ToolFlowrateRequest{Factor: 150}).Validate())
+
+	err := (&ToolSelectRequest{Tool: "foo"}).Do(context.Background(), NewClient("http://127.0.0.1:0", ""))
+	assert.EqualError(t, err, `invalid tool "foo", must match the format tool{n}`)
+}
+
+func TestPrinterRequests_Validate(t *testing.T) {
+	assert.NoError(t, (&PrintHeadJogRequest{X: 10, Speed: 100}).Validate())
+	assert.Error(t, (&PrintHeadJogRequest{X: 10, Speed: -1}).Validate())
+	assert.NoError(t, (&PrintHeadHomeRequest{Axes: []Axis{XAxis, YAxis}}).Validate())
+	assert.Error(t, (&PrintHeadHomeRequest{}).Validate())
+	assert.Error(t, (&PrintHeadHomeRequest{Axes: []Axis{"e"}}).Validate())
+	assert.NoError(t, (&PrintHeadFeedrateRequest{Factor: 200}).Validate())
+	assert.Error(t, (&PrintHeadFeedrateRequest{Factor: 20}).Validate())
+	assert.NoError(t, (&BedTargetRequest{}).Validate())
+	assert.Error(t, (&BedTargetRequest{Target: -5}).Validate())
+	assert.Error(t, (&BedOffsetRequest{Offset: 60}).Validate())
+	assert.NoError(t, (&CommandRequest{Command: "M105"}).Validate())
+	assert.Error(t, (&CommandRequest{}).Validate())
+	assert.Error(t, (&CommandRequest{Commands: []string{"G28", " "}}).Validate())
+	assert.EqualError(t, (&CommandRequest{Command: "M105", Commands: []string{"G28"}}).Validate(),
+		"command and commands are mutually exclusive")
+	assert.Error(t, (&PauseRequest{Action: "stop"}).Validate())
+	assert.NoError(t, (&PauseRequest{}).Validate())
+	assert.Error(t, (&ConnectRequest{BaudRate: -1}).Validate())
+
+	err := (&PrintHeadHomeRequest{Axes: []Axis{"e"}}).Do(context.Background(), NewClient("http://127.0.0.1:0", ""))
+	assert.EqualError(t, err, `invalid axis "e", must be x, y or z`)
+}
+
+func TestBedRequests_Do(t *testing.T) {
+	var body, query, uri string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != URIPrintBed {
+			w.WriteHeader(404)
+			return
+		}
+
+		b, _ := ioutil.ReadAll(r.Body)
+		body, query, uri = string(b), r.URL.RawQuery, r.RequestURI
+		if r.Method == "GET" {
+			w.Write([]byte(`{
+				"bed": {"actual": 50.221, "target": 70.0, "offset": 5},
+				"history": [{"time": 1395651928, "bed": {"actual": 50.221, "target": 70.0}}]
+			}`))
+			return
+		}
+
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+
+	err := (&BedTargetRequest{Target: 60}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"command": "target", "target": 60}`, body)
+
+	err = (&BedOffsetRequest{Offset: -2.5}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"command": "offset", "offset": -2.5}`, body)
+
+	state, err := (&BedStateRequest{History: true, Limit: 1}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "history=true&limit=1", query)
+	assert.Equal(t, 70., *state.Current["bed"].Target)
+	assert.Len(t, state.History, 1)
+
+	_, err = (&BedStateRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, URIPrintBed, uri)
+}
+
+func TestChamberStateRequest_Do(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != URIPrintChamber {
+			w.WriteHeader(409)
+			return
+		}
+
+		query = r.URL.RawQuery
+		w.Write([]byte(`{
+			"chamber": {"actual": 30.2, "target": 40.0, "offset": 0},
+			"history": [
+				{"time": 1395651926, "chamber": {"actual": 29.5, "target": 40.0}},
+				{"time": 1395651927},
+				{"time": 1395651928, "chamber": {"actual": 30.2, "target": 40.0}}
+			]
+		}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	state, err := (&ChamberStateRequest{History: true, Limit: 3}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "history=true&limit=3", query)
+
+	h := state.Heater("chamber")
+	assert.Equal(t, "chamber", h.Name)
+	assert.Equal(t, 30.2, h.Current.Actual)
+	assert.Equal(t, 40., *h.Current.Target)
+	assert.Len(t, h.History, 2)
+	assert.Equal(t, int64(1395651926), h.History[0].Time.Unix())
+	assert.Equal(t, 29.5, h.History[0].Actual)
+	assert.Nil(t, state.Heater("bed"))
+
+	_, err = (&BedStateRequest{}).Do(context.Background(), cli)
+	assert.EqualError(t, err, "Printer is not operational or the selected printer profile does not have a heated bed.")
+}
+
+func TestSDFilesRequest_Do(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != URIFiles+"/sdcard" {
+			w.WriteHeader(404)
+			return
+		}
+
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"files": [{
+			"name": "BENCHY~1.GCO",
+			"display": "benchy_0.2mm.gcode",
+			"path": "BENCHY~1.GCO",
+			"origin": "sdcard",
+			"type": "machinecode",
+			"size": 2048
+		}]}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	files, err := (&SDFilesRequest{Filter: "machinecode"}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "filter=machinecode&recursive=false", query)
+	assert.Len(t, files, 1)
+	assert.Equal(t, "BENCHY~1.GCO", files[0].Name)
+	assert.Equal(t, "benchy_0.2mm.gcode", files[0].Display)
+	assert.Equal(t, uint64(2048), files[0].Size)
+}
+
+func TestClient_SendGcode(t *testing.T) {
+	var batches []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		batches = append(batches, string(b))
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	defer func(n int) { GcodeBatchSize = n }(GcodeBatchSize)
+	GcodeBatchSize = 2
+
+	cli := NewClient(ts.URL, "")
+	err := cli.SendGcode(context.Background(), "G28 ; home\n\nG1 Z10", "M104 S200", "; comment")
+	assert.NoError(t, err)
+	assert.Len(t, batches, 2)
+	assert.JSONEq(t, `{"commands": ["G28", "G1 Z10"]}`, batches[0])
+	assert.JSONEq(t, `{"commands": ["M104 S200"]}`, batches[1])
+
+	batches = nil
+	err = (&CommandRequest{Command: "M112"}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"command": "M112"}`, batches[0])
+}
+
+func TestStateRequest_Query(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{
+			"temperature": {
+				"tool0": {"actual": 214.8821, "target": 220.0, "offset": 0},
+				"history": [
+					{"time": 1395651928, "tool0": {"actual": 214.8821, "target": 220.0}},
+					{"time": 1395651926, "tool0": {"actual": 212.32, "target": 220.0}}
+				]
+			}
+		}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+
+	_, err := (&StateRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "", query)
+
+	_, err = (&StateRequest{Limit: 2}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "", query)
+
+	state, err := (&StateRequest{
+		History: true,
+		Limit:   2,
+		Exclude: []string{"sd", "state"},
+	}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "exclude=sd%2Cstate&history=true&limit=2", query)
+	assert.Len(t, state.Temperature.History, 2)
+	assert.Equal(t, 212.32, state.Temperature.History[1].Tools["tool0"].Actual)
+}
@@ -0,0 +1,141 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const URIPrinterProfiles = "/api/printerprofiles"
+
+var (
+	PrinterProfilesErrors = statusMapping{
+		404: "The profile does not exist",
+	}
+	PrinterProfileCreateErrors = statusMapping{
+		400: "The profile is missing or invalid, or the base profile does not exist",
+	}
+	PrinterProfileUpdateErrors = statusMapping{
+		400: "The profile is missing or invalid",
+		404: "The profile does not exist",
+	}
+	PrinterProfileDeleteErrors = statusMapping{
+		404: "The profile does not exist",
+		409: "The profile is the default or the currently selected one",
+	}
+)
+
+// PrinterProfileListRequest retrieves all configured printer profiles.
+type PrinterProfileListRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *PrinterProfileListRequest) Do(ctx context.Context, c *Client) (*PrinterProfilesResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIPrinterProfiles, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PrinterProfilesResponse{}
+	if err := c.decode(URIPrinterProfiles, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// PrinterProfileRequest retrieves a single printer profile.
+type PrinterProfileRequest struct {
+	// ID of the profile to retrieve.
+	ID string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *PrinterProfileRequest) Do(ctx context.Context, c *Client) (*Profile, error) {
+	uri := fmt.Sprintf("%s/%s", URIPrinterProfiles, cmd.ID)
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, PrinterProfilesErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Profile{}
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// PrinterProfileCreateRequest adds a new printer profile. Returns the created
+// profile.
+type PrinterProfileCreateRequest struct {
+	// Profile to create, missing values are taken from the base profile.
+	Profile *Profile `json:"profile"`
+	// BasedOn is the identifier of the profile to use as base, if empty the
+	// default profile is used.
+	BasedOn string `json:"basedOn,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *PrinterProfileCreateRequest) Do(ctx context.Context, c *Client) (*Profile, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	return doProfileRequest(ctx, c, "POST", URIPrinterProfiles, b, PrinterProfileCreateErrors)
+}
+
+// PrinterProfileUpdateRequest updates an existing printer profile, the given
+// profile is merged into the existing one. Since every field is sent, the
+// profile is usually retrieved with PrinterProfileRequest and modified.
+// Returns the updated profile.
+type PrinterProfileUpdateRequest struct {
+	// ID of the profile to update.
+	ID string `json:"-"`
+	// Profile with the values to update.
+	Profile *Profile `json:"profile"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *PrinterProfileUpdateRequest) Do(ctx context.Context, c *Client) (*Profile, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	uri := fmt.Sprintf("%s/%s", URIPrinterProfiles, cmd.ID)
+	return doProfileRequest(ctx, c, "PATCH", uri, b, PrinterProfileUpdateErrors)
+}
+
+// PrinterProfileDeleteRequest deletes a printer profile. Neither the default
+// profile nor the currently selected one can be deleted.
+type PrinterProfileDeleteRequest struct {
+	// ID of the profile to delete.
+	ID string
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *PrinterProfileDeleteRequest) Do(ctx context.Context, c *Client) error {
+	uri := fmt.Sprintf("%s/%s", URIPrinterProfiles, cmd.ID)
+	_, err := c.doJSONRequest(ctx, "DELETE", uri, nil, PrinterProfileDeleteErrors)
+	return err
+}
+
+func doProfileRequest(ctx context.Context, c *Client, method, uri string, body io.Reader, m statusMapping) (*Profile, error) {
+	b, err := c.doJSONRequest(ctx, method, uri, body, m)
+	if err != nil {
+		return nil, err
+	}
+
+	r := struct {
+		Profile *Profile `json:"profile"`
+	}{}
+
+	if err := c.decode(uri, b, &r); err != nil {
+		return nil, err
+	}
+
+	return r.Profile, nil
+}
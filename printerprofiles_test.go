@@ -0,0 +1,152 @@
+package octoprint
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const profileJSON = `{
+	"id": "_default",
+	"name": "Default",
+	"color": "default",
+	"model": "Generic RepRap Printer",
+	"default": true,
+	"current": true,
+	"resource": "http://example.com/api/printerprofiles/_default",
+	"volume": {
+		"formFactor": "rectangular",
+		"origin": "lowerleft",
+		"width": 200,
+		"depth": 200,
+		"height": 200,
+		"custom_box": false
+	},
+	"heatedBed": true,
+	"heatedChamber": false,
+	"axes": {
+		"x": {"speed": 6000, "inverted": false},
+		"y": {"speed": 6000, "inverted": false},
+		"z": {"speed": 200, "inverted": true},
+		"e": {"speed": 300, "inverted": false}
+	},
+	"extruder": {
+		"count": 2,
+		"offsets": [[0.0, 0.0], [18.5, 0.0]],
+		"nozzleDiameter": 0.4,
+		"sharedNozzle": false,
+		"defaultExtrusionLength": 5
+	}
+}`
+
+func TestPrinterProfileListRequest_Do(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"profiles": {"_default": ` + profileJSON + `}}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&PrinterProfileListRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+
+	p := r.Profiles["_default"]
+	assert.Equal(t, "Generic RepRap Printer", p.Model)
+	assert.True(t, p.Current)
+	assert.Equal(t, 200., p.Volume.Height)
+	assert.Nil(t, p.Volume.CustomBox)
+	assert.True(t, p.Axes.Z.Inverted)
+	assert.Equal(t, 2, p.Extruder.Count)
+	assert.Equal(t, [2]float64{18.5, 0}, p.Extruder.Offsets[1])
+}
+
+func TestPrinterProfileCreateRequest_Do(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"profile": {
+			"id": "delta",
+			"name": "Delta",
+			"volume": {
+				"formFactor": "circular",
+				"origin": "center",
+				"width": 180,
+				"depth": 180,
+				"height": 300,
+				"custom_box": {"x_min": -100, "x_max": 100, "y_min": -100, "y_max": 100, "z_min": 0, "z_max": 300}
+			}
+		}}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	p, err := (&PrinterProfileCreateRequest{
+		Profile: &Profile{
+			ID:   "delta",
+			Name: "Delta",
+			Volume: &ProfileVolume{
+				FormFactor: "circular",
+				Origin:     "center",
+				Width:      180,
+				Depth:      180,
+				Height:     300,
+			},
+		},
+		BasedOn: "_default",
+	}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"profile": {
+			"id": "delta",
+			"name": "Delta",
+			"volume": {
+				"formFactor": "circular",
+				"origin": "center",
+				"width": 180,
+				"depth": 180,
+				"height": 300,
+				"custom_box": false
+			},
+			"heatedBed": false,
+			"heatedChamber": false
+		},
+		"basedOn": "_default"
+	}`, body)
+
+	assert.Equal(t, "delta", p.ID)
+	assert.Equal(t, -100., p.Volume.CustomBox.XMin)
+	assert.Equal(t, 300., p.Volume.CustomBox.ZMax)
+}
+
+func TestPrinterProfileUpdateAndDeleteRequest_Do(t *testing.T) {
+	var method, path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		if r.Method == "DELETE" {
+			w.WriteHeader(409)
+			return
+		}
+
+		w.Write([]byte(`{"profile": ` + profileJSON + `}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	p, err := (&PrinterProfileUpdateRequest{
+		ID:      "_default",
+		Profile: &Profile{Name: "Default"},
+	}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "PATCH", method)
+	assert.Equal(t, URIPrinterProfiles+"/_default", path)
+	assert.Equal(t, "Default", p.Name)
+
+	err = (&PrinterProfileDeleteRequest{ID: "_default"}).Do(context.Background(), cli)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, "DELETE", method)
+}
@@ -0,0 +1,87 @@
+package octoprint
+
+import (
+	"io"
+	"os"
+)
+
+// ProgressFunc is called while transferring a file, with the amount of bytes
+// transferred so far and the total amount of bytes to transfer, -1 if unknown.
+type ProgressFunc func(transferred, total int64)
+
+// progressReader calls fn after every read from r.
+type progressReader struct {
+	r     io.Reader
+	fn    ProgressFunc
+	n     int64
+	total int64
+}
+
+func newProgressReader(r io.Reader, total int64, fn ProgressFunc) io.Reader {
+	if fn == nil {
+		return r
+	}
+
+	return &progressReader{r: r, fn: fn, total: total}
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.n += int64(n)
+		r.fn(r.n, r.total)
+	}
+
+	return n, err
+}
+
+// progressWriter calls fn after every write to w.
+type progressWriter struct {
+	w     io.Writer
+	fn    ProgressFunc
+	n     int64
+	total int64
+}
+
+func newProgressWriter(w io.Writer, offset, total int64, fn ProgressFunc) io.Writer {
+	if fn == nil {
+		return w
+	}
+
+	return &progressWriter{w: w, fn: fn, n: offset, total: total}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if n > 0 {
+		w.n += int64(n)
+		w.fn(w.n, w.total)
+	}
+
+	return n, err
+}
+
+// readerSize returns the amount of bytes left to read from r, -1 if it can't
+// be determined.
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case *os.File:
+		fi, err := v.Stat()
+		if err != nil || !fi.Mode().IsRegular() {
+			return -1
+		}
+
+		pos, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+
+		return fi.Size() - pos
+	case interface{ Size() int64 }:
+		return v.Size()
+	}
+
+	return -1
+}
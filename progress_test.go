@@ -0,0 +1,41 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadFileRequest_DoWithProgress(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(201)
+		w.Write([]byte(`{"done": true}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+
+	var last, total int64
+	r := &UploadFileRequest{Location: Local, Progress: func(n, t int64) {
+		last, total = n, t
+	}}
+
+	r.AddFile("foo.gcode", bytes.NewBufferString(strings.Repeat("G28\n", 100000)))
+	_, err := r.Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(400000), total)
+	assert.Equal(t, int64(400000), last)
+}
+
+func TestReaderSize(t *testing.T) {
+	assert.Equal(t, int64(3), readerSize(bytes.NewBufferString("foo")))
+	assert.Equal(t, int64(3), readerSize(strings.NewReader("foo")))
+	assert.Equal(t, int64(-1), readerSize(ioutil.NopCloser(nil)))
+}
@@ -0,0 +1,740 @@
+package push
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+// EventType is the name of an event triggered on the server.
+type EventType string
+
+// Server events.
+const (
+	// EventStartup is triggered when the server has started.
+	EventStartup EventType = "Startup"
+	// EventShutdown is triggered when the server is shutting down.
+	EventShutdown EventType = "Shutdown"
+	// EventConnectivityChanged is triggered when the internet connectivity of the
+	// server changes, payload ConnectivityChangedPayload.
+	EventConnectivityChanged EventType = "ConnectivityChanged"
+	// EventClientOpened is triggered when a client connects to the push API,
+	// payload ClientPayload.
+	EventClientOpened EventType = "ClientOpened"
+	// EventClientAuthed is triggered when a client authenticates a push API
+	// socket, payload ClientPayload.
+	EventClientAuthed EventType = "ClientAuthed"
+	// EventClientClosed is triggered when a client disconnects from the push API,
+	// payload ClientPayload.
+	EventClientClosed EventType = "ClientClosed"
+	// EventUserLoggedIn is triggered when a user logs in, payload UserPayload.
+	EventUserLoggedIn EventType = "UserLoggedIn"
+	// EventUserLoggedOut is triggered when a user logs out, payload UserPayload.
+	EventUserLoggedOut EventType = "UserLoggedOut"
+	// EventConnectionsAutorefreshed is triggered when the list of serial ports
+	// changes, payload ConnectionsAutorefreshedPayload.
+	EventConnectionsAutorefreshed EventType = "ConnectionsAutorefreshed"
+)
+
+// Printer communication events.
+const (
+	// EventConnecting is triggered when the server starts connecting to the
+	// printer.
+	EventConnecting EventType = "Connecting"
+	// EventConnected is triggered when the server has connected to the printer,
+	// payload ConnectedPayload.
+	EventConnected EventType = "Connected"
+	// EventDisconnecting is triggered when the server starts disconnecting from the
+	// printer.
+	EventDisconnecting EventType = "Disconnecting"
+	// EventDisconnected is triggered when the server has disconnected from the
+	// printer.
+	EventDisconnected EventType = "Disconnected"
+	// EventPrinterStateChanged is triggered when the state of the printer changes,
+	// payload PrinterStateChangedPayload.
+	EventPrinterStateChanged EventType = "PrinterStateChanged"
+	// EventPrinterReset is triggered when the printer resets unexpectedly.
+	EventPrinterReset EventType = "PrinterReset"
+	// EventError is triggered when an unrecoverable error occurs on the
+	// communication with the printer, payload ErrorPayload.
+	EventError EventType = "Error"
+)
+
+// File handling events.
+const (
+	// EventUpload is triggered when a file is uploaded, payload UploadPayload.
+	EventUpload EventType = "Upload"
+	// EventFileAdded is triggered when a file is added to a storage, payload
+	// FilePayload.
+	EventFileAdded EventType = "FileAdded"
+	// EventFileRemoved is triggered when a file is removed from a storage, payload
+	// FilePayload.
+	EventFileRemoved EventType = "FileRemoved"
+	// EventFileMoved is triggered when a file is moved, payload MovedPayload.
+	EventFileMoved EventType = "FileMoved"
+	// EventFolderAdded is triggered when a folder is added to a storage, payload
+	// FolderPayload.
+	EventFolderAdded EventType = "FolderAdded"
+	// EventFolderRemoved is triggered when a folder is removed from a storage,
+	// payload FolderPayload.
+	EventFolderRemoved EventType = "FolderRemoved"
+	// EventFolderMoved is triggered when a folder is moved, payload MovedPayload.
+	EventFolderMoved EventType = "FolderMoved"
+	// EventUpdatedFiles is triggered when the list of files changes, payload
+	// UpdatedFilesPayload.
+	EventUpdatedFiles EventType = "UpdatedFiles"
+	// EventMetadataAnalysisStarted is triggered when the analysis of a file
+	// starts, payload MetadataAnalysisPayload.
+	EventMetadataAnalysisStarted EventType = "MetadataAnalysisStarted"
+	// EventMetadataAnalysisFinished is triggered when the analysis of a file
+	// finishes, payload MetadataAnalysisPayload.
+	EventMetadataAnalysisFinished EventType = "MetadataAnalysisFinished"
+	// EventFileSelected is triggered when a file is selected for printing, payload
+	// FileSelectedPayload.
+	EventFileSelected EventType = "FileSelected"
+	// EventFileDeselected is triggered when no file is selected anymore.
+	EventFileDeselected EventType = "FileDeselected"
+	// EventTransferStarted is triggered when a file transfer to the SD card starts,
+	// payload TransferPayload.
+	EventTransferStarted EventType = "TransferStarted"
+	// EventTransferDone is triggered when a file transfer to the SD card finishes,
+	// payload TransferPayload.
+	EventTransferDone EventType = "TransferDone"
+	// EventTransferFailed is triggered when a file transfer to the SD card fails,
+	// payload TransferPayload.
+	EventTransferFailed EventType = "TransferFailed"
+)
+
+// Printing events.
+const (
+	// EventPrintStarted is triggered when a print starts, payload PrintPayload.
+	EventPrintStarted EventType = "PrintStarted"
+	// EventPrintFailed is triggered when a print fails, payload PrintPayload.
+	EventPrintFailed EventType = "PrintFailed"
+	// EventPrintDone is triggered when a print finishes successfully, payload
+	// PrintPayload.
+	EventPrintDone EventType = "PrintDone"
+	// EventPrintCancelling is triggered when a print is being cancelled, payload
+	// PrintPayload.
+	EventPrintCancelling EventType = "PrintCancelling"
+	// EventPrintCancelled is triggered when a print has been cancelled, payload
+	// PrintPayload.
+	EventPrintCancelled EventType = "PrintCancelled"
+	// EventPrintPaused is triggered when a print is paused, payload PrintPayload.
+	EventPrintPaused EventType = "PrintPaused"
+	// EventPrintResumed is triggered when a print is resumed, payload PrintPayload.
+	EventPrintResumed EventType = "PrintResumed"
+)
+
+// GCODE processing events.
+const (
+	// EventPowerOn is triggered when a M80 command is sent to the printer.
+	EventPowerOn EventType = "PowerOn"
+	// EventPowerOff is triggered when a M81 command is sent to the printer.
+	EventPowerOff EventType = "PowerOff"
+	// EventHome is triggered when a G28 command is sent to the printer.
+	EventHome EventType = "Home"
+	// EventZChange is triggered when the Z position of the print head changes
+	// while printing, payload ZChangePayload.
+	EventZChange EventType = "ZChange"
+	// EventDwell is triggered when a G4 command is sent to the printer.
+	EventDwell EventType = "Dwell"
+	// EventWaiting is triggered when a wait command is sent to the printer.
+	EventWaiting EventType = "Waiting"
+	// EventCooling is triggered when a M245 command is sent to the printer.
+	EventCooling EventType = "Cooling"
+	// EventAlert is triggered when a M300 command is sent to the printer.
+	EventAlert EventType = "Alert"
+	// EventConveyor is triggered when a M240 command is sent to the printer.
+	EventConveyor EventType = "Conveyor"
+	// EventEject is triggered when a M40 command is sent to the printer.
+	EventEject EventType = "Eject"
+	// EventEStop is triggered when a M112 command is sent to the printer.
+	EventEStop EventType = "EStop"
+	// EventFilamentChange is triggered when a M600, M701 or M702 command is sent
+	// to the printer.
+	EventFilamentChange EventType = "FilamentChange"
+	// EventPositionUpdate is triggered when the printer reports its position,
+	// payload Position.
+	EventPositionUpdate EventType = "PositionUpdate"
+	// EventToolChange is triggered when the active tool changes, payload
+	// ToolChangePayload.
+	EventToolChange EventType = "ToolChange"
+	// EventCommandSuppressed is triggered when a command is suppressed by the
+	// server, payload CommandSuppressedPayload.
+	EventCommandSuppressed EventType = "CommandSuppressed"
+	// EventInvalidToolReported is triggered when the printer reports an invalid
+	// tool, payload InvalidToolReportedPayload.
+	EventInvalidToolReported EventType = "InvalidToolReported"
+)
+
+// Timelapse events.
+const (
+	// EventCaptureStart is triggered when a timelapse frame capture starts,
+	// payload CapturePayload.
+	EventCaptureStart EventType = "CaptureStart"
+	// EventCaptureDone is triggered when a timelapse frame capture finishes,
+	// payload CapturePayload.
+	EventCaptureDone EventType = "CaptureDone"
+	// EventCaptureFailed is triggered when a timelapse frame capture fails,
+	// payload CapturePayload.
+	EventCaptureFailed EventType = "CaptureFailed"
+	// EventPostRollStart is triggered when the post roll of a timelapse starts,
+	// payload PostRollPayload.
+	EventPostRollStart EventType = "PostRollStart"
+	// EventPostRollEnd is triggered when the post roll of a timelapse ends.
+	EventPostRollEnd EventType = "PostRollEnd"
+	// EventMovieRendering is triggered when the rendering of a timelapse starts,
+	// payload MoviePayload.
+	EventMovieRendering EventType = "MovieRendering"
+	// EventMovieDone is triggered when the rendering of a timelapse finishes,
+	// payload MoviePayload.
+	EventMovieDone EventType = "MovieDone"
+	// EventMovieFailed is triggered when the rendering of a timelapse fails,
+	// payload MoviePayload.
+	EventMovieFailed EventType = "MovieFailed"
+)
+
+// Slicing events.
+const (
+	// EventSlicingStarted is triggered when slicing starts, payload
+	// SlicingPayload.
+	EventSlicingStarted EventType = "SlicingStarted"
+	// EventSlicingDone is triggered when slicing finishes, payload SlicingPayload.
+	EventSlicingDone EventType = "SlicingDone"
+	// EventSlicingCancelled is triggered when slicing is cancelled, payload
+	// SlicingPayload.
+	EventSlicingCancelled EventType = "SlicingCancelled"
+	// EventSlicingFailed is triggered when slicing fails, payload SlicingPayload.
+	EventSlicingFailed EventType = "SlicingFailed"
+	// EventSlicingProfileAdded is triggered when a slicing profile is added,
+	// payload SlicingProfilePayload.
+	EventSlicingProfileAdded EventType = "SlicingProfileAdded"
+	// EventSlicingProfileModified is triggered when a slicing profile is modified,
+	// payload SlicingProfilePayload.
+	EventSlicingProfileModified EventType = "SlicingProfileModified"
+	// EventSlicingProfileDeleted is triggered when a slicing profile is deleted,
+	// payload SlicingProfilePayload.
+	EventSlicingProfileDeleted EventType = "SlicingProfileDeleted"
+)
+
+// Settings and printer profile events.
+const (
+	// EventSettingsUpdated is triggered when the settings are updated, payload
+	// SettingsUpdatedPayload.
+	EventSettingsUpdated EventType = "SettingsUpdated"
+	// EventPrinterProfileAdded is triggered when a printer profile is added,
+	// payload PrinterProfilePayload.
+	EventPrinterProfileAdded EventType = "PrinterProfileAdded"
+	// EventPrinterProfileModified is triggered when a printer profile is modified,
+	// payload PrinterProfilePayload.
+	EventPrinterProfileModified EventType = "PrinterProfileModified"
+	// EventPrinterProfileDeleted is triggered when a printer profile is deleted,
+	// payload PrinterProfilePayload.
+	EventPrinterProfileDeleted EventType = "PrinterProfileDeleted"
+)
+
+// Push client events, they are not sent by the server but emitted by the
+// client itself when reconnection is enabled, see WithReconnect.
+const (
+	// EventSocketDisconnected is emitted when the connection to the server is
+	// lost, payload SocketDisconnectedPayload. Messages may be lost until the
+	// EventSocketReconnected event.
+	EventSocketDisconnected EventType = "SocketDisconnected"
+	// EventSocketReconnected is emitted when the connection to the server is
+	// established again, payload Connected.
+	EventSocketReconnected EventType = "SocketReconnected"
+)
+
+// SocketDisconnectedPayload is the payload of the SocketDisconnected event.
+type SocketDisconnectedPayload struct {
+	// Error is the reason why the connection was lost.
+	Error string `json:"error"`
+}
+
+// ConnectivityChangedPayload is the payload of the ConnectivityChanged event.
+type ConnectivityChangedPayload struct {
+	// Old connectivity state.
+	Old bool `json:"old"`
+	// New connectivity state.
+	New bool `json:"new"`
+}
+
+// ClientPayload is the payload of the ClientOpened, ClientAuthed and
+// ClientClosed events.
+type ClientPayload struct {
+	// RemoteAddress of the client.
+	RemoteAddress string `json:"remoteAddress"`
+	// Username of the authenticated user, only on ClientAuthed.
+	Username string `json:"username"`
+}
+
+// UserPayload is the payload of the UserLoggedIn and UserLoggedOut events.
+type UserPayload struct {
+	// Username of the user.
+	Username string `json:"username"`
+}
+
+// ConnectionsAutorefreshedPayload is the payload of the
+// ConnectionsAutorefreshed event.
+type ConnectionsAutorefreshedPayload struct {
+	// Ports is the new list of serial ports.
+	Ports []string `json:"ports"`
+}
+
+// ConnectedPayload is the payload of the Connected event.
+type ConnectedPayload struct {
+	// Port the printer is connected to.
+	Port string `json:"port"`
+	// BaudRate of the connection.
+	BaudRate int `json:"baudrate"`
+}
+
+// PrinterStateChangedPayload is the payload of the PrinterStateChanged event.
+type PrinterStateChangedPayload struct {
+	// StateID is the identifier of the new state, e.g. `OPERATIONAL`.
+	StateID string `json:"state_id"`
+	// StateString is the human readable new state, e.g. `Operational`.
+	StateString string `json:"state_string"`
+}
+
+// ErrorPayload is the payload of the Error event.
+type ErrorPayload struct {
+	// Error is the error message.
+	Error string `json:"error"`
+	// Reason of the error, e.g. `firmware` or `autodetect`.
+	Reason string `json:"reason"`
+}
+
+// UploadPayload is the payload of the Upload event.
+type UploadPayload struct {
+	// Name of the file.
+	Name string `json:"name"`
+	// Path of the file in the target storage.
+	Path string `json:"path"`
+	// Target storage of the file.
+	Target octoprint.Location `json:"target"`
+	// Select whether the file was selected after the upload.
+	Select bool `json:"select"`
+	// Print whether the file was printed after the upload.
+	Print bool `json:"print"`
+}
+
+// FilePayload is the payload of the FileAdded and FileRemoved events.
+type FilePayload struct {
+	// Storage of the file.
+	Storage octoprint.Location `json:"storage"`
+	// Path of the file.
+	Path string `json:"path"`
+	// Name of the file.
+	Name string `json:"name"`
+	// Type of the file, e.g. `["machinecode", "gcode"]`.
+	Type []string `json:"type"`
+}
+
+// FolderPayload is the payload of the FolderAdded and FolderRemoved events.
+type FolderPayload struct {
+	// Storage of the folder.
+	Storage octoprint.Location `json:"storage"`
+	// Path of the folder.
+	Path string `json:"path"`
+	// Name of the folder.
+	Name string `json:"name"`
+}
+
+// MovedPayload is the payload of the FileMoved and FolderMoved events.
+type MovedPayload struct {
+	// Storage of the file or folder.
+	Storage octoprint.Location `json:"storage"`
+	// SourcePath is the path before the move.
+	SourcePath string `json:"source_path"`
+	// SourceName is the name before the move.
+	SourceName string `json:"source_name"`
+	// DestinationPath is the path after the move.
+	DestinationPath string `json:"destination_path"`
+	// DestinationName is the name after the move.
+	DestinationName string `json:"destination_name"`
+}
+
+// UpdatedFilesPayload is the payload of the UpdatedFiles event.
+type UpdatedFilesPayload struct {
+	// Type of the updated files, e.g. `printables`.
+	Type string `json:"type"`
+}
+
+// MetadataAnalysisPayload is the payload of the MetadataAnalysisStarted and
+// MetadataAnalysisFinished events.
+type MetadataAnalysisPayload struct {
+	// Name of the file.
+	Name string `json:"name"`
+	// Path of the file.
+	Path string `json:"path"`
+	// Origin of the file.
+	Origin octoprint.Location `json:"origin"`
+	// Type of the file.
+	Type []string `json:"type"`
+	// Result of the analysis, only on MetadataAnalysisFinished.
+	Result *octoprint.GCodeAnalysisInformation `json:"result"`
+}
+
+// FileSelectedPayload is the payload of the FileSelected event.
+type FileSelectedPayload struct {
+	// Name of the file.
+	Name string `json:"name"`
+	// Path of the file.
+	Path string `json:"path"`
+	// Origin of the file.
+	Origin octoprint.Location `json:"origin"`
+	// Size of the file in bytes.
+	Size uint64 `json:"size"`
+}
+
+// TransferPayload is the payload of the TransferStarted, TransferDone and
+// TransferFailed events.
+type TransferPayload struct {
+	// Local is the name of the local file.
+	Local string `json:"local"`
+	// Remote is the name of the file on the SD card.
+	Remote string `json:"remote"`
+	// Time of the transfer in seconds, only on TransferDone.
+	Time float64 `json:"time"`
+}
+
+// PrintPayload is the payload of the printing events.
+type PrintPayload struct {
+	// Name of the file.
+	Name string `json:"name"`
+	// Path of the file.
+	Path string `json:"path"`
+	// Origin of the file.
+	Origin octoprint.Location `json:"origin"`
+	// Size of the file in bytes.
+	Size uint64 `json:"size"`
+	// Owner of the file.
+	Owner string `json:"owner"`
+	// User who started the print.
+	User string `json:"user"`
+	// Time elapsed printing in seconds, only on PrintFailed, PrintDone and
+	// PrintCancelled.
+	Time float64 `json:"time"`
+	// Reason of the failure, `error` or `cancelled`, only on PrintFailed.
+	Reason string `json:"reason"`
+	// Position of the print head, only on PrintCancelled and PrintPaused if
+	// known.
+	Position *Position `json:"position"`
+	// FirmwareError is the error reported by the firmware, only on
+	// PrintCancelling if the print was cancelled due to an error.
+	FirmwareError string `json:"firmwareError"`
+}
+
+// Position is the position of the print head, payload of the PositionUpdate
+// event.
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+	E float64 `json:"e"`
+	// T is the active tool.
+	T int `json:"t"`
+	// F is the feed rate.
+	F float64 `json:"f"`
+}
+
+// ZChangePayload is the payload of the ZChange event.
+type ZChangePayload struct {
+	// New Z position.
+	New float64 `json:"new"`
+	// Old Z position, nil for the first change of a print.
+	Old *float64 `json:"old"`
+}
+
+// ToolChangePayload is the payload of the ToolChange event.
+type ToolChangePayload struct {
+	// Old active tool.
+	Old int `json:"old"`
+	// New active tool.
+	New int `json:"new"`
+}
+
+// CommandSuppressedPayload is the payload of the CommandSuppressed event.
+type CommandSuppressedPayload struct {
+	// Command that was suppressed.
+	Command string `json:"command"`
+	// Message explaining why the command was suppressed.
+	Message string `json:"message"`
+	// Severity of the suppression, `info` or `warn`.
+	Severity string `json:"severity"`
+}
+
+// InvalidToolReportedPayload is the payload of the InvalidToolReported event.
+type InvalidToolReportedPayload struct {
+	// Tool reported by the printer.
+	Tool int `json:"tool"`
+	// Fallback tool selected by the server.
+	Fallback int `json:"fallback"`
+}
+
+// CapturePayload is the payload of the CaptureStart, CaptureDone and
+// CaptureFailed events.
+type CapturePayload struct {
+	// File the frame is saved to.
+	File string `json:"file"`
+	// Error of the capture, only on CaptureFailed.
+	Error string `json:"error"`
+}
+
+// PostRollPayload is the payload of the PostRollStart event.
+type PostRollPayload struct {
+	// PostRollDuration is the duration of the post roll in seconds.
+	PostRollDuration float64 `json:"postroll_duration"`
+	// PostRollLength is the number of frames of the post roll.
+	PostRollLength int `json:"postroll_length"`
+	// CaptureDuration is the time between frames in seconds.
+	CaptureDuration float64 `json:"capture_duration"`
+}
+
+// MoviePayload is the payload of the MovieRendering, MovieDone and
+// MovieFailed events.
+type MoviePayload struct {
+	// GCode is the name of the printed file.
+	GCode string `json:"gcode"`
+	// Movie is the path of the timelapse.
+	Movie string `json:"movie"`
+	// MovieBasename is the name of the timelapse.
+	MovieBasename string `json:"movie_basename"`
+	// ReturnCode of the rendering, only on MovieFailed.
+	ReturnCode int `json:"returncode"`
+	// Reason of the failure, only on MovieFailed.
+	Reason string `json:"reason"`
+}
+
+// SlicingPayload is the payload of the SlicingStarted, SlicingDone,
+// SlicingCancelled and SlicingFailed events.
+type SlicingPayload struct {
+	// STL is the name of the sliced model.
+	STL string `json:"stl"`
+	// STLLocation is the location of the sliced model.
+	STLLocation octoprint.Location `json:"stl_location"`
+	// GCode is the name of the sliced file.
+	GCode string `json:"gcode"`
+	// GCodeLocation is the location of the sliced file.
+	GCodeLocation octoprint.Location `json:"gcode_location"`
+	// ProgressAvailable whether progress is reported with SlicingProgress
+	// messages, only on SlicingStarted.
+	ProgressAvailable bool `json:"progressAvailable"`
+	// Time of the slicing in seconds, only on SlicingDone.
+	Time float64 `json:"time"`
+	// Reason of the failure, only on SlicingFailed.
+	Reason string `json:"reason"`
+}
+
+// SlicingProfilePayload is the payload of the slicing profile events.
+type SlicingProfilePayload struct {
+	// Slicer of the profile.
+	Slicer string `json:"slicer"`
+	// Profile is the name of the profile.
+	Profile string `json:"profile"`
+}
+
+// SettingsUpdatedPayload is the payload of the SettingsUpdated event.
+type SettingsUpdatedPayload struct {
+	// ConfigHash is the hash of the new configuration.
+	ConfigHash string `json:"config_hash"`
+	// EffectiveHash is the hash of the new effective configuration.
+	EffectiveHash string `json:"effective_hash"`
+}
+
+// PrinterProfilePayload is the payload of the printer profile events.
+type PrinterProfilePayload struct {
+	// Identifier of the profile.
+	Identifier string `json:"identifier"`
+}
+
+// knownEvents are the event types triggered by OctoPrint, or emitted by the
+// client.
+var knownEvents = map[EventType]bool{
+	EventStartup:                  true,
+	EventShutdown:                 true,
+	EventConnectivityChanged:      true,
+	EventClientOpened:             true,
+	EventClientAuthed:             true,
+	EventClientClosed:             true,
+	EventUserLoggedIn:             true,
+	EventUserLoggedOut:            true,
+	EventConnectionsAutorefreshed: true,
+	EventConnecting:               true,
+	EventConnected:                true,
+	EventDisconnecting:            true,
+	EventDisconnected:             true,
+	EventPrinterStateChanged:      true,
+	EventPrinterReset:             true,
+	EventError:                    true,
+	EventUpload:                   true,
+	EventFileAdded:                true,
+	EventFileRemoved:              true,
+	EventFileMoved:                true,
+	EventFolderAdded:              true,
+	EventFolderRemoved:            true,
+	EventFolderMoved:              true,
+	EventUpdatedFiles:             true,
+	EventMetadataAnalysisStarted:  true,
+	EventMetadataAnalysisFinished: true,
+	EventFileSelected:             true,
+	EventFileDeselected:           true,
+	EventTransferStarted:          true,
+	EventTransferDone:             true,
+	EventTransferFailed:           true,
+	EventPrintStarted:             true,
+	EventPrintFailed:              true,
+	EventPrintDone:                true,
+	EventPrintCancelling:          true,
+	EventPrintCancelled:           true,
+	EventPrintPaused:              true,
+	EventPrintResumed:             true,
+	EventPowerOn:                  true,
+	EventPowerOff:                 true,
+	EventHome:                     true,
+	EventZChange:                  true,
+	EventDwell:                    true,
+	EventWaiting:                  true,
+	EventCooling:                  true,
+	EventAlert:                    true,
+	EventConveyor:                 true,
+	EventEject:                    true,
+	EventEStop:                    true,
+	EventFilamentChange:           true,
+	EventPositionUpdate:           true,
+	EventToolChange:               true,
+	EventCommandSuppressed:        true,
+	EventInvalidToolReported:      true,
+	EventCaptureStart:             true,
+	EventCaptureDone:              true,
+	EventCaptureFailed:            true,
+	EventPostRollStart:            true,
+	EventPostRollEnd:              true,
+	EventMovieRendering:           true,
+	EventMovieDone:                true,
+	EventMovieFailed:              true,
+	EventSlicingStarted:           true,
+	EventSlicingDone:              true,
+	EventSlicingCancelled:         true,
+	EventSlicingFailed:            true,
+	EventSlicingProfileAdded:      true,
+	EventSlicingProfileModified:   true,
+	EventSlicingProfileDeleted:    true,
+	EventSettingsUpdated:          true,
+	EventPrinterProfileAdded:      true,
+	EventPrinterProfileModified:   true,
+	EventPrinterProfileDeleted:    true,
+	EventSocketDisconnected:       true,
+	EventSocketReconnected:        true,
+}
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	return string(t)
+}
+
+// IsValid returns true if the event type is triggered by OctoPrint, or
+// emitted by the client. Events triggered by plugins are not valid.
+func (t EventType) IsValid() bool {
+	return knownEvents[t]
+}
+
+// ParseEventType returns the event type with the given name, returning an
+// error if it's not valid.
+func ParseEventType(s string) (EventType, error) {
+	t := EventType(s)
+	if !t.IsValid() {
+		return "", fmt.Errorf("unknown event type %q", s)
+	}
+
+	return t, nil
+}
+
+// eventPayloads maps the event types to a constructor of their payload.
+var eventPayloads = map[EventType]func() interface{}{
+	EventConnectivityChanged:      func() interface{} { return &ConnectivityChangedPayload{} },
+	EventClientOpened:             func() interface{} { return &ClientPayload{} },
+	EventClientAuthed:             func() interface{} { return &ClientPayload{} },
+	EventClientClosed:             func() interface{} { return &ClientPayload{} },
+	EventUserLoggedIn:             func() interface{} { return &UserPayload{} },
+	EventUserLoggedOut:            func() interface{} { return &UserPayload{} },
+	EventConnectionsAutorefreshed: func() interface{} { return &ConnectionsAutorefreshedPayload{} },
+	EventConnected:                func() interface{} { return &ConnectedPayload{} },
+	EventPrinterStateChanged:      func() interface{} { return &PrinterStateChangedPayload{} },
+	EventError:                    func() interface{} { return &ErrorPayload{} },
+	EventUpload:                   func() interface{} { return &UploadPayload{} },
+	EventFileAdded:                func() interface{} { return &FilePayload{} },
+	EventFileRemoved:              func() interface{} { return &FilePayload{} },
+	EventFileMoved:                func() interface{} { return &MovedPayload{} },
+	EventFolderAdded:              func() interface{} { return &FolderPayload{} },
+	EventFolderRemoved:            func() interface{} { return &FolderPayload{} },
+	EventFolderMoved:              func() interface{} { return &MovedPayload{} },
+	EventUpdatedFiles:             func() interface{} { return &UpdatedFilesPayload{} },
+	EventMetadataAnalysisStarted:  func() interface{} { return &MetadataAnalysisPayload{} },
+	EventMetadataAnalysisFinished: func() interface{} { return &MetadataAnalysisPayload{} },
+	EventFileSelected:             func() interface{} { return &FileSelectedPayload{} },
+	EventTransferStarted:          func() interface{} { return &TransferPayload{} },
+	EventTransferDone:             func() interface{} { return &TransferPayload{} },
+	EventTransferFailed:           func() interface{} { return &TransferPayload{} },
+	EventPrintStarted:             func() interface{} { return &PrintPayload{} },
+	EventPrintFailed:              func() interface{} { return &PrintPayload{} },
+	EventPrintDone:                func() interface{} { return &PrintPayload{} },
+	EventPrintCancelling:          func() interface{} { return &PrintPayload{} },
+	EventPrintCancelled:           func() interface{} { return &PrintPayload{} },
+	EventPrintPaused:              func() interface{} { return &PrintPayload{} },
+	EventPrintResumed:             func() interface{} { return &PrintPayload{} },
+	EventZChange:                  func() interface{} { return &ZChangePayload{} },
+	EventPositionUpdate:           func() interface{} { return &Position{} },
+	EventToolChange:               func() interface{} { return &ToolChangePayload{} },
+	EventCommandSuppressed:        func() interface{} { return &CommandSuppressedPayload{} },
+	EventInvalidToolReported:      func() interface{} { return &InvalidToolReportedPayload{} },
+	EventCaptureStart:             func() interface{} { return &CapturePayload{} },
+	EventCaptureDone:              func() interface{} { return &CapturePayload{} },
+	EventCaptureFailed:            func() interface{} { return &CapturePayload{} },
+	EventPostRollStart:            func() interface{} { return &PostRollPayload{} },
+	EventMovieRendering:           func() interface{} { return &MoviePayload{} },
+	EventMovieDone:                func() interface{} { return &MoviePayload{} },
+	EventMovieFailed:              func() interface{} { return &MoviePayload{} },
+	EventSlicingStarted:           func() interface{} { return &SlicingPayload{} },
+	EventSlicingDone:              func() interface{} { return &SlicingPayload{} },
+	EventSlicingCancelled:         func() interface{} { return &SlicingPayload{} },
+	EventSlicingFailed:            func() interface{} { return &SlicingPayload{} },
+	EventSlicingProfileAdded:      func() interface{} { return &SlicingProfilePayload{} },
+	EventSlicingProfileModified:   func() interface{} { return &SlicingProfilePayload{} },
+	EventSlicingProfileDeleted:    func() interface{} { return &SlicingProfilePayload{} },
+	EventSettingsUpdated:          func() interface{} { return &SettingsUpdatedPayload{} },
+	EventPrinterProfileAdded:      func() interface{} { return &PrinterProfilePayload{} },
+	EventPrinterProfileModified:   func() interface{} { return &PrinterProfilePayload{} },
+	EventPrinterProfileDeleted:    func() interface{} { return &PrinterProfilePayload{} },
+	EventSocketDisconnected:       func() interface{} { return &SocketDisconnectedPayload{} },
+	EventSocketReconnected:        func() interface{} { return &Connected{} },
+}
+
+// DecodePayload decodes the payload of the event into the payload struct of
+// its type, e.g. a *PrintPayload for EventPrintStarted. Events without payload
+// return nil, and unknown events, e.g. triggered by plugins, return the raw
+// payload as a json.RawMessage.
+func (e *Event) DecodePayload() (interface{}, error) {
+	fn, ok := eventPayloads[e.Type]
+	if !ok {
+		if len(e.Payload) == 0 || string(e.Payload) == "null" {
+			return nil, nil
+		}
+
+		return e.Payload, nil
+	}
+
+	v := fn()
+	if len(e.Payload) == 0 || string(e.Payload) == "null" {
+		return v, nil
+	}
+
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
@@ -0,0 +1,72 @@
+package push
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvent_DecodePayload(t *testing.T) {
+	e := &Event{Type: EventPrintDone, Payload: json.RawMessage(`{
+		"name": "benchy.gcode",
+		"path": "folder/benchy.gcode",
+		"origin": "local",
+		"size": 1024,
+		"time": 3600.5
+	}`)}
+
+	v, err := e.DecodePayload()
+	assert.NoError(t, err)
+
+	p := v.(*PrintPayload)
+	assert.Equal(t, "folder/benchy.gcode", p.Path)
+	assert.Equal(t, octoprint.Local, p.Origin)
+	assert.Equal(t, uint64(1024), p.Size)
+	assert.Equal(t, 3600.5, p.Time)
+
+	e = &Event{Type: EventZChange, Payload: json.RawMessage(`{"new": 0.3, "old": null}`)}
+	v, err = e.DecodePayload()
+	assert.NoError(t, err)
+	assert.Equal(t, 0.3, v.(*ZChangePayload).New)
+	assert.Nil(t, v.(*ZChangePayload).Old)
+
+	e = &Event{Type: EventMetadataAnalysisFinished, Payload: json.RawMessage(`{
+		"name": "benchy.gcode",
+		"origin": "local",
+		"result": {"estimatedPrintTime": 1200}
+	}`)}
+
+	v, err = e.DecodePayload()
+	assert.NoError(t, err)
+	assert.Equal(t, 1200., v.(*MetadataAnalysisPayload).Result.EstimatedPrintTime)
+}
+
+func TestEvent_DecodePayloadWithoutPayload(t *testing.T) {
+	v, err := (&Event{Type: EventStartup, Payload: json.RawMessage(`null`)}).DecodePayload()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	v, err = (&Event{Type: EventUserLoggedIn}).DecodePayload()
+	assert.NoError(t, err)
+	assert.Equal(t, &UserPayload{}, v)
+}
+
+func TestEvent_DecodePayloadUnknown(t *testing.T) {
+	e := &Event{Type: "plugin_foo_event", Payload: json.RawMessage(`{"foo": 1}`)}
+	v, err := e.DecodePayload()
+	assert.NoError(t, err)
+	assert.Equal(t, json.RawMessage(`{"foo": 1}`), v)
+}
+
+func TestParseEventType(t *testing.T) {
+	e, err := ParseEventType("PrintDone")
+	assert.NoError(t, err)
+	assert.Equal(t, EventPrintDone, e)
+	assert.Equal(t, "PrintDone", e.String())
+
+	_, err = ParseEventType("PrintExploded")
+	assert.EqualError(t, err, `unknown event type "PrintExploded"`)
+	assert.False(t, EventType("").IsValid())
+}
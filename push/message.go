@@ -0,0 +1,125 @@
+package push
+
+import (
+	"encoding/json"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+// Message is a message received from the push API. Only one of the fields is
+// set, depending on the type of the message.
+type Message struct {
+	// Connected is sent once, right after the connection is established.
+	Connected *Connected `json:"connected"`
+	// Current is sent periodically with the current state of the printer.
+	Current *Current `json:"current"`
+	// History is sent once after connecting or authenticating, with the
+	// state of the printer and the temperature history.
+	History *Current `json:"history"`
+	// Event is sent for every event triggered on the server.
+	Event *Event `json:"event"`
+	// Plugin is sent by plugins with plugin specific data.
+	Plugin *PluginMessage `json:"plugin"`
+	// SlicingProgress is sent while slicing a file.
+	SlicingProgress *SlicingProgress `json:"slicingProgress"`
+	// Timelapse is sent when the timelapse configuration changes.
+	Timelapse *octoprint.TimelapseConfig `json:"timelapse"`
+	// ReauthRequired is sent when the authentication of the socket is not
+	// valid anymore, e.g. after a logout.
+	ReauthRequired *ReauthRequired `json:"reauthRequired"`
+}
+
+// Connected is the message sent by the server right after connecting.
+type Connected struct {
+	// Version of the server.
+	Version string `json:"version"`
+	// DisplayVersion is the version of the server, to display.
+	DisplayVersion string `json:"display_version"`
+	// Branch of the server, if running from source.
+	Branch string `json:"branch"`
+	// PluginHash is the hash of the enabled plugins, it changes when the
+	// plugins change.
+	PluginHash string `json:"plugin_hash"`
+	// ConfigHash is the hash of the configuration, it changes when the
+	// configuration changes.
+	ConfigHash string `json:"config_hash"`
+	// Debug whether the server runs in debug mode.
+	Debug bool `json:"debug"`
+	// SafeMode whether the server runs in safe mode.
+	SafeMode bool `json:"safe_mode"`
+	// Permissions are the permissions available on the server.
+	Permissions []*octoprint.Permission `json:"permissions"`
+}
+
+// Current is the state of the printer, sent by the server periodically.
+type Current struct {
+	// State of the printer.
+	State octoprint.PrinterState `json:"state"`
+	// Job is the current print job.
+	Job octoprint.JobInformation `json:"job"`
+	// Progress of the current print job.
+	Progress octoprint.ProgressInformation `json:"progress"`
+	// CurrentZ is the current Z position of the print head, nil if unknown.
+	CurrentZ *float64 `json:"currentZ"`
+	// Offsets are the temperature offsets, by heater.
+	Offsets map[string]float64 `json:"offsets"`
+	// Temps are the temperatures since the last message.
+	Temps []*octoprint.HistoricTemperatureData `json:"temps"`
+	// Logs are the lines of the serial communication since the last
+	// message.
+	Logs []string `json:"logs"`
+	// Messages are the messages received from the printer since the last
+	// message.
+	Messages []string `json:"messages"`
+	// BusyFiles are the files currently in use, e.g. being printed.
+	BusyFiles []*BusyFile `json:"busyFiles"`
+	// ServerTime is the time of the server, as unix timestamp.
+	ServerTime float64 `json:"serverTime"`
+}
+
+// BusyFile is a file in use.
+type BusyFile struct {
+	// Origin of the file, `local` or `sdcard`.
+	Origin octoprint.Location `json:"origin"`
+	// Path of the file.
+	Path string `json:"path"`
+}
+
+// Event is an event triggered on the server.
+type Event struct {
+	// Type of the event, e.g. `PrintStarted`.
+	Type EventType `json:"type"`
+	// Payload of the event, its schema depends on the type.
+	Payload json.RawMessage `json:"payload"`
+}
+
+// PluginMessage is a message sent by a plugin.
+type PluginMessage struct {
+	// Plugin is the identifier of the plugin.
+	Plugin string `json:"plugin"`
+	// Data is the plugin specific data.
+	Data json.RawMessage `json:"data"`
+}
+
+// SlicingProgress is the progress of a slicing job.
+type SlicingProgress struct {
+	// Slicer used to slice the file.
+	Slicer string `json:"slicer"`
+	// SourceLocation of the file being sliced.
+	SourceLocation octoprint.Location `json:"source_location"`
+	// SourcePath of the file being sliced.
+	SourcePath string `json:"source_path"`
+	// DestLocation of the sliced file.
+	DestLocation octoprint.Location `json:"dest_location"`
+	// DestPath of the sliced file.
+	DestPath string `json:"dest_path"`
+	// Progress of the slicing job, in percent.
+	Progress float64 `json:"progress"`
+}
+
+// ReauthRequired is sent when the socket needs to be authenticated again.
+type ReauthRequired struct {
+	// Reason why the authentication is required, e.g. `logout`, `stale` or
+	// `removed`.
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,110 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// PluginPSUControl is the identifier of the PSU Control plugin.
+const PluginPSUControl = "psucontrol"
+
+// PSUControlData is the data of the messages of the PSU Control plugin.
+type PSUControlData struct {
+	// IsPSUOn whether the power supply is on.
+	IsPSUOn bool `json:"isPSUOn"`
+}
+
+var (
+	pluginsMu   sync.RWMutex
+	pluginTypes = map[string]func() interface{}{
+		PluginPSUControl: func() interface{} { return &PSUControlData{} },
+	}
+)
+
+// RegisterPlugin registers the type of the data sent by the given plugin, fn
+// returns a new value to decode the data into, e.g.
+//
+//	push.RegisterPlugin("DisplayLayerProgress", func() interface{} {
+//		return &LayerProgress{}
+//	})
+//
+// Registering a plugin again replaces the previous type.
+func RegisterPlugin(plugin string, fn func() interface{}) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	pluginTypes[plugin] = fn
+}
+
+// DecodeData decodes the data of the message into the type registered for its
+// plugin with RegisterPlugin. Messages of unknown plugins return the raw data
+// as a json.RawMessage.
+func (m *PluginMessage) DecodeData() (interface{}, error) {
+	pluginsMu.RLock()
+	fn, ok := pluginTypes[m.Plugin]
+	pluginsMu.RUnlock()
+
+	if !ok {
+		return m.Data, nil
+	}
+
+	v := fn()
+	if len(m.Data) == 0 || string(m.Data) == "null" {
+		return v, nil
+	}
+
+	if err := json.Unmarshal(m.Data, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+type pluginSubscriber struct {
+	plugin string
+	ch     chan interface{}
+	closed chan struct{}
+}
+
+func (s *pluginSubscriber) deliver(m *Message) {
+	if m.Plugin == nil || m.Plugin.Plugin != s.plugin {
+		return
+	}
+
+	v, err := m.Plugin.DecodeData()
+	if err != nil {
+		return
+	}
+
+	select {
+	case s.ch <- v:
+	default:
+	}
+}
+
+func (s *pluginSubscriber) close() {
+	close(s.ch)
+	close(s.closed)
+}
+
+func (s *pluginSubscriber) done() <-chan struct{} {
+	return s.closed
+}
+
+// SubscribePlugin returns a channel receiving the data of the messages sent by
+// the given plugin, decoded as DecodeData does, e.g. a *PSUControlData for
+// PluginPSUControl. Messages that can't be decoded are dropped. The
+// subscription follows the same rules as Subscribe.
+func (c *Client) SubscribePlugin(ctx context.Context, plugin string) (<-chan interface{}, error) {
+	s := &pluginSubscriber{
+		plugin: plugin,
+		ch:     make(chan interface{}, c.bufferSize),
+		closed: make(chan struct{}),
+	}
+
+	if err := c.subscribe(ctx, s); err != nil {
+		return nil, err
+	}
+
+	return s.ch, nil
+}
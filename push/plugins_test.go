@@ -0,0 +1,63 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginMessage_DecodeData(t *testing.T) {
+	m := &PluginMessage{Plugin: PluginPSUControl, Data: json.RawMessage(`{"isPSUOn": true}`)}
+	v, err := m.DecodeData()
+	assert.NoError(t, err)
+	assert.True(t, v.(*PSUControlData).IsPSUOn)
+
+	m = &PluginMessage{Plugin: "unknown", Data: json.RawMessage(`{"foo": 1}`)}
+	v, err = m.DecodeData()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"foo": 1}`, string(v.(json.RawMessage)))
+}
+
+func TestRegisterPlugin(t *testing.T) {
+	type layerProgress struct {
+		CurrentLayer string `json:"currentLayer"`
+	}
+
+	RegisterPlugin("DisplayLayerProgress", func() interface{} { return &layerProgress{} })
+	defer func() {
+		pluginsMu.Lock()
+		delete(pluginTypes, "DisplayLayerProgress")
+		pluginsMu.Unlock()
+	}()
+
+	m := &PluginMessage{Plugin: "DisplayLayerProgress", Data: json.RawMessage(`{"currentLayer": "3"}`)}
+	v, err := m.DecodeData()
+	assert.NoError(t, err)
+	assert.Equal(t, "3", v.(*layerProgress).CurrentLayer)
+}
+
+func TestClient_SubscribePlugin(t *testing.T) {
+	ts, _ := newServer(t,
+		`{"plugin": {"plugin": "psucontrol", "data": {"isPSUOn": true}}}`,
+		`{"plugin": {"plugin": "other", "data": {}}}`,
+		`{"plugin": {"plugin": "psucontrol", "data": {"isPSUOn": "invalid"}}}`,
+		`{"plugin": {"plugin": "psucontrol", "data": {"isPSUOn": false}}}`,
+	)
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+
+	ch, err := c.SubscribePlugin(context.Background(), PluginPSUControl)
+	assert.NoError(t, err)
+
+	var states []bool
+	for v := range ch {
+		states = append(states, v.(*PSUControlData).IsPSUOn)
+	}
+
+	assert.Equal(t, []bool{true, false}, states)
+}
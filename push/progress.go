@@ -0,0 +1,76 @@
+package push
+
+import (
+	"context"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+type progressSubscriber struct {
+	ch     chan octoprint.ProgressInformation
+	closed chan struct{}
+	last   *octoprint.ProgressInformation
+}
+
+func (s *progressSubscriber) deliver(m *Message) {
+	var p octoprint.ProgressInformation
+	switch {
+	case m.History != nil:
+		p = m.History.Progress
+	case m.Current != nil:
+		p = m.Current.Progress
+	case m.Event != nil && m.Event.Type == EventPrintDone && s.last != nil:
+		// the last `current` message of a print is usually sent before the
+		// print is done, leaving the progress slightly below 100%.
+		var left float64
+		p = *s.last
+		p.Completion, p.PrintTimeLeft = 100, &left
+	default:
+		return
+	}
+
+	if s.last != nil && s.last.Equal(&p) {
+		return
+	}
+
+	s.last = &p
+	select {
+	case s.ch <- p:
+	default:
+	}
+}
+
+func (s *progressSubscriber) close() {
+	close(s.ch)
+	close(s.closed)
+}
+
+func (s *progressSubscriber) done() <-chan struct{} {
+	return s.closed
+}
+
+// OnProgress calls fn with the progress of the current print job every time it
+// changes, as reported by the `history` and `current` messages, until ctx is
+// done or the connection is lost. Unchanged updates are skipped, and the
+// progress is reported as completed when the print is done.
+//
+// fn is called sequentially from a dedicated goroutine, updates received
+// while fn is running are buffered, following the same rules as Subscribe.
+func (c *Client) OnProgress(ctx context.Context, fn func(octoprint.ProgressInformation)) error {
+	s := &progressSubscriber{
+		ch:     make(chan octoprint.ProgressInformation, c.bufferSize),
+		closed: make(chan struct{}),
+	}
+
+	if err := c.subscribe(ctx, s); err != nil {
+		return err
+	}
+
+	go func() {
+		for p := range s.ch {
+			fn(p)
+		}
+	}()
+
+	return nil
+}
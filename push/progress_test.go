@@ -0,0 +1,53 @@
+package push
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_OnProgress(t *testing.T) {
+	ts, _ := newServer(t,
+		`{"history": {"progress": {"completion": 10, "filepos": 100, "printTime": 60, "printTimeLeft": 540}}}`,
+		`{"current": {"progress": {"completion": 10, "filepos": 100, "printTime": 60, "printTimeLeft": 540}}}`,
+		`{"current": {"progress": {"completion": 99.5, "filepos": 995, "printTime": 597, "printTimeLeft": 3}}}`,
+		`{"event": {"type": "PrintDone", "payload": {"name": "benchy.gcode"}}}`,
+	)
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+
+	var mu sync.Mutex
+	var updates []octoprint.ProgressInformation
+	done := make(chan struct{})
+
+	err := c.OnProgress(context.Background(), func(p octoprint.ProgressInformation) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		updates = append(updates, p)
+		if len(updates) == 3 {
+			close(done)
+		}
+	})
+	assert.NoError(t, err)
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []octoprint.ProgressInformation{
+		{Completion: 10, FilePosition: 100, PrintTime: float(60), PrintTimeLeft: float(540)},
+		{Completion: 99.5, FilePosition: 995, PrintTime: float(597), PrintTimeLeft: float(3)},
+		{Completion: 100, FilePosition: 995, PrintTime: float(597), PrintTimeLeft: float(0)},
+	}, updates)
+}
+
+func float(v float64) *float64 {
+	return &v
+}
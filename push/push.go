@@ -0,0 +1,412 @@
+// Package push implements a client for the push API of OctoPrint, receiving
+// the state of the printer and the events of the server over a websocket,
+// instead of polling the REST API.
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mcuadros/go-octoprint"
+)
+
+// URIWebsocket is the raw websocket endpoint of the SockJS server of OctoPrint.
+const URIWebsocket = "/sockjs/websocket"
+
+// ErrNotConnected is returned when using a client that is not connected.
+var ErrNotConnected = errors.New("Push client not connected")
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithAuth configures the client to authenticate the socket with the given
+// user and session, as returned by a passive login. Without authentication
+// the socket only receives the data available to anonymous users.
+func WithAuth(user, session string) Option {
+	return func(c *Client) {
+		c.user, c.session = user, session
+	}
+}
+
+// WithDialer configures the websocket dialer used to connect.
+func WithDialer(d *websocket.Dialer) Option {
+	return func(c *Client) {
+		c.dialer = d
+	}
+}
+
+// WithHeader adds a header sent when connecting, on the websocket handshake or
+// on every request of the XHR transports, e.g. for reverse proxies requiring
+// authentication.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithThrottle configures the throttle factor sent right after connecting, see
+// Client.Throttle.
+func WithThrottle(factor int) Option {
+	return func(c *Client) {
+		c.throttle = factor
+	}
+}
+
+// WithLogger sets the logger used by the client to report the connections
+// lost, the reconnections and the messages that can't be decoded, by default
+// nothing is logged.
+func WithLogger(l octoprint.Logger) Option {
+	return func(c *Client) {
+		c.log = l
+	}
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// Client is a client of the push API.
+type Client struct {
+	// Endpoint address of the OctoPrint server, the same used by the REST
+	// client, e.g. `http://localhost:5000`.
+	Endpoint string
+
+	user, session string
+	dialer        *websocket.Dialer
+	httpClient    *http.Client
+	transports    []Transport
+	header        http.Header
+	bufferSize    int
+	reconnect     *ReconnectPolicy
+	rest          *octoprint.Client
+	poll          *octoprint.Client
+	pollInterval  time.Duration
+	throttle      int
+	log           octoprint.Logger
+
+	mu        sync.Mutex
+	writeMu   sync.Mutex
+	sock      socket
+	transport Transport
+	connected *Connected
+	history   *Current
+	closing   chan struct{}
+	subs      subscriptions
+
+	// lost is set by the reader when the connection is lost, to reconnect on
+	// the next read.
+	lost bool
+}
+
+// NewClient returns a new push API client, Connect needs to be called before
+// receiving messages.
+func NewClient(endpoint string, opts ...Option) *Client {
+	c := &Client{
+		Endpoint:   endpoint,
+		dialer:     websocket.DefaultDialer,
+		transports: DefaultTransports,
+		header:     make(http.Header),
+		bufferSize: DefaultBufferSize,
+		log:        nopLogger{},
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Connect connects to the server and performs the handshake, waiting for the
+// `connected` message and authenticating the socket if configured.
+func (c *Client) Connect(ctx context.Context) error {
+	var sock socket
+	var transport Transport
+	var err error
+	for _, t := range c.transports {
+		sock, err = c.dial(ctx, t)
+		if err == nil {
+			transport = t
+			break
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	if sock == nil {
+		if err == nil {
+			err = errors.New("no transport configured")
+		}
+
+		return err
+	}
+
+	connected, err := handshake(ctx, sock)
+	if err != nil {
+		sock.close()
+		return err
+	}
+
+	c.mu.Lock()
+	old := c.sock
+	c.sock, c.transport, c.connected, c.history = sock, transport, connected, nil
+	if c.closing == nil {
+		c.closing = make(chan struct{})
+	}
+	c.mu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+
+	c.subs.mu.Lock()
+	c.subs.err = nil
+	c.subs.mu.Unlock()
+
+	user, session, err := c.credentials(ctx)
+	if err != nil {
+		c.Close()
+		return err
+	}
+
+	if user != "" {
+		if err := c.Auth(user, session); err != nil {
+			c.Close()
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	throttle := c.throttle
+	c.mu.Unlock()
+
+	if throttle > 1 {
+		if err := c.sendThrottle(throttle); err != nil {
+			c.Close()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// handshake waits for the `connected` message, honoring the deadline of ctx.
+func handshake(ctx context.Context, sock socket) (*Connected, error) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			sock.close()
+		case <-done:
+		}
+	}()
+
+	for {
+		m, err := readMessage(sock)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			return nil, err
+		}
+
+		if m.Connected != nil {
+			return m.Connected, nil
+		}
+	}
+}
+
+// Connected returns the information sent by the server on the handshake, nil if
+// not connected.
+func (c *Client) Connected() *Connected {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connected
+}
+
+// Transport returns the transport used by the current connection, empty if
+// not connected.
+func (c *Client) Transport() Transport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.transport
+}
+
+// History returns the last `history` message received since connecting, a
+// snapshot of the state of the printer including the temperature history and
+// the recent log lines, nil if not received yet. The server sends it right
+// after connecting and again after authenticating.
+func (c *Client) History() *Current {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.history
+}
+
+// Auth authenticates the socket with the given user and session, as returned
+// by a passive login.
+func (c *Client) Auth(user, session string) error {
+	return c.Send(map[string]string{"auth": fmt.Sprintf("%s:%s", user, session)})
+}
+
+// Throttle reduces the rate of the `current` messages sent by the server, by
+// the given factor of the base rate of 500ms, e.g. a factor of 2 receives a
+// message every second. A factor of 1 restores the base rate. The factor is
+// sent again when reconnecting.
+func (c *Client) Throttle(factor int) error {
+	if factor < 1 {
+		return fmt.Errorf("invalid throttle factor %d, must be at least 1", factor)
+	}
+
+	c.mu.Lock()
+	c.throttle = factor
+	c.mu.Unlock()
+
+	return c.sendThrottle(factor)
+}
+
+func (c *Client) sendThrottle(factor int) error {
+	return c.Send(map[string]int{"throttle": factor})
+}
+
+// Send sends a message to the server, encoded as JSON.
+func (c *Client) Send(v interface{}) error {
+	sock := c.conn()
+	if sock == nil {
+		return ErrNotConnected
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return sock.write(v)
+}
+
+// Next blocks until the next message is received, returning an error if the
+// connection is lost or closed. It returns ErrSubscribed once Subscribe has
+// been called.
+func (c *Client) Next() (*Message, error) {
+	c.subs.mu.Lock()
+	running := c.subs.running
+	c.subs.mu.Unlock()
+
+	if running {
+		return nil, ErrSubscribed
+	}
+
+	return c.next()
+}
+
+func (c *Client) next() (*Message, error) {
+	if c.lost {
+		return c.reconnected()
+	}
+
+	sock := c.conn()
+	if sock == nil {
+		return nil, ErrNotConnected
+	}
+
+	b, err := sock.read()
+	if err != nil {
+		if c.reconnect != nil && c.conn() == sock {
+			return c.dropped(err), nil
+		}
+
+		return nil, err
+	}
+
+	m, err := decodeMessage(b)
+	if err != nil {
+		c.log.Warn("failed to decode message", "error", err)
+		return nil, err
+	}
+
+	if m.History != nil {
+		c.mu.Lock()
+		c.history = m.History
+		c.mu.Unlock()
+	}
+
+	if m.ReauthRequired != nil && c.rest != nil {
+		if err := c.reauth(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	sock := c.sock
+	c.sock, c.transport, c.connected, c.history = nil, "", nil, nil
+	if c.closing != nil {
+		close(c.closing)
+		c.closing = nil
+	}
+	c.mu.Unlock()
+
+	if sock == nil {
+		return nil
+	}
+
+	return sock.close()
+}
+
+func (c *Client) conn() socket {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sock
+}
+
+func readMessage(sock socket) (*Message, error) {
+	b, err := sock.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeMessage(b)
+}
+
+func decodeMessage(b []byte) (*Message, error) {
+	m := &Message{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// websocketURL returns the URL of the websocket endpoint for the given server
+// address.
+func websocketURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	switch u.Scheme {
+	case "http", "":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + URIWebsocket
+	return u.String(), nil
+}
@@ -0,0 +1,209 @@
+package push
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// newServer returns a push API server sending the `connected` message followed
+// by the given frames, and reporting the messages received from the client.
+func newServer(t *testing.T, frames ...string) (*httptest.Server, <-chan string) {
+	received := make(chan string, 10)
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != URIWebsocket {
+			w.WriteHeader(404)
+			return
+		}
+
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer ws.Close()
+		ws.WriteMessage(websocket.TextMessage, []byte(`{"connected": {
+			"version": "1.8.6",
+			"display_version": "1.8.6",
+			"plugin_hash": "abc",
+			"config_hash": "def",
+			"safe_mode": false
+		}}`))
+
+		go func() {
+			for {
+				_, b, err := ws.ReadMessage()
+				if err != nil {
+					return
+				}
+
+				received <- string(b)
+			}
+		}()
+
+		for _, f := range frames {
+			ws.WriteMessage(websocket.TextMessage, []byte(f))
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}))
+
+	return ts, received
+}
+
+func TestClient_Connect(t *testing.T) {
+	ts, received := newServer(t,
+		`{"current": {
+			"state": {"text": "Printing", "flags": {"printing": true}},
+			"job": {"file": {"name": "benchy.gcode"}},
+			"progress": {"completion": 42.5, "printTime": 600},
+			"currentZ": 1.2,
+			"temps": [{"time": 1600000000, "tool0": {"actual": 210.1, "target": 210}}],
+			"logs": ["Send: M105"],
+			"busyFiles": [{"origin": "local", "path": "benchy.gcode"}],
+			"serverTime": 1600000000.5
+		}}`,
+		`{"event": {"type": "PrintStarted", "payload": {"name": "benchy.gcode"}}}`,
+		`{"plugin": {"plugin": "psucontrol", "data": {"isPSUOn": true}}}`,
+		`{"slicingProgress": {"slicer": "curalegacy", "source_path": "cube.stl", "progress": 50}}`,
+		`{"timelapse": {"type": "zchange", "fps": 25}}`,
+	)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithAuth("foo", "bar"))
+	err := c.Connect(context.Background())
+	assert.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, "1.8.6", c.Connected().Version)
+	assert.JSONEq(t, `{"auth": "foo:bar"}`, <-received)
+
+	m, err := c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "Printing", m.Current.State.Text)
+	assert.True(t, m.Current.State.Flags.Printing)
+	assert.Equal(t, "benchy.gcode", m.Current.Job.File.Name)
+	assert.Equal(t, 42.5, m.Current.Progress.Completion)
+	assert.Equal(t, 1.2, *m.Current.CurrentZ)
+	assert.Equal(t, 210.1, m.Current.Temps[0].Tools["tool0"].Actual)
+	assert.Equal(t, "benchy.gcode", m.Current.BusyFiles[0].Path)
+
+	m, err = c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, EventPrintStarted, m.Event.Type)
+	assert.JSONEq(t, `{"name": "benchy.gcode"}`, string(m.Event.Payload))
+
+	m, err = c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "psucontrol", m.Plugin.Plugin)
+
+	m, err = c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, 50., m.SlicingProgress.Progress)
+
+	m, err = c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "zchange", m.Timelapse.Type)
+
+	assert.NoError(t, c.Close())
+	_, err = c.Next()
+	assert.Equal(t, ErrNotConnected, err)
+}
+
+func TestClient_ConnectCancelled(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer ws.Close()
+		time.Sleep(time.Second)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := NewClient(ts.URL).Connect(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestClient_History(t *testing.T) {
+	ts, _ := newServer(t,
+		`{"history": {
+			"state": {"text": "Operational", "flags": {"operational": true, "ready": true}},
+			"job": {"file": {"name": "benchy.gcode", "origin": "local"}, "estimatedPrintTime": 3600},
+			"progress": {"completion": null},
+			"currentZ": null,
+			"offsets": {"tool0": 5},
+			"temps": [
+				{"time": 1600000000, "tool0": {"actual": 24.5, "target": 0}, "bed": {"actual": 23.1, "target": 0}},
+				{"time": 1600000002, "tool0": {"actual": 25.0, "target": 210}, "bed": {"actual": 23.2, "target": 60}}
+			],
+			"logs": ["Recv: ok", "Send: M105"],
+			"messages": ["ok"],
+			"busyFiles": []
+		}}`,
+	)
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+	assert.Nil(t, c.History())
+
+	m, err := c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, m.History, c.History())
+
+	h := c.History()
+	assert.Equal(t, "Operational", h.State.Text)
+	assert.True(t, h.State.Flags.Ready)
+	assert.Equal(t, "benchy.gcode", h.Job.File.Name)
+	assert.Nil(t, h.CurrentZ)
+	assert.Equal(t, 5., h.Offsets["tool0"])
+	assert.Len(t, h.Temps, 2)
+	assert.Equal(t, 210., *h.Temps[1].Tools["tool0"].Target)
+	assert.Equal(t, 60., *h.Temps[1].Tools["bed"].Target)
+	assert.Equal(t, []string{"Recv: ok", "Send: M105"}, h.Logs)
+
+	assert.NoError(t, c.Close())
+	assert.Nil(t, c.History())
+}
+
+func TestClient_Throttle(t *testing.T) {
+	ts, received := newServer(t)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithThrottle(2))
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+
+	assert.JSONEq(t, `{"throttle": 2}`, <-received)
+
+	assert.NoError(t, c.Throttle(4))
+	assert.JSONEq(t, `{"throttle": 4}`, <-received)
+
+	assert.Error(t, c.Throttle(0))
+}
+
+func TestWebsocketURL(t *testing.T) {
+	for endpoint, expected := range map[string]string{
+		"http://localhost:5000":          "ws://localhost:5000/sockjs/websocket",
+		"https://example.com/octoprint/": "wss://example.com/octoprint/sockjs/websocket",
+	} {
+		u, err := websocketURL(endpoint)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, u)
+		assert.True(t, strings.HasSuffix(u, URIWebsocket))
+	}
+}
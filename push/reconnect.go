@@ -0,0 +1,133 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"time"
+)
+
+// DefaultReconnectPolicy is a sensible reconnect policy, retrying forever with
+// a backoff up to 30 seconds, enough to survive a restart of the server.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	MinBackoff: time.Second,
+	MaxBackoff: 30 * time.Second,
+	Jitter:     0.2,
+}
+
+// ReconnectPolicy describes how the client reconnects when the connection to
+// the server is lost.
+type ReconnectPolicy struct {
+	// MaxAttempts is the maximum number of attempts to reconnect, zero means
+	// retrying forever.
+	MaxAttempts int
+	// MinBackoff is the time to wait before the first attempt, it is doubled
+	// after every failed attempt.
+	MinBackoff time.Duration
+	// MaxBackoff is the upper limit of the time to wait between attempts.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, between 0 and 1, of random variation applied to
+	// every backoff, avoiding several clients reconnecting in lockstep.
+	Jitter float64
+}
+
+// WithReconnect configures the client to reconnect when the connection to the
+// server is lost, following the given policy. The socket is authenticated
+// again and the subscriptions are kept, an EventSocketDisconnected event is
+// emitted when the connection is lost and an EventSocketReconnected event once
+// it is established again, so consumers can handle the gap.
+func WithReconnect(p ReconnectPolicy) Option {
+	return func(c *Client) {
+		c.reconnect = &p
+	}
+}
+
+func (p *ReconnectPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+	}
+
+	return d
+}
+
+// redial reconnects to the server following the reconnect policy, giving up
+// when the attempts are exhausted or the client is closed.
+func (c *Client) redial() error {
+	c.mu.Lock()
+	closing := c.closing
+	c.mu.Unlock()
+
+	if closing == nil {
+		return ErrNotConnected
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var err error
+	for attempt := 1; c.reconnect.MaxAttempts <= 0 || attempt <= c.reconnect.MaxAttempts; attempt++ {
+		select {
+		case <-time.After(c.reconnect.backoff(attempt)):
+		case <-ctx.Done():
+			return ErrNotConnected
+		}
+
+		c.log.Debug("reconnecting", "attempt", attempt)
+		err = c.Connect(ctx)
+		if ctx.Err() != nil {
+			c.Close()
+			return ErrNotConnected
+		}
+
+		if err == nil {
+			c.log.Info("reconnected", "attempt", attempt)
+			return nil
+		}
+
+		c.log.Warn("reconnection failed", "attempt", attempt, "error", err)
+	}
+
+	c.log.Error("giving up reconnecting", "error", err)
+	return err
+}
+
+// dropped handles a lost connection, returning the EventSocketDisconnected
+// event and scheduling the reconnection on the next read.
+func (c *Client) dropped(err error) *Message {
+	c.lost = true
+	c.log.Warn("connection lost", "error", err)
+	payload, _ := json.Marshal(&SocketDisconnectedPayload{Error: err.Error()})
+	return &Message{Event: &Event{Type: EventSocketDisconnected, Payload: payload}}
+}
+
+// reconnected reconnects to the server, returning the EventSocketReconnected
+// event.
+func (c *Client) reconnected() (*Message, error) {
+	c.lost = false
+	if err := c.redial(); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(c.Connected())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{Event: &Event{Type: EventSocketReconnected, Payload: payload}}, nil
+}
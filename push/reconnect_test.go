@@ -0,0 +1,120 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Reconnect(t *testing.T) {
+	ts, received := newServer(t,
+		`{"event": {"type": "PrintStarted", "payload": {"name": "benchy.gcode"}}}`,
+	)
+	defer ts.Close()
+
+	c := NewClient(ts.URL,
+		WithAuth("foo", "bar"),
+		WithReconnect(ReconnectPolicy{MinBackoff: time.Millisecond}),
+	)
+
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+	assert.JSONEq(t, `{"auth": "foo:bar"}`, <-received)
+
+	m, err := c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, EventPrintStarted, m.Event.Type)
+
+	m, err = c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, EventSocketDisconnected, m.Event.Type)
+
+	payload, err := m.Event.DecodePayload()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, payload.(*SocketDisconnectedPayload).Error)
+
+	m, err = c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, EventSocketReconnected, m.Event.Type)
+	assert.JSONEq(t, `{"auth": "foo:bar"}`, <-received)
+
+	payload, err = m.Event.DecodePayload()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.8.6", payload.(*Connected).Version)
+
+	m, err = c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, EventPrintStarted, m.Event.Type)
+}
+
+type testLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *testLogger) log(level, msg string, keyvals ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, strings.TrimSpace(fmt.Sprintln(level, msg, keyvals)))
+}
+
+func (l *testLogger) Debug(msg string, kv ...interface{}) { l.log("DEBUG", msg, kv...) }
+func (l *testLogger) Info(msg string, kv ...interface{})  { l.log("INFO", msg, kv...) }
+func (l *testLogger) Warn(msg string, kv ...interface{})  { l.log("WARN", msg, kv...) }
+func (l *testLogger) Error(msg string, kv ...interface{}) { l.log("ERROR", msg, kv...) }
+
+func TestClient_ReconnectGiveUp(t *testing.T) {
+	ts, _ := newServer(t)
+
+	l := &testLogger{}
+	c := NewClient(ts.URL, WithLogger(l), WithReconnect(ReconnectPolicy{
+		MaxAttempts: 2,
+		MinBackoff:  time.Millisecond,
+	}))
+
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+	ts.Close()
+
+	m, err := c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, EventSocketDisconnected, m.Event.Type)
+
+	_, err = c.Next()
+	assert.Error(t, err)
+
+	var levels []string
+	for _, line := range l.lines {
+		levels = append(levels, strings.Join(strings.Fields(line)[:2], " "))
+	}
+
+	assert.Equal(t, []string{
+		"WARN connection", "DEBUG reconnecting", "WARN reconnection",
+		"DEBUG reconnecting", "WARN reconnection", "ERROR giving",
+	}, levels)
+}
+
+func TestClient_ReconnectClosed(t *testing.T) {
+	ts, _ := newServer(t)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithReconnect(DefaultReconnectPolicy))
+	assert.NoError(t, c.Connect(context.Background()))
+	assert.NoError(t, c.Close())
+
+	_, err := c.Next()
+	assert.Equal(t, ErrNotConnected, err)
+}
+
+func TestReconnectPolicy_Backoff(t *testing.T) {
+	p := &ReconnectPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, p.backoff(1))
+	assert.Equal(t, 2*time.Second, p.backoff(2))
+	assert.Equal(t, 4*time.Second, p.backoff(3))
+	assert.Equal(t, 5*time.Second, p.backoff(4))
+}
@@ -0,0 +1,53 @@
+package push
+
+import (
+	"context"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+// WithSession configures the client to authenticate the socket with the login
+// session of the given REST client. If the REST client is not logged in, a
+// passive login is performed on every connection, and again when the server
+// requires it with a `reauthRequired` message, so the socket always receives
+// the full state instead of the data available to anonymous users.
+func WithSession(rest *octoprint.Client) Option {
+	return func(c *Client) {
+		c.rest = rest
+	}
+}
+
+// credentials returns the user and session to authenticate the socket with,
+// empty if the socket should stay anonymous.
+func (c *Client) credentials(ctx context.Context) (user, session string, err error) {
+	if c.rest == nil {
+		return c.user, c.session, nil
+	}
+
+	user, session = c.rest.Session()
+	if session != "" {
+		return user, session, nil
+	}
+
+	return c.login(ctx)
+}
+
+// login performs a passive login with the REST client.
+func (c *Client) login(ctx context.Context) (user, session string, err error) {
+	r, err := (&octoprint.LoginRequest{Passive: true}).Do(ctx, c.rest)
+	if err != nil {
+		return "", "", err
+	}
+
+	return r.Name, r.Session, nil
+}
+
+// reauth logs in again and authenticates the socket with the new session.
+func (c *Client) reauth(ctx context.Context) error {
+	user, session, err := c.login(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.Auth(user, session)
+}
@@ -0,0 +1,61 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mcuadros/go-octoprint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ConnectWithSession(t *testing.T) {
+	var logins int
+	received := make(chan string, 10)
+	upgrader := websocket.Upgrader{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(octoprint.URILogin, func(w http.ResponseWriter, r *http.Request) {
+		logins++
+		fmt.Fprintf(w, `{"name": "foo", "session": "session%d"}`, logins)
+	})
+
+	mux.HandleFunc(URIWebsocket, func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		defer ws.Close()
+		ws.WriteMessage(websocket.TextMessage, []byte(`{"connected": {"version": "1.8.6"}}`))
+
+		_, b, _ := ws.ReadMessage()
+		received <- string(b)
+
+		ws.WriteMessage(websocket.TextMessage, []byte(`{"reauthRequired": {"reason": "stale"}}`))
+
+		_, b, _ = ws.ReadMessage()
+		received <- string(b)
+
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	rest := octoprint.NewClient(ts.URL, "")
+	c := NewClient(ts.URL, WithSession(rest))
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+
+	assert.JSONEq(t, `{"auth": "foo:session1"}`, <-received)
+
+	m, err := c.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "stale", m.ReauthRequired.Reason)
+	assert.JSONEq(t, `{"auth": "foo:session2"}`, <-received)
+}
@@ -0,0 +1,45 @@
+package push
+
+import "context"
+
+type slicingSubscriber struct {
+	ch     chan SlicingProgress
+	closed chan struct{}
+}
+
+func (s *slicingSubscriber) deliver(m *Message) {
+	if m.SlicingProgress == nil {
+		return
+	}
+
+	select {
+	case s.ch <- *m.SlicingProgress:
+	default:
+	}
+}
+
+func (s *slicingSubscriber) close() {
+	close(s.ch)
+	close(s.closed)
+}
+
+func (s *slicingSubscriber) done() <-chan struct{} {
+	return s.closed
+}
+
+// SlicingProgressStream returns a channel receiving the progress of the
+// slicing jobs running on the server, e.g. started with a SliceFileRequest. The
+// subscription follows the same rules as Subscribe, combine it with the
+// EventSlicingDone and EventSlicingFailed events to know when a job finishes.
+func (c *Client) SlicingProgressStream(ctx context.Context) (<-chan SlicingProgress, error) {
+	s := &slicingSubscriber{
+		ch:     make(chan SlicingProgress, c.bufferSize),
+		closed: make(chan struct{}),
+	}
+
+	if err := c.subscribe(ctx, s); err != nil {
+		return nil, err
+	}
+
+	return s.ch, nil
+}
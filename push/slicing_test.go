@@ -0,0 +1,42 @@
+package push
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SlicingProgressStream(t *testing.T) {
+	ts, _ := newServer(t,
+		`{"slicingProgress": {
+			"slicer": "curalegacy",
+			"source_location": "local",
+			"source_path": "cube.stl",
+			"dest_location": "local",
+			"dest_path": "cube.gcode",
+			"progress": 25.5
+		}}`,
+		`{"current": {"state": {"text": "Operational"}}}`,
+		`{"slicingProgress": {"slicer": "curalegacy", "source_path": "cube.stl", "progress": 100}}`,
+	)
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+
+	ch, err := c.SlicingProgressStream(context.Background())
+	assert.NoError(t, err)
+
+	p := <-ch
+	assert.Equal(t, "curalegacy", p.Slicer)
+	assert.Equal(t, octoprint.Local, p.SourceLocation)
+	assert.Equal(t, "cube.stl", p.SourcePath)
+	assert.Equal(t, "cube.gcode", p.DestPath)
+	assert.Equal(t, 25.5, p.Progress)
+
+	p = <-ch
+	assert.Equal(t, 100., p.Progress)
+}
@@ -0,0 +1,181 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// DefaultBufferSize is the default number of events buffered per subscriber.
+const DefaultBufferSize = 64
+
+// ErrSubscribed is returned by Next once the client has subscribers, since the
+// messages are consumed by the subscriptions.
+var ErrSubscribed = errors.New("Push client messages are consumed by subscriptions")
+
+// WithBufferSize configures the number of events buffered per subscriber,
+// events received while the buffer of a subscriber is full are dropped for
+// that subscriber, so a slow subscriber never blocks the others.
+func WithBufferSize(size int) Option {
+	return func(c *Client) {
+		c.bufferSize = size
+	}
+}
+
+// subscriber receives the messages read by the dispatcher. deliver must never
+// block, and close is called once, when the subscription is cancelled.
+type subscriber interface {
+	deliver(m *Message)
+	close()
+	done() <-chan struct{}
+}
+
+type eventSubscriber struct {
+	events map[EventType]bool
+	ch     chan Event
+	closed chan struct{}
+}
+
+func (s *eventSubscriber) wants(t EventType) bool {
+	return len(s.events) == 0 || s.events[t]
+}
+
+func (s *eventSubscriber) deliver(m *Message) {
+	if m.Event == nil || !s.wants(m.Event.Type) {
+		return
+	}
+
+	select {
+	case s.ch <- *m.Event:
+	default:
+	}
+}
+
+func (s *eventSubscriber) close() {
+	close(s.ch)
+	close(s.closed)
+}
+
+func (s *eventSubscriber) done() <-chan struct{} {
+	return s.closed
+}
+
+type subscriptions struct {
+	mu      sync.Mutex
+	running bool
+	err     error
+	subs    map[subscriber]struct{}
+}
+
+// Subscribe returns a channel receiving the events of the given types, or all
+// the events if no type is given. The subscription is cancelled, and the
+// channel closed, when ctx is done or the connection is lost.
+//
+// The first subscription starts reading the messages in the background, after
+// that Next cannot be used anymore.
+func (c *Client) Subscribe(ctx context.Context, events ...EventType) (<-chan Event, error) {
+	s := &eventSubscriber{
+		events: make(map[EventType]bool, len(events)),
+		ch:     make(chan Event, c.bufferSize),
+		closed: make(chan struct{}),
+	}
+
+	for _, e := range events {
+		s.events[e] = true
+	}
+
+	if err := c.subscribe(ctx, s); err != nil {
+		return nil, err
+	}
+
+	return s.ch, nil
+}
+
+// subscribe registers the subscriber, starting the dispatcher if needed, until
+// ctx is done.
+func (c *Client) subscribe(ctx context.Context, s subscriber) error {
+	if c.conn() == nil {
+		return ErrNotConnected
+	}
+
+	c.subs.mu.Lock()
+	if c.subs.err != nil {
+		err := c.subs.err
+		c.subs.mu.Unlock()
+		return err
+	}
+
+	if c.subs.subs == nil {
+		c.subs.subs = make(map[subscriber]struct{})
+	}
+
+	c.subs.subs[s] = struct{}{}
+	if !c.subs.running {
+		c.subs.running = true
+		go c.dispatch()
+	}
+	c.subs.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.unsubscribe(s)
+		case <-s.done():
+		}
+	}()
+
+	return nil
+}
+
+func (c *Client) unsubscribe(s subscriber) {
+	c.subs.mu.Lock()
+	defer c.subs.mu.Unlock()
+
+	if _, ok := c.subs.subs[s]; !ok {
+		return
+	}
+
+	delete(c.subs.subs, s)
+	s.close()
+}
+
+// dispatch reads the messages until the connection is lost, sending them to
+// the subscribers.
+func (c *Client) dispatch() {
+	for {
+		m, err := c.next()
+		if err != nil {
+			c.closeSubscriptions(err)
+			return
+		}
+
+		c.publish(m)
+	}
+}
+
+func (c *Client) publish(m *Message) {
+	c.subs.mu.Lock()
+	defer c.subs.mu.Unlock()
+
+	for s := range c.subs.subs {
+		s.deliver(m)
+	}
+}
+
+func (c *Client) closeSubscriptions(err error) {
+	c.subs.mu.Lock()
+	defer c.subs.mu.Unlock()
+
+	c.subs.err, c.subs.running = err, false
+	for s := range c.subs.subs {
+		delete(c.subs.subs, s)
+		s.close()
+	}
+}
+
+// Err returns the error that stopped the subscriptions, if any.
+func (c *Client) Err() error {
+	c.subs.mu.Lock()
+	defer c.subs.mu.Unlock()
+	return c.subs.err
+}
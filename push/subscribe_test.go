@@ -0,0 +1,94 @@
+package push
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Subscribe(t *testing.T) {
+	ts, _ := newServer(t,
+		`{"current": {"state": {"text": "Operational"}}}`,
+		`{"event": {"type": "PrintStarted", "payload": {"name": "benchy.gcode"}}}`,
+		`{"event": {"type": "ZChange", "payload": {"new": 0.3}}}`,
+		`{"event": {"type": "PrintDone", "payload": {"name": "benchy.gcode"}}}`,
+	)
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+
+	prints, err := c.Subscribe(context.Background(), EventPrintStarted, EventPrintDone)
+	assert.NoError(t, err)
+
+	_, err = c.Next()
+	assert.Equal(t, ErrSubscribed, err)
+
+	var types []EventType
+	for e := range prints {
+		types = append(types, e.Type)
+	}
+
+	assert.Equal(t, []EventType{EventPrintStarted, EventPrintDone}, types)
+	assert.Error(t, c.Err())
+}
+
+func TestClient_SubscribeFanOut(t *testing.T) {
+	c := NewClient("http://localhost")
+	all := &eventSubscriber{ch: make(chan Event, c.bufferSize)}
+	prints := &eventSubscriber{
+		events: map[EventType]bool{EventPrintStarted: true},
+		ch:     make(chan Event, c.bufferSize),
+	}
+
+	c.subs.subs = map[subscriber]struct{}{all: {}, prints: {}}
+
+	c.publish(&Message{Event: &Event{Type: EventPrintStarted}})
+	c.publish(&Message{Event: &Event{Type: EventZChange}})
+	c.publish(&Message{Current: &Current{}})
+
+	assert.Len(t, all.ch, 2)
+	assert.Len(t, prints.ch, 1)
+	assert.Equal(t, EventPrintStarted, (<-prints.ch).Type)
+}
+
+func TestClient_SubscribeCancel(t *testing.T) {
+	ts, _ := newServer(t)
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := c.Subscribe(ctx)
+	assert.NoError(t, err)
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("subscription not cancelled")
+	}
+}
+
+func TestClient_SubscribeNotConnected(t *testing.T) {
+	_, err := NewClient("http://localhost").Subscribe(context.Background())
+	assert.Equal(t, ErrNotConnected, err)
+}
+
+func TestClient_SubscribeBufferFull(t *testing.T) {
+	c := NewClient("http://localhost", WithBufferSize(1))
+	s := &eventSubscriber{ch: make(chan Event, c.bufferSize)}
+	c.subs.subs = map[subscriber]struct{}{s: {}}
+
+	c.publish(&Message{Event: &Event{Type: EventPrintStarted}})
+	c.publish(&Message{Event: &Event{Type: EventPrintDone}})
+
+	assert.Equal(t, EventPrintStarted, (<-s.ch).Type)
+	assert.Len(t, s.ch, 0)
+}
@@ -0,0 +1,110 @@
+package push
+
+import (
+	"context"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+)
+
+// DefaultPollInterval is the default interval between polls of the REST API,
+// matching the default temperature reporting interval of OctoPrint.
+const DefaultPollInterval = 2 * time.Second
+
+// WithPolling configures the REST client used to poll the state of the printer
+// every interval, when the push API is not available, by the streams
+// supporting it, e.g. TemperatureStream. A zero interval uses
+// DefaultPollInterval.
+func WithPolling(rest *octoprint.Client, interval time.Duration) Option {
+	return func(c *Client) {
+		if interval <= 0 {
+			interval = DefaultPollInterval
+		}
+
+		c.poll, c.pollInterval = rest, interval
+	}
+}
+
+type temperatureSubscriber struct {
+	ch     chan octoprint.HistoricTemperatureData
+	closed chan struct{}
+}
+
+func (s *temperatureSubscriber) deliver(m *Message) {
+	if m.Current == nil {
+		return
+	}
+
+	for _, t := range m.Current.Temps {
+		select {
+		case s.ch <- *t:
+		default:
+		}
+	}
+}
+
+func (s *temperatureSubscriber) close() {
+	close(s.ch)
+	close(s.closed)
+}
+
+func (s *temperatureSubscriber) done() <-chan struct{} {
+	return s.closed
+}
+
+// TemperatureStream returns a channel receiving the temperatures of the
+// printer, one sample per reporting interval, until ctx is done.
+//
+// The samples are read from the `current` messages of the push API, following
+// the same rules as Subscribe. If the client is not connected and polling is
+// configured with WithPolling, it tries to connect, falling back to polling
+// the REST API if the push API is not available. Failed polls are skipped.
+func (c *Client) TemperatureStream(ctx context.Context) (<-chan octoprint.HistoricTemperatureData, error) {
+	if c.conn() == nil && c.poll != nil {
+		if err := c.Connect(ctx); err != nil {
+			return c.pollTemperatures(ctx), nil
+		}
+	}
+
+	s := &temperatureSubscriber{
+		ch:     make(chan octoprint.HistoricTemperatureData, c.bufferSize),
+		closed: make(chan struct{}),
+	}
+
+	if err := c.subscribe(ctx, s); err != nil {
+		return nil, err
+	}
+
+	return s.ch, nil
+}
+
+func (c *Client) pollTemperatures(ctx context.Context) <-chan octoprint.HistoricTemperatureData {
+	ch := make(chan octoprint.HistoricTemperatureData, c.bufferSize)
+	go func() {
+		defer close(ch)
+
+		t := time.NewTicker(c.pollInterval)
+		defer t.Stop()
+
+		for {
+			r, err := (&octoprint.StateRequest{Exclude: []string{"sd", "state"}}).Do(ctx, c.poll)
+			if err == nil {
+				select {
+				case ch <- octoprint.HistoricTemperatureData{
+					Time:  octoprint.UnixTime{Time: time.Now()},
+					Tools: r.Temperature.Current,
+				}:
+				default:
+				}
+			}
+
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,73 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_TemperatureStream(t *testing.T) {
+	ts, _ := newServer(t,
+		`{"current": {"temps": [
+			{"time": 1600000000, "tool0": {"actual": 24.5, "target": 210}},
+			{"time": 1600000002, "tool0": {"actual": 30.1, "target": 210}}
+		]}}`,
+		`{"event": {"type": "ZChange", "payload": {"new": 0.3}}}`,
+		`{"current": {"temps": [{"time": 1600000004, "tool0": {"actual": 35.7, "target": 210}}]}}`,
+	)
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+
+	ch, err := c.TemperatureStream(context.Background())
+	assert.NoError(t, err)
+
+	var actual []float64
+	for s := range ch {
+		actual = append(actual, s.Tools["tool0"].Actual)
+	}
+
+	assert.Equal(t, []float64{24.5, 30.1, 35.7}, actual)
+}
+
+func TestClient_TemperatureStreamPolling(t *testing.T) {
+	var polls int
+	mux := http.NewServeMux()
+	mux.HandleFunc(octoprint.URIPrinter, func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		assert.Equal(t, "sd,state", r.URL.Query().Get("exclude"))
+		fmt.Fprintf(w, `{"temperature": {"tool0": {"actual": %d, "target": 210}}}`, polls)
+	})
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	rest := octoprint.NewClient(ts.URL, "")
+	c := NewClient(ts.URL, WithPolling(rest, 10*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := c.TemperatureStream(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1., (<-ch).Tools["tool0"].Actual)
+	assert.Equal(t, 2., (<-ch).Tools["tool0"].Actual)
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestClient_TemperatureStreamNotConnected(t *testing.T) {
+	_, err := NewClient("http://localhost").TemperatureStream(context.Background())
+	assert.Equal(t, ErrNotConnected, err)
+}
@@ -0,0 +1,99 @@
+package push
+
+import (
+	"context"
+	"strings"
+)
+
+// LogDirection is the direction of a line of the serial communication.
+type LogDirection string
+
+const (
+	// LogSend is a line sent to the printer.
+	LogSend LogDirection = "Send"
+	// LogRecv is a line received from the printer.
+	LogRecv LogDirection = "Recv"
+	// LogInfo is a line logged by the server itself, e.g. a change of the
+	// state of the connection.
+	LogInfo LogDirection = ""
+)
+
+// LogLine is a line of the serial communication between the server and the
+// printer.
+type LogLine struct {
+	// Direction of the line.
+	Direction LogDirection
+	// Text of the line, without the direction prefix, e.g. `M105`.
+	Text string
+}
+
+// ParseLogLine parses a line of the serial log as sent by the server, e.g.
+// `Send: M105` or `Recv: ok T:210.0 /210.0`.
+func ParseLogLine(line string) LogLine {
+	for _, d := range []LogDirection{LogSend, LogRecv} {
+		prefix := string(d) + ":"
+		if strings.HasPrefix(line, prefix) {
+			return LogLine{Direction: d, Text: strings.TrimSpace(line[len(prefix):])}
+		}
+	}
+
+	return LogLine{Direction: LogInfo, Text: line}
+}
+
+// String returns the line as sent by the server.
+func (l LogLine) String() string {
+	if l.Direction == LogInfo {
+		return l.Text
+	}
+
+	return string(l.Direction) + ": " + l.Text
+}
+
+type logSubscriber struct {
+	ch     chan LogLine
+	closed chan struct{}
+}
+
+func (s *logSubscriber) deliver(m *Message) {
+	var lines []string
+	switch {
+	case m.History != nil:
+		lines = m.History.Logs
+	case m.Current != nil:
+		lines = m.Current.Logs
+	}
+
+	for _, l := range lines {
+		select {
+		case s.ch <- ParseLogLine(l):
+		default:
+		}
+	}
+}
+
+func (s *logSubscriber) close() {
+	close(s.ch)
+	close(s.closed)
+}
+
+func (s *logSubscriber) done() <-chan struct{} {
+	return s.closed
+}
+
+// LogStream returns a channel receiving the lines of the serial communication
+// between the server and the printer, as contained in the `history` and
+// `current` messages, e.g. to build a remote terminal. The subscription
+// follows the same rules as Subscribe, lines received while the buffer is full
+// are dropped.
+func (c *Client) LogStream(ctx context.Context) (<-chan LogLine, error) {
+	s := &logSubscriber{
+		ch:     make(chan LogLine, c.bufferSize),
+		closed: make(chan struct{}),
+	}
+
+	if err := c.subscribe(ctx, s); err != nil {
+		return nil, err
+	}
+
+	return s.ch, nil
+}
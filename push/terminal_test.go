@@ -0,0 +1,49 @@
+package push
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogLine(t *testing.T) {
+	for line, expected := range map[string]LogLine{
+		"Send: N5 M105*39":        {Direction: LogSend, Text: "N5 M105*39"},
+		"Recv: ok T:210.0 /210.0": {Direction: LogRecv, Text: "ok T:210.0 /210.0"},
+		"Changing monitoring state from \"Offline\" to \"Opening serial connection\"": {
+			Direction: LogInfo,
+			Text:      "Changing monitoring state from \"Offline\" to \"Opening serial connection\"",
+		},
+	} {
+		l := ParseLogLine(line)
+		assert.Equal(t, expected, l)
+		assert.Equal(t, line, l.String())
+	}
+}
+
+func TestClient_LogStream(t *testing.T) {
+	ts, _ := newServer(t,
+		`{"history": {"logs": ["Connected to: Serial<id=0x1>"]}}`,
+		`{"current": {"logs": ["Send: M105", "Recv: ok T:24.5 /0.0"]}}`,
+	)
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+
+	ch, err := c.LogStream(context.Background())
+	assert.NoError(t, err)
+
+	var lines []LogLine
+	for l := range ch {
+		lines = append(lines, l)
+	}
+
+	assert.Equal(t, []LogLine{
+		{Direction: LogInfo, Text: "Connected to: Serial<id=0x1>"},
+		{Direction: LogSend, Text: "M105"},
+		{Direction: LogRecv, Text: "ok T:24.5 /0.0"},
+	}, lines)
+}
@@ -0,0 +1,102 @@
+package push
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// Transport is a transport used to connect to the push API.
+type Transport string
+
+const (
+	// TransportWebsocket connects to the raw websocket endpoint.
+	TransportWebsocket Transport = "websocket"
+	// TransportXHRStreaming connects using the SockJS xhr-streaming transport,
+	// receiving the messages over a long running HTTP response.
+	TransportXHRStreaming Transport = "xhr_streaming"
+	// TransportXHRPolling connects using the SockJS xhr-polling transport,
+	// receiving the messages with a long polling HTTP request each.
+	TransportXHRPolling Transport = "xhr"
+)
+
+// DefaultTransports are the transports tried, in order, when connecting. The
+// XHR transports work where websockets are blocked, e.g. by restrictive
+// proxies.
+var DefaultTransports = []Transport{
+	TransportWebsocket,
+	TransportXHRStreaming,
+	TransportXHRPolling,
+}
+
+// WithTransports configures the transports tried, in order, when connecting.
+func WithTransports(t ...Transport) Option {
+	return func(c *Client) {
+		c.transports = t
+	}
+}
+
+// WithHTTPClient configures the HTTP client used by the XHR transports.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// socket is a connection to the push API, over any transport.
+type socket interface {
+	// read blocks until the next message is received.
+	read() ([]byte, error)
+	// write sends a message, encoded as JSON.
+	write(v interface{}) error
+	close() error
+}
+
+// dial connects to the server with the given transport.
+func (c *Client) dial(ctx context.Context, t Transport) (socket, error) {
+	switch t {
+	case TransportWebsocket:
+		return c.dialWebsocket(ctx)
+	case TransportXHRStreaming, TransportXHRPolling:
+		return c.dialXHR(ctx, t)
+	default:
+		return nil, fmt.Errorf("unsupported transport %q", t)
+	}
+}
+
+type wsSocket struct {
+	ws *websocket.Conn
+}
+
+func (c *Client) dialWebsocket(ctx context.Context) (socket, error) {
+	target, err := websocketURL(c.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, resp, err := c.dialer.DialContext(ctx, target, c.header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("error connecting to %s: %s: %w", target, resp.Status, err)
+		}
+
+		return nil, err
+	}
+
+	return &wsSocket{ws: ws}, nil
+}
+
+func (s *wsSocket) read() ([]byte, error) {
+	_, b, err := s.ws.ReadMessage()
+	return b, err
+}
+
+func (s *wsSocket) write(v interface{}) error {
+	return s.ws.WriteJSON(v)
+}
+
+func (s *wsSocket) close() error {
+	return s.ws.Close()
+}
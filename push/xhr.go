@@ -0,0 +1,247 @@
+package push
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// URISockJS is the endpoint of the SockJS server of OctoPrint, used by the XHR
+// transports.
+const URISockJS = "/sockjs"
+
+// xhrSocket implements the SockJS xhr-streaming and xhr-polling transports.
+// The server sends frames, one per line: `o` when the session is opened, `h`
+// as heartbeat, `a` followed by a JSON array of messages, and `c` followed by
+// the code and reason when the session is closed.
+type xhrSocket struct {
+	hc        *http.Client
+	header    http.Header
+	base      string
+	streaming bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// queue, body and r are only used by the reader.
+	queue []string
+	body  io.ReadCloser
+	r     *bufio.Reader
+}
+
+func (c *Client) dialXHR(ctx context.Context, t Transport) (socket, error) {
+	base, err := sockJSURL(c.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	hc := c.httpClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	sctx, cancel := context.WithCancel(context.Background())
+	s := &xhrSocket{
+		hc:        hc,
+		header:    c.header,
+		base:      fmt.Sprintf("%s/%03d/%s", base, rand.Intn(1000), sessionID()),
+		streaming: t == TransportXHRStreaming,
+		ctx:       sctx,
+		cancel:    cancel,
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-done:
+		}
+	}()
+
+	if err := s.open(); err != nil {
+		cancel()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// open waits for the open frame, skipping the heartbeats.
+func (s *xhrSocket) open() error {
+	for {
+		f, err := s.frame()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case f == "o":
+			return nil
+		case strings.HasPrefix(f, "h"):
+		default:
+			return fmt.Errorf("unexpected SockJS frame %q, expecting open frame", f)
+		}
+	}
+}
+
+func (s *xhrSocket) read() ([]byte, error) {
+	for len(s.queue) == 0 {
+		f, err := s.frame()
+		if err != nil {
+			return nil, err
+		}
+
+		if f == "" {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		switch f[0] {
+		case 'o', 'h':
+		case 'a':
+			if err := json.Unmarshal([]byte(f[1:]), &s.queue); err != nil {
+				return nil, err
+			}
+		case 'c':
+			return nil, fmt.Errorf("SockJS session closed by the server: %s", f[1:])
+		default:
+			return nil, fmt.Errorf("unexpected SockJS frame %q", f)
+		}
+	}
+
+	m := s.queue[0]
+	s.queue = s.queue[1:]
+	return []byte(m), nil
+}
+
+// frame returns the next frame sent by the server.
+func (s *xhrSocket) frame() (string, error) {
+	if !s.streaming {
+		resp, err := s.post("/xhr", nil)
+		if err != nil {
+			return "", err
+		}
+
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		return strings.TrimSpace(string(b)), err
+	}
+
+	for {
+		if s.r == nil {
+			resp, err := s.post("/xhr_streaming", nil)
+			if err != nil {
+				return "", err
+			}
+
+			s.body, s.r = resp.Body, bufio.NewReader(resp.Body)
+		}
+
+		line, err := s.r.ReadString('\n')
+		if err == io.EOF {
+			// the server ends the response after some amount of data,
+			// the next frames are received with a new request.
+			s.body.Close()
+			s.body, s.r = nil, nil
+			if line == "" {
+				continue
+			}
+
+			err = nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		return strings.TrimSpace(line), nil
+	}
+}
+
+func (s *xhrSocket) write(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal([]string{string(b)})
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.post("/xhr_send", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+func (s *xhrSocket) post(path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(s.ctx, "POST", s.base+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range s.header {
+		req.Header[k] = v
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+
+	resp, err := s.hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("error requesting %s: %s", req.URL, resp.Status)
+	}
+
+	return resp, nil
+}
+
+func (s *xhrSocket) close() error {
+	s.cancel()
+	return nil
+}
+
+// sockJSURL returns the URL of the SockJS endpoint for the given server
+// address.
+func sockJSURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + URISockJS
+	return u.String(), nil
+}
+
+// sessionID returns a random SockJS session identifier.
+func sessionID() string {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	b := make([]byte, 16)
+	for i := range b {
+		b[i] = letters[rand.Intn(len(letters))]
+	}
+
+	return string(b)
+}
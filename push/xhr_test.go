@@ -0,0 +1,124 @@
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const closeFrame = `c[3000,"Go away!"]`
+
+// newSockJSServer returns a server implementing the SockJS XHR transports,
+// sending the connected message followed by the given messages, and the
+// messages sent by the client to the returned channel.
+func newSockJSServer(t *testing.T, messages ...string) (*httptest.Server, <-chan string) {
+	received := make(chan string, 10)
+
+	var mu sync.Mutex
+	frames := []string{"o", `a["{\"connected\": {\"version\": \"1.8.6\"}}"]`}
+	for _, m := range messages {
+		b, err := json.Marshal([]string{m})
+		assert.NoError(t, err)
+		frames = append(frames, "a"+string(b))
+	}
+
+	next := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(frames) == 0 {
+			return closeFrame
+		}
+
+		f := frames[0]
+		frames = frames[1:]
+		return f
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(r.URL.Path, "/")
+		if len(parts) != 5 || parts[1] != "sockjs" || r.Method != "POST" {
+			w.WriteHeader(404)
+			return
+		}
+
+		switch parts[4] {
+		case "xhr":
+			fmt.Fprintln(w, next())
+		case "xhr_streaming":
+			fmt.Fprintln(w, strings.Repeat("h", 2048))
+			for {
+				f := next()
+				fmt.Fprintln(w, f)
+				if f == closeFrame {
+					return
+				}
+			}
+		case "xhr_send":
+			var msgs []string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&msgs))
+			for _, m := range msgs {
+				received <- m
+			}
+
+			w.WriteHeader(204)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+
+	return ts, received
+}
+
+func TestClient_ConnectXHR(t *testing.T) {
+	for _, transport := range []Transport{TransportXHRStreaming, TransportXHRPolling} {
+		ts, received := newSockJSServer(t,
+			`{"event": {"type": "PrintStarted", "payload": {"name": "benchy.gcode"}}}`,
+			`{"current": {"state": {"text": "Printing"}}}`,
+		)
+
+		c := NewClient(ts.URL, WithAuth("foo", "bar"), WithTransports(transport))
+		assert.NoError(t, c.Connect(context.Background()))
+		assert.Equal(t, transport, c.Transport())
+		assert.Equal(t, "1.8.6", c.Connected().Version)
+		assert.JSONEq(t, `{"auth": "foo:bar"}`, <-received)
+
+		m, err := c.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, EventPrintStarted, m.Event.Type)
+
+		m, err = c.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, "Printing", m.Current.State.Text)
+
+		_, err = c.Next()
+		assert.Error(t, err)
+
+		assert.NoError(t, c.Close())
+		ts.Close()
+	}
+}
+
+func TestClient_ConnectFallback(t *testing.T) {
+	ts, _ := newSockJSServer(t)
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	assert.NoError(t, c.Connect(context.Background()))
+	defer c.Close()
+
+	assert.Equal(t, TransportXHRStreaming, c.Transport())
+}
+
+func TestSockJSURL(t *testing.T) {
+	u, err := sockJSURL("https://example.com/octoprint/")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/octoprint/sockjs", u)
+}
@@ -0,0 +1,529 @@
+// Package queue implements a print queue, printing a list of jobs one after
+// another on a printer.
+//
+//	q, err := queue.New(c, queue.NewFileStore("queue.json"))
+//	q.Events = p // a connected *push.Client, optional
+//	q.Add(&queue.Job{LocalPath: "benchy.gcode"})
+//	err = q.Run(ctx)
+//
+// Jobs are started when the printer is operational and idle, and the next one
+// once the print is done. The jobs are saved to the Store on every change.
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/mcuadros/go-octoprint/push"
+)
+
+// ErrJobNotFound is returned when a job is not in the queue.
+var ErrJobNotFound = errors.New("Job not found in the queue")
+
+// ErrJobPrinting is returned when removing the job being printed.
+var ErrJobPrinting = errors.New("Job is being printed")
+
+// Status is the status of a job.
+type Status string
+
+const (
+	// Pending jobs wait to be printed.
+	Pending Status = "pending"
+	// Printing is the status of the job being printed.
+	Printing Status = "printing"
+	// Done jobs were printed successfully.
+	Done Status = "done"
+	// Failed jobs failed to upload or print, or were cancelled.
+	Failed Status = "failed"
+)
+
+// Job is a job of the queue.
+type Job struct {
+	// ID of the job, assigned when added.
+	ID string `json:"id"`
+	// LocalPath is the path of a file on the local disk, uploaded to the
+	// `local` location of OctoPrint when the job is started.
+	LocalPath string `json:"localPath,omitempty"`
+	// Location of a file already uploaded, `local` if not set.
+	Location octoprint.Location `json:"location,omitempty"`
+	// Path of the file within Location. Set once uploaded for jobs with
+	// LocalPath.
+	Path string `json:"path,omitempty"`
+	// Status of the job.
+	Status Status `json:"status"`
+	// Error is the reason of the failure of Failed jobs.
+	Error string `json:"error,omitempty"`
+	// Added is the time the job was added.
+	Added time.Time `json:"added"`
+	// Started is the time the print started.
+	Started time.Time `json:"started,omitempty"`
+	// Finished is the time the print finished.
+	Finished time.Time `json:"finished,omitempty"`
+}
+
+// Events is a source of events, implemented by *push.Client.
+type Events interface {
+	Subscribe(ctx context.Context, events ...push.EventType) (<-chan push.Event, error)
+}
+
+// Queue is a print queue of a printer. It is safe for concurrent use.
+type Queue struct {
+	// Events is used to wait for the completion of the prints. If nil, or
+	// the subscription ends, the job is polled instead.
+	Events Events
+	// Interval is the interval between polls, zero means
+	// octoprint.DefaultWatchInterval.
+	Interval time.Duration
+	// BeforeStart is called before starting every job, once the printer is
+	// idle, e.g. to wait until the bed is cleared. If it returns an error Run
+	// stops and returns it.
+	BeforeStart func(ctx context.Context, job *Job) error
+
+	// OnStarted is called when the print of a job starts.
+	OnStarted func(job Job)
+	// OnDone is called when a job is printed successfully.
+	OnDone func(job Job)
+	// OnFailed is called when a job fails, the queue continues with the next
+	// job.
+	OnFailed func(job Job)
+	// OnError is called when a poll fails or the queue can't be saved.
+	OnError func(err error)
+
+	c     *octoprint.Client
+	store Store
+
+	mu     sync.Mutex
+	jobs   []*Job
+	lastID int
+	wake   chan struct{}
+}
+
+// New returns a new Queue printing on the printer of c, loading the jobs from
+// store, if not nil.
+func New(c *octoprint.Client, store Store) (*Queue, error) {
+	q := &Queue{c: c, store: store, wake: make(chan struct{}, 1)}
+	if store == nil {
+		return q, nil
+	}
+
+	jobs, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, j := range jobs {
+		if id, err := strconv.Atoi(j.ID); err == nil && id > q.lastID {
+			q.lastID = id
+		}
+	}
+
+	q.jobs = jobs
+	return q, nil
+}
+
+// Add appends a job to the queue, returning its ID.
+func (q *Queue) Add(job *Job) (string, error) {
+	if job.LocalPath == "" && job.Path == "" {
+		return "", fmt.Errorf("invalid job, LocalPath or Path are required")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.lastID++
+	j := *job
+	j.ID = strconv.Itoa(q.lastID)
+	j.Status = Pending
+	j.Error = ""
+	if j.Added.IsZero() {
+		j.Added = time.Now()
+	}
+
+	q.jobs = append(q.jobs, &j)
+	if err := q.save(); err != nil {
+		return "", err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return j.ID, nil
+}
+
+// Remove removes a job from the queue, the job being printed can't be removed.
+func (q *Queue) Remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, j := range q.jobs {
+		if j.ID != id {
+			continue
+		}
+
+		if j.Status == Printing {
+			return ErrJobPrinting
+		}
+
+		q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+		return q.save()
+	}
+
+	return ErrJobNotFound
+}
+
+// Jobs returns a copy of the jobs of the queue, in order.
+func (q *Queue) Jobs() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]Job, len(q.jobs))
+	for i, j := range q.jobs {
+		jobs[i] = *j
+	}
+
+	return jobs
+}
+
+// Run prints the pending jobs until ctx is done, returning ctx.Err(), waiting
+// for new jobs once the queue is empty. A job left printing by a previous run
+// is tracked until it completes.
+func (q *Queue) Run(ctx context.Context) error {
+	for {
+		job := q.next()
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-q.wake:
+				continue
+			}
+		}
+
+		if err := q.run(ctx, job); err != nil {
+			return err
+		}
+	}
+}
+
+// next returns the job being printed or, if none, the first pending job.
+func (q *Queue) next() *Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var pending *Job
+	for _, j := range q.jobs {
+		if j.Status == Printing {
+			return j
+		}
+
+		if j.Status == Pending && pending == nil {
+			pending = j
+		}
+	}
+
+	return pending
+}
+
+func (q *Queue) run(ctx context.Context, job *Job) error {
+	if job.Status == Printing {
+		// left printing by a previous run.
+		return q.poll(ctx, job)
+	}
+
+	if err := q.waitIdle(ctx); err != nil {
+		return err
+	}
+
+	if q.BeforeStart != nil {
+		if err := q.BeforeStart(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	if err := q.upload(ctx, job); err != nil {
+		return q.failed(ctx, job, err)
+	}
+
+	// subscribing before starting the print, so no event is missed.
+	sctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var events <-chan push.Event
+	if q.Events != nil {
+		var err error
+		events, err = q.Events.Subscribe(sctx,
+			push.EventPrintDone, push.EventPrintFailed, push.EventPrintCancelled,
+		)
+
+		if err != nil {
+			q.error(err)
+		}
+	}
+
+	err := (&octoprint.SelectFileRequest{
+		Location: job.location(),
+		Path:     job.Path,
+		Print:    true,
+	}).Do(ctx, q.c)
+
+	if err != nil {
+		return q.failed(ctx, job, err)
+	}
+
+	q.update(func() {
+		job.Status = Printing
+		job.Started = time.Now()
+	})
+
+	callJob(q.OnStarted, q.copy(job))
+	return q.wait(ctx, job, events)
+}
+
+// waitIdle waits until the printer is operational and idle.
+func (q *Queue) waitIdle(ctx context.Context) error {
+	ticker := time.NewTicker(q.interval())
+	defer ticker.Stop()
+
+	for {
+		r, err := (&octoprint.StateRequest{Exclude: []string{"sd", "temperature"}}).Do(ctx, q.c)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		switch {
+		case errors.Is(err, octoprint.ErrConflict):
+			// the printer is not connected.
+		case err != nil:
+			q.error(err)
+		case (&octoprint.PrinterStatus{State: r.State}).IsIdle():
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// upload uploads the local file of the job, if not uploaded yet.
+func (q *Queue) upload(ctx context.Context, job *Job) error {
+	if job.LocalPath == "" || job.Path != "" {
+		return nil
+	}
+
+	f, err := os.Open(job.LocalPath)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	req := &octoprint.UploadFileRequest{Location: octoprint.Local}
+	if err := req.AddFile(filepath.Base(job.LocalPath), f); err != nil {
+		return err
+	}
+
+	r, err := req.Do(ctx, q.c)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Base(job.LocalPath)
+	if r.File.Local != nil && r.File.Local.Path != "" {
+		path = r.File.Local.Path
+	}
+
+	q.update(func() {
+		job.Location = octoprint.Local
+		job.Path = path
+	})
+
+	return nil
+}
+
+// wait waits for the end of the print, with the events if any, falling back
+// to polling once they end.
+func (q *Queue) wait(ctx context.Context, job *Job, events <-chan push.Event) error {
+	for events != nil {
+		e, ok := <-events
+		if !ok {
+			break
+		}
+
+		p, err := e.DecodePayload()
+		if err != nil {
+			continue
+		}
+
+		payload, ok := p.(*push.PrintPayload)
+		if !ok || !job.matches(payload.Origin, payload.Path) {
+			continue
+		}
+
+		if e.Type == push.EventPrintDone {
+			return q.done(job)
+		}
+
+		return q.failed(ctx, job, fmt.Errorf("print %s", strings.ToLower(
+			strings.TrimPrefix(string(e.Type), "Print"),
+		)))
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	return q.poll(ctx, job)
+}
+
+// poll polls the job until the print of job ends, finishing it from the state
+// of the job: done if the file of the job is no longer printing and completed,
+// failed otherwise. The server can be unreachable, or disconnected from the
+// printer, during octoprint.DefaultRestartGrace, e.g. while it restarts.
+func (q *Queue) poll(ctx context.Context, job *Job) error {
+	ticker := time.NewTicker(q.interval())
+	defer ticker.Stop()
+
+	var lostSince time.Time
+	for {
+		r, err := (&octoprint.JobRequest{}).Do(ctx, q.c)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		switch {
+		case err != nil || r.IsOffline():
+			if err != nil {
+				q.error(err)
+			}
+
+			if lostSince.IsZero() {
+				lostSince = time.Now()
+			} else if time.Since(lostSince) > octoprint.DefaultRestartGrace {
+				return q.failed(ctx, job, fmt.Errorf("printer unreachable"))
+			}
+		case !job.matches(r.Job.File.Origin, r.Job.File.Path):
+			return q.failed(ctx, job, fmt.Errorf("print interrupted"))
+		case r.IsActive():
+			lostSince = time.Time{}
+		case r.Progress.Completion >= 100 && !strings.HasPrefix(r.State, "Error"):
+			return q.done(job)
+		default:
+			return q.failed(ctx, job, fmt.Errorf("print failed or cancelled"))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Queue) done(job *Job) error {
+	q.update(func() {
+		job.Status = Done
+		job.Finished = time.Now()
+	})
+
+	callJob(q.OnDone, q.copy(job))
+	return nil
+}
+
+// failed marks the job as failed, returning ctx.Err() if the failure was
+// caused by ctx.
+func (q *Queue) failed(ctx context.Context, job *Job, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	q.update(func() {
+		job.Status = Failed
+		job.Error = err.Error()
+		job.Finished = time.Now()
+	})
+
+	callJob(q.OnFailed, q.copy(job))
+	return nil
+}
+
+// update calls fn with the lock held, saving the jobs after.
+func (q *Queue) update(fn func()) {
+	q.mu.Lock()
+	fn()
+	err := q.save()
+	q.mu.Unlock()
+
+	if err != nil {
+		q.error(err)
+	}
+}
+
+// save stores the jobs, must be called with the lock held.
+func (q *Queue) save() error {
+	if q.store == nil {
+		return nil
+	}
+
+	return q.store.Save(q.jobs)
+}
+
+func (q *Queue) copy(job *Job) Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return *job
+}
+
+func (q *Queue) interval() time.Duration {
+	if q.Interval <= 0 {
+		return octoprint.DefaultWatchInterval
+	}
+
+	return q.Interval
+}
+
+func (q *Queue) error(err error) {
+	if q.OnError != nil {
+		q.OnError(err)
+	}
+}
+
+func (j *Job) location() octoprint.Location {
+	if j.Location == "" {
+		return octoprint.Local
+	}
+
+	return j.Location
+}
+
+// matches returns true if the file is the one of the job. An empty path, not
+// reported by old versions of OctoPrint, matches any file.
+func (j *Job) matches(origin octoprint.Location, path string) bool {
+	if path == "" {
+		return true
+	}
+
+	if origin != "" && origin != j.location() {
+		return false
+	}
+
+	return strings.TrimPrefix(path, "/") == strings.TrimPrefix(j.Path, "/")
+}
+
+func callJob(fn func(Job), job Job) {
+	if fn != nil {
+		fn(job)
+	}
+}
@@ -0,0 +1,305 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/mcuadros/go-octoprint/push"
+	"github.com/stretchr/testify/assert"
+)
+
+// printer is a fake OctoPrint server printing the selected files, each print
+// lasts three polls of the job, or none if instant.
+type printer struct {
+	mu       sync.Mutex
+	printing string
+	last     string
+	instant  bool
+	polls    int
+	prints   []string
+	uploads  []string
+	onPrint  func(path string)
+}
+
+func (p *printer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case r.URL.Path == octoprint.URIPrinter:
+		if p.printing != "" {
+			w.Write([]byte(`{"state": {"text": "Printing", "flags": {"operational": true, "printing": true}}}`))
+			return
+		}
+
+		w.Write([]byte(`{"state": {"text": "Operational", "flags": {"operational": true, "ready": true}}}`))
+	case r.URL.Path == octoprint.JobTool:
+		if p.printing != "" {
+			p.polls++
+			if p.polls <= 2 {
+				fmt.Fprintf(w, `{"job": {"file": {"path": %q, "origin": "local"}}, "progress": {"completion": 50}, "state": "Printing"}`, p.printing)
+				return
+			}
+
+			p.last, p.printing = p.printing, ""
+		}
+
+		if p.last != "" {
+			fmt.Fprintf(w, `{"job": {"file": {"path": %q, "origin": "local"}}, "progress": {"completion": 100}, "state": "Operational"}`, p.last)
+			return
+		}
+
+		w.Write([]byte(`{"state": "Operational"}`))
+	case r.URL.Path == octoprint.URIFiles+"/local":
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(400)
+			return
+		}
+
+		f.Close()
+		p.uploads = append(p.uploads, h.Filename)
+		fmt.Fprintf(w, `{"files": {"local": {"path": %q, "origin": "local"}}, "done": true}`, "queue/"+h.Filename)
+	case strings.HasPrefix(r.URL.Path, octoprint.URIFiles+"/local/"):
+		path := strings.TrimPrefix(r.URL.Path, octoprint.URIFiles+"/local/")
+		if path == "missing.gcode" {
+			w.WriteHeader(404)
+			return
+		}
+
+		p.printing, p.polls = path, 0
+		if p.instant {
+			p.printing, p.last = "", path
+		}
+
+		p.prints = append(p.prints, path)
+		w.WriteHeader(204)
+		if p.onPrint != nil {
+			go p.onPrint(path)
+		}
+	default:
+		w.WriteHeader(404)
+	}
+}
+
+type fakeEvents struct {
+	ch chan push.Event
+}
+
+func (e *fakeEvents) Subscribe(ctx context.Context, events ...push.EventType) (<-chan push.Event, error) {
+	return e.ch, nil
+}
+
+func TestQueue_Run(t *testing.T) {
+	dir, err := ioutil.TempDir("", "queue")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	local := filepath.Join(dir, "benchy.gcode")
+	assert.NoError(t, ioutil.WriteFile(local, []byte("G28\n"), 0644))
+
+	events := &fakeEvents{ch: make(chan push.Event, 10)}
+	p := &printer{}
+	p.onPrint = func(path string) {
+		payload, _ := json.Marshal(&push.PrintPayload{Path: "other.gcode", Origin: octoprint.Local})
+		events.ch <- push.Event{Type: push.EventPrintDone, Payload: payload}
+
+		typ := push.EventPrintDone
+		if path == "cube.gcode" {
+			typ = push.EventPrintCancelled
+		}
+
+		payload, _ = json.Marshal(&push.PrintPayload{Path: path, Origin: octoprint.Local})
+		events.ch <- push.Event{Type: typ, Payload: payload}
+
+		p.mu.Lock()
+		p.printing = ""
+		p.mu.Unlock()
+	}
+
+	ts := httptest.NewServer(p)
+	defer ts.Close()
+
+	store := NewFileStore(filepath.Join(dir, "queue.json"))
+	q, err := New(octoprint.NewClient(ts.URL, ""), store)
+	assert.NoError(t, err)
+
+	q.Events = events
+	q.Interval = time.Millisecond
+
+	_, err = q.Add(&Job{})
+	assert.Error(t, err)
+
+	for _, j := range []*Job{
+		{LocalPath: local},
+		{Path: "cube.gcode"},
+		{Path: "missing.gcode"},
+		{Path: "cube.gcode"},
+	} {
+		_, err := q.Add(j)
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, q.Remove("4"))
+	assert.Equal(t, ErrJobNotFound, q.Remove("4"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var finished []string
+	q.OnDone = func(j Job) {
+		mu.Lock()
+		defer mu.Unlock()
+		finished = append(finished, "done "+j.Path)
+	}
+
+	q.OnFailed = func(j Job) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		finished = append(finished, "failed "+j.Path)
+		if len(finished) == 3 {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, context.Canceled, q.Run(ctx))
+	assert.Equal(t, []string{"done queue/benchy.gcode", "failed cube.gcode", "failed missing.gcode"}, finished)
+	assert.Equal(t, []string{"benchy.gcode"}, p.uploads)
+	assert.Equal(t, []string{"queue/benchy.gcode", "cube.gcode"}, p.prints)
+
+	jobs := q.Jobs()
+	assert.Len(t, jobs, 3)
+	assert.Equal(t, Done, jobs[0].Status)
+	assert.Equal(t, Failed, jobs[1].Status)
+	assert.Equal(t, "print cancelled", jobs[1].Error)
+	assert.Equal(t, Failed, jobs[2].Status)
+
+	// the queue is restored from the store.
+	q, err = New(octoprint.NewClient(ts.URL, ""), store)
+	assert.NoError(t, err)
+
+	restored := q.Jobs()
+	assert.Len(t, restored, 3)
+	for i, j := range restored {
+		assert.Equal(t, jobs[i].ID, j.ID)
+		assert.Equal(t, jobs[i].Path, j.Path)
+		assert.Equal(t, jobs[i].Status, j.Status)
+		assert.True(t, jobs[i].Finished.Equal(j.Finished))
+	}
+
+	id, err := q.Add(&Job{Path: "cube.gcode"})
+	assert.NoError(t, err)
+	assert.Equal(t, "4", id)
+}
+
+func TestQueue_RunPolling(t *testing.T) {
+	p := &printer{}
+	ts := httptest.NewServer(p)
+	defer ts.Close()
+
+	q, err := New(octoprint.NewClient(ts.URL, ""), nil)
+	assert.NoError(t, err)
+	q.Interval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var started, done []string
+	q.OnStarted = func(j Job) { started = append(started, j.Path) }
+	q.OnDone = func(j Job) {
+		done = append(done, j.Path)
+		if len(done) == 2 {
+			cancel()
+		}
+	}
+
+	q.Add(&Job{Path: "foo.gcode"})
+	q.Add(&Job{Path: "bar.gcode"})
+
+	assert.Equal(t, context.Canceled, q.Run(ctx))
+	assert.Equal(t, []string{"foo.gcode", "bar.gcode"}, started)
+	assert.Equal(t, []string{"foo.gcode", "bar.gcode"}, done)
+}
+
+func TestQueue_RunFinishedBetweenPolls(t *testing.T) {
+	p := &printer{instant: true}
+	ts := httptest.NewServer(p)
+	defer ts.Close()
+
+	q, err := New(octoprint.NewClient(ts.URL, ""), nil)
+	assert.NoError(t, err)
+
+	// the subscription ends before the print is done.
+	events := &fakeEvents{ch: make(chan push.Event)}
+	close(events.ch)
+
+	q.Events = events
+	q.Interval = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var done []string
+	q.OnDone = func(j Job) {
+		done = append(done, j.Path)
+		cancel()
+	}
+
+	q.Add(&Job{Path: "foo.gcode"})
+
+	assert.Equal(t, context.Canceled, q.Run(ctx))
+	assert.Equal(t, []string{"foo.gcode"}, done)
+}
+
+func TestQueue_RunResume(t *testing.T) {
+	p := &printer{printing: "foo.gcode"}
+	ts := httptest.NewServer(p)
+	defer ts.Close()
+
+	store := &memoryStore{jobs: []*Job{
+		{ID: "1", Path: "foo.gcode", Status: Printing},
+		{ID: "2", Path: "bar.gcode", Status: Pending},
+	}}
+
+	q, err := New(octoprint.NewClient(ts.URL, ""), store)
+	assert.NoError(t, err)
+	q.Interval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q.OnDone = func(j Job) {
+		if j.ID == "2" {
+			cancel()
+		}
+	}
+
+	assert.Equal(t, context.Canceled, q.Run(ctx))
+	assert.Equal(t, []string{"bar.gcode"}, p.prints)
+	assert.Equal(t, Done, store.jobs[0].Status)
+	assert.Equal(t, Done, store.jobs[1].Status)
+}
+
+type memoryStore struct {
+	jobs []*Job
+}
+
+func (s *memoryStore) Load() ([]*Job, error) { return s.jobs, nil }
+
+func (s *memoryStore) Save(jobs []*Job) error {
+	s.jobs = jobs
+	return nil
+}
@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Store persists the jobs of a queue, so it survives restarts.
+type Store interface {
+	// Load returns the jobs stored, in order.
+	Load() ([]*Job, error)
+	// Save stores the jobs, replacing the ones stored.
+	Save(jobs []*Job) error
+}
+
+// FileStore is a Store keeping the jobs in a JSON file.
+type FileStore struct {
+	// Path of the file, created on the first save.
+	Path string
+}
+
+// NewFileStore returns a new FileStore storing the jobs at the given path.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{Path: path}
+}
+
+// Load implements Store. No jobs are returned if the file doesn't exist.
+func (s *FileStore) Load() ([]*Job, error) {
+	b, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*Job
+	if err := json.Unmarshal(b, &jobs); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// Save implements Store. The file is replaced atomically, so it is never left
+// half written.
+func (s *FileStore) Save(jobs []*Job) error {
+	b, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.Path)
+}
@@ -0,0 +1,95 @@
+package octoprint
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithRateLimit limits the rate of requests sent by the client to rps requests
+// per second, allowing bursts of up to burst requests. Useful to avoid
+// overwhelming an OctoPrint instance running on a small board while printing
+// when polling aggressively. Critical commands, like cancelling a job, are
+// never delayed.
+func WithRateLimit(rps float64, burst int) Option {
+	return func(c *Client) {
+		if burst < 1 {
+			burst = 1
+		}
+
+		c.limiter = &rateLimiter{
+			rate:   rps,
+			burst:  float64(burst),
+			tokens: float64(burst),
+			last:   time.Now(),
+		}
+	}
+}
+
+type bypassRateLimitKey struct{}
+
+// WithoutRateLimit returns a copy of ctx marking the request as critical, so
+// it is sent immediately regardless of the configured rate limit.
+func WithoutRateLimit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassRateLimitKey{}, true)
+}
+
+// rateLimiter is a token bucket, refilled at rate tokens per second.
+type rateLimiter struct {
+	sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// Wait blocks until a request can be sent, or the context is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	if bypass, _ := ctx.Value(bypassRateLimitKey{}).(bool); bypass {
+		return nil
+	}
+
+	for {
+		wait := l.reserve()
+		if wait == 0 {
+			return nil
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// reserve takes a token if available, otherwise returns the time to wait
+// until the next one is.
+func (l *rateLimiter) reserve() time.Duration {
+	l.Lock()
+	defer l.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	if l.rate <= 0 {
+		return time.Second
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
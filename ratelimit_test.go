@@ -0,0 +1,50 @@
+package octoprint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"api": "0.1", "server": "1.3.9"}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "", WithRateLimit(20, 1))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := (&VersionRequest{}).Do(context.Background(), cli)
+		assert.NoError(t, err)
+	}
+
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+}
+
+func TestWithRateLimit_Bypass(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"api": "0.1", "server": "1.3.9"}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "", WithRateLimit(0.1, 1))
+	_, err := (&VersionRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	err = (&CancelRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = (&VersionRequest{}).Do(ctx, cli)
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package recorder
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recorder")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var versions int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		switch r.URL.Path {
+		case octoprint.URIVersion:
+			versions++
+			if versions == 1 {
+				w.Write([]byte(`{"api": "0.1", "server": "1.8.6", "text": "OctoPrint 1.8.6"}`))
+				return
+			}
+
+			w.Write([]byte(`{"api": "0.1", "server": "1.9.0", "text": "OctoPrint 1.9.0"}`))
+		case octoprint.URILogin:
+			w.Write([]byte(`{"name": "foo", "apikey": "userkey", "session": "abc"}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+
+	c := octoprint.NewClient(ts.URL, "secret", octoprint.WithTransport(New(dir, Record)))
+	v, err := (&octoprint.VersionRequest{}).Do(context.Background(), c)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.8.6", v.Server)
+
+	v, err = (&octoprint.VersionRequest{}).Do(context.Background(), c)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.9.0", v.Server)
+
+	_, err = (&octoprint.LoginRequest{Username: "foo", Password: "bar"}).Do(context.Background(), c)
+	assert.NoError(t, err)
+	ts.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 3)
+
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		assert.NoError(t, err)
+		for _, secret := range []string{"secret", "userkey", `"bar"`, `"abc"`} {
+			assert.False(t, strings.Contains(string(b), secret), "%s contains %s", f, secret)
+		}
+	}
+
+	c = octoprint.NewClient(ts.URL, "other", octoprint.WithTransport(New(dir, Replay)))
+	v, err = (&octoprint.VersionRequest{}).Do(context.Background(), c)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.8.6", v.Server)
+
+	v, err = (&octoprint.VersionRequest{}).Do(context.Background(), c)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.9.0", v.Server)
+
+	r, err := (&octoprint.LoginRequest{Username: "foo", Password: "dummy"}).Do(context.Background(), c)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", r.Name)
+	assert.Equal(t, redacted, r.Session)
+
+	_, err = (&octoprint.VersionRequest{}).Do(context.Background(), c)
+	assert.ErrorIs(t, err, ErrNoFixture)
+}
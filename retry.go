@@ -0,0 +1,151 @@
+package octoprint
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// DefaultRetryPolicy is a sensible retry policy for OctoPrint instances running
+// on small boards, where dropped connections and restarting proxies are common.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  10 * time.Second,
+	Jitter:      0.2,
+	StatusCodes: []int{502, 503, 504},
+}
+
+// RetryPolicy describes how transient failures are retried. Only idempotent
+// requests (GET and HEAD) are retried, any other request is only retried if
+// its context was marked with WithRetry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+	// MinBackoff is the time to wait before the first retry, it is doubled
+	// after every failed attempt.
+	MinBackoff time.Duration
+	// MaxBackoff is the upper limit of the time to wait between attempts.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, between 0 and 1, of random variation applied to
+	// every backoff, avoiding several clients retrying in lockstep.
+	Jitter float64
+	// StatusCodes is the list of HTTP status codes considered transient.
+	// Transport errors (timeouts, dropped connections, ...) are always
+	// considered transient.
+	StatusCodes []int
+}
+
+// WithRetryPolicy configures the client to retry transient failures following
+// the given policy.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = &p
+	}
+}
+
+type retryKey struct{}
+
+// WithRetry returns a copy of ctx marking the request as safe to be retried,
+// even if it is a command. Use it for commands that can be safely sent twice,
+// e.g. setting a target temperature or selecting a file.
+func WithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+func (p *RetryPolicy) isRetryable(req *http.Request) bool {
+	if p == nil || p.MaxAttempts <= 1 {
+		return false
+	}
+
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+
+	if req.Method == "GET" || req.Method == "HEAD" {
+		return true
+	}
+
+	retry, _ := req.Context().Value(retryKey{}).(bool)
+	return retry
+}
+
+func (p *RetryPolicy) isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var se *streamError
+	if errors.As(err, &se) {
+		return false
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+
+	for _, code := range p.StatusCodes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 + p.Jitter*(2*rand.Float64()-1)))
+	}
+
+	return d
+}
+
+// do executes fn, retrying it while it fails with a transient error and the
+// policy allows it.
+func (p *RetryPolicy) do(req *http.Request, log Logger, fn func(*http.Request) error) error {
+	if !p.isRetryable(req) {
+		return fn(req)
+	}
+
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		err := fn(req)
+		if err == nil || attempt >= p.MaxAttempts || !p.isTransient(ctx, err) {
+			return err
+		}
+
+		wait := p.backoff(attempt)
+		log.Warn("retrying request", "method", req.Method, "url", redactURL(req.URL),
+			"attempt", attempt, "backoff", wait, "error", err)
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+
+			req.Body = body
+		}
+	}
+}
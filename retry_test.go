@@ -0,0 +1,91 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+	StatusCodes: []int{502, 503},
+}
+
+func TestRetryPolicy_GET(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(503)
+			return
+		}
+
+		w.Write([]byte(`{"api": "0.1", "server": "1.3.9"}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "", WithRetryPolicy(testRetryPolicy))
+	v, err := (&VersionRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.3.9", v.Server)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryPolicy_GiveUp(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(502)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "", WithRetryPolicy(testRetryPolicy))
+	_, err := (&VersionRequest{}).Do(context.Background(), cli)
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryPolicy_Commands(t *testing.T) {
+	var calls int
+	var bodies [][]byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, b)
+		w.WriteHeader(503)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "", WithRetryPolicy(testRetryPolicy))
+	err := (&StartRequest{}).Do(context.Background(), cli)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = (&StartRequest{}).Do(WithRetry(context.Background()), cli)
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+	assert.True(t, bytes.Equal(bodies[1], bodies[3]))
+}
+
+func TestRetryPolicy_NotTransient(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(404)
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "", WithRetryPolicy(testRetryPolicy))
+	_, err := (&VersionRequest{}).Do(context.Background(), cli)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
@@ -1,21 +1,117 @@
 package octoprint
 
-import "encoding/json"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+)
 
-const URISettings = "/api/settings"
+const (
+	URISettings       = "/api/settings"
+	URISettingsAPIKey = "/api/settings/apikey"
+)
 
 // SettingsRequest retrieves the current configuration of OctoPrint.
 type SettingsRequest struct{}
 
 // Do sends an API request and returns the API response.
-func (cmd *SettingsRequest) Do(c *Client) (*Settings, error) {
-	b, err := c.doJSONRequest("GET", URISettings, nil, nil)
+func (cmd *SettingsRequest) Do(ctx context.Context, c *Client) (*Settings, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URISettings, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &Settings{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(URISettings, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// SettingsUpdateRequest updates the configuration of OctoPrint. Only the values
+// set on the request are sent, OctoPrint merges them into the current
+// configuration and returns the effective settings.
+type SettingsUpdateRequest struct {
+	// Settings is the partial settings document to send, e.g.
+	// `{"webcam": {"flipH": true}}`.
+	Settings map[string]interface{}
+}
+
+// Set sets the value at the given dot separated path, e.g.
+// `appearance.name` or `plugins.tracking.enabled`.
+func (cmd *SettingsUpdateRequest) Set(path string, value interface{}) *SettingsUpdateRequest {
+	if cmd.Settings == nil {
+		cmd.Settings = make(map[string]interface{})
+	}
+
+	keys := strings.Split(path, ".")
+	m := cmd.Settings
+	for _, k := range keys[:len(keys)-1] {
+		child, ok := m[k].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			m[k] = child
+		}
+
+		m = child
+	}
+
+	m[keys[len(keys)-1]] = value
+	return cmd
+}
+
+// SetTemperatureProfiles replaces the temperature presets with the given ones.
+func (cmd *SettingsUpdateRequest) SetTemperatureProfiles(p ...*TemperatureProfile) *SettingsUpdateRequest {
+	return cmd.Set("temperature.profiles", p)
+}
+
+// SetGCodeScript sets the content of the GCODE script with the given name, e.g.
+// `afterPrintCancelled` or `beforePrintStarted`.
+func (cmd *SettingsUpdateRequest) SetGCodeScript(name, script string) *SettingsUpdateRequest {
+	return cmd.Set("scripts.gcode."+name, script)
+}
+
+// SetWebcam replaces the webcam configuration. Every field is sent, so the
+// configuration is usually retrieved with SettingsRequest and modified.
+func (cmd *SettingsUpdateRequest) SetWebcam(w *WebcamConfig) *SettingsUpdateRequest {
+	return cmd.Set("webcam", w)
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *SettingsUpdateRequest) Do(ctx context.Context, c *Client) (*Settings, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd.Settings); err != nil {
+		return nil, err
+	}
+
+	b2, err := c.doJSONRequest(ctx, "POST", URISettings, b, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Settings{}
+	if err := c.decode(URISettings, b2, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// GenerateAPIKeyRequest generates a new global API key, replacing the current
+// one.
+type GenerateAPIKeyRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *GenerateAPIKeyRequest) Do(ctx context.Context, c *Client) (*APIKeyResponse, error) {
+	b, err := c.doJSONRequest(ctx, "POST", URISettingsAPIKey, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &APIKeyResponse{}
+	if err := c.decode(URISettingsAPIKey, b, r); err != nil {
 		return nil, err
 	}
 
@@ -1,6 +1,10 @@
 package octoprint
 
 import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,8 +14,93 @@ func TestSettingsRequest_Do(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
 	r := &SettingsRequest{}
-	settings, err := r.Do(cli)
+	settings, err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	assert.Equal(t, settings.API.Enabled, true)
 }
+
+func TestSettingsRequest_Decode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"api": {"allowCrossOrigin": false, "key": "foo"},
+			"appearance": {"name": "Prusa", "color": "orange", "showFahrenheitAlso": true},
+			"feature": {"sdSupport": true, "printStartConfirmation": true, "autoUppercaseBlacklist": ["M117"]},
+			"folder": {"uploads": "/home/pi/.octoprint/uploads"},
+			"printer": {"defaultExtrusionLength": 5},
+			"scripts": {"gcode": {"afterPrintCancelled": "M104 T0 S0"}},
+			"serial": {"port": "/dev/ttyACM0", "baudrate": 115200, "supportResendsWithoutOk": "detect"},
+			"server": {"allowFraming": true, "diskspace": {"warning": 524288000}},
+			"temperature": {"cutoff": 30, "profiles": [{"name": "PLA", "extruder": 210, "bed": 60}]},
+			"webcam": {
+				"webcamEnabled": true,
+				"streamUrl": "/webcam/?action=stream",
+				"bitrate": "10000k",
+				"watermark": true,
+				"flipH": true
+			},
+			"plugins": {"tracking": {"enabled": false}, "softwareupdate": {"check_overlay_url": ""}}
+		}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	s, err := (&SettingsRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "foo", s.API.Key)
+	assert.Equal(t, "Prusa", s.Appearance.Name)
+	assert.True(t, s.Appearance.ShowFahrenheitAlso)
+	assert.True(t, s.Feature.PrintStartConfirmation)
+	assert.Equal(t, []string{"M117"}, s.Feature.AutoUppercaseBlacklist)
+	assert.Equal(t, 5., s.Printer.DefaultExtrusionLength)
+	assert.Equal(t, "M104 T0 S0", s.Scripts.GCode["afterPrintCancelled"])
+	assert.Equal(t, "detect", s.Serial.SupportResendsWithoutOk)
+	assert.Equal(t, "detect", s.Serial.SupportResendsWIthoutOk)
+	assert.True(t, s.Server.AllowFraming)
+	assert.Equal(t, uint64(524288000), s.Server.Diskspace.Warning)
+	assert.Equal(t, 210., s.Temperature.Profiles[0].Extruder)
+	assert.True(t, s.Webcam.WebcamEnabled)
+	assert.Equal(t, "10000k", s.Webcam.Bitrate)
+	assert.True(t, s.Webcam.Watermark)
+	assert.Len(t, s.Plugins, 2)
+
+	tracking := struct {
+		Enabled bool `json:"enabled"`
+	}{Enabled: true}
+
+	ok, err := s.Plugin("tracking", &tracking)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, tracking.Enabled)
+
+	ok, err = s.Plugin("missing", &tracking)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSettingsUpdateRequest_Do(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"appearance": {"name": "Voron"}}`))
+	}))
+	defer ts.Close()
+
+	r := &SettingsUpdateRequest{}
+	r.Set("appearance.name", "Voron").
+		Set("appearance.color", "red").
+		SetGCodeScript("afterPrintCancelled", "M84").
+		SetTemperatureProfiles(&TemperatureProfile{Name: "PETG", Extruder: 240, Bed: 80})
+
+	cli := NewClient(ts.URL, "")
+	s, err := r.Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"appearance": {"name": "Voron", "color": "red"},
+		"scripts": {"gcode": {"afterPrintCancelled": "M84"}},
+		"temperature": {"profiles": [{"name": "PETG", "extruder": 240, "bed": 80}]}
+	}`, body)
+	assert.Equal(t, "Voron", s.Appearance.Name)
+}
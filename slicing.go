@@ -0,0 +1,167 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+const URISlicing = "/api/slicing"
+
+var (
+	SlicingErrors = statusMapping{
+		404: "The slicer or the profile does not exist",
+	}
+	SlicingProfileUpdateErrors = statusMapping{
+		400: "The profile data is invalid",
+		404: "The slicer or the profile does not exist",
+	}
+	SlicingProfileDeleteErrors = statusMapping{
+		404: "The slicer does not exist",
+		409: "The profile is the default profile of the slicer",
+	}
+)
+
+// SlicersRequest retrieves the available slicers and their profiles.
+type SlicersRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *SlicersRequest) Do(ctx context.Context, c *Client) (map[string]*Slicer, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URISlicing, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var r map[string]*Slicer
+	if err := c.decode(URISlicing, b, &r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// SlicingProfilesRequest retrieves the profiles of a slicer.
+type SlicingProfilesRequest struct {
+	// Slicer is the identifier of the slicer, e.g. `curalegacy`.
+	Slicer string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *SlicingProfilesRequest) Do(ctx context.Context, c *Client) (map[string]*SlicingProfile, error) {
+	uri := fmt.Sprintf("%s/%s/profiles", URISlicing, url.PathEscape(cmd.Slicer))
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, SlicingErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	var r map[string]*SlicingProfile
+	if err := c.decode(uri, b, &r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// SlicingProfileRequest retrieves a slicing profile, including its data.
+type SlicingProfileRequest struct {
+	// Slicer is the identifier of the slicer.
+	Slicer string
+	// Key is the identifier of the profile.
+	Key string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *SlicingProfileRequest) Do(ctx context.Context, c *Client) (*SlicingProfile, error) {
+	uri := slicingProfileURI(cmd.Slicer, cmd.Key)
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, SlicingErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSlicingProfile(c, uri, b)
+}
+
+// SlicingProfileCreateRequest creates a slicing profile, replacing it if
+// already exists. Returns the created profile.
+type SlicingProfileCreateRequest struct {
+	// Slicer is the identifier of the slicer.
+	Slicer string `json:"-"`
+	// Key is the identifier of the profile.
+	Key string `json:"-"`
+	// DisplayName of the profile.
+	DisplayName string `json:"displayName,omitempty"`
+	// Description of the profile.
+	Description string `json:"description,omitempty"`
+	// Data are the slicer specific settings of the profile.
+	Data map[string]interface{} `json:"data"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *SlicingProfileCreateRequest) Do(ctx context.Context, c *Client) (*SlicingProfile, error) {
+	return doSlicingProfileRequest(ctx, c, "PUT", slicingProfileURI(cmd.Slicer, cmd.Key), cmd, SlicingProfileUpdateErrors)
+}
+
+// SlicingProfileUpdateRequest updates an existing slicing profile, only the
+// provided fields and data keys are changed. Returns the updated profile.
+type SlicingProfileUpdateRequest struct {
+	// Slicer is the identifier of the slicer.
+	Slicer string `json:"-"`
+	// Key is the identifier of the profile.
+	Key string `json:"-"`
+	// DisplayName of the profile.
+	DisplayName *string `json:"displayName,omitempty"`
+	// Description of the profile.
+	Description *string `json:"description,omitempty"`
+	// Default whether to make the profile the default of the slicer.
+	Default *bool `json:"default,omitempty"`
+	// Data are the slicer specific settings to change.
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *SlicingProfileUpdateRequest) Do(ctx context.Context, c *Client) (*SlicingProfile, error) {
+	return doSlicingProfileRequest(ctx, c, "PATCH", slicingProfileURI(cmd.Slicer, cmd.Key), cmd, SlicingProfileUpdateErrors)
+}
+
+// SlicingProfileDeleteRequest deletes a slicing profile.
+type SlicingProfileDeleteRequest struct {
+	// Slicer is the identifier of the slicer.
+	Slicer string
+	// Key is the identifier of the profile.
+	Key string
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *SlicingProfileDeleteRequest) Do(ctx context.Context, c *Client) error {
+	_, err := c.doJSONRequest(ctx, "DELETE", slicingProfileURI(cmd.Slicer, cmd.Key), nil, SlicingProfileDeleteErrors)
+	return err
+}
+
+func slicingProfileURI(slicer, key string) string {
+	return fmt.Sprintf("%s/%s/profiles/%s", URISlicing, url.PathEscape(slicer), url.PathEscape(key))
+}
+
+func doSlicingProfileRequest(ctx context.Context, c *Client, method, uri string, cmd interface{}, m statusMapping) (*SlicingProfile, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	b2, err := c.doJSONRequest(ctx, method, uri, b, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSlicingProfile(c, uri, b2)
+}
+
+func decodeSlicingProfile(c *Client, uri string, b []byte) (*SlicingProfile, error) {
+	r := &SlicingProfile{}
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
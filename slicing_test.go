@@ -0,0 +1,96 @@
+package octoprint
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlicersRequest_Do(t *testing.T) {
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if r.URL.Path == URISlicing {
+			w.Write([]byte(`{"curalegacy": {
+				"key": "curalegacy",
+				"displayName": "Cura Legacy",
+				"default": true,
+				"configured": true,
+				"profiles": {"high_quality": {"key": "high_quality", "displayName": "High Quality", "default": true}}
+			}}`))
+			return
+		}
+
+		w.Write([]byte(`{"high_quality": {"key": "high_quality", "displayName": "High Quality", "default": true}}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	slicers, err := (&SlicersRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.True(t, slicers["curalegacy"].Configured)
+	assert.True(t, slicers["curalegacy"].Profiles["high_quality"].Default)
+
+	profiles, err := (&SlicingProfilesRequest{Slicer: "curalegacy"}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, URISlicing+"/curalegacy/profiles", path)
+	assert.Equal(t, "High Quality", profiles["high_quality"].DisplayName)
+}
+
+func TestSlicingProfileRequests_Do(t *testing.T) {
+	var method, path, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(b)
+		if r.Method == "DELETE" {
+			w.WriteHeader(204)
+			return
+		}
+
+		w.Write([]byte(`{
+			"key": "draft",
+			"displayName": "Draft",
+			"description": "Fast and ugly",
+			"default": false,
+			"resource": "http://example.com/api/slicing/curalegacy/profiles/draft",
+			"data": {"layer_height": 0.3, "fill_density": 10}
+		}`))
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	cli := NewClient(ts.URL, "")
+
+	p, err := (&SlicingProfileRequest{Slicer: "curalegacy", Key: "draft"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.3, p.Data["layer_height"])
+
+	_, err = (&SlicingProfileCreateRequest{
+		Slicer:      "curalegacy",
+		Key:         "draft",
+		DisplayName: "Draft",
+		Data:        map[string]interface{}{"layer_height": 0.3},
+	}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "PUT", method)
+	assert.Equal(t, URISlicing+"/curalegacy/profiles/draft", path)
+	assert.JSONEq(t, `{"displayName": "Draft", "data": {"layer_height": 0.3}}`, body)
+
+	isDefault := true
+	_, err = (&SlicingProfileUpdateRequest{
+		Slicer:  "curalegacy",
+		Key:     "draft",
+		Default: &isDefault,
+	}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "PATCH", method)
+	assert.JSONEq(t, `{"default": true}`, body)
+
+	err = (&SlicingProfileDeleteRequest{Slicer: "curalegacy", Key: "draft"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE", method)
+}
@@ -0,0 +1,95 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	URISoftwareUpdateCheck  = "/plugin/softwareupdate/check"
+	URISoftwareUpdateUpdate = "/plugin/softwareupdate/update"
+)
+
+var (
+	SoftwareUpdateCheckErrors = statusMapping{
+		403: "The user is not allowed to check for updates",
+		500: "The update information could not be retrieved",
+	}
+	SoftwareUpdateErrors = statusMapping{
+		400: "The targets are unknown or can't be updated",
+		403: "The user is not allowed to apply updates",
+		409: "The printer is printing or an update is already in progress",
+	}
+)
+
+// SoftwareUpdateCheckRequest checks for updates of OctoPrint and its plugins,
+// using the bundled software update plugin.
+type SoftwareUpdateCheckRequest struct {
+	// Targets are the components to check, all the components if empty.
+	Targets []string
+	// Force whether to bypass the cache of the server.
+	Force bool
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *SoftwareUpdateCheckRequest) Do(ctx context.Context, c *Client) (*SoftwareUpdateCheckResponse, error) {
+	q := url.Values{}
+	if len(cmd.Targets) != 0 {
+		q.Set("targets", strings.Join(cmd.Targets, ","))
+	}
+
+	if cmd.Force {
+		q.Set("force", "true")
+	}
+
+	uri := URISoftwareUpdateCheck
+	if len(q) != 0 {
+		uri = fmt.Sprintf("%s?%s", uri, q.Encode())
+	}
+
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, SoftwareUpdateCheckErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SoftwareUpdateCheckResponse{}
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// SoftwareUpdateRequest triggers the update of the given components. The update
+// runs in the background, and OctoPrint usually restarts afterwards.
+type SoftwareUpdateRequest struct {
+	// Targets are the components to update, all the components with updates
+	// available if empty.
+	Targets []string `json:"targets,omitempty"`
+	// Force whether to update even if no update is reported as available.
+	Force bool `json:"force,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *SoftwareUpdateRequest) Do(ctx context.Context, c *Client) (*SoftwareUpdateResponse, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	b2, err := c.doJSONRequest(ctx, "POST", URISoftwareUpdateUpdate, b, SoftwareUpdateErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SoftwareUpdateResponse{}
+	if err := c.decode(URISoftwareUpdateUpdate, b2, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
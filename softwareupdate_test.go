@@ -0,0 +1,70 @@
+package octoprint
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSoftwareUpdateCheckRequest_Do(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{
+			"status": "updatePossible",
+			"information": {
+				"octoprint": {
+					"displayName": "OctoPrint",
+					"displayVersion": "1.4.0",
+					"information": {
+						"local": {"name": "1.4.0", "value": "1.4.0"},
+						"remote": {"name": "1.4.2", "value": "1.4.2"}
+					},
+					"updateAvailable": true,
+					"updatePossible": true,
+					"online": true,
+					"error": null,
+					"releaseNotes": "https://github.com/OctoPrint/OctoPrint/releases/tag/1.4.2"
+				},
+				"pip": {
+					"displayName": "Pip",
+					"displayVersion": "20.0",
+					"updateAvailable": false,
+					"updatePossible": false
+				}
+			}
+		}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&SoftwareUpdateCheckRequest{Targets: []string{"octoprint", "pip"}, Force: true}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "force=true&targets=octoprint%2Cpip", query)
+	assert.Equal(t, "updatePossible", r.Status)
+
+	op := r.Information["octoprint"]
+	assert.Equal(t, "1.4.0", op.Information.Local.Value)
+	assert.Equal(t, "1.4.2", op.Information.Remote.Value)
+	assert.Equal(t, []string{"octoprint"}, r.Available())
+}
+
+func TestSoftwareUpdateRequest_Do(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.Write([]byte(`{"order": ["octoprint"], "checks": {}}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&SoftwareUpdateRequest{Targets: []string{"octoprint"}}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"targets": ["octoprint"]}`, body)
+	assert.Equal(t, []string{"octoprint"}, r.Order)
+}
@@ -1,14 +1,19 @@
 package octoprint
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 )
 
-var ExecuteErrors = statusMapping{
-	404: "The command could not be found for source and action",
-	500: "The command didn’t define a command to execute, the command returned a non-zero return code and ignore was not true or some other internal server error occurred",
-}
+var (
+	ExecuteErrors = statusMapping{
+		404: "The command could not be found for source and action",
+		500: "The command didn’t define a command to execute, the command returned a non-zero return code and ignore was not true or some other internal server error occurred",
+	}
+	SystemSourceErrors = statusMapping{
+		404: "The source is neither core nor custom",
+	}
+)
 
 const URISystemCommands = "/api/system/commands"
 
@@ -16,43 +21,58 @@ const URISystemCommands = "/api/system/commands"
 type SystemCommandsRequest struct{}
 
 // Do sends an API request and returns the API response.
-func (cmd *SystemCommandsRequest) Do(c *Client) (*SystemCommandsResponse, error) {
-	b, err := c.doJSONRequest("GET", URISystemCommands, nil, nil)
+func (cmd *SystemCommandsRequest) Do(ctx context.Context, c *Client) (*SystemCommandsResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URISystemCommands, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &SystemCommandsResponse{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(URISystemCommands, b, r); err != nil {
 		return nil, err
 	}
-	for i := range r.Core {
-		x := r.Core[i]
-		if err2 := json.Unmarshal(x.RawConfirm, x.Confirm); err2 != nil {
-		    x.Confirm = ""
-		}
+
+	return r, err
+}
+
+// SystemSourceCommandsRequest retrieves the system commands configured for a
+// given source.
+type SystemSourceCommandsRequest struct {
+	// Source for which to list commands.
+	Source CommandSource
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *SystemSourceCommandsRequest) Do(ctx context.Context, c *Client) ([]*CommandDefinition, error) {
+	uri := fmt.Sprintf("%s/%s", URISystemCommands, cmd.Source)
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, SystemSourceErrors)
+	if err != nil {
+		return nil, err
 	}
-	for i := range r.Custom {
-		x := r.Custom[i]
-		if err2 := json.Unmarshal(x.RawConfirm, x.Confirm); err2 != nil {
-		    x.Confirm = ""
-		}
+
+	var r []*CommandDefinition
+	if err := c.decode(uri, b, &r); err != nil {
+		return nil, err
 	}
 
 	return r, err
 }
 
-// SystemExecuteCommandRequest retrieves all configured system commands.
+// SystemExecuteCommandRequest executes a configured system command.
 type SystemExecuteCommandRequest struct {
-	// Source for which to list commands.
+	// Source of the command.
 	Source CommandSource `json:"source"`
 	// Action is the identifier of the command, action from its definition.
 	Action string `json:"action"`
 }
 
 // Do sends an API request and returns an error if any.
-func (cmd *SystemExecuteCommandRequest) Do(c *Client) error {
+func (cmd *SystemExecuteCommandRequest) Do(ctx context.Context, c *Client) error {
+	if cmd.Action == CommandDivider {
+		return fmt.Errorf("invalid action %q, dividers can't be executed", cmd.Action)
+	}
+
 	uri := fmt.Sprintf("%s/%s/%s", URISystemCommands, cmd.Source, cmd.Action)
-	_, err := c.doJSONRequest("POST", uri, nil, ExecuteErrors)
+	_, err := c.doJSONRequest(ctx, "POST", uri, nil, ExecuteErrors)
 	return err
 }
@@ -1,6 +1,9 @@
 package octoprint
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,7 +13,7 @@ func TestSystemCommandsRequest_Do(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
 	r := &SystemCommandsRequest{}
-	state, err := r.Do(cli)
+	state, err := r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 
 	assert.Len(t, state.Core, 1)
@@ -22,10 +25,60 @@ func TestSystemExecuteCommandRequest_Do(t *testing.T) {
 	cli := NewClient("http://localhost:5000", "")
 
 	r := &SystemExecuteCommandRequest{}
-	err := r.Do(cli)
+	err := r.Do(context.Background(), cli)
 	assert.Error(t, err)
 
 	r = &SystemExecuteCommandRequest{Source: Core, Action: "shutdown"}
-	err = r.Do(cli)
+	err = r.Do(context.Background(), cli)
 	assert.NoError(t, err)
 }
+
+func TestSystemCommandsRequest_Decode(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"core": [
+				{"action": "shutdown", "name": "Shutdown system", "confirm": "You are about to shutdown the system.", "source": "core", "resource": "http://example.com/api/system/commands/core/shutdown"},
+				{"action": "restart", "name": "Restart OctoPrint", "confirm": false, "source": "core"}
+			],
+			"custom": [
+				{"action": "divider"},
+				{"action": "backup", "name": "Backup", "command": "/usr/bin/backup", "async": true, "source": "custom"}
+			]
+		}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&SystemCommandsRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+
+	assert.Len(t, r.Core, 2)
+	assert.Len(t, r.Custom, 2)
+	assert.Equal(t, "You are about to shutdown the system.", r.Core[0].Confirm)
+	assert.Equal(t, "", r.Core[1].Confirm)
+	assert.True(t, r.Custom[0].IsDivider())
+
+	cmds := r.Commands()
+	assert.Len(t, cmds, 3)
+	assert.Equal(t, "/usr/bin/backup", r.Find(Custom, "backup").Command)
+	assert.Nil(t, r.Find(Core, "backup"))
+
+	err = (&SystemExecuteCommandRequest{Source: Custom, Action: CommandDivider}).Do(context.Background(), cli)
+	assert.Error(t, err)
+}
+
+func TestSystemSourceCommandsRequest_Do(t *testing.T) {
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`[{"action": "reboot", "name": "Reboot", "confirm": false, "source": "core"}]`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	cmds, err := (&SystemSourceCommandsRequest{Source: Core}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, URISystemCommands+"/core", path)
+	assert.Len(t, cmds, 1)
+	assert.Equal(t, "reboot", cmds[0].Action)
+}
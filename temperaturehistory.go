@@ -0,0 +1,182 @@
+package octoprint
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TemperatureHistory is a bounded in-memory history of temperatures, fed from
+// polled state (StateRequest with History) or from the push API, keeping the
+// most recent samples. It is safe for concurrent use.
+type TemperatureHistory struct {
+	resolution time.Duration
+
+	mu    sync.RWMutex
+	buf   []HistoricTemperatureData
+	start int
+	n     int
+}
+
+// TemperaturePoint is a temperature of a single tool at a given time.
+type TemperaturePoint struct {
+	// Time of the sample.
+	Time time.Time
+	TemperatureData
+}
+
+// NewTemperatureHistory returns a history keeping up to size samples. If
+// resolution is not zero the samples are downsampled, dropping the samples
+// closer than resolution to the previous one kept.
+func NewTemperatureHistory(size int, resolution time.Duration) *TemperatureHistory {
+	if size < 1 {
+		size = 1
+	}
+
+	return &TemperatureHistory{
+		resolution: resolution,
+		buf:        make([]HistoricTemperatureData, size),
+	}
+}
+
+// Add adds the given samples, in chronological order. Samples not newer than
+// the last one kept are ignored, so overlapping histories can be added safely,
+// and the oldest samples are discarded once the history is full.
+func (h *TemperatureHistory) Add(samples ...*HistoricTemperatureData) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, s := range samples {
+		if s == nil {
+			continue
+		}
+
+		if h.n > 0 {
+			last := h.at(h.n - 1).Time.Time
+			if !s.Time.After(last) || s.Time.Sub(last) < h.resolution {
+				continue
+			}
+		}
+
+		sample := HistoricTemperatureData{Time: s.Time, Tools: copyTools(s.Tools)}
+		if h.n < len(h.buf) {
+			h.buf[(h.start+h.n)%len(h.buf)] = sample
+			h.n++
+			continue
+		}
+
+		h.buf[h.start] = sample
+		h.start = (h.start + 1) % len(h.buf)
+	}
+}
+
+// Collect adds the samples received from ch, e.g. a push TemperatureStream,
+// until ch is closed or ctx is done.
+func (h *TemperatureHistory) Collect(ctx context.Context, ch <-chan HistoricTemperatureData) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			h.Add(&s)
+		}
+	}
+}
+
+// Len returns the number of samples in the history.
+func (h *TemperatureHistory) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.n
+}
+
+// Latest returns up to the n most recent samples, oldest first.
+func (h *TemperatureHistory) Latest(n int) []*HistoricTemperatureData {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if n > h.n {
+		n = h.n
+	}
+
+	if n < 0 {
+		n = 0
+	}
+
+	return h.slice(h.n-n, h.n)
+}
+
+// Range returns the samples taken from from, inclusive, to to, exclusive,
+// oldest first.
+func (h *TemperatureHistory) Range(from, to time.Time) []*HistoricTemperatureData {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	i := 0
+	for i < h.n && h.at(i).Time.Before(from) {
+		i++
+	}
+
+	j := i
+	for j < h.n && h.at(j).Time.Before(to) {
+		j++
+	}
+
+	return h.slice(i, j)
+}
+
+// Series returns the temperatures of the given tool, e.g. `tool0` or `bed`,
+// oldest first. Samples without the tool are skipped.
+func (h *TemperatureHistory) Series(tool string) []TemperaturePoint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var series []TemperaturePoint
+	for i := 0; i < h.n; i++ {
+		s := h.at(i)
+		if t, ok := s.Tools[tool]; ok {
+			series = append(series, TemperaturePoint{Time: s.Time.Time, TemperatureData: t})
+		}
+	}
+
+	return series
+}
+
+// at returns the i-th oldest sample.
+func (h *TemperatureHistory) at(i int) *HistoricTemperatureData {
+	return &h.buf[(h.start+i)%len(h.buf)]
+}
+
+// slice returns copies of the samples from the i-th to the j-th oldest.
+func (h *TemperatureHistory) slice(i, j int) []*HistoricTemperatureData {
+	samples := make([]*HistoricTemperatureData, 0, j-i)
+	for ; i < j; i++ {
+		s := h.at(i)
+		samples = append(samples, &HistoricTemperatureData{Time: s.Time, Tools: copyTools(s.Tools)})
+	}
+
+	return samples
+}
+
+func copyTools(tools map[string]TemperatureData) map[string]TemperatureData {
+	c := make(map[string]TemperatureData, len(tools))
+	for k, v := range tools {
+		v.Target, v.Offset = copyFloat(v.Target), copyFloat(v.Offset)
+		c[k] = v
+	}
+
+	return c
+}
+
+func copyFloat(f *float64) *float64 {
+	if f == nil {
+		return nil
+	}
+
+	v := *f
+	return &v
+}
@@ -0,0 +1,80 @@
+package octoprint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func temperatureSample(sec int64, tool0 float64) *HistoricTemperatureData {
+	return &HistoricTemperatureData{
+		Time:  UnixTime{Time: time.Unix(sec, 0)},
+		Tools: map[string]TemperatureData{"tool0": {Actual: tool0}},
+	}
+}
+
+func TestTemperatureHistory(t *testing.T) {
+	h := NewTemperatureHistory(3, 0)
+	h.Add(temperatureSample(1, 20), temperatureSample(2, 21))
+	h.Add(temperatureSample(2, 99), temperatureSample(3, 22), temperatureSample(4, 23))
+	assert.Equal(t, 3, h.Len())
+
+	latest := h.Latest(2)
+	assert.Len(t, latest, 2)
+	assert.Equal(t, 22., latest[0].Tools["tool0"].Actual)
+	assert.Equal(t, 23., latest[1].Tools["tool0"].Actual)
+	assert.Len(t, h.Latest(10), 3)
+	assert.Len(t, h.Latest(-1), 0)
+
+	// the samples returned are copies.
+	latest[1].Tools["tool0"] = TemperatureData{Actual: 99}
+	assert.Equal(t, 23., h.Latest(1)[0].Tools["tool0"].Actual)
+
+	r := h.Range(time.Unix(3, 0), time.Unix(4, 0))
+	assert.Len(t, r, 1)
+	assert.Equal(t, int64(3), r[0].Time.Unix())
+
+	series := h.Series("tool0")
+	assert.Len(t, series, 3)
+	assert.Equal(t, time.Unix(2, 0), series[0].Time)
+	assert.Equal(t, 21., series[0].Actual)
+	assert.Len(t, h.Series("bed"), 0)
+
+	// including the targets.
+	target := 210.
+	h.Add(&HistoricTemperatureData{
+		Time:  UnixTime{Time: time.Unix(5, 0)},
+		Tools: map[string]TemperatureData{"tool0": {Actual: 24, Target: &target}},
+	})
+
+	target = 0
+	*h.Latest(1)[0].Tools["tool0"].Target = 0
+	assert.Equal(t, 210., *h.Latest(1)[0].Tools["tool0"].Target)
+}
+
+func TestTemperatureHistory_Downsampling(t *testing.T) {
+	h := NewTemperatureHistory(10, 5*time.Second)
+	for i := int64(0); i < 12; i++ {
+		h.Add(temperatureSample(i, float64(i)))
+	}
+
+	var times []int64
+	for _, s := range h.Latest(10) {
+		times = append(times, s.Time.Unix())
+	}
+
+	assert.Equal(t, []int64{0, 5, 10}, times)
+}
+
+func TestTemperatureHistory_Collect(t *testing.T) {
+	ch := make(chan HistoricTemperatureData, 2)
+	ch <- *temperatureSample(1, 20)
+	ch <- *temperatureSample(2, 21)
+	close(ch)
+
+	h := NewTemperatureHistory(10, 0)
+	h.Collect(context.Background(), ch)
+	assert.Equal(t, 2, h.Len())
+}
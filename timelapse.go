@@ -0,0 +1,149 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+const (
+	URITimelapse           = "/api/timelapse"
+	URITimelapseUnrendered = "/api/timelapse/unrendered"
+	URIDownloadTimelapse   = "/downloads/timelapse"
+)
+
+var (
+	TimelapseErrors = statusMapping{
+		404: "The timelapse does not exist",
+		409: "The timelapse is currently being recorded or rendered",
+	}
+	TimelapseConfigErrors = statusMapping{
+		400: "Invalid timelapse configuration",
+	}
+)
+
+// TimelapseRequest retrieves the finished timelapses and the current timelapse
+// configuration.
+type TimelapseRequest struct {
+	// Unrendered whether to also retrieve the unrendered timelapses.
+	Unrendered bool
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *TimelapseRequest) Do(ctx context.Context, c *Client) (*TimelapseResponse, error) {
+	uri := URITimelapse
+	if cmd.Unrendered {
+		uri = fmt.Sprintf("%s?unrendered=true", uri)
+	}
+
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeTimelapseResponse(c, uri, b)
+}
+
+// TimelapseDownloadRequest downloads a finished timelapse, streaming its
+// content to the given writer.
+type TimelapseDownloadRequest struct {
+	// Filename of the timelapse to download.
+	Filename string
+	// Progress is called while downloading the file. Optional.
+	Progress ProgressFunc
+}
+
+// Do sends an API request writing the content of the timelapse to w, and
+// returns the amount of bytes written, even on error.
+func (cmd *TimelapseDownloadRequest) Do(ctx context.Context, c *Client, w io.Writer) (int64, error) {
+	uri := fmt.Sprintf("%s/%s", URIDownloadTimelapse, url.PathEscape(cmd.Filename))
+	return c.download(ctx, uri, TimelapseErrors, w, cmd.Progress)
+}
+
+// TimelapseDeleteRequest deletes a finished timelapse. Returns the updated list
+// of timelapses.
+type TimelapseDeleteRequest struct {
+	// Filename of the timelapse to delete.
+	Filename string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *TimelapseDeleteRequest) Do(ctx context.Context, c *Client) (*TimelapseResponse, error) {
+	uri := fmt.Sprintf("%s/%s", URITimelapse, url.PathEscape(cmd.Filename))
+	b, err := c.doJSONRequest(ctx, "DELETE", uri, nil, TimelapseErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeTimelapseResponse(c, uri, b)
+}
+
+// TimelapseRenderRequest renders an unrendered timelapse.
+type TimelapseRenderRequest struct {
+	// Name of the unrendered timelapse to render.
+	Name string
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *TimelapseRenderRequest) Do(ctx context.Context, c *Client) error {
+	uri := fmt.Sprintf("%s/%s", URITimelapseUnrendered, url.PathEscape(cmd.Name))
+	return doCommandRequest(ctx, c, uri, "render", TimelapseErrors)
+}
+
+// TimelapseDeleteUnrenderedRequest deletes an unrendered timelapse. Returns the
+// updated list of timelapses.
+type TimelapseDeleteUnrenderedRequest struct {
+	// Name of the unrendered timelapse to delete.
+	Name string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *TimelapseDeleteUnrenderedRequest) Do(ctx context.Context, c *Client) (*TimelapseResponse, error) {
+	uri := fmt.Sprintf("%s/%s", URITimelapseUnrendered, url.PathEscape(cmd.Name))
+	b, err := c.doJSONRequest(ctx, "DELETE", uri, nil, TimelapseErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeTimelapseResponse(c, uri, b)
+}
+
+// TimelapseConfigRequest changes the timelapse configuration. Returns the
+// updated list of timelapses and configuration.
+type TimelapseConfigRequest struct {
+	TimelapseConfig
+	// Save whether to persist the configuration as the new default, instead
+	// of only using it for the next print.
+	Save bool `json:"save"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *TimelapseConfigRequest) Do(ctx context.Context, c *Client) (*TimelapseResponse, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	b2, err := c.doJSONRequest(ctx, "POST", URITimelapse, b, TimelapseConfigErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeTimelapseResponse(c, URITimelapse, b2)
+}
+
+func decodeTimelapseResponse(c *Client, uri string, b []byte) (*TimelapseResponse, error) {
+	r := &TimelapseResponse{}
+	if len(b) == 0 {
+		return r, nil
+	}
+
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
@@ -0,0 +1,192 @@
+// Package timelapse downloads the rendered timelapses of OctoPrint to a local
+// directory, optionally deleting them from OctoPrint afterwards to free space.
+//
+//	s := timelapse.New(c, "/srv/timelapses")
+//	s.Events = p // a connected *push.Client, optional
+//	s.Delete = true
+//	err := s.Run(ctx)
+//
+// The timelapses already downloaded, a file with the same name and size, are
+// skipped, so the directory can be synced several times or by several runs.
+package timelapse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/mcuadros/go-octoprint/push"
+)
+
+// partialSuffix is the suffix of the files being downloaded.
+const partialSuffix = ".part"
+
+// Events is a source of events, implemented by *push.Client.
+type Events interface {
+	Subscribe(ctx context.Context, events ...push.EventType) (<-chan push.Event, error)
+}
+
+// Syncer downloads the rendered timelapses to a local directory.
+type Syncer struct {
+	// Client is the client of the OctoPrint server.
+	Client *octoprint.Client
+	// Dir is the local directory where the timelapses are stored, created if
+	// it doesn't exist.
+	Dir string
+	// Delete whether to delete the timelapses from OctoPrint once
+	// downloaded.
+	Delete bool
+	// Events is used to sync as soon as a timelapse is rendered. If nil, or
+	// the subscription ends, the timelapses are only polled.
+	Events Events
+	// Interval is the interval between polls, zero means
+	// octoprint.DefaultWatchInterval.
+	Interval time.Duration
+	// OnDownload is called for every timelapse downloaded.
+	OnDownload func(f *octoprint.TimelapseFile, path string)
+	// OnError is called with the errors syncing, Run keeps running.
+	OnError func(err error)
+}
+
+// New returns a Syncer downloading the timelapses to dir.
+func New(c *octoprint.Client, dir string) *Syncer {
+	return &Syncer{Client: c, Dir: dir}
+}
+
+// Run syncs the timelapses every Interval, and every time a timelapse is
+// rendered if Events is set, until ctx is done, returning ctx.Err().
+func (s *Syncer) Run(ctx context.Context) error {
+	var events <-chan push.Event
+	if s.Events != nil {
+		var err error
+		events, err = s.Events.Subscribe(ctx, push.EventMovieDone)
+		if err != nil {
+			s.error(err)
+		}
+	}
+
+	t := time.NewTicker(s.interval())
+	defer t.Stop()
+
+	for {
+		if _, err := s.Sync(ctx); err != nil && ctx.Err() == nil {
+			s.error(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		case _, ok := <-events:
+			if !ok {
+				events = nil
+			}
+		}
+	}
+}
+
+// Sync downloads the timelapses not in Dir yet, returning the paths of the
+// downloaded files. The timelapses are deleted from OctoPrint if Delete is set,
+// including the ones downloaded previously. It stops at the first error.
+func (s *Syncer) Sync(ctx context.Context) ([]string, error) {
+	r, err := (&octoprint.TimelapseRequest{}).Do(ctx, s.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var downloaded []string
+	for _, f := range r.Files {
+		path, err := s.localPath(f.Name)
+		if err != nil {
+			return downloaded, err
+		}
+
+		if !exists(path, f.Bytes) {
+			if err := s.download(ctx, f, path); err != nil {
+				return downloaded, err
+			}
+
+			downloaded = append(downloaded, path)
+			if s.OnDownload != nil {
+				s.OnDownload(f, path)
+			}
+		}
+
+		if !s.Delete {
+			continue
+		}
+
+		if _, err := (&octoprint.TimelapseDeleteRequest{Filename: f.Name}).Do(ctx, s.Client); err != nil {
+			return downloaded, fmt.Errorf("error deleting timelapse %q: %w", f.Name, err)
+		}
+	}
+
+	return downloaded, nil
+}
+
+// download downloads the timelapse to a temporary file, renamed to path once
+// complete, so interrupted downloads are never taken as synced.
+func (s *Syncer) download(ctx context.Context, f *octoprint.TimelapseFile, path string) error {
+	tmp := path + partialSuffix
+	w, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	n, err := (&octoprint.TimelapseDownloadRequest{Filename: f.Name}).Do(ctx, s.Client, w)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil && uint64(n) != f.Bytes {
+		err = fmt.Errorf("unexpected size %d, expected %d", n, f.Bytes)
+	}
+
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("error downloading timelapse %q: %w", f.Name, err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// localPath returns the path of a timelapse in Dir, rejecting the names
+// escaping it.
+func (s *Syncer) localPath(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid timelapse name %q", name)
+	}
+
+	return filepath.Join(s.Dir, name), nil
+}
+
+func (s *Syncer) interval() time.Duration {
+	if s.Interval <= 0 {
+		return octoprint.DefaultWatchInterval
+	}
+
+	return s.Interval
+}
+
+func (s *Syncer) error(err error) {
+	if s.OnError != nil {
+		s.OnError(err)
+	}
+}
+
+// exists returns whether a regular file exists at path with the given size.
+func exists(path string, size uint64) bool {
+	fi, err := os.Stat(path)
+	if err != nil || !fi.Mode().IsRegular() {
+		return false
+	}
+
+	return uint64(fi.Size()) == size
+}
@@ -0,0 +1,154 @@
+package timelapse
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/mcuadros/go-octoprint/push"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEvents chan push.Event
+
+func (e fakeEvents) Subscribe(ctx context.Context, events ...push.EventType) (<-chan push.Event, error) {
+	return e, nil
+}
+
+// server is a fake OctoPrint serving the given timelapses.
+type server struct {
+	sync.Mutex
+	files     map[string]string
+	downloads []string
+	deleted   []string
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == octoprint.URITimelapse:
+		resp := &octoprint.TimelapseResponse{}
+		for name, content := range s.files {
+			resp.Files = append(resp.Files, &octoprint.TimelapseFile{Name: name, Bytes: uint64(len(content))})
+		}
+
+		json.NewEncoder(w).Encode(resp)
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, octoprint.URIDownloadTimelapse+"/"):
+		name := strings.TrimPrefix(r.URL.Path, octoprint.URIDownloadTimelapse+"/")
+		s.downloads = append(s.downloads, name)
+		w.Write([]byte(s.files[name]))
+	case r.Method == "DELETE":
+		name := strings.TrimPrefix(r.URL.Path, octoprint.URITimelapse+"/")
+		s.deleted = append(s.deleted, name)
+		delete(s.files, name)
+		w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	srv := &server{files: map[string]string{
+		"benchy.mp4": "benchy",
+		"cube.mp4":   "cube",
+	}}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "timelapse")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// cube.mp4 was downloaded before, benchy.mp4 only partially.
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "cube.mp4"), []byte("cube"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "benchy.mp4"), []byte("ben"), 0644))
+
+	var notified []string
+	s := New(octoprint.NewClient(ts.URL, ""), dir)
+	s.OnDownload = func(f *octoprint.TimelapseFile, path string) {
+		notified = append(notified, f.Name)
+	}
+
+	downloaded, err := s.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "benchy.mp4")}, downloaded)
+	assert.Equal(t, []string{"benchy.mp4"}, notified)
+	assert.Equal(t, []string{"benchy.mp4"}, srv.downloads)
+	assert.Len(t, srv.deleted, 0)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "benchy.mp4"))
+	assert.NoError(t, err)
+	assert.Equal(t, "benchy", string(b))
+
+	// the synced timelapses are deleted, without downloading them again.
+	s.Delete = true
+	downloaded, err = s.Sync(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, downloaded, 0)
+	assert.Len(t, srv.downloads, 1)
+	assert.ElementsMatch(t, []string{"benchy.mp4", "cube.mp4"}, srv.deleted)
+	assert.Len(t, srv.files, 0)
+}
+
+func TestSyncer_SyncInvalidName(t *testing.T) {
+	srv := &server{files: map[string]string{"..": "x"}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "timelapse")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = New(octoprint.NewClient(ts.URL, ""), dir).Sync(context.Background())
+	assert.EqualError(t, err, `invalid timelapse name ".."`)
+}
+
+func TestSyncer_Run(t *testing.T) {
+	srv := &server{files: map[string]string{}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "timelapse")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	done := make(chan string, 1)
+	events := make(fakeEvents, 1)
+	s := New(octoprint.NewClient(ts.URL, ""), dir)
+	s.Events = events
+	s.Interval = time.Hour
+	s.OnDownload = func(f *octoprint.TimelapseFile, path string) {
+		done <- f.Name
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- s.Run(ctx) }()
+
+	srv.Lock()
+	srv.files["benchy.mp4"] = "benchy"
+	srv.Unlock()
+	events <- push.Event{Type: push.EventMovieDone}
+
+	select {
+	case name := <-done:
+		assert.Equal(t, "benchy.mp4", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timelapse not synced")
+	}
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-errs)
+}
@@ -0,0 +1,107 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const timelapseJSON = `{
+	"config": {"type": "zchange", "postRoll": 0, "fps": 25, "retractionZHop": 0.2},
+	"enabled": true,
+	"files": [{
+		"name": "benchy_20190101.mp4",
+		"size": "1.2MB",
+		"bytes": 1258291,
+		"date": "2019-01-01 12:30",
+		"timestamp": 1546345800.0,
+		"url": "/downloads/timelapse/benchy_20190101.mp4"
+	}],
+	"unrendered": [{
+		"name": "cube_20190102",
+		"size": "5.0MB",
+		"bytes": 5242880,
+		"date": "2019-01-02 09:00",
+		"recording": false,
+		"rendering": true,
+		"processing": true
+	}]
+}`
+
+func TestTimelapseRequest_Do(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(timelapseJSON))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&TimelapseRequest{Unrendered: true}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "unrendered=true", query)
+	assert.Equal(t, "zchange", r.Config.Type)
+	assert.Equal(t, 0.2, r.Config.RetractionZHop)
+	assert.Len(t, r.Files, 1)
+	assert.Equal(t, uint64(1258291), r.Files[0].Bytes)
+	assert.Equal(t, "2019-01-01 12:30", r.Files[0].Date)
+	assert.Equal(t, int64(1546345800), r.Files[0].Timestamp.Unix())
+	assert.True(t, r.Unrendered[0].Timestamp.IsZero())
+	assert.Len(t, r.Unrendered, 1)
+	assert.True(t, r.Unrendered[0].Rendering)
+}
+
+func TestTimelapseCommands_Do(t *testing.T) {
+	var method, path, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(b)
+		switch {
+		case r.URL.Path == URIDownloadTimelapse+"/benchy.mp4":
+			w.Write([]byte("mp4"))
+		case r.Method == "POST" && r.URL.Path != URITimelapse:
+			w.WriteHeader(204)
+		default:
+			w.Write([]byte(timelapseJSON))
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	cli := NewClient(ts.URL, "")
+
+	buf := bytes.NewBuffer(nil)
+	n, err := (&TimelapseDownloadRequest{Filename: "benchy.mp4"}).Do(ctx, cli, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, "mp4", buf.String())
+
+	r, err := (&TimelapseDeleteRequest{Filename: "benchy.mp4"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE", method)
+	assert.Equal(t, URITimelapse+"/benchy.mp4", path)
+	assert.Len(t, r.Files, 1)
+
+	err = (&TimelapseRenderRequest{Name: "cube"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, URITimelapseUnrendered+"/cube", path)
+	assert.JSONEq(t, `{"command": "render"}`, body)
+
+	_, err = (&TimelapseDeleteUnrenderedRequest{Name: "cube"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE", method)
+	assert.Equal(t, URITimelapseUnrendered+"/cube", path)
+
+	_, err = (&TimelapseConfigRequest{
+		TimelapseConfig: TimelapseConfig{Type: "timed", Interval: 10, FPS: 30},
+		Save:            true,
+	}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, URITimelapse, path)
+	assert.JSONEq(t, `{"type": "timed", "interval": 10, "fps": 30, "save": true}`, body)
+}
@@ -0,0 +1,84 @@
+package octoprint
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// TimeoutClass classifies requests by their expected duration, so different
+// timeouts can be applied to each of them.
+type TimeoutClass int
+
+const (
+	// StatusClass is the class of requests retrieving information, like the
+	// printer or the job state. Default class for GET requests.
+	StatusClass TimeoutClass = iota
+	// CommandClass is the class of commands sent to the server or the
+	// printer. Default class for any non GET request.
+	CommandClass
+	// TransferClass is the class of slow operations, like file uploads and
+	// downloads, slicing or backup creation.
+	TransferClass
+)
+
+// Timeouts are the per class timeouts applied to every attempt of a request. A
+// zero value means no timeout, besides any set at the http.Client or at the
+// context of the request.
+type Timeouts struct {
+	// Status is the timeout for requests of the StatusClass.
+	Status time.Duration
+	// Command is the timeout for requests of the CommandClass.
+	Command time.Duration
+	// Transfer is the timeout for requests of the TransferClass.
+	Transfer time.Duration
+}
+
+// WithTimeouts sets per class timeouts, allowing e.g. quick status calls to
+// fail fast while uploading big files is still possible.
+func WithTimeouts(t Timeouts) Option {
+	return func(c *Client) {
+		c.timeouts = t
+	}
+}
+
+type timeoutClassKey struct{}
+
+// WithTimeoutClass returns a copy of ctx overriding the timeout class of the
+// request.
+func WithTimeoutClass(ctx context.Context, class TimeoutClass) context.Context {
+	return context.WithValue(ctx, timeoutClassKey{}, class)
+}
+
+func (t Timeouts) forRequest(req *http.Request) time.Duration {
+	class, ok := req.Context().Value(timeoutClassKey{}).(TimeoutClass)
+	if !ok {
+		class = CommandClass
+		if req.Method == "GET" || req.Method == "HEAD" {
+			class = StatusClass
+		}
+	}
+
+	switch class {
+	case StatusClass:
+		return t.Status
+	case CommandClass:
+		return t.Command
+	case TransferClass:
+		return t.Transfer
+	}
+
+	return 0
+}
+
+// withTimeout returns a copy of req, with the timeout of its class applied to
+// its context.
+func (t Timeouts) withTimeout(req *http.Request) (*http.Request, context.CancelFunc) {
+	d := t.forRequest(req)
+	if d <= 0 {
+		return req, func() {}
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), d)
+	return req.WithContext(ctx), cancel
+}
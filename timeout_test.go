@@ -0,0 +1,35 @@
+package octoprint
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTimeouts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.Write([]byte(`{"api": "0.1", "server": "1.3.9"}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "", WithTimeouts(Timeouts{
+		Status:   10 * time.Millisecond,
+		Transfer: time.Second,
+	}))
+
+	_, err := (&VersionRequest{}).Do(context.Background(), cli)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	ctx := WithTimeoutClass(context.Background(), TransferClass)
+	_, err = (&VersionRequest{}).Do(ctx, cli)
+	assert.NoError(t, err)
+
+	err = (&StartRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,229 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const URIUsers = "/api/access/users"
+
+var (
+	UsersErrors = statusMapping{
+		403: "The user is not an admin or is trying to manage another user",
+		404: "The user does not exist",
+	}
+	UserCreateErrors = statusMapping{
+		400: "Name or password is missing or the request is otherwise invalid",
+		403: "The user is not an admin",
+		409: "A user with the same name already exists",
+	}
+	UserPasswordErrors = statusMapping{
+		400: "The password is missing or the request is otherwise invalid",
+		403: "The user is not an admin or the current password is wrong",
+		404: "The user does not exist",
+	}
+)
+
+// UserListRequest retrieves the list of registered users.
+type UserListRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *UserListRequest) Do(ctx context.Context, c *Client) (*UsersResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIUsers, nil, UsersErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeUsersResponse(c, URIUsers, b)
+}
+
+// UserRequest retrieves a single user.
+type UserRequest struct {
+	// Username of the user to retrieve.
+	Username string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *UserRequest) Do(ctx context.Context, c *Client) (*User, error) {
+	uri := fmt.Sprintf("%s/%s", URIUsers, cmd.Username)
+	b, err := c.doJSONRequest(ctx, "GET", uri, nil, UsersErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &User{}
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// UserCreateRequest adds a new user. Returns the updated list of users.
+type UserCreateRequest struct {
+	// Name of the new user.
+	Name string `json:"name"`
+	// Password of the new user.
+	Password string `json:"password"`
+	// Active whether the user account is active.
+	Active bool `json:"active"`
+	// Admin whether the user has admin rights.
+	Admin bool `json:"admin,omitempty"`
+	// Groups keys of the groups the user belongs to. If not set the default
+	// groups will be used.
+	Groups []string `json:"groups,omitempty"`
+	// Permissions keys of the permissions granted directly to the user.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *UserCreateRequest) Do(ctx context.Context, c *Client) (*UsersResponse, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	b2, err := c.doJSONRequest(ctx, "POST", URIUsers, b, UserCreateErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeUsersResponse(c, URIUsers, b2)
+}
+
+// UserUpdateRequest updates an existing user, only the provided fields are
+// changed. Returns the updated list of users.
+type UserUpdateRequest struct {
+	// Username of the user to update.
+	Username string `json:"-"`
+	// Active whether the user account is active.
+	Active *bool `json:"active,omitempty"`
+	// Admin whether the user has admin rights.
+	Admin *bool `json:"admin,omitempty"`
+	// Groups keys of the groups the user belongs to.
+	Groups []string `json:"groups,omitempty"`
+	// Permissions keys of the permissions granted directly to the user.
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *UserUpdateRequest) Do(ctx context.Context, c *Client) (*UsersResponse, error) {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return nil, err
+	}
+
+	uri := fmt.Sprintf("%s/%s", URIUsers, cmd.Username)
+	b2, err := c.doJSONRequest(ctx, "PUT", uri, b, UsersErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeUsersResponse(c, uri, b2)
+}
+
+// UserSetActiveRequest activates or deactivates a user account. Returns the
+// updated list of users.
+type UserSetActiveRequest struct {
+	// Username of the user to update.
+	Username string
+	// Active whether the user account is active.
+	Active bool
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *UserSetActiveRequest) Do(ctx context.Context, c *Client) (*UsersResponse, error) {
+	active := cmd.Active
+	return (&UserUpdateRequest{Username: cmd.Username, Active: &active}).Do(ctx, c)
+}
+
+// UserDeleteRequest deletes a user. Returns the updated list of users.
+type UserDeleteRequest struct {
+	// Username of the user to delete.
+	Username string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *UserDeleteRequest) Do(ctx context.Context, c *Client) (*UsersResponse, error) {
+	uri := fmt.Sprintf("%s/%s", URIUsers, cmd.Username)
+	b, err := c.doJSONRequest(ctx, "DELETE", uri, nil, UsersErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeUsersResponse(c, uri, b)
+}
+
+// UserChangePasswordRequest changes the password of a user.
+type UserChangePasswordRequest struct {
+	// Username of the user to update.
+	Username string `json:"-"`
+	// Password is the new password.
+	Password string `json:"password"`
+	// Current is the current password, required when not changing the
+	// password as an admin.
+	Current string `json:"current,omitempty"`
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *UserChangePasswordRequest) Do(ctx context.Context, c *Client) error {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return err
+	}
+
+	uri := fmt.Sprintf("%s/%s/password", URIUsers, cmd.Username)
+	_, err := c.doJSONRequest(ctx, "PUT", uri, b, UserPasswordErrors)
+	return err
+}
+
+func decodeUsersResponse(c *Client, uri string, b []byte) (*UsersResponse, error) {
+	r := &UsersResponse{}
+	if len(b) == 0 {
+		return r, nil
+	}
+
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GenerateUserAPIKeyRequest generates a new API key for a user, replacing the
+// existing one, if any.
+type GenerateUserAPIKeyRequest struct {
+	// Username of the user for which to generate the API key.
+	Username string
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *GenerateUserAPIKeyRequest) Do(ctx context.Context, c *Client) (*APIKeyResponse, error) {
+	uri := fmt.Sprintf("%s/%s/apikey", URIUsers, cmd.Username)
+	b, err := c.doJSONRequest(ctx, "POST", uri, nil, UsersErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &APIKeyResponse{}
+	if err := c.decode(uri, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// DeleteUserAPIKeyRequest revokes the API key of a user.
+type DeleteUserAPIKeyRequest struct {
+	// Username of the user for which to revoke the API key.
+	Username string
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *DeleteUserAPIKeyRequest) Do(ctx context.Context, c *Client) error {
+	uri := fmt.Sprintf("%s/%s/apikey", URIUsers, cmd.Username)
+	_, err := c.doJSONRequest(ctx, "DELETE", uri, nil, UsersErrors)
+	return err
+}
@@ -0,0 +1,60 @@
+package octoprint
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateUserAPIKeyRequest_Do(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == URIUsers+"/foo/apikey":
+			w.Write([]byte(`{"apikey": "bar"}`))
+		case r.Method == "DELETE" && r.URL.Path == URIUsers+"/foo/apikey":
+			w.WriteHeader(204)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&GenerateUserAPIKeyRequest{Username: "foo"}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", r.APIKey)
+
+	err = (&DeleteUserAPIKeyRequest{Username: "foo"}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+
+	_, err = (&GenerateUserAPIKeyRequest{Username: "qux"}).Do(context.Background(), cli)
+	assert.Error(t, err)
+}
+
+func TestUserUpdateRequest_Do(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+
+		if r.Method != "PUT" || r.URL.Path != URIUsers+"/foo" {
+			w.WriteHeader(404)
+			return
+		}
+
+		w.Write([]byte(`{"users": [{"name": "foo", "active": false, "groups": ["users"]}]}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&UserSetActiveRequest{Username: "foo"}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"active": false}`, body)
+	assert.Len(t, r.Users, 1)
+	assert.Equal(t, "foo", r.Users[0].Name)
+	assert.False(t, r.Users[0].Active)
+}
@@ -0,0 +1,179 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+const URIUtilTest = "/api/util/test"
+
+var UtilTestErrors = statusMapping{
+	400: "Invalid command or parameters",
+}
+
+// UtilTestPathRequest tests whether a path exists on the server and is of the
+// expected type and access.
+type UtilTestPathRequest struct {
+	// Path to test.
+	Path string `json:"path"`
+	// CheckType is the expected type of the path, `file` or `dir`. Optional.
+	CheckType string `json:"check_type,omitempty"`
+	// CheckAccess is the expected access to the path, any combination of
+	// `r`, `w` and `x`. Optional.
+	CheckAccess []string `json:"check_access,omitempty"`
+	// AllowCreateDir whether to create the directory if it doesn't exist,
+	// only used if CheckType is `dir`.
+	AllowCreateDir bool `json:"allow_create_dir,omitempty"`
+	// CheckWritableDir whether to also check that files can be created in
+	// the directory, only used if CheckType is `dir`.
+	CheckWritableDir bool `json:"check_writable_dir,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *UtilTestPathRequest) Do(ctx context.Context, c *Client) (*UtilTestPathResponse, error) {
+	payload := struct {
+		Command string `json:"command"`
+		UtilTestPathRequest
+	}{"path", *cmd}
+
+	r := &UtilTestPathResponse{}
+	if err := doUtilTest(ctx, c, payload, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// UtilTestURLRequest tests whether a URL is reachable from the server, e.g. a
+// webcam stream.
+type UtilTestURLRequest struct {
+	// URL to test.
+	URL string `json:"url"`
+	// Method is the HTTP method to use, defaults to HEAD.
+	Method string `json:"method,omitempty"`
+	// Timeout of the request, in seconds. Defaults to 3 seconds.
+	Timeout float64 `json:"timeout,omitempty"`
+	// ValidSSL whether to validate the SSL certificate, defaults to true.
+	ValidSSL *bool `json:"validSsl,omitempty"`
+	// Status are the status codes or code groups (e.g. `success`) considered
+	// a successful response. Defaults to `success`.
+	Status []string `json:"status,omitempty"`
+	// ContentTypeWhitelist are the content types considered a successful
+	// response. Optional.
+	ContentTypeWhitelist []string `json:"content_type_whitelist,omitempty"`
+	// ContentTypeBlacklist are the content types considered a failed
+	// response. Optional.
+	ContentTypeBlacklist []string `json:"content_type_blacklist,omitempty"`
+	// Response whether to include the response in the result, `true`,
+	// `json` or `bytes`. Optional.
+	Response string `json:"-"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *UtilTestURLRequest) Do(ctx context.Context, c *Client) (*UtilTestURLResponse, error) {
+	payload := struct {
+		Command string `json:"command"`
+		UtilTestURLRequest
+		Response interface{} `json:"response,omitempty"`
+	}{Command: "url", UtilTestURLRequest: *cmd}
+
+	if cmd.Response == "true" {
+		payload.Response = true
+	} else if cmd.Response != "" {
+		payload.Response = cmd.Response
+	}
+
+	r := &UtilTestURLResponse{}
+	if err := doUtilTest(ctx, c, payload, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// UtilTestServerRequest tests whether a server port is reachable from the
+// server.
+type UtilTestServerRequest struct {
+	// Host to test.
+	Host string `json:"host"`
+	// Port to test.
+	Port int `json:"port"`
+	// Protocol to use, `tcp` or `udp`. Defaults to `tcp`.
+	Protocol string `json:"protocol,omitempty"`
+	// Timeout of the test, in seconds. Defaults to 3.05 seconds.
+	Timeout float64 `json:"timeout,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *UtilTestServerRequest) Do(ctx context.Context, c *Client) (*UtilTestServerResponse, error) {
+	payload := struct {
+		Command string `json:"command"`
+		UtilTestServerRequest
+	}{"server", *cmd}
+
+	r := &UtilTestServerResponse{}
+	if err := doUtilTest(ctx, c, payload, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// UtilTestResolutionRequest tests whether a hostname can be resolved by the
+// server.
+type UtilTestResolutionRequest struct {
+	// Name is the hostname to resolve.
+	Name string `json:"name"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *UtilTestResolutionRequest) Do(ctx context.Context, c *Client) (*UtilTestResolutionResponse, error) {
+	payload := struct {
+		Command string `json:"command"`
+		UtilTestResolutionRequest
+	}{"resolution", *cmd}
+
+	r := &UtilTestResolutionResponse{}
+	if err := doUtilTest(ctx, c, payload, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// UtilTestAddressRequest tests whether an address is part of the local network
+// of the server.
+type UtilTestAddressRequest struct {
+	// Address to test, if empty the address of the client is used.
+	Address string `json:"address,omitempty"`
+}
+
+// Do sends an API request and returns the API response.
+func (cmd *UtilTestAddressRequest) Do(ctx context.Context, c *Client) (*UtilTestAddressResponse, error) {
+	payload := struct {
+		Command string `json:"command"`
+		UtilTestAddressRequest
+	}{"address", *cmd}
+
+	r := &UtilTestAddressResponse{}
+	if err := doUtilTest(ctx, c, payload, r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func doUtilTest(ctx context.Context, c *Client, payload, r interface{}) error {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(payload); err != nil {
+		return err
+	}
+
+	b2, err := c.doJSONRequest(ctx, "POST", URIUtilTest, b, UtilTestErrors)
+	if err != nil {
+		return err
+	}
+
+	return c.decode(URIUtilTest, b2, r)
+}
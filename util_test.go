@@ -0,0 +1,63 @@
+package octoprint
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUtilTestRequests_Do(t *testing.T) {
+	var payload map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload = nil
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		switch payload["command"] {
+		case "path":
+			w.Write([]byte(`{"path": "/tmp", "exists": true, "typeok": true, "broken_symlink": false, "access": true, "result": true}`))
+		case "url":
+			w.Write([]byte(`{"url": "http://cam/snapshot", "status": 200, "result": true, "response": {"headers": {"content-type": "image/jpeg"}, "content_type": "image/jpeg"}}`))
+		case "server":
+			w.Write([]byte(`{"host": "8.8.8.8", "port": 53, "protocol": "udp", "result": true}`))
+		case "resolution":
+			w.Write([]byte(`{"name": "octoprint.org", "result": true}`))
+		case "address":
+			w.Write([]byte(`{"address": "192.168.1.10", "is_lan_address": true, "result": true}`))
+		default:
+			w.WriteHeader(400)
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	cli := NewClient(ts.URL, "")
+
+	p, err := (&UtilTestPathRequest{Path: "/tmp", CheckType: "dir", CheckAccess: []string{"r", "w"}}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.True(t, p.TypeOK)
+	assert.Equal(t, "dir", payload["check_type"])
+	assert.Equal(t, []interface{}{"r", "w"}, payload["check_access"])
+
+	u, err := (&UtilTestURLRequest{URL: "http://cam/snapshot", Method: "GET", Response: "true"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, u.Status)
+	assert.Equal(t, "image/jpeg", u.Response.ContentType)
+	assert.Equal(t, true, payload["response"])
+
+	s, err := (&UtilTestServerRequest{Host: "8.8.8.8", Port: 53, Protocol: "udp"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "udp", s.Protocol)
+	assert.Equal(t, float64(53), payload["port"])
+
+	r, err := (&UtilTestResolutionRequest{Name: "octoprint.org"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.True(t, r.Result)
+
+	a, err := (&UtilTestAddressRequest{Address: "192.168.1.10"}).Do(ctx, cli)
+	assert.NoError(t, err)
+	assert.True(t, a.IsLANAddress)
+}
@@ -1,25 +1,246 @@
 package octoprint
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
-const URIVersion = "/api/version"
+const (
+	URIVersion = "/api/version"
+	URIServer  = "/api/server"
+)
 
 // VersionRequest retrieve information regarding server and API version.
 type VersionRequest struct{}
 
 // Do sends an API request and returns the API response.
-func (cmd *VersionRequest) Do(c *Client) (*VersionResponse, error) {
-	b, err := c.doJSONRequest("GET", URIVersion, nil, nil)
+func (cmd *VersionRequest) Do(ctx context.Context, c *Client) (*VersionResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIVersion, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &VersionResponse{}
-	if err := json.Unmarshal(b, r); err != nil {
+	if err := c.decode(URIVersion, b, r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// ServerRequest retrieves information regarding the server, such as its version
+// and whether it is running in safe mode. Available since OctoPrint 1.5.0.
+type ServerRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *ServerRequest) Do(ctx context.Context, c *Client) (*ServerResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIServer, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &ServerResponse{}
+	if err := c.decode(URIServer, b, r); err != nil {
 		return nil, err
 	}
 
 	return r, err
 }
+
+var (
+	semVerRegexp = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(.*)$`)
+	suffixRegexp = regexp.MustCompile(
+		`^(?i)(?:(a|alpha|b|beta|c|rc|pre|preview)[.-]?(\d*))?` +
+			`(?:[.-]?(?:post|rev|r)[.-]?(\d*))?` +
+			`(?:[.-]?dev[.-]?(\d*))?(?:\+(.+))?$`,
+	)
+)
+
+// SemVer is a parsed server version, e.g. 1.5.0rc1.
+type SemVer struct {
+	Major, Minor, Patch int
+	// Suffix is the remaining part of the version, e.g. `rc1`, `post1` or
+	// `+g1234` for local versions, without leading `.` or `-` separators.
+	Suffix string
+}
+
+// ParseSemVer parses a version as reported by OctoPrint. Missing minor and
+// patch numbers are considered zero.
+func ParseSemVer(v string) (SemVer, error) {
+	m := semVerRegexp.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return SemVer{}, fmt.Errorf("invalid version %q", v)
+	}
+
+	var s SemVer
+	for i, n := range []*int{&s.Major, &s.Minor, &s.Patch} {
+		if m[i+1] == "" {
+			continue
+		}
+
+		*n, _ = strconv.Atoi(m[i+1])
+	}
+
+	s.Suffix = strings.TrimLeft(m[4], ".-")
+	return s, nil
+}
+
+// Compare returns -1, 0 or +1 depending on whether s is lower, equal or greater
+// than o, following the ordering of PEP 440: development releases (`dev`) are
+// lower than pre-releases (`a`, `b` and `rc`, in this order), pre-releases
+// lower than the release, and post-releases (`post`) greater. Local versions
+// (`+local`) are greater than the same version without it. Unknown suffixes
+// are lower than any other and compared as strings.
+func (s SemVer) Compare(o SemVer) int {
+	sk, sLocal := s.suffixKey()
+	ok, oLocal := o.suffixKey()
+
+	d := []int{s.Major - o.Major, s.Minor - o.Minor, s.Patch - o.Patch}
+	for i := range sk {
+		d = append(d, compareInt(sk[i], ok[i]))
+	}
+
+	d = append(d, compareLocal(sLocal, oLocal))
+	for _, d := range d {
+		switch {
+		case d < 0:
+			return -1
+		case d > 0:
+			return 1
+		}
+	}
+
+	if sk[0] == suffixUnknown {
+		return strings.Compare(s.Suffix, o.Suffix)
+	}
+
+	return 0
+}
+
+// AtLeast returns true if s is equal or greater than the given release.
+func (s SemVer) AtLeast(major, minor, patch int) bool {
+	return s.Compare(SemVer{Major: major, Minor: minor, Patch: patch}) >= 0
+}
+
+const (
+	suffixUnknown = -2
+	suffixDev     = -1
+	suffixRelease = 3
+	suffixNone    = -1
+	suffixMax     = math.MaxInt32
+)
+
+// suffixKey returns the sort key of the suffix: the pre-release kind and
+// number, the post-release number and the development release number, and the
+// local version.
+func (s SemVer) suffixKey() ([4]int, string) {
+	m := suffixRegexp.FindStringSubmatchIndex(s.Suffix)
+	if m == nil {
+		return [4]int{suffixUnknown}, ""
+	}
+
+	group := func(i int) (int, bool) {
+		if m[2*i] < 0 {
+			return 0, false
+		}
+
+		n, _ := strconv.Atoi(s.Suffix[m[2*i]:m[2*i+1]])
+		return n, true
+	}
+
+	k := [4]int{suffixRelease, 0, suffixNone, suffixMax}
+	if m[2] >= 0 {
+		switch strings.ToLower(s.Suffix[m[2]:m[3]]) {
+		case "a", "alpha":
+			k[0] = 0
+		case "b", "beta":
+			k[0] = 1
+		default:
+			k[0] = 2
+		}
+
+		k[1], _ = group(2)
+	}
+
+	if n, ok := group(3); ok {
+		k[2] = n
+	}
+
+	if n, ok := group(4); ok {
+		k[3] = n
+		if m[2] < 0 && k[2] == suffixNone {
+			// a development release of the release, e.g. 1.5.0.dev1.
+			k[0] = suffixDev
+		}
+	}
+
+	var local string
+	if m[10] >= 0 {
+		local = s.Suffix[m[10]:m[11]]
+	}
+
+	return k, local
+}
+
+// compareLocal compares local versions by segment, numeric segments are
+// greater than alphanumeric ones.
+func compareLocal(a, b string) int {
+	if a == "" || b == "" {
+		return compareInt(len(a), len(b))
+	}
+
+	as, bs := strings.FieldsFunc(a, isLocalSeparator), strings.FieldsFunc(b, isLocalSeparator)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+
+		var d int
+		switch {
+		case aErr == nil && bErr == nil:
+			d = compareInt(an, bn)
+		case aErr == nil:
+			d = 1
+		case bErr == nil:
+			d = -1
+		default:
+			d = strings.Compare(strings.ToLower(as[i]), strings.ToLower(bs[i]))
+		}
+
+		if d != 0 {
+			return d
+		}
+	}
+
+	return compareInt(len(as), len(bs))
+}
+
+func isLocalSeparator(r rune) bool {
+	return r == '.' || r == '-' || r == '_'
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (s SemVer) String() string {
+	v := fmt.Sprintf("%d.%d.%d", s.Major, s.Minor, s.Patch)
+	switch {
+	case s.Suffix == "":
+		return v
+	case strings.HasPrefix(s.Suffix, "post") || strings.HasPrefix(s.Suffix, "dev"):
+		return v + "." + s.Suffix
+	default:
+		return v + s.Suffix
+	}
+}
@@ -0,0 +1,101 @@
+package octoprint
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerRequest_Do(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case URIServer:
+			w.Write([]byte(`{"version": "1.5.0", "safemode": "settings"}`))
+		case URIVersion:
+			w.Write([]byte(`{"api": "0.1", "server": "1.3.10", "text": "OctoPrint 1.3.10"}`))
+		}
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	s, err := (&ServerRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "settings", s.Safemode)
+
+	v, err := s.SemVer()
+	assert.NoError(t, err)
+	assert.True(t, v.AtLeast(1, 5, 0))
+
+	r, err := (&VersionRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "OctoPrint 1.3.10", r.Text)
+
+	v, err = r.SemVer()
+	assert.NoError(t, err)
+	assert.Equal(t, SemVer{Major: 1, Minor: 3, Patch: 10}, v)
+	assert.False(t, v.AtLeast(1, 4, 0))
+}
+
+func TestParseSemVer(t *testing.T) {
+	v, err := ParseSemVer("1.5.0rc1")
+	assert.NoError(t, err)
+	assert.Equal(t, SemVer{Major: 1, Minor: 5, Suffix: "rc1"}, v)
+	assert.Equal(t, "1.5.0rc1", v.String())
+
+	v, err = ParseSemVer("1.3.12.post0.dev1+g1234")
+	assert.NoError(t, err)
+	assert.Equal(t, "post0.dev1+g1234", v.Suffix)
+
+	_, err = ParseSemVer("unknown")
+	assert.Error(t, err)
+
+	v, err = ParseSemVer("1.5.0+g1234")
+	assert.NoError(t, err)
+	assert.Equal(t, "+g1234", v.Suffix)
+	assert.Equal(t, "1.5.0+g1234", v.String())
+
+	ordered := []string{"1.4.2", "1.5.0.dev12", "1.5.0rc1", "1.5.0", "1.5.0.post1", "1.10"}
+	for i := 1; i < len(ordered); i++ {
+		a, _ := ParseSemVer(ordered[i-1])
+		b, _ := ParseSemVer(ordered[i])
+		assert.Equal(t, -1, a.Compare(b), "%s < %s", a, b)
+		assert.Equal(t, 1, b.Compare(a), "%s > %s", b, a)
+	}
+}
+
+func TestSemVer_Compare(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		cmp  int
+	}{
+		{"1.5.0rc2", "1.5.0rc10", -1},
+		{"1.5.0.dev1", "1.5.0a1", -1},
+		{"1.5.0a1", "1.5.0b1", -1},
+		{"1.5.0b2", "1.5.0rc1", -1},
+		{"1.5.0rc1.dev3", "1.5.0rc1", -1},
+		{"1.5.0rc1", "1.5.0rc1.post1", -1},
+		{"1.5.0.dev2", "1.5.0.dev10", -1},
+		{"1.5.0", "1.5.0+g1234", -1},
+		{"1.5.0+g1234", "1.5.0.post1", -1},
+		{"1.5.0rc1", "1.5.0rc1+g1234", -1},
+		{"1.5.0+abc", "1.5.0+1", -1},
+		{"1.5.0+1.2", "1.5.0+1.10", -1},
+		{"1.5.0.post1", "1.5.0.post2", -1},
+		{"1.5.0.post1", "1.5.0.rev1", 0},
+		{"1.5.0alpha1", "1.5.0a1", 0},
+		{"1.5.0RC1", "1.5.0rc1", 0},
+		{"1.5.0", "1.5", 0},
+		{"1.5.0foo", "1.5.0.dev1", -1},
+	} {
+		a, err := ParseSemVer(tc.a)
+		assert.NoError(t, err)
+		b, err := ParseSemVer(tc.b)
+		assert.NoError(t, err)
+
+		assert.Equal(t, tc.cmp, a.Compare(b), tc.a+" <=> "+tc.b)
+		assert.Equal(t, -tc.cmp, b.Compare(a), tc.b+" <=> "+tc.a)
+	}
+}
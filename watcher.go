@@ -0,0 +1,202 @@
+package octoprint
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// DefaultWatchInterval is the default interval between polls of a Watcher,
+// matching the default temperature reporting interval of OctoPrint.
+const DefaultWatchInterval = 2 * time.Second
+
+// Watcher polls the state of the printer, the current job and the connection,
+// calling the callbacks every time they change. It is useful where the push
+// API is not available, e.g. behind proxies not supporting websockets.
+//
+// The callbacks are called sequentially from the goroutine running Run, the
+// first poll of every endpoint is reported as a change from the zero value.
+type Watcher struct {
+	// PrinterInterval is the interval between polls of the printer state,
+	// used by OnStateChange, OnTemperature and OnPoll.
+	PrinterInterval time.Duration
+	// JobInterval is the interval between polls of the current job, used by
+	// OnProgress.
+	JobInterval time.Duration
+	// ConnectionInterval is the interval between polls of the connection,
+	// used by OnConnectionChange.
+	ConnectionInterval time.Duration
+
+	// OnStateChange is called when the state of the printer changes. The
+	// state is the zero PrinterState while the printer is not connected.
+	OnStateChange func(old, new PrinterState)
+	// OnTemperature is called when the temperatures of the printer change,
+	// by tool, e.g. `tool0` or `bed`.
+	OnTemperature func(temps map[string]TemperatureData)
+	// OnProgress is called when the progress of the current job changes.
+	OnProgress func(job *JobResponse)
+	// OnConnectionChange is called when the state of the connection to the
+	// printer changes.
+	OnConnectionChange func(old, new ConnectionState)
+	// OnPoll is called after every successful poll of the printer state,
+	// changed or not, after OnStateChange and OnTemperature.
+	OnPoll func()
+	// OnError is called when a poll fails, the watcher keeps polling.
+	OnError func(err error)
+
+	// last polled values, nil until polled.
+	state      *PrinterState
+	temps      map[string]TemperatureData
+	progress   *ProgressInformation
+	connection *ConnectionState
+}
+
+// Run polls the server until ctx is done, returning ctx.Err(). Endpoints
+// without callbacks are not polled, a zero interval means
+// DefaultWatchInterval.
+func (w *Watcher) Run(ctx context.Context, c *Client) error {
+	printer := w.ticker(w.PrinterInterval,
+		w.OnStateChange != nil || w.OnTemperature != nil || w.OnPoll != nil,
+	)
+	job := w.ticker(w.JobInterval, w.OnProgress != nil)
+	connection := w.ticker(w.ConnectionInterval, w.OnConnectionChange != nil)
+
+	for _, t := range []*time.Ticker{printer, job, connection} {
+		if t != nil {
+			defer t.Stop()
+		}
+	}
+
+	if printer != nil {
+		w.pollPrinter(ctx, c)
+	}
+
+	if job != nil {
+		w.pollJob(ctx, c)
+	}
+
+	if connection != nil {
+		w.pollConnection(ctx, c)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick(printer):
+			w.pollPrinter(ctx, c)
+		case <-tick(job):
+			w.pollJob(ctx, c)
+		case <-tick(connection):
+			w.pollConnection(ctx, c)
+		}
+	}
+}
+
+func (w *Watcher) ticker(interval time.Duration, enabled bool) *time.Ticker {
+	if !enabled {
+		return nil
+	}
+
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	return time.NewTicker(interval)
+}
+
+// tick returns the channel of t, nil if t is nil so it blocks forever.
+func tick(t *time.Ticker) <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+
+	return t.C
+}
+
+func (w *Watcher) pollPrinter(ctx context.Context, c *Client) {
+	r, err := (&StateRequest{Exclude: []string{"sd"}}).Do(ctx, c)
+	if errors.Is(err, ErrConflict) {
+		// the printer is not connected.
+		r, err = &FullStateResponse{}, nil
+	}
+
+	if err != nil {
+		w.error(ctx, err)
+		return
+	}
+
+	if w.state == nil || *w.state != r.State {
+		var old PrinterState
+		if w.state != nil {
+			old = *w.state
+		}
+
+		state := r.State
+		w.state = &state
+		if w.OnStateChange != nil {
+			w.OnStateChange(old, state)
+		}
+	}
+
+	if w.temps == nil || !reflect.DeepEqual(w.temps, r.Temperature.Current) {
+		w.temps = r.Temperature.Current
+		if w.temps == nil {
+			w.temps = map[string]TemperatureData{}
+		}
+
+		if w.OnTemperature != nil {
+			w.OnTemperature(w.temps)
+		}
+	}
+
+	if w.OnPoll != nil {
+		w.OnPoll()
+	}
+}
+
+func (w *Watcher) pollJob(ctx context.Context, c *Client) {
+	r, err := (&JobRequest{}).Do(ctx, c)
+	if err != nil {
+		w.error(ctx, err)
+		return
+	}
+
+	if w.progress != nil && w.progress.Equal(&r.Progress) {
+		return
+	}
+
+	p := r.Progress
+	w.progress = &p
+	w.OnProgress(r)
+}
+
+func (w *Watcher) pollConnection(ctx context.Context, c *Client) {
+	r, err := (&ConnectionRequest{}).Do(ctx, c)
+	if err != nil {
+		w.error(ctx, err)
+		return
+	}
+
+	if w.connection != nil && *w.connection == r.Current.State {
+		return
+	}
+
+	var old ConnectionState
+	if w.connection != nil {
+		old = *w.connection
+	}
+
+	state := r.Current.State
+	w.connection = &state
+	w.OnConnectionChange(old, state)
+}
+
+func (w *Watcher) error(ctx context.Context, err error) {
+	if ctx.Err() != nil || w.OnError == nil {
+		return
+	}
+
+	w.OnError(err)
+}
@@ -0,0 +1,137 @@
+package octoprint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher_Run(t *testing.T) {
+	var printerPolls, jobPolls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case URIPrinter:
+			printerPolls++
+			if printerPolls == 1 {
+				w.WriteHeader(409)
+				return
+			}
+
+			actual := 203
+			if printerPolls == 2 {
+				actual = 202
+			}
+
+			fmt.Fprintf(w, `{
+				"state": {"text": "Printing", "flags": {"printing": true}},
+				"temperature": {"tool0": {"actual": %d, "target": 210}}
+			}`, actual)
+		case JobTool:
+			jobPolls++
+			completion := 20
+			if jobPolls == 1 {
+				completion = 10
+			}
+
+			fmt.Fprintf(w, `{"progress": {"completion": %d}}`, completion)
+		case URIConnection:
+			w.Write([]byte(`{"current": {"state": "Printing"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	var states []string
+	var temps []float64
+	var progress []float64
+	var connections []ConnectionState
+	var polls int
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	w := &Watcher{
+		PrinterInterval:    time.Millisecond,
+		JobInterval:        time.Millisecond,
+		ConnectionInterval: time.Millisecond,
+		OnStateChange: func(old, new PrinterState) {
+			states = append(states, old.Text+">"+new.Text)
+		},
+		OnTemperature: func(t map[string]TemperatureData) {
+			temps = append(temps, t["tool0"].Actual)
+		},
+		OnProgress: func(r *JobResponse) {
+			progress = append(progress, r.Progress.Completion)
+		},
+		OnConnectionChange: func(old, new ConnectionState) {
+			connections = append(connections, old, new)
+		},
+		OnPoll: func() { polls++ },
+	}
+
+	err := w.Run(ctx, NewClient(ts.URL, ""))
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	assert.Equal(t, []string{">", ">Printing"}, states)
+	assert.Equal(t, []float64{0, 202, 203}, temps)
+	assert.Equal(t, []float64{10, 20}, progress)
+	assert.Equal(t, []ConnectionState{"", "Printing"}, connections)
+	assert.True(t, polls > 3)
+}
+
+func TestWatcher_RunUnchangedProgress(t *testing.T) {
+	var polls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		w.Write([]byte(`{"progress": {
+			"completion": 42.5, "printTime": 120, "printTimeLeft": 3600,
+			"printTimeLeftOrigin": "estimate"
+		}}`))
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var progress int
+	w := &Watcher{
+		JobInterval: time.Millisecond,
+		OnProgress: func(r *JobResponse) {
+			progress++
+		},
+	}
+
+	err := w.Run(ctx, NewClient(ts.URL, ""))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	ts.Close()
+
+	assert.Equal(t, 1, progress)
+	assert.True(t, polls > 1)
+}
+
+func TestWatcher_RunError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(500)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var errs []error
+	w := &Watcher{
+		OnProgress: func(*JobResponse) {},
+		OnError: func(err error) {
+			errs = append(errs, err)
+			cancel()
+		},
+	}
+
+	assert.Equal(t, context.Canceled, w.Run(ctx, NewClient(ts.URL, "")))
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrInternalServer)
+}
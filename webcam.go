@@ -0,0 +1,206 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var (
+	// ErrNoSnapshotURL the webcam has no snapshot URL configured.
+	ErrNoSnapshotURL = errors.New("No webcam snapshot URL configured")
+
+	SnapshotErrors = statusMapping{
+		404: "The snapshot URL was not found",
+		503: "The webcam is not available",
+	}
+)
+
+// SnapshotRequest captures a snapshot of the webcam, from the snapshot URL
+// configured in OctoPrint, applying the configured flips and rotation.
+//
+// Relative snapshot URLs, and the ones pointing to the loopback interface, are
+// resolved against the endpoint of the client, since they refer to the host
+// running OctoPrint. The credentials of the client are only sent if the
+// snapshot is served by the same host and port as the API.
+type SnapshotRequest struct {
+	// Webcam is the webcam configuration, retrieved from the settings if nil.
+	Webcam *WebcamConfig
+}
+
+// Snapshot is a snapshot of the webcam.
+type Snapshot struct {
+	// Data is the content of the snapshot, as returned by the webcam, without
+	// the transformations applied.
+	Data []byte
+	// ContentType is the content type reported by the webcam.
+	ContentType string
+	// Format is the name of the image format, e.g. `jpeg` or `png`.
+	Format string
+	// Image is the decoded snapshot with the flips and rotation applied.
+	Image image.Image
+}
+
+// Do sends an API request and returns the snapshot.
+func (cmd *SnapshotRequest) Do(ctx context.Context, c *Client) (*Snapshot, error) {
+	w := cmd.Webcam
+	if w == nil {
+		s, err := (&SettingsRequest{}).Do(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+
+		w = s.Webcam
+	}
+
+	if w == nil || w.SnapshotURL == "" {
+		return nil, ErrNoSnapshotURL
+	}
+
+	if w.SnapshotTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(w.SnapshotTimeout)*time.Second)
+		defer cancel()
+	}
+
+	s := &Snapshot{}
+	fetch := func(resp *http.Response) (err error) {
+		s.ContentType = resp.Header.Get("Content-Type")
+		s.Data, err = ioutil.ReadAll(resp.Body)
+		return err
+	}
+
+	u, own, err := snapshotURL(c.Endpoint, w.SnapshotURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if own {
+		err = c.doStreamRequest(ctx, "GET", u.String(), nil, SnapshotErrors, fetch)
+	} else {
+		err = fetchSnapshot(ctx, c.httpClient(), u.String(), fetch)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(s.Data))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding snapshot: %w", err)
+	}
+
+	s.Format = format
+	s.Image = TransformImage(img, w.FlipH, w.FlipV, w.Rotate90)
+	return s, nil
+}
+
+// snapshotURL resolves the snapshot URL against the endpoint, returning
+// whether it is served by the same host and port.
+func snapshotURL(endpoint, snapshot string) (*url.URL, bool, error) {
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, false, err
+	}
+
+	u, err := url.Parse(snapshot)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid snapshot URL %q: %w", snapshot, err)
+	}
+
+	u = base.ResolveReference(u)
+	if isLoopback(u.Hostname()) && !isLoopback(base.Hostname()) {
+		port := u.Port()
+		u.Host = base.Hostname()
+		if port != "" {
+			u.Host = net.JoinHostPort(u.Host, port)
+		}
+	}
+
+	return u, u.Scheme == base.Scheme && u.Host == base.Host, nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// fetchSnapshot fetches a snapshot served by a host other than OctoPrint,
+// without sending the credentials of the client.
+func fetchSnapshot(ctx context.Context, hc *http.Client, uri string, fn func(*http.Response) error) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add("User-Agent", fmt.Sprintf("go-octoprint/%s", Version))
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 209 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return newAPIError(resp.StatusCode, body, SnapshotErrors)
+	}
+
+	return fn(resp)
+}
+
+// TransformImage flips the image horizontally and vertically and then rotates
+// it 90° counter clockwise, as OctoPrint does with the webcam. The image is
+// returned as is if no transformation is required.
+func TransformImage(img image.Image, flipH, flipV, rotate90 bool) image.Image {
+	if !flipH && !flipV && !rotate90 {
+		return img
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+
+	dstW, dstH := w, h
+	if rotate90 {
+		dstW, dstH = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			tx, ty := x, y
+			if flipH {
+				tx = w - 1 - tx
+			}
+
+			if flipV {
+				ty = h - 1 - ty
+			}
+
+			if rotate90 {
+				tx, ty = ty, w-1-tx
+			}
+
+			i, j := src.PixOffset(x, y), dst.PixOffset(tx, ty)
+			copy(dst.Pix[j:j+4], src.Pix[i:i+4])
+		}
+	}
+
+	return dst
+}
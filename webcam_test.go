@@ -0,0 +1,118 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testImage returns a 3x2 image, the top left pixel red, the top right green.
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	img.Set(2, 0, color.RGBA{0, 255, 0, 255})
+	return img
+}
+
+func TestSnapshotRequest_Do(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	assert.NoError(t, png.Encode(b, testImage()))
+
+	var apiKey string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case URISettings:
+			w.Write([]byte(`{"webcam": {"snapshotUrl": "/webcam/?action=snapshot", "rotate90": true}}`))
+		case "/webcam/":
+			apiKey = r.Header.Get("X-Api-Key")
+			assert.Equal(t, "action=snapshot", r.URL.RawQuery)
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(b.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "foo")
+	s, err := (&SnapshotRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", apiKey)
+	assert.Equal(t, b.Bytes(), s.Data)
+	assert.Equal(t, "image/png", s.ContentType)
+	assert.Equal(t, "png", s.Format)
+	assert.Equal(t, image.Rect(0, 0, 2, 3), s.Image.Bounds())
+
+	_, err = (&SnapshotRequest{Webcam: &WebcamConfig{}}).Do(context.Background(), cli)
+	assert.Equal(t, ErrNoSnapshotURL, err)
+
+	_, err = (&SnapshotRequest{Webcam: &WebcamConfig{SnapshotURL: "/missing"}}).Do(context.Background(), cli)
+	assert.Error(t, err)
+}
+
+func TestSnapshotRequest_DoOtherHost(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	assert.NoError(t, png.Encode(b, testImage()))
+
+	var apiKey string
+	cam := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("X-Api-Key")
+		w.Write(b.Bytes())
+	}))
+	defer cam.Close()
+
+	cli := NewClient("http://localhost:1", "foo")
+	s, err := (&SnapshotRequest{Webcam: &WebcamConfig{SnapshotURL: cam.URL}}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Equal(t, "", apiKey)
+	assert.Equal(t, image.Rect(0, 0, 3, 2), s.Image.Bounds())
+}
+
+func TestSnapshotURL(t *testing.T) {
+	for _, tc := range []struct {
+		endpoint, snapshot, url string
+		own                     bool
+	}{
+		{"http://octopi.local", "/webcam/?action=snapshot", "http://octopi.local/webcam/?action=snapshot", true},
+		{"http://octopi.local", "http://127.0.0.1:8080/?action=snapshot", "http://octopi.local:8080/?action=snapshot", false},
+		{"http://localhost:5000", "http://127.0.0.1:8080/?action=snapshot", "http://127.0.0.1:8080/?action=snapshot", false},
+		{"http://octopi.local", "http://cam.local/snapshot.jpg", "http://cam.local/snapshot.jpg", false},
+	} {
+		u, own, err := snapshotURL(tc.endpoint, tc.snapshot)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.url, u.String())
+		assert.Equal(t, tc.own, own, tc.snapshot)
+	}
+}
+
+func TestTransformImage(t *testing.T) {
+	red, green := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}
+
+	img := testImage()
+	assert.Equal(t, img, TransformImage(img, false, false, false))
+
+	flipped := TransformImage(img, true, false, false)
+	assert.Equal(t, green, flipped.At(0, 0))
+	assert.Equal(t, red, flipped.At(2, 0))
+
+	flipped = TransformImage(img, false, true, false)
+	assert.Equal(t, red, flipped.At(0, 1))
+	assert.Equal(t, green, flipped.At(2, 1))
+
+	// rotated counter clockwise, the top right corner moves to the top left.
+	rotated := TransformImage(img, false, false, true)
+	assert.Equal(t, image.Rect(0, 0, 2, 3), rotated.Bounds())
+	assert.Equal(t, green, rotated.At(0, 0))
+	assert.Equal(t, red, rotated.At(0, 2))
+
+	rotated = TransformImage(img, true, false, true)
+	assert.Equal(t, red, rotated.At(0, 0))
+	assert.Equal(t, green, rotated.At(0, 2))
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// DeadLetter stores the deliveries that failed permanently, so they can be
+// inspected or delivered again with Dispatcher.Redeliver.
+type DeadLetter interface {
+	// Put stores a failed delivery.
+	Put(d *Delivery) error
+}
+
+// FileDeadLetter is a DeadLetter appending the deliveries to a file, one JSON
+// object per line. It is safe for concurrent use.
+type FileDeadLetter struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileDeadLetter returns a new FileDeadLetter writing to the given path, the
+// file is created if it does not exist.
+func NewFileDeadLetter(path string) *FileDeadLetter {
+	return &FileDeadLetter{path: path}
+}
+
+// Put appends the delivery to the file.
+func (s *FileDeadLetter) Put(d *Delivery) error {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Load returns the deliveries stored, oldest first, none if the file does not
+// exist.
+func (s *FileDeadLetter) Load() ([]*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var deliveries []*Delivery
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		d := &Delivery{}
+		if err := json.Unmarshal(scanner.Bytes(), d); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, scanner.Err()
+}
+
+// Clear removes all the deliveries stored.
+func (s *FileDeadLetter) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
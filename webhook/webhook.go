@@ -0,0 +1,411 @@
+// Package webhook delivers the events of the push API to HTTP endpoints, as
+// signed JSON POST requests, so external pipelines can react to them, e.g. to
+// the completion of a print.
+//
+//	d := &webhook.Dispatcher{
+//		Endpoints: []webhook.Endpoint{{
+//			URL:    "https://ci.example.com/hooks/octoprint",
+//			Secret: "s3cr3t",
+//			Events: []push.EventType{push.EventPrintDone, push.EventPrintFailed},
+//		}},
+//		DeadLetter: webhook.NewFileDeadLetter("dead-letters.jsonl"),
+//	}
+//
+//	err := d.Run(ctx, p) // a connected *push.Client
+//
+// Every endpoint has its own queue, so a slow or failing endpoint doesn't delay
+// the others. Failed deliveries are retried following the RetryPolicy and, once
+// the attempts are exhausted, handed to the DeadLetter.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mcuadros/go-octoprint"
+	"github.com/mcuadros/go-octoprint/push"
+)
+
+const (
+	// HeaderEvent is the header with the type of the event delivered.
+	HeaderEvent = "X-OctoPrint-Event"
+	// HeaderDelivery is the header with the unique ID of the delivery, the
+	// same across retries.
+	HeaderDelivery = "X-OctoPrint-Delivery"
+	// HeaderSignature is the header with the HMAC-SHA256 signature of the
+	// body, in the format `sha256=<hex>`, sent for endpoints with a Secret.
+	HeaderSignature = "X-OctoPrint-Signature"
+)
+
+// DefaultQueueSize is the default number of deliveries queued per endpoint.
+const DefaultQueueSize = 100
+
+// ErrQueueFull is the error of the deliveries dropped because the queue of the
+// endpoint is full.
+var ErrQueueFull = errors.New("Webhook endpoint queue is full")
+
+// DefaultRetryPolicy retries a failed delivery up to 5 times, during about
+// half a minute.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	MinBackoff:  2 * time.Second,
+	MaxBackoff:  time.Minute,
+}
+
+// RetryPolicy describes how failed deliveries are retried. Transport errors
+// and the 408, 429 and 5xx status codes are retried, any other status code
+// is considered permanent.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	MaxAttempts int
+	// MinBackoff is the time to wait before the first retry, it is doubled
+	// after every failed attempt.
+	MinBackoff time.Duration
+	// MaxBackoff is the upper limit of the time to wait between attempts.
+	MaxBackoff time.Duration
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	return d
+}
+
+// Endpoint is an HTTP endpoint receiving events.
+type Endpoint struct {
+	// URL of the endpoint.
+	URL string
+	// Secret is the key used to sign the body of the requests, no signature
+	// is sent if empty.
+	Secret string
+	// Events are the types of the events delivered, all of them if empty.
+	Events []push.EventType
+	// Header are additional headers sent, e.g. `Authorization`.
+	Header http.Header
+}
+
+func (e *Endpoint) wants(t push.EventType) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+
+	for _, et := range e.Events {
+		if et == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Delivery is the delivery of an event to an endpoint.
+type Delivery struct {
+	// ID is the unique ID of the delivery.
+	ID string `json:"id"`
+	// URL of the endpoint.
+	URL string `json:"url"`
+	// Event delivered.
+	Event push.Event `json:"event"`
+	// Time the event was received.
+	Time time.Time `json:"time"`
+	// Attempts is the number of attempts made.
+	Attempts int `json:"attempts"`
+	// Error is the error of the last attempt, if failed.
+	Error string `json:"error,omitempty"`
+}
+
+// body is the body of the requests.
+type body struct {
+	ID      string          `json:"id"`
+	Type    push.EventType  `json:"type"`
+	Payload json.RawMessage `json:"payload"`
+	Time    time.Time       `json:"time"`
+}
+
+// Events is a source of events, implemented by *push.Client.
+type Events interface {
+	Subscribe(ctx context.Context, events ...push.EventType) (<-chan push.Event, error)
+}
+
+// Dispatcher delivers the events to the endpoints.
+type Dispatcher struct {
+	// Endpoints receiving the events.
+	Endpoints []Endpoint
+	// Client is the HTTP client used, http.DefaultClient if nil.
+	Client *http.Client
+	// Retry is the retry policy, DefaultRetryPolicy if zero.
+	Retry RetryPolicy
+	// QueueSize is the number of deliveries queued per endpoint, zero means
+	// DefaultQueueSize. Events received while the queue is full are handed
+	// to the DeadLetter with ErrQueueFull.
+	QueueSize int
+	// DeadLetter receives the deliveries that failed permanently, they are
+	// discarded if nil.
+	DeadLetter DeadLetter
+	// OnError is called when a delivery fails, including the attempts
+	// retried, or a dead letter can't be stored.
+	OnError func(err error)
+}
+
+// Run delivers the events received from events until ctx is done, returning
+// ctx.Err(), or the subscription ends, returning nil. The deliveries still
+// queued are handed to the DeadLetter.
+func (d *Dispatcher) Run(ctx context.Context, events Events) error {
+	ch, err := events.Subscribe(ctx, d.eventTypes()...)
+	if err != nil {
+		return err
+	}
+
+	wctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	queues := make([]chan *Delivery, len(d.Endpoints))
+	for i := range d.Endpoints {
+		queues[i] = make(chan *Delivery, d.queueSize())
+
+		wg.Add(1)
+		go func(e *Endpoint, q chan *Delivery) {
+			defer wg.Done()
+			d.worker(wctx, e, q)
+		}(&d.Endpoints[i], queues[i])
+	}
+
+	defer func() {
+		for _, q := range queues {
+			close(q)
+		}
+
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-ch:
+			if !ok {
+				return nil
+			}
+
+			d.dispatch(e, queues)
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(e push.Event, queues []chan *Delivery) {
+	now := time.Now()
+	for i := range d.Endpoints {
+		if !d.Endpoints[i].wants(e.Type) {
+			continue
+		}
+
+		del := &Delivery{ID: newID(), URL: d.Endpoints[i].URL, Event: e, Time: now}
+		select {
+		case queues[i] <- del:
+		default:
+			d.dead(del, ErrQueueFull)
+		}
+	}
+}
+
+// worker delivers the deliveries of q to e until q is closed, once ctx is done
+// the remaining deliveries are handed to the DeadLetter.
+func (d *Dispatcher) worker(ctx context.Context, e *Endpoint, q <-chan *Delivery) {
+	for del := range q {
+		if ctx.Err() != nil {
+			d.dead(del, ctx.Err())
+			continue
+		}
+
+		if err := d.deliver(ctx, e, del); err != nil {
+			d.dead(del, err)
+		}
+	}
+}
+
+// Redeliver delivers again a delivery that failed, e.g. loaded from a
+// FileDeadLetter, following the retry policy. The endpoint is looked up by
+// URL.
+func (d *Dispatcher) Redeliver(ctx context.Context, del *Delivery) error {
+	for i := range d.Endpoints {
+		if d.Endpoints[i].URL == del.URL {
+			return d.deliver(ctx, &d.Endpoints[i], del)
+		}
+	}
+
+	return fmt.Errorf("unknown endpoint %q", del.URL)
+}
+
+// deliver sends the delivery to e, retrying following the retry policy.
+func (d *Dispatcher) deliver(ctx context.Context, e *Endpoint, del *Delivery) error {
+	p := d.retryPolicy()
+	for attempt := 1; ; attempt++ {
+		del.Attempts++
+		retry, err := d.send(ctx, e, del)
+		if err == nil {
+			del.Error = ""
+			return nil
+		}
+
+		del.Error = err.Error()
+		d.error(fmt.Errorf("error delivering %s to %s: %w", del.Event.Type, e.URL, err))
+		if !retry || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(p.backoff(attempt)):
+		}
+	}
+}
+
+// send sends a single attempt, returning whether it can be retried if failed.
+func (d *Dispatcher) send(ctx context.Context, e *Endpoint, del *Delivery) (bool, error) {
+	payload := del.Event.Payload
+	if len(payload) == 0 {
+		payload = json.RawMessage("null")
+	}
+
+	b, err := json.Marshal(&body{ID: del.ID, Type: del.Event.Type, Payload: payload, Time: del.Time})
+	if err != nil {
+		return false, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", e.URL, bytes.NewReader(b))
+	if err != nil {
+		return false, err
+	}
+
+	for k, v := range e.Header {
+		req.Header[k] = v
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("go-octoprint/%s", octoprint.Version))
+	req.Header.Set(HeaderEvent, string(del.Event.Type))
+	req.Header.Set(HeaderDelivery, del.ID)
+	if e.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(e.Secret, b))
+	}
+
+	resp, err := d.client().Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+
+	return retry, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+}
+
+func (d *Dispatcher) dead(del *Delivery, err error) {
+	del.Error = err.Error()
+	if d.DeadLetter == nil {
+		return
+	}
+
+	if err := d.DeadLetter.Put(del); err != nil {
+		d.error(fmt.Errorf("error storing dead letter %s: %w", del.ID, err))
+	}
+}
+
+// eventTypes returns the types of events of all the endpoints, nil if any of
+// them wants all the events.
+func (d *Dispatcher) eventTypes() []push.EventType {
+	seen := make(map[push.EventType]bool)
+	var types []push.EventType
+	for _, e := range d.Endpoints {
+		if len(e.Events) == 0 {
+			return nil
+		}
+
+		for _, t := range e.Events {
+			if !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		}
+	}
+
+	return types
+}
+
+func (d *Dispatcher) client() *http.Client {
+	if d.Client == nil {
+		return http.DefaultClient
+	}
+
+	return d.Client
+}
+
+func (d *Dispatcher) retryPolicy() *RetryPolicy {
+	if d.Retry == (RetryPolicy{}) {
+		return &DefaultRetryPolicy
+	}
+
+	return &d.Retry
+}
+
+func (d *Dispatcher) queueSize() int {
+	if d.QueueSize <= 0 {
+		return DefaultQueueSize
+	}
+
+	return d.QueueSize
+}
+
+func (d *Dispatcher) error(err error) {
+	if d.OnError != nil {
+		d.OnError(err)
+	}
+}
+
+// Sign returns the signature of body with the given secret, as sent in the
+// HeaderSignature header.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns true if signature is the valid signature of body with the
+// given secret, to be used by the receivers of the requests.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mcuadros/go-octoprint/push"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEvents chan push.Event
+
+func (e fakeEvents) Subscribe(ctx context.Context, events ...push.EventType) (<-chan push.Event, error) {
+	return e, nil
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	var attempts int
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		b, _ := ioutil.ReadAll(r.Body)
+		assert.True(t, Verify("secret", b, r.Header.Get(HeaderSignature)))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer foo", r.Header.Get("Authorization"))
+
+		// the first attempt fails, and is retried.
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var body struct {
+			ID      string
+			Type    push.EventType
+			Payload json.RawMessage
+		}
+
+		assert.NoError(t, json.Unmarshal(b, &body))
+		assert.Equal(t, r.Header.Get(HeaderDelivery), body.ID)
+		received = append(received, string(body.Type)+" "+string(body.Payload))
+	}))
+	defer ok.Close()
+
+	var rejected int
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Empty(t, r.Header.Get(HeaderSignature))
+		rejected++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer rejecting.Close()
+
+	dir, err := ioutil.TempDir("", "webhook")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	dl := NewFileDeadLetter(filepath.Join(dir, "dead.jsonl"))
+
+	var errs int
+	d := &Dispatcher{
+		Endpoints: []Endpoint{{
+			URL:    ok.URL,
+			Secret: "secret",
+			Events: []push.EventType{push.EventPrintDone},
+			Header: http.Header{"Authorization": {"Bearer foo"}},
+		}, {
+			URL: rejecting.URL,
+		}},
+		Retry:      RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond},
+		DeadLetter: dl,
+		OnError: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs++
+		},
+	}
+
+	assert.Nil(t, d.eventTypes())
+
+	events := make(fakeEvents, 2)
+	events <- push.Event{Type: push.EventPrintDone, Payload: []byte(`{"name":"cube.gcode"}`)}
+	events <- push.Event{Type: push.EventPrintStarted}
+	close(events)
+
+	assert.NoError(t, d.Run(context.Background(), events))
+
+	assert.Equal(t, []string{`PrintDone {"name":"cube.gcode"}`}, received)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, 2, rejected)
+	assert.Equal(t, 3, errs)
+
+	dead, err := dl.Load()
+	assert.NoError(t, err)
+	assert.Len(t, dead, 2)
+	for _, del := range dead {
+		assert.Equal(t, rejecting.URL, del.URL)
+		assert.Equal(t, 1, del.Attempts)
+		assert.Equal(t, "unexpected status code 400", del.Error)
+	}
+
+	// the dead letters can be delivered again.
+	d.Endpoints[1].URL = ok.URL
+	dead[0].URL = ok.URL
+	assert.NoError(t, d.Redeliver(context.Background(), dead[0]))
+	assert.Len(t, received, 2)
+	assert.Equal(t, 2, dead[0].Attempts)
+
+	assert.NoError(t, dl.Clear())
+	dead, err = dl.Load()
+	assert.NoError(t, err)
+	assert.Len(t, dead, 0)
+}
+
+func TestDispatcher_EventTypes(t *testing.T) {
+	d := &Dispatcher{Endpoints: []Endpoint{
+		{Events: []push.EventType{push.EventPrintDone, push.EventPrintFailed}},
+		{Events: []push.EventType{push.EventPrintFailed}},
+	}}
+
+	assert.Equal(t, []push.EventType{push.EventPrintDone, push.EventPrintFailed}, d.eventTypes())
+}
+
+func TestSign(t *testing.T) {
+	sig := Sign("secret", []byte(`{}`))
+	assert.Equal(t, "sha256=77325902caca812dc259733aacd046b73817372c777b8d95b402647474516e13", sig)
+	assert.True(t, Verify("secret", []byte(`{}`), sig))
+	assert.False(t, Verify("other", []byte(`{}`), sig))
+}
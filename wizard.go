@@ -0,0 +1,50 @@
+package octoprint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+const URIWizard = "/api/setup/wizard"
+
+var WizardErrors = statusMapping{
+	403: "The user is not an admin",
+}
+
+// WizardRequest retrieves the wizards provided by OctoPrint and its plugins,
+// and whether they are required to complete the setup.
+type WizardRequest struct{}
+
+// Do sends an API request and returns the API response.
+func (cmd *WizardRequest) Do(ctx context.Context, c *Client) (WizardResponse, error) {
+	b, err := c.doJSONRequest(ctx, "GET", URIWizard, nil, WizardErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	r := WizardResponse{}
+	if err := c.decode(URIWizard, b, &r); err != nil {
+		return nil, err
+	}
+
+	return r, err
+}
+
+// WizardFinishRequest marks the given wizards as finished, completing the first
+// run setup if all the required wizards are handled.
+type WizardFinishRequest struct {
+	// Handled are the names of the wizards handled.
+	Handled []string `json:"handled"`
+}
+
+// Do sends an API request and returns an error if any.
+func (cmd *WizardFinishRequest) Do(ctx context.Context, c *Client) error {
+	b := bytes.NewBuffer(nil)
+	if err := json.NewEncoder(b).Encode(cmd); err != nil {
+		return err
+	}
+
+	_, err := c.doJSONRequest(ctx, "POST", URIWizard, b, WizardErrors)
+	return err
+}
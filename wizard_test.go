@@ -0,0 +1,47 @@
+package octoprint
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWizardRequest_Do(t *testing.T) {
+	var body string
+	var paths []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Method == "POST" {
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+			w.WriteHeader(204)
+			return
+		}
+
+		w.Write([]byte(`{
+			"corewizard": {"required": true, "ignored": false, "version": 3, "details": {"required": ["acl"]}},
+			"tracking": {"required": true, "ignored": false, "version": null, "details": {}},
+			"softwareupdate": {"required": false, "ignored": false, "version": 1, "details": {}}
+		}`))
+	}))
+	defer ts.Close()
+
+	cli := NewClient(ts.URL, "")
+	r, err := (&WizardRequest{}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.Len(t, r, 3)
+	assert.Equal(t, 3, *r["corewizard"].Version)
+	assert.Nil(t, r["tracking"].Version)
+
+	required := r.Required()
+	assert.Equal(t, []string{"corewizard", "tracking"}, required)
+
+	err = (&WizardFinishRequest{Handled: required}).Do(context.Background(), cli)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"handled": ["corewizard", "tracking"]}`, body)
+	assert.Equal(t, []string{"/api/setup/wizard", "/api/setup/wizard"}, paths)
+}